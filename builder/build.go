@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/gofrs/flock"
+	"github.com/tinygo-org/tinygo/cgo"
 	"github.com/tinygo-org/tinygo/compileopts"
 	"github.com/tinygo-org/tinygo/compiler"
 	"github.com/tinygo-org/tinygo/goenv"
@@ -144,6 +145,12 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			return errors.New("could not find wasi-libc, perhaps you need to run `make wasi-libc`?")
 		}
 		libcDependencies = append(libcDependencies, dummyCompileJob(path))
+	case "wasi-libc-threads":
+		path := filepath.Join(root, "lib/wasi-libc/sysroot-threads/lib/wasm32-wasi/libc.a")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return errors.New("could not find wasi-libc with threads support, perhaps you need to run `make wasi-libc-threads`?")
+		}
+		libcDependencies = append(libcDependencies, dummyCompileJob(path))
 	case "mingw-w64":
 		_, unlock, err := MinGW.load(config, dir)
 		if err != nil {
@@ -172,6 +179,7 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		AutomaticStackSize: config.AutomaticStackSize(),
 		DefaultStackSize:   config.Target.DefaultStackSize,
 		NeedsStackObjects:  config.NeedsStackObjects(),
+		BuildMode:          config.BuildMode(),
 		Debug:              true,
 	}
 
@@ -427,6 +435,7 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	// Add job that links and optimizes all packages together.
 	var mod llvm.Module
 	var stackSizeLoads []string
+	var asyncifyRemoveList []string
 	programJob := &compileJob{
 		description:  "link+optimize packages (LTO)",
 		dependencies: packageJobs,
@@ -508,6 +517,12 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 			if config.AutomaticStackSize() {
 				stackSizeLoads = transform.CreateStackSizeLoads(mod, config)
 			}
+
+			// Find the functions that never pause a goroutine, so that
+			// wasm-opt doesn't need to instrument them.
+			if config.Scheduler() == "asyncify" && !config.Options.AsyncifyAll {
+				asyncifyRemoveList = transform.AsyncifyRemoveList(mod)
+			}
 			return nil
 		},
 	}
@@ -608,17 +623,68 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 		linkerDependencies = append(linkerDependencies, job)
 	}
 
-	// Add jobs to compile C files in all packages. This is part of CGo.
+	// Link libc++ and libc++abi if there are C++ files and the target has a
+	// libc to build them on. As a side effect, this creates the libc++ headers
+	// used to compile the C++ files.
+	if config.HasLibCXX() && hasCXXFiles(lprogram) {
+		if _, err := os.Stat(filepath.Join(llvmSourceDir(), "libcxx")); err != nil {
+			return errors.New("could not find the libc++ sources, perhaps you need to run `make llvm-source`?")
+		}
+		job, unlock, err := LibCXX.load(config, dir)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		linkerDependencies = append(linkerDependencies, job)
+	}
+
+	// Add jobs to compile C, C++ and assembly files in all packages. This is
+	// part of CGo.
+	// Like with the gc toolchain, C++ and assembly files are only compiled in
+	// packages that use CGo: assembly files in other packages (such as the
+	// runtime) are included through the target extra files when needed.
+	// See Config.CXXFlags for the C++ features that can be used.
 	// TODO: do this as part of building the package to be able to link the
 	// bitcode files together.
 	for _, pkg := range lprogram.Sorted() {
 		pkg := pkg
+		type sourceFile struct {
+			name   string
+			cflags []string
+		}
+		cflags, cxxflags := pkg.CFlags, pkg.CXXFlags
+		if len(pkg.CgoFiles) != 0 {
+			// Make the _cgo_export.h header available to C and C++ files in
+			// this package, if the package exports any functions.
+			header := cgo.ExportHeader(pkg.Pkg, pkg.Files, pkg.CGoHeaders)
+			if header != "" {
+				includeDir, err := writeCGoExportHeader(cacheDir, header)
+				if err != nil {
+					return err
+				}
+				cflags = append(cflags[:len(cflags):len(cflags)], "-I"+includeDir)
+				cxxflags = append(cxxflags[:len(cxxflags):len(cxxflags)], "-I"+includeDir)
+			}
+		}
+		var files []sourceFile
 		for _, filename := range pkg.CFiles {
-			abspath := filepath.Join(pkg.Dir, filename)
+			files = append(files, sourceFile{filename, cflags})
+		}
+		if len(pkg.CgoFiles) != 0 {
+			for _, filename := range pkg.CXXFiles {
+				files = append(files, sourceFile{filename, cxxflags})
+			}
+			for _, filename := range pkg.SFiles {
+				files = append(files, sourceFile{filename, cflags})
+			}
+		}
+		for _, file := range files {
+			abspath := filepath.Join(pkg.Dir, file.name)
+			cflags := file.cflags
 			job := &compileJob{
 				description: "compile CGo file " + abspath,
 				run: func(job *compileJob) error {
-					result, err := compileAndCacheCFile(abspath, dir, pkg.CFlags, config.UseThinLTO(), config.Options.PrintCommands)
+					result, err := compileAndCacheCFile(abspath, dir, cflags, config.UseThinLTO(), config.Options.PrintCommands)
 					job.result = result
 					return err
 				},
@@ -757,10 +823,24 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 				default:
 					return fmt.Errorf("unknown opt level: %q", config.Options.Opt)
 				}
-				cmd := exec.Command(goenv.Get("WASMOPT"), "--asyncify", "-g",
+				args := []string{"--asyncify", "-g",
 					"--optimize-level", strconv.Itoa(optLevel),
-					"--shrink-level", strconv.Itoa(shrinkLevel),
-					executable, "--output", executable)
+					"--shrink-level", strconv.Itoa(shrinkLevel)}
+				if !config.Options.AsyncifyAll {
+					// Only instrument functions that may pause a goroutine.
+					// The list of functions that don't is usually too long
+					// for the command line, so it is passed in a file.
+					removeListPath := filepath.Join(dir, "asyncify-removelist.txt")
+					err := ioutil.WriteFile(removeListPath, []byte(strings.Join(asyncifyRemoveList, ",")), 0666)
+					if err != nil {
+						return err
+					}
+					args = append(args,
+						"--pass-arg=asyncify-ignore-imports",
+						"--pass-arg=asyncify-removelist@@"+removeListPath)
+				}
+				args = append(args, executable, "--output", executable)
+				cmd := exec.Command(goenv.Get("WASMOPT"), args...)
 				cmd.Stdout = os.Stdout
 				cmd.Stderr = os.Stderr
 
@@ -874,6 +954,17 @@ func Build(pkgName, outpath string, config *compileopts.Config, action func(Buil
 	})
 }
 
+// hasCXXFiles returns whether any package in the program has C++ files that
+// are compiled, which is only the case for packages that use CGo.
+func hasCXXFiles(lprogram *loader.Program) bool {
+	for _, pkg := range lprogram.Sorted() {
+		if len(pkg.CgoFiles) != 0 && len(pkg.CXXFiles) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // optimizeProgram runs a series of optimizations and transformations that are
 // needed to convert a program to its final form. Some transformations are not
 // optional and must be run as the compiler expects them to run.
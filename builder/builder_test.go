@@ -36,6 +36,7 @@ func TestClangAttributes(t *testing.T) {
 		"nintendoswitch",
 		"riscv-qemu",
 		"wasi",
+		"wasi-threads",
 		"wasm",
 	}
 	if hasBuiltinTools {
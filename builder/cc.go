@@ -233,6 +233,42 @@ func makeCFileCachePath(paths []string, depfileNameHash, ext string) (string, er
 	return outpath, nil
 }
 
+// writeCGoExportHeader stores a _cgo_export.h header in a directory in the
+// cache that is named after the contents of the header, and returns this
+// directory. Because the path only changes when the header changes, C files
+// that include the header can be cached like any other C file.
+func writeCGoExportHeader(cacheDir, header string) (string, error) {
+	hash := sha512.Sum512_224([]byte(header))
+	dir := filepath.Join(cacheDir, "cgo-export-"+hex.EncodeToString(hash[:]))
+	path := filepath.Join(dir, "_cgo_export.h")
+	if _, err := os.Stat(path); err == nil {
+		return dir, nil
+	}
+	err := os.MkdirAll(dir, 0777)
+	if err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first, so that a parallel build never sees a
+	// partially written header.
+	f, err := ioutil.TempFile(dir, "tmp-*.h")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.WriteString(header)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	err = f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return dir, os.Rename(f.Name(), path)
+}
+
 // hashFile hashes the given file path and returns the hash as a hex string.
 func hashFile(path string) (string, error) {
 	f, err := os.Open(path)
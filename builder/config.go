@@ -37,13 +37,43 @@ func NewConfig(options *compileopts.Options) (*compileopts.Config, error) {
 		return nil, fmt.Errorf("requires go version 1.15 through 1.17, got go%d.%d", major, minor)
 	}
 
+	if options.BuildMode == "c-shared" {
+		isWASI := false
+		for _, tag := range spec.BuildTags {
+			if tag == "wasi" {
+				isWASI = true
+			}
+		}
+		if !isWASI {
+			return nil, errors.New("-buildmode=c-shared is only supported on WASI")
+		}
+	}
+
 	clangHeaderPath := getClangHeaderPath(goenv.Get("TINYGOROOT"))
 
-	return &compileopts.Config{
+	config := &compileopts.Config{
 		Options:        options,
 		Target:         spec,
 		GoMinorVersion: minor,
 		ClangHeaders:   clangHeaderPath,
 		TestConfig:     options.TestConfig,
-	}, nil
+	}
+
+	for _, tag := range spec.BuildTags {
+		if tag == "wasi_threads" {
+			// Only the conservative GC and the asyncify scheduler know how to
+			// deal with goroutines running on multiple threads.
+			if config.GC() != "conservative" {
+				return nil, fmt.Errorf("-gc=%s is not supported with threads, only -gc=conservative is", config.GC())
+			}
+			if config.Scheduler() != "asyncify" {
+				return nil, fmt.Errorf("-scheduler=%s is not supported with threads, only -scheduler=asyncify is", config.Scheduler())
+			}
+			if options.BuildMode == "c-shared" {
+				return nil, errors.New("-buildmode=c-shared is not supported with threads")
+			}
+		}
+	}
+
+	return config, nil
 }
@@ -0,0 +1,175 @@
+package builder
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/goenv"
+)
+
+// LibCXX is the C++ standard library (libc++) together with the C++ ABI
+// library it is built on (libc++abi), for C++ files in CGo packages. It is
+// built from the LLVM sources, on top of the target libc.
+//
+// Both are built in the configuration that fits TinyGo: without threads (all
+// goroutines run on one thread), localization, filesystem support and random
+// device, and without exceptions as there is no unwinder. The new and delete
+// operators come from libc++abi and allocate with malloc.
+var LibCXX = Library{
+	name:      "libcxx",
+	needsLibc: true,
+	makeHeaders: func(target, includeDir string) error {
+		// Use the libc++ headers with a __config_site for this target, and
+		// the libc++abi headers (such as cxxabi.h).
+		for _, dir := range []string{"libcxx/include", "libcxxabi/include"} {
+			err := copyHeaders(filepath.Join(llvmSourceDir(), dir), includeDir)
+			if err != nil {
+				return err
+			}
+		}
+		config := []string{
+			"#define _LIBCPP_ABI_VERSION 1",
+			"#define _LIBCPP_ABI_NAMESPACE __1",
+			"#define _LIBCPP_HAS_NO_VENDOR_AVAILABILITY_ANNOTATIONS",
+			"#define _LIBCPP_HAS_NO_THREADS",
+			"#define _LIBCPP_HAS_NO_LOCALIZATION",
+			"#define _LIBCPP_HAS_NO_FILESYSTEM_LIBRARY",
+			"#define _LIBCPP_HAS_NO_RANDOM_DEVICE",
+		}
+		switch {
+		case strings.Contains(target, "-linux"):
+			// musl can't be detected from its headers.
+			config = append(config, "#define _LIBCPP_HAS_MUSL_LIBC")
+		case strings.HasPrefix(target, "wasm"):
+			// WASI is detected by libc++ itself.
+		default:
+			// Baremetal targets with picolibc have no monotonic clock.
+			config = append(config, "#define _LIBCPP_HAS_NO_MONOTONIC_CLOCK")
+		}
+		data := "#ifndef _LIBCPP_CONFIG_SITE\n#define _LIBCPP_CONFIG_SITE\n\n" + strings.Join(config, "\n") + "\n\n#endif // _LIBCPP_CONFIG_SITE\n"
+		return ioutil.WriteFile(filepath.Join(includeDir, "__config_site"), []byte(data), 0o666)
+	},
+	cflags: func(target, headerPath string) []string {
+		llvmDir := llvmSourceDir()
+		return []string{
+			"-Wall",
+			"-std=gnu++20",
+			"-nostdinc++",
+			"-fno-exceptions",
+			"-fvisibility-inlines-hidden",
+			"-D_LIBCPP_BUILDING_LIBRARY",
+			"-D_LIBCPP_DISABLE_NEW_DELETE_DEFINITIONS", // provided by libc++abi
+			"-DLIBCXX_BUILDING_LIBCXXABI",
+			"-D_LIBCXXABI_BUILDING_LIBRARY",
+			"-D_LIBCXXABI_HAS_NO_THREADS",
+			"-D_LIBCXXABI_NO_EXCEPTIONS",
+			"-isystem", headerPath,
+			"-I" + llvmDir + "/libcxx/src",
+			"-I" + llvmDir + "/libcxxabi/src",
+		}
+	},
+	sourceDir: func() string {
+		return llvmSourceDir()
+	},
+	librarySources: func(target string) []string {
+		return libcxxSources
+	},
+}
+
+// The libc++ and libc++abi sources that are part of the configuration above,
+// according to their CMakeLists.txt files.
+var libcxxSources = []string{
+	"libcxx/src/algorithm.cpp",
+	"libcxx/src/any.cpp",
+	"libcxx/src/bind.cpp",
+	"libcxx/src/charconv.cpp",
+	"libcxx/src/chrono.cpp",
+	"libcxx/src/debug.cpp",
+	"libcxx/src/exception.cpp",
+	"libcxx/src/format.cpp",
+	"libcxx/src/functional.cpp",
+	"libcxx/src/hash.cpp",
+	"libcxx/src/legacy_pointer_safety.cpp",
+	"libcxx/src/memory.cpp",
+	"libcxx/src/new.cpp",
+	"libcxx/src/optional.cpp",
+	"libcxx/src/random.cpp",
+	"libcxx/src/random_shuffle.cpp",
+	"libcxx/src/stdexcept.cpp",
+	"libcxx/src/string.cpp",
+	"libcxx/src/system_error.cpp",
+	"libcxx/src/typeinfo.cpp",
+	"libcxx/src/utility.cpp",
+	"libcxx/src/valarray.cpp",
+	"libcxx/src/variant.cpp",
+	"libcxx/src/vector.cpp",
+
+	"libcxxabi/src/abort_message.cpp",
+	"libcxxabi/src/cxa_aux_runtime.cpp",
+	"libcxxabi/src/cxa_default_handlers.cpp",
+	"libcxxabi/src/cxa_demangle.cpp",
+	"libcxxabi/src/cxa_exception_storage.cpp",
+	"libcxxabi/src/cxa_guard.cpp",
+	"libcxxabi/src/cxa_handlers.cpp",
+	"libcxxabi/src/cxa_noexception.cpp",
+	"libcxxabi/src/cxa_vector.cpp",
+	"libcxxabi/src/cxa_virtual.cpp",
+	"libcxxabi/src/fallback_malloc.cpp",
+	"libcxxabi/src/private_typeinfo.cpp",
+	"libcxxabi/src/stdlib_exception.cpp",
+	"libcxxabi/src/stdlib_new_delete.cpp",
+	"libcxxabi/src/stdlib_stdexcept.cpp",
+	"libcxxabi/src/stdlib_typeinfo.cpp",
+}
+
+// llvmSourceDir returns the directory with the libc++ and libc++abi sources:
+// the llvm-project checkout when running from the source directory, or the
+// copy in the installation directory.
+func llvmSourceDir() string {
+	root := goenv.Get("TINYGOROOT")
+	dir := filepath.Join(root, "llvm-project")
+	if _, err := os.Stat(filepath.Join(dir, "libcxx")); err == nil {
+		return dir
+	}
+	return filepath.Join(root, "lib")
+}
+
+// copyHeaders copies the header files in srcDir (recursively) to dstDir.
+// Files that are inputs for the CMake build, like __config_site.in, are left
+// out.
+func copyHeaders(srcDir, dstDir string) error {
+	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dstDir, rel)
+		if info.IsDir() {
+			return os.MkdirAll(dst, 0o777)
+		}
+		if strings.HasSuffix(path, ".in") || strings.HasSuffix(path, ".txt") {
+			return nil
+		}
+		inf, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer inf.Close()
+		outf, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(outf, inf)
+		if err != nil {
+			outf.Close()
+			return err
+		}
+		return outf.Close()
+	})
+}
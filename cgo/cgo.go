@@ -12,12 +12,16 @@ package cgo
 // source file parsing.
 
 import (
+	"bytes"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/scanner"
 	"go/token"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,8 +35,10 @@ type cgoPackage struct {
 	generated       *ast.File
 	generatedPos    token.Pos
 	errors          []error
-	currentDir      string // current working directory
-	packageDir      string // full path to the package to process
+	currentDir      string          // current working directory
+	packageDir      string          // full path to the package to process
+	buildTags       map[string]bool // build tags that are set, for #cgo lines
+	sysroot         string          // --sysroot of the target, for pkg-config
 	fset            *token.FileSet
 	tokenFiles      map[string]*token.File
 	missingSymbols  map[string]struct{}
@@ -44,6 +50,7 @@ type cgoPackage struct {
 	enums           map[string]enumInfo
 	anonStructNum   int
 	cflags          []string // CFlags from #cgo lines
+	cxxflags        []string // CXXFlags from #cgo lines
 	ldflags         []string // LDFlags from #cgo lines
 	visitedFiles    map[string][]byte
 }
@@ -79,11 +86,12 @@ type typedefInfo struct {
 // elaboratedTypeInfo contains some information about an elaborated type
 // (struct, union) found in the C AST.
 type elaboratedTypeInfo struct {
-	typeExpr   *ast.StructType
-	pos        token.Pos
-	bitfields  []bitfieldInfo
-	unionSize  int64 // union size in bytes, nonzero when union getters/setters should be created
-	unionAlign int64 // union alignment in bytes
+	typeExpr     *ast.StructType
+	pos          token.Pos
+	bitfields    []bitfieldInfo
+	packedFields []packedFieldInfo
+	unionSize    int64 // union size in bytes, nonzero when union getters/setters should be created
+	unionAlign   int64 // union alignment in bytes
 }
 
 // bitfieldInfo contains information about a single bitfield in a struct. It
@@ -97,6 +105,27 @@ type bitfieldInfo struct {
 	endBit   int64 // may be 0 meaning "until the end of the field"
 }
 
+// fieldLayout contains the offset, size, and alignment in bytes of a struct
+// field as reported by Clang.
+type fieldLayout struct {
+	field  *ast.Field
+	offset int64
+	size   int64
+	align  int64
+}
+
+// packedFieldInfo contains information about a struct field that is not aligned
+// as its type requires, for example in a packed struct. Such a field is stored
+// as a byte array (the renamed field) and accessed through a getter and
+// setter.
+type packedFieldInfo struct {
+	field    *ast.Field
+	name     string
+	typeExpr ast.Expr // original type of the field
+	size     int64
+	pos      token.Pos
+}
+
 // enumInfo contains information about an enum in the C.
 type enumInfo struct {
 	typeExpr ast.Expr
@@ -194,12 +223,15 @@ func GoBytes(ptr unsafe.Pointer, length C.int) []byte {
 // with libclang, and modifies the AST to use this information. It returns a
 // newly created *ast.File that should be added to the list of to-be-parsed
 // files, the CGo header snippets that should be compiled (for inline
-// functions), the CFLAGS and LDFLAGS found in #cgo lines, and a map of file
-// hashes of the accessed C header files. If there is one or more error, it
-// returns these in the []error slice but still modifies the AST.
-func Process(files []*ast.File, dir string, fset *token.FileSet, cflags []string, clangHeaders string) (*ast.File, []string, []string, []string, map[string][]byte, []error) {
+// functions), the CFLAGS, CXXFLAGS and LDFLAGS found in #cgo lines (where
+// CPPFLAGS are included in both CFLAGS and CXXFLAGS), and a map of file
+// hashes of the accessed C header files. The build tags are used for the build
+// constraints in #cgo lines. If there is one or more error, it returns these in
+// the []error slice but still modifies the AST.
+func Process(files []*ast.File, dir string, fset *token.FileSet, cflags []string, buildTags []string, clangHeaders string) (*ast.File, []string, []string, []string, []string, map[string][]byte, []error) {
 	p := &cgoPackage{
 		currentDir:      dir,
+		buildTags:       map[string]bool{"cgo": true},
 		fset:            fset,
 		tokenFiles:      map[string]*token.File{},
 		missingSymbols:  map[string]struct{}{},
@@ -211,6 +243,14 @@ func Process(files []*ast.File, dir string, fset *token.FileSet, cflags []string
 		enums:           map[string]enumInfo{},
 		visitedFiles:    map[string][]byte{},
 	}
+	for _, tag := range buildTags {
+		p.buildTags[tag] = true
+	}
+	for _, flag := range cflags {
+		if strings.HasPrefix(flag, "--sysroot=") {
+			p.sysroot = flag[len("--sysroot="):]
+		}
+	}
 
 	// Add a new location for the following file.
 	generatedTokenPos := p.fset.AddFile(dir+"/!cgo.go", -1, 0)
@@ -220,7 +260,7 @@ func Process(files []*ast.File, dir string, fset *token.FileSet, cflags []string
 	// Find the absolute path for this package.
 	packagePath, err := filepath.Abs(fset.File(files[0].Pos()).Name())
 	if err != nil {
-		return nil, nil, nil, nil, nil, []error{
+		return nil, nil, nil, nil, nil, nil, []error{
 			scanner.Error{
 				Pos: fset.Position(files[0].Pos()),
 				Msg: "cgo: cannot find absolute path: " + err.Error(), // TODO: wrap this error
@@ -374,7 +414,7 @@ func Process(files []*ast.File, dir string, fset *token.FileSet, cflags []string
 	// Print the newly generated in-memory AST, for debugging.
 	//ast.Print(fset, p.generated)
 
-	return p.generated, cgoHeaders, p.cflags, p.ldflags, p.visitedFiles, p.errors
+	return p.generated, cgoHeaders, p.cflags, p.cxxflags, p.ldflags, p.visitedFiles, p.errors
 }
 
 // makePathsAbsolute converts some common path compiler flags (-I, -L) from
@@ -434,22 +474,22 @@ func (p *cgoPackage) parseCGoPreprocessorLines(text string, pos token.Pos) strin
 		}
 
 		// Extract the fields before the colon. These fields are a list
-		// of build tags and the C environment variable.
+		// of build constraints and the C environment variable.
 		fields := strings.Fields(line[4:colon])
 		if len(fields) == 0 {
 			p.addErrorAfter(pos, text[:lineStart+colon-1], "invalid #cgo line")
 			continue
 		}
 
-		if len(fields) > 1 {
-			p.addErrorAfter(pos, text[:lineStart+5], "not implemented: build constraints in #cgo line")
+		if len(fields) > 1 && !p.matchBuildConstraints(fields[:len(fields)-1]) {
+			// This line is for a different target.
 			continue
 		}
 
 		name := fields[len(fields)-1]
 		value := line[colon+1:]
 		switch name {
-		case "CFLAGS":
+		case "CFLAGS", "CPPFLAGS", "CXXFLAGS":
 			flags, err := shlex.Split(value)
 			if err != nil {
 				// TODO: find the exact location where the error happened.
@@ -461,7 +501,12 @@ func (p *cgoPackage) parseCGoPreprocessorLines(text string, pos token.Pos) strin
 				continue
 			}
 			p.makePathsAbsolute(flags)
-			p.cflags = append(p.cflags, flags...)
+			if name != "CXXFLAGS" {
+				p.cflags = append(p.cflags, flags...)
+			}
+			if name != "CFLAGS" {
+				p.cxxflags = append(p.cxxflags, flags...)
+			}
 		case "LDFLAGS":
 			flags, err := shlex.Split(value)
 			if err != nil {
@@ -475,6 +520,29 @@ func (p *cgoPackage) parseCGoPreprocessorLines(text string, pos token.Pos) strin
 			}
 			p.makePathsAbsolute(flags)
 			p.ldflags = append(p.ldflags, flags...)
+		case "pkg-config":
+			args, err := shlex.Split(value)
+			if err != nil {
+				// TODO: find the exact location where the error happened.
+				p.addErrorAfter(pos, text[:lineStart+colon+1], "failed to parse flags in #cgo line: "+err.Error())
+				continue
+			}
+			cflags, ldflags, err := p.pkgConfig(args)
+			if err != nil {
+				p.addErrorAfter(pos, text[:lineStart+colon+1], err.Error())
+				continue
+			}
+			if err := checkCompilerFlags("CFLAGS", cflags); err != nil {
+				p.addErrorAfter(pos, text[:lineStart+colon+1], "pkg-config --cflags: "+err.Error())
+				continue
+			}
+			if err := checkLinkerFlags("LDFLAGS", ldflags); err != nil {
+				p.addErrorAfter(pos, text[:lineStart+colon+1], "pkg-config --libs: "+err.Error())
+				continue
+			}
+			p.cflags = append(p.cflags, cflags...)
+			p.cxxflags = append(p.cxxflags, cflags...)
+			p.ldflags = append(p.ldflags, ldflags...)
 		default:
 			startPos := strings.LastIndex(line[4:colon], name) + 4
 			p.addErrorAfter(pos, text[:lineStart+startPos], "invalid #cgo line: "+name)
@@ -484,6 +552,115 @@ func (p *cgoPackage) parseCGoPreprocessorLines(text string, pos token.Pos) strin
 	return text
 }
 
+// matchBuildConstraints returns whether one of the build constraints of a #cgo
+// line matches. Each constraint is a comma separated list of build tags that
+// must all be set, or must not be set when prefixed with '!', like in
+// "#cgo linux,!arm darwin LDFLAGS: -lfoo".
+func (p *cgoPackage) matchBuildConstraints(constraints []string) bool {
+	for _, constraint := range constraints {
+		match := true
+		for _, tag := range strings.Split(constraint, ",") {
+			if strings.HasPrefix(tag, "!") {
+				match = match && !p.buildTags[tag[1:]]
+			} else {
+				match = match && p.buildTags[tag]
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// pkgConfig runs pkg-config (or $PKG_CONFIG) for the arguments of a
+// "#cgo pkg-config:" line and returns the resulting compiler and linker flags.
+// See pkgConfigEnv for how the libraries of the target are found.
+func (p *cgoPackage) pkgConfig(args []string) (cflags, ldflags []string, err error) {
+	// Arguments starting with "--" are pkg-config flags, the others are
+	// package names. Package names must not look like flags.
+	var pcflags, packages []string
+	for _, arg := range args {
+		if arg == "--" {
+			// Added below.
+		} else if strings.HasPrefix(arg, "--") {
+			pcflags = append(pcflags, arg)
+		} else {
+			if !safeArg(arg) {
+				return nil, nil, fmt.Errorf("invalid pkg-config package name: %s", arg)
+			}
+			packages = append(packages, arg)
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil, fmt.Errorf("no packages in #cgo pkg-config line")
+	}
+
+	env, err := p.pkgConfigEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	command := os.Getenv("PKG_CONFIG")
+	if command == "" {
+		command = "pkg-config"
+	}
+	run := func(flag string) ([]string, error) {
+		cmdArgs := append([]string{flag}, pcflags...)
+		cmdArgs = append(cmdArgs, "--")
+		cmdArgs = append(cmdArgs, packages...)
+		cmd := exec.Command(command, cmdArgs...)
+		cmd.Env = env
+		stderr := &bytes.Buffer{}
+		cmd.Stderr = stderr
+		out, err := cmd.Output()
+		if err != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return nil, fmt.Errorf("%s %s: %s", command, strings.Join(cmdArgs, " "), msg)
+		}
+		return shlex.Split(string(out))
+	}
+	cflags, err = run("--cflags")
+	if err != nil {
+		return nil, nil, err
+	}
+	ldflags, err = run("--libs")
+	if err != nil {
+		return nil, nil, err
+	}
+	return cflags, ldflags, nil
+}
+
+// pkgConfigEnv returns the environment to run pkg-config in. When building for
+// the host, the environment is passed on unchanged. When cross compiling, the
+// .pc files of the host must not be used, as they describe host libraries and
+// include paths. If $PKG_CONFIG_LIBDIR is set, the user has selected the .pc
+// files for the target. Otherwise, pkg-config only searches the sysroot of the
+// target, and the paths it returns are prefixed with the sysroot. Targets
+// without a sysroot (most baremetal targets) can't use pkg-config without
+// $PKG_CONFIG_LIBDIR.
+func (p *cgoPackage) pkgConfigEnv() ([]string, error) {
+	env := os.Environ()
+	crossCompiling := !p.buildTags[runtime.GOOS] || !p.buildTags[runtime.GOARCH] || p.buildTags["baremetal"]
+	if !crossCompiling || os.Getenv("PKG_CONFIG_LIBDIR") != "" {
+		return env, nil
+	}
+	if p.sysroot == "" {
+		return nil, fmt.Errorf("pkg-config: cannot find libraries for a target without sysroot, set $PKG_CONFIG_LIBDIR to the .pc files for this target")
+	}
+	var libdirs []string
+	for _, dir := range []string{"usr/lib/pkgconfig", "usr/share/pkgconfig", "lib/pkgconfig", "share/pkgconfig"} {
+		libdirs = append(libdirs, filepath.Join(p.sysroot, dir))
+	}
+	env = append(env, "PKG_CONFIG_LIBDIR="+strings.Join(libdirs, string(filepath.ListSeparator)))
+	if os.Getenv("PKG_CONFIG_SYSROOT_DIR") == "" {
+		env = append(env, "PKG_CONFIG_SYSROOT_DIR="+p.sysroot)
+	}
+	return env, nil
+}
+
 // addFuncDecls adds the C function declarations found by libclang in the
 // comment above the `import "C"` statement.
 func (p *cgoPackage) addFuncDecls() {
@@ -839,10 +1016,181 @@ func (p *cgoPackage) addElaboratedTypes() {
 			p.createBitfieldGetter(bitfield, typeName)
 			p.createBitfieldSetter(bitfield, typeName)
 		}
+		// Likewise for fields that are not aligned in Go.
+		for _, field := range typ.packedFields {
+			p.createPackedFieldGetter(field, typeName)
+			p.createPackedFieldSetter(field, typeName)
+		}
 		p.generated.Decls = append(p.generated.Decls, gen)
 	}
 }
 
+// layoutStruct makes sure the Go struct in fieldList has the same layout as the
+// C struct it was created from, as described by the field layouts, size, and
+// alignment (all in bytes) reported by Clang. For regular C structs this
+// doesn't change anything, but structs with packed or aligned attributes (or
+// #pragma pack) may have fields at an offset that Go would not use, or have
+// an alignment that is different from the natural alignment of their fields.
+//
+// Fields that are not aligned as their type requires are replaced with a byte
+// array, named __packed_<name>. These fields must be accessed through getters
+// and setters, which are created for each returned packedFieldInfo. Padding
+// fields are inserted where needed to put the remaining fields at the right
+// offset, and to give the struct the right size and alignment.
+//
+// If the layout can't be expressed as a Go struct, an error is added at pos
+// and the field list is left unmodified. Such layouts are only reported when
+// the natural Go layout of the fields is different from the C layout.
+func (p *cgoPackage) layoutStruct(fieldList *ast.FieldList, layouts []fieldLayout, size, align int64, pos token.Pos) []packedFieldInfo {
+	if size < 0 || align <= 0 || len(layouts) != len(fieldList.List) {
+		// Incomplete struct, there is no layout to check.
+		return nil
+	}
+
+	// Determine whether the C layout is different from the layout Go would
+	// use for these fields.
+	needsLayout := false
+	fieldsAlign := int64(1)
+	for _, f := range layouts {
+		if f.align > 0 && (f.offset%f.align != 0 || f.align > align) {
+			needsLayout = true
+		}
+		if f.align > fieldsAlign {
+			fieldsAlign = f.align
+		}
+	}
+	if align > fieldsAlign {
+		needsLayout = true
+	}
+	unsupported := func(msg string) []packedFieldInfo {
+		if needsLayout {
+			p.addError(pos, msg)
+		}
+		return nil
+	}
+
+	// Check whether the layout can be represented in Go before changing
+	// anything.
+	offset := int64(0)
+	for _, f := range layouts {
+		if f.size < 0 || f.align <= 0 {
+			return unsupported("packed or aligned struct with a field of unknown size is not supported")
+		}
+		if f.offset < offset {
+			// This happens with some bitfields.
+			return unsupported("packed or aligned struct with overlapping fields is not supported")
+		}
+		if f.offset%f.align != 0 || f.align > align {
+			if strings.HasPrefix(f.field.Names[0].Name, "__bitfield_") {
+				// The bitfield getters and setters expect a regular integer
+				// field.
+				return unsupported("unaligned bitfield in a packed or aligned struct is not supported")
+			}
+		}
+		offset = f.offset + f.size
+	}
+	if offset > size {
+		return unsupported("internal error: struct fields extend beyond the end of the struct")
+	}
+	if _, ok := alignmentFieldTypes[align]; !ok && align > 1 {
+		// There is no Go type with this alignment, so the struct can't be
+		// given the right alignment.
+		return unsupported(fmt.Sprintf("struct alignment of %d bytes is not supported", align))
+	}
+
+	var packedFields []packedFieldInfo
+	var fields []*ast.Field
+	goOffset := int64(0)
+	goAlign := int64(1)
+	for _, f := range layouts {
+		fieldAlign := f.align
+		if f.offset%f.align != 0 || f.align > align {
+			// This field is not aligned as its type requires, or it would
+			// increase the alignment of the struct. Store it as a byte array
+			// instead.
+			name := f.field.Names[0].Name
+			packedName := "__packed_" + name
+			packedFields = append(packedFields, packedFieldInfo{
+				field:    f.field,
+				name:     name,
+				typeExpr: f.field.Type,
+				size:     f.size,
+				pos:      f.field.Names[0].NamePos,
+			})
+			f.field.Names[0].Name = packedName
+			f.field.Names[0].Obj.Name = packedName
+			f.field.Type = makeByteArrayType(f.field.Names[0].NamePos, f.size)
+			fieldAlign = 1
+		}
+		if alignUp(goOffset, fieldAlign) != f.offset {
+			fields = append(fields, makePaddingField(f.field.Names[0].NamePos, f.offset-goOffset))
+		}
+		fields = append(fields, f.field)
+		goOffset = f.offset + f.size
+		if fieldAlign > goAlign {
+			goAlign = fieldAlign
+		}
+	}
+	if goAlign < align {
+		// The struct has a larger alignment than its fields, for example
+		// because of an aligned attribute. Add a zero-length field to give
+		// it the same alignment in Go.
+		fields = append([]*ast.Field{{
+			Names: []*ast.Ident{
+				{
+					NamePos: fieldList.Opening,
+					Name:    "_",
+				},
+			},
+			Type: &ast.ArrayType{
+				Lbrack: fieldList.Opening,
+				Len: &ast.BasicLit{
+					ValuePos: fieldList.Opening,
+					Kind:     token.INT,
+					Value:    "0",
+				},
+				Elt: &ast.Ident{
+					NamePos: fieldList.Opening,
+					Name:    alignmentFieldTypes[align],
+				},
+			},
+		}}, fields...)
+		goAlign = align
+	}
+	if alignUp(goOffset, goAlign) != size {
+		fields = append(fields, makePaddingField(fieldList.Closing, size-goOffset))
+	}
+	fieldList.List = fields
+	return packedFields
+}
+
+// alignmentFieldTypes maps an alignment in bytes to a Go type with that
+// alignment, for use in a zero-length array that sets the alignment of a
+// struct.
+var alignmentFieldTypes = map[int64]string{
+	2: "uint16",
+	4: "uint32",
+	8: "uint64",
+}
+
+// makePaddingField returns a blank field of the given number of bytes.
+func makePaddingField(pos token.Pos, size int64) *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{
+			{
+				NamePos: pos,
+				Name:    "_",
+			},
+		},
+		Type: makeByteArrayType(pos, size),
+	}
+}
+
+// alignUp rounds n up to a multiple of align.
+func alignUp(n, align int64) int64 {
+	return (n + align - 1) / align * align
+}
+
 // makeUnionField creates a new struct from an existing *elaboratedTypeInfo,
 // that has just a single field that must be accessed through special accessors.
 // It returns nil when there is an error. In case of an error, that error has
@@ -1304,6 +1652,227 @@ func (p *cgoPackage) createBitfieldSetter(bitfield bitfieldInfo, typeName string
 	p.generated.Decls = append(p.generated.Decls, setter)
 }
 
+// createPackedFieldGetter creates a getter for a field that is not aligned in
+// Go (see layoutStruct), like the following:
+//
+//     func (s *C.struct_foo) packedfield_b() (value C.int) {
+//         copy((*[4]byte)(unsafe.Pointer(&value))[:], s.__packed_b[:])
+//         return
+//     }
+func (p *cgoPackage) createPackedFieldGetter(field packedFieldInfo, typeName string) {
+	pos := field.pos
+	getter := &ast.FuncDecl{
+		Recv: makePackedFieldReceiver(pos, typeName),
+		Name: &ast.Ident{
+			NamePos: pos,
+			Name:    "packedfield_" + field.name,
+		},
+		Type: &ast.FuncType{
+			Func: pos,
+			Params: &ast.FieldList{
+				Opening: pos,
+				Closing: pos,
+			},
+			Results: &ast.FieldList{
+				Opening: pos,
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{
+							{
+								NamePos: pos,
+								Name:    "value",
+							},
+						},
+						Type: field.typeExpr,
+					},
+				},
+				Closing: pos,
+			},
+		},
+		Body: &ast.BlockStmt{
+			Lbrace: pos,
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.Ident{
+							NamePos: pos,
+							Name:    "copy",
+						},
+						Lparen: pos,
+						Args: []ast.Expr{
+							makePackedFieldValueBytes(field),
+							makePackedFieldBytes(field),
+						},
+						Rparen: pos,
+					},
+				},
+				&ast.ReturnStmt{
+					Return: pos,
+				},
+			},
+			Rbrace: pos,
+		},
+	}
+	p.generated.Decls = append(p.generated.Decls, getter)
+}
+
+// createPackedFieldSetter creates a setter for a field that is not aligned in
+// Go (see layoutStruct), like the following:
+//
+//     func (s *C.struct_foo) set_packedfield_b(value C.int) {
+//         copy(s.__packed_b[:], (*[4]byte)(unsafe.Pointer(&value))[:])
+//     }
+func (p *cgoPackage) createPackedFieldSetter(field packedFieldInfo, typeName string) {
+	pos := field.pos
+	setter := &ast.FuncDecl{
+		Recv: makePackedFieldReceiver(pos, typeName),
+		Name: &ast.Ident{
+			NamePos: pos,
+			Name:    "set_packedfield_" + field.name,
+		},
+		Type: &ast.FuncType{
+			Func: pos,
+			Params: &ast.FieldList{
+				Opening: pos,
+				List: []*ast.Field{
+					{
+						Names: []*ast.Ident{
+							{
+								NamePos: pos,
+								Name:    "value",
+							},
+						},
+						Type: field.typeExpr,
+					},
+				},
+				Closing: pos,
+			},
+		},
+		Body: &ast.BlockStmt{
+			Lbrace: pos,
+			List: []ast.Stmt{
+				&ast.ExprStmt{
+					X: &ast.CallExpr{
+						Fun: &ast.Ident{
+							NamePos: pos,
+							Name:    "copy",
+						},
+						Lparen: pos,
+						Args: []ast.Expr{
+							makePackedFieldBytes(field),
+							makePackedFieldValueBytes(field),
+						},
+						Rparen: pos,
+					},
+				},
+			},
+			Rbrace: pos,
+		},
+	}
+	p.generated.Decls = append(p.generated.Decls, setter)
+}
+
+// makePackedFieldReceiver returns the receiver (s *typeName) of a packed field
+// getter or setter.
+func makePackedFieldReceiver(pos token.Pos, typeName string) *ast.FieldList {
+	return &ast.FieldList{
+		Opening: pos,
+		List: []*ast.Field{
+			{
+				Names: []*ast.Ident{
+					{
+						NamePos: pos,
+						Name:    "s",
+					},
+				},
+				Type: &ast.StarExpr{
+					Star: pos,
+					X: &ast.Ident{
+						NamePos: pos,
+						Name:    typeName,
+					},
+				},
+			},
+		},
+		Closing: pos,
+	}
+}
+
+// makePackedFieldBytes returns the expression s.__packed_name[:].
+func makePackedFieldBytes(field packedFieldInfo) ast.Expr {
+	return &ast.SliceExpr{
+		X: &ast.SelectorExpr{
+			X: &ast.Ident{
+				NamePos: field.pos,
+				Name:    "s",
+			},
+			Sel: &ast.Ident{
+				NamePos: field.pos,
+				Name:    field.field.Names[0].Name,
+			},
+		},
+		Lbrack: field.pos,
+		Rbrack: field.pos,
+	}
+}
+
+// makePackedFieldValueBytes returns the expression
+// (*[size]byte)(unsafe.Pointer(&value))[:].
+func makePackedFieldValueBytes(field packedFieldInfo) ast.Expr {
+	return &ast.SliceExpr{
+		X: &ast.CallExpr{
+			Fun: &ast.ParenExpr{
+				Lparen: field.pos,
+				X: &ast.StarExpr{
+					Star: field.pos,
+					X:    makeByteArrayType(field.pos, field.size),
+				},
+				Rparen: field.pos,
+			},
+			Lparen: field.pos,
+			Args: []ast.Expr{
+				&ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   &ast.Ident{NamePos: field.pos, Name: "unsafe"},
+						Sel: &ast.Ident{NamePos: field.pos, Name: "Pointer"},
+					},
+					Lparen: field.pos,
+					Args: []ast.Expr{
+						&ast.UnaryExpr{
+							OpPos: field.pos,
+							Op:    token.AND,
+							X: &ast.Ident{
+								NamePos: field.pos,
+								Name:    "value",
+							},
+						},
+					},
+					Rparen: field.pos,
+				},
+			},
+			Rparen: field.pos,
+		},
+		Lbrack: field.pos,
+		Rbrack: field.pos,
+	}
+}
+
+// makeByteArrayType returns the type expression [size]byte.
+func makeByteArrayType(pos token.Pos, size int64) ast.Expr {
+	return &ast.ArrayType{
+		Lbrack: pos,
+		Len: &ast.BasicLit{
+			ValuePos: pos,
+			Kind:     token.INT,
+			Value:    strconv.FormatInt(size, 10),
+		},
+		Elt: &ast.Ident{
+			NamePos: pos,
+			Name:    "byte",
+		},
+	}
+}
+
 // addEnumTypes adds C enums to the AST. For example, the following C code:
 //
 //     enum option {
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/constant"
 	"go/format"
 	"go/parser"
 	"go/token"
 	"go/types"
 	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -29,6 +32,7 @@ func normalizeResult(result string) string {
 
 func TestCGo(t *testing.T) {
 	var cflags = []string{"--target=armv6m-unknown-unknown-eabi"}
+	var buildTags = []string{"linux", "arm", "baremetal"}
 
 	for _, name := range []string{
 		"basic",
@@ -63,7 +67,7 @@ func TestCGo(t *testing.T) {
 			}
 
 			// Process the AST with CGo.
-			cgoAST, _, _, _, _, cgoErrors := Process([]*ast.File{f}, "testdata", fset, cflags, "")
+			cgoAST, _, _, _, _, _, cgoErrors := Process([]*ast.File{f}, "testdata", fset, cflags, buildTags, "")
 
 			// Check the AST for type errors.
 			var typecheckErrors []error
@@ -130,6 +134,220 @@ func TestCGo(t *testing.T) {
 	}
 }
 
+func TestExportHeader(t *testing.T) {
+	var cflags = []string{"--target=armv6m-unknown-unknown-eabi"}
+	var buildTags = []string{"linux", "arm", "baremetal"}
+
+	path := filepath.Join("testdata", "export.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal("could not parse Go source file:", err)
+	}
+	cgoAST, cgoHeaders, _, _, _, _, cgoErrors := Process([]*ast.File{f}, "testdata", fset, cflags, buildTags, "")
+	for _, err := range cgoErrors {
+		t.Error(err)
+	}
+	config := types.Config{
+		Importer: simpleImporter{},
+		Sizes:    types.SizesFor("gccgo", "arm"),
+	}
+	files := []*ast.File{f, cgoAST}
+	pkg, err := config.Check("", fset, files, nil)
+	if err != nil {
+		t.Fatal("could not type check:", err)
+	}
+	actual := normalizeResult(ExportHeader(pkg, files, cgoHeaders))
+
+	outfile := filepath.Join("testdata", "export.out.h")
+	expectedBytes, err := ioutil.ReadFile(outfile)
+	if err != nil {
+		t.Fatalf("could not read expected output: %v", err)
+	}
+	expected := strings.ReplaceAll(string(expectedBytes), "\r\n", "\n")
+	if expected != actual {
+		if *flagUpdate {
+			err := ioutil.WriteFile(outfile, []byte(actual), 0666)
+			if err != nil {
+				t.Error("could not write updated output file:", err)
+			}
+			return
+		}
+		t.Errorf("output did not match:\n%s", actual)
+	}
+}
+
+// TestPkgConfig checks that the flags of a "#cgo pkg-config:" line are
+// added, using the .pc file in testdata/sysroot. The target is cross compiled,
+// so pkg-config must find it through $PKG_CONFIG_LIBDIR or the sysroot of the
+// target, and must not fall back to the .pc files of the host.
+func TestPkgConfig(t *testing.T) {
+	if _, err := exec.LookPath("pkg-config"); err != nil {
+		t.Skip("pkg-config not found:", err)
+	}
+	var buildTags = []string{"linux", "arm", "baremetal"}
+
+	sysroot, err := filepath.Abs(filepath.Join("testdata", "sysroot"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	libdir := filepath.Join(sysroot, "usr", "lib", "pkgconfig")
+	for _, name := range []string{"PKG_CONFIG", "PKG_CONFIG_PATH", "PKG_CONFIG_LIBDIR", "PKG_CONFIG_SYSROOT_DIR"} {
+		if value, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, value)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Unsetenv(name)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		libdir        string // $PKG_CONFIG_LIBDIR
+		sysrootDir    string // $PKG_CONFIG_SYSROOT_DIR
+		targetSysroot string // --sysroot of the target
+		cflags        string
+		ldflags       string
+		err           string
+	}{
+		{
+			name:    "libdir",
+			libdir:  libdir,
+			cflags:  "-I/opt/tinygo-test/include -DPKGCONFIG_TEST=1",
+			ldflags: "-L/opt/tinygo-test/lib -ltinygotest",
+		},
+		{
+			name:       "libdir and sysroot dir",
+			libdir:     libdir,
+			sysrootDir: "/sysroot",
+			cflags:     "-I/sysroot/opt/tinygo-test/include -DPKGCONFIG_TEST=1",
+			ldflags:    "-L/sysroot/opt/tinygo-test/lib -ltinygotest",
+		},
+		{
+			name:          "target sysroot",
+			targetSysroot: sysroot,
+			cflags:        "-I" + sysroot + "/opt/tinygo-test/include -DPKGCONFIG_TEST=1",
+			ldflags:       "-L" + sysroot + "/opt/tinygo-test/lib -ltinygotest",
+		},
+		{
+			name: "no target sysroot",
+			err:  "pkg-config: cannot find libraries for a target without sysroot, set $PKG_CONFIG_LIBDIR to the .pc files for this target",
+		},
+	} {
+		os.Setenv("PKG_CONFIG_LIBDIR", tc.libdir)
+		os.Setenv("PKG_CONFIG_SYSROOT_DIR", tc.sysrootDir)
+		var cflags = []string{"--target=armv6m-unknown-unknown-eabi"}
+		if tc.targetSysroot != "" {
+			cflags = append(cflags, "--sysroot="+tc.targetSysroot)
+		}
+
+		path := filepath.Join("testdata", "pkgconfig.go")
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal("could not parse Go source file:", err)
+		}
+		_, _, pkgCFlags, _, pkgLDFlags, _, cgoErrors := Process([]*ast.File{f}, "testdata", fset, cflags, buildTags, "")
+		if tc.err != "" {
+			if len(cgoErrors) != 1 || !strings.HasSuffix(cgoErrors[0].Error(), tc.err) {
+				t.Errorf("%s: expected error %q, got %v", tc.name, tc.err, cgoErrors)
+			}
+			continue
+		}
+		for _, err := range cgoErrors {
+			t.Errorf("%s: %s", tc.name, err)
+		}
+		if actual := strings.Join(pkgCFlags, " "); actual != tc.cflags {
+			t.Errorf("%s: unexpected CFLAGS: %s", tc.name, actual)
+		}
+		if actual := strings.Join(pkgLDFlags, " "); actual != tc.ldflags {
+			t.Errorf("%s: unexpected LDFLAGS: %s", tc.name, actual)
+		}
+	}
+}
+
+// TestStructLayout checks that the Go structs created for C structs with
+// unusual layouts (packed, aligned) have the same layout as in C, by comparing
+// the offset, size, and alignment of each type against the values calculated
+// by Clang.
+func TestStructLayout(t *testing.T) {
+	var cflags = []string{"--target=armv6m-unknown-unknown-eabi"}
+	var buildTags = []string{"linux", "arm", "baremetal"}
+
+	path := filepath.Join("testdata", "layout.go")
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal("could not parse Go source file:", err)
+	}
+	cgoAST, _, _, _, _, _, cgoErrors := Process([]*ast.File{f}, "testdata", fset, cflags, buildTags, "")
+	for _, err := range cgoErrors {
+		t.Error(err)
+	}
+	sizes := types.SizesFor("gccgo", "arm")
+	config := types.Config{
+		Importer: simpleImporter{},
+		Sizes:    sizes,
+	}
+	pkg, err := config.Check("", fset, []*ast.File{f, cgoAST}, nil)
+	if err != nil {
+		t.Fatal("could not type check:", err)
+	}
+
+	// Find the type with the given name (the part after sizeof_ etc).
+	lookupType := func(name string) types.Type {
+		for _, prefix := range []string{"C.struct_", "C.union_"} {
+			if obj := pkg.Scope().Lookup(prefix + name); obj != nil {
+				return obj.Type()
+			}
+		}
+		t.Fatalf("type for %s not found", name)
+		return nil
+	}
+
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if !ok {
+			continue
+		}
+		expected, ok := constant.Int64Val(obj.Val())
+		if !ok {
+			t.Errorf("%s: not an integer constant", name)
+			continue
+		}
+		var actual int64
+		switch {
+		case strings.HasPrefix(name, "C.sizeof_"):
+			actual = sizes.Sizeof(lookupType(name[len("C.sizeof_"):]))
+		case strings.HasPrefix(name, "C.alignof_"):
+			actual = sizes.Alignof(lookupType(name[len("C.alignof_"):]))
+		case strings.HasPrefix(name, "C.offsetof_"):
+			parts := strings.SplitN(name[len("C.offsetof_"):], "__", 2)
+			st := lookupType(parts[0]).Underlying().(*types.Struct)
+			var fields []*types.Var
+			actual = -1
+			for i := 0; i < st.NumFields(); i++ {
+				fields = append(fields, st.Field(i))
+			}
+			offsets := sizes.Offsetsof(fields)
+			for i, field := range fields {
+				if field.Name() == parts[1] || field.Name() == "__packed_"+parts[1] {
+					actual = offsets[i]
+				}
+			}
+			if actual < 0 {
+				t.Errorf("%s: field not found", name)
+				continue
+			}
+		default:
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: expected %d, got %d", name, expected, actual)
+		}
+	}
+}
+
 // simpleImporter implements the types.Importer interface, but only allows
 // importing the unsafe package.
 type simpleImporter struct {
@@ -0,0 +1,241 @@
+package cgo
+
+// This file generates the _cgo_export.h header, which declares the Go functions
+// that are exported with //export so that C code in the same package can call
+// them.
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// exportHeaderPrefix is the first part of a _cgo_export.h header, before the
+// CGo preambles of the package.
+const exportHeaderPrefix = `/* Code generated by TinyGo. DO NOT EDIT. */
+
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+`
+
+// exportHeaderTypes declares the C equivalents of the Go basic types. The Go int
+// and uint types are 64 bits on 64-bit systems and 32 bits otherwise.
+const exportHeaderTypes = `
+#line 1 "cgo-generated-wrapper"
+
+#ifndef GO_CGO_PROLOGUE_H
+#define GO_CGO_PROLOGUE_H
+
+typedef int8_t GoInt8;
+typedef uint8_t GoUint8;
+typedef int16_t GoInt16;
+typedef uint16_t GoUint16;
+typedef int32_t GoInt32;
+typedef uint32_t GoUint32;
+typedef int64_t GoInt64;
+typedef uint64_t GoUint64;
+#if UINTPTR_MAX > UINT32_MAX
+typedef GoInt64 GoInt;
+typedef GoUint64 GoUint;
+#else
+typedef GoInt32 GoInt;
+typedef GoUint32 GoUint;
+#endif
+typedef uintptr_t GoUintptr;
+typedef float GoFloat32;
+typedef double GoFloat64;
+typedef bool GoBool;
+
+#endif
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+`
+
+const exportHeaderSuffix = `
+#ifdef __cplusplus
+}
+#endif
+`
+
+// ExportHeader returns the contents of the _cgo_export.h header for a package
+// that uses CGo, or the empty string if the package doesn't export any
+// functions. The files must be type checked and the preambles are the CGo
+// header snippets of each file, as returned by Process.
+//
+// Like with the gc toolchain, the header includes the preamble of every file
+// that exports a function, so these preambles should only contain
+// declarations. Exported functions may only use parameter and result types that
+// can be passed to and from C directly: integers, floats, bools, pointers and C
+// types. At most one result is supported. Other functions are left out of the
+// header with a comment explaining why.
+func ExportHeader(pkg *types.Package, files []*ast.File, preambles []string) string {
+	var preambleText, declText strings.Builder
+	for i, f := range files {
+		hasExports := false
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Recv != nil {
+				continue
+			}
+			name := exportName(decl)
+			if name == "" {
+				continue
+			}
+			hasExports = true
+			fn, ok := pkg.Scope().Lookup(decl.Name.Name).(*types.Func)
+			if !ok {
+				continue
+			}
+			prototype, err := exportPrototype(name, fn.Type().(*types.Signature))
+			if err != nil {
+				fmt.Fprintf(&declText, "/* %s: %s */\n", name, err)
+				continue
+			}
+			declText.WriteString(prototype)
+		}
+		if hasExports && i < len(preambles) {
+			preambleText.WriteString(preambles[i])
+			preambleText.WriteString("\n")
+		}
+	}
+	if declText.Len() == 0 {
+		return ""
+	}
+	return exportHeaderPrefix + preambleText.String() + exportHeaderTypes + declText.String() + exportHeaderSuffix
+}
+
+// exportName returns the C name of a function exported with //export or
+// //go:export, or the empty string if the function isn't exported.
+func exportName(decl *ast.FuncDecl) string {
+	if decl.Doc == nil || decl.Body == nil {
+		return ""
+	}
+	for _, comment := range decl.Doc.List {
+		text := comment.Text
+		if !strings.HasPrefix(text, "//export ") && !strings.HasPrefix(text, "//go:export ") {
+			continue
+		}
+		parts := strings.Fields(text)
+		if len(parts) == 2 {
+			return parts[1]
+		}
+	}
+	return ""
+}
+
+// exportPrototype returns the C prototype of an exported function.
+func exportPrototype(name string, sig *types.Signature) (string, error) {
+	result := "void"
+	switch sig.Results().Len() {
+	case 0:
+	case 1:
+		typ, err := exportCType(sig.Results().At(0).Type())
+		if err != nil {
+			return "", fmt.Errorf("unsupported result type: %w", err)
+		}
+		result = typ
+	default:
+		return "", fmt.Errorf("multiple results are not supported")
+	}
+	var params []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		typ, err := exportCType(sig.Params().At(i).Type())
+		if err != nil {
+			return "", fmt.Errorf("unsupported parameter type: %w", err)
+		}
+		params = append(params, fmt.Sprintf("%s p%d", typ, i))
+	}
+	if sig.Variadic() {
+		return "", fmt.Errorf("variadic functions are not supported")
+	}
+	if len(params) == 0 {
+		params = append(params, "void")
+	}
+	return fmt.Sprintf("extern %s %s(%s);\n", result, name, strings.Join(params, ", ")), nil
+}
+
+// exportCType returns the C type for the given Go type of a parameter or result
+// of an exported function.
+func exportCType(t types.Type) (string, error) {
+	switch t := t.(type) {
+	case *types.Basic:
+		switch t.Kind() {
+		case types.Bool:
+			return "GoBool", nil
+		case types.Int:
+			return "GoInt", nil
+		case types.Int8:
+			return "GoInt8", nil
+		case types.Int16:
+			return "GoInt16", nil
+		case types.Int32:
+			return "GoInt32", nil
+		case types.Int64:
+			return "GoInt64", nil
+		case types.Uint:
+			return "GoUint", nil
+		case types.Uint8:
+			return "GoUint8", nil
+		case types.Uint16:
+			return "GoUint16", nil
+		case types.Uint32:
+			return "GoUint32", nil
+		case types.Uint64:
+			return "GoUint64", nil
+		case types.Uintptr:
+			return "GoUintptr", nil
+		case types.Float32:
+			return "GoFloat32", nil
+		case types.Float64:
+			return "GoFloat64", nil
+		case types.UnsafePointer:
+			return "void*", nil
+		}
+	case *types.Pointer:
+		elem, err := exportCType(t.Elem())
+		if err != nil {
+			// Pointers to Go types that have no C equivalent can still be
+			// passed around as opaque pointers.
+			elem = "void"
+		}
+		return elem + "*", nil
+	case *types.Named:
+		if name := t.Obj().Name(); strings.HasPrefix(name, "C.") {
+			return exportCTypeName(name[len("C."):]), nil
+		}
+		return exportCType(t.Underlying())
+	}
+	return "", fmt.Errorf("%s", t.String())
+}
+
+// exportCTypeName converts the name of a C type as used in Go (C.uint,
+// C.struct_foo) back to the name used in C (unsigned int, struct foo).
+func exportCTypeName(name string) string {
+	switch name {
+	case "schar":
+		return "signed char"
+	case "uchar":
+		return "unsigned char"
+	case "ushort":
+		return "unsigned short"
+	case "uint":
+		return "unsigned int"
+	case "ulong":
+		return "unsigned long"
+	case "longlong":
+		return "long long"
+	case "ulonglong":
+		return "unsigned long long"
+	}
+	for _, prefix := range []string{"struct_", "union_", "enum_"} {
+		if strings.HasPrefix(name, prefix) {
+			return prefix[:len(prefix)-1] + " " + name[len(prefix):]
+		}
+	}
+	return name
+}
@@ -600,10 +600,10 @@ func (p *cgoPackage) makeASTType(typ C.CXType, pos token.Pos) ast.Expr {
 		if name == "" {
 			// Anonymous record, probably inside a typedef.
 			typeInfo := p.makeASTRecordType(cursor, pos)
-			if typeInfo.bitfields != nil || typeInfo.unionSize != 0 {
-				// This record is a union or is a struct with bitfields, so we
-				// have to declare it as a named type (for getters/setters to
-				// work).
+			if typeInfo.bitfields != nil || typeInfo.unionSize != 0 || typeInfo.packedFields != nil {
+				// This record is a union or is a struct with bitfields or
+				// packed fields, so we have to declare it as a named type (for
+				// getters/setters to work).
 				p.anonStructNum++
 				cgoName := cgoRecordPrefix + strconv.Itoa(p.anonStructNum)
 				p.elaboratedTypes[cgoName] = typeInfo
@@ -672,6 +672,7 @@ func (p *cgoPackage) makeASTRecordType(cursor C.GoCXCursor, pos token.Pos) *elab
 		Closing: pos,
 	}
 	var bitfieldList []bitfieldInfo
+	var layouts []fieldLayout
 	inBitfield := false
 	bitfieldNum := 0
 	ref := storedRefs.Put(struct {
@@ -680,19 +681,23 @@ func (p *cgoPackage) makeASTRecordType(cursor C.GoCXCursor, pos token.Pos) *elab
 		inBitfield   *bool
 		bitfieldNum  *int
 		bitfieldList *[]bitfieldInfo
-	}{fieldList, p, &inBitfield, &bitfieldNum, &bitfieldList})
+		layouts      *[]fieldLayout
+	}{fieldList, p, &inBitfield, &bitfieldNum, &bitfieldList, &layouts})
 	defer storedRefs.Remove(ref)
 	C.tinygo_clang_visitChildren(cursor, C.CXCursorVisitor(C.tinygo_clang_struct_visitor), C.CXClientData(ref))
 	renameFieldKeywords(fieldList)
 	switch C.tinygo_clang_getCursorKind(cursor) {
 	case C.CXCursor_StructDecl:
+		typ := C.tinygo_clang_getCursorType(cursor)
+		packedFields := p.layoutStruct(fieldList, layouts, int64(C.clang_Type_getSizeOf(typ)), int64(C.clang_Type_getAlignOf(typ)), pos)
 		return &elaboratedTypeInfo{
 			typeExpr: &ast.StructType{
 				Struct: pos,
 				Fields: fieldList,
 			},
-			pos:       pos,
-			bitfields: bitfieldList,
+			pos:          pos,
+			bitfields:    bitfieldList,
+			packedFields: packedFields,
 		}
 	case C.CXCursor_UnionDecl:
 		typeInfo := &elaboratedTypeInfo{
@@ -741,6 +746,7 @@ func tinygo_clang_struct_visitor(c, parent C.GoCXCursor, client_data C.CXClientD
 		inBitfield   *bool
 		bitfieldNum  *int
 		bitfieldList *[]bitfieldInfo
+		layouts      *[]fieldLayout
 	})
 	fieldList := passed.fieldList
 	p := passed.pkg
@@ -772,11 +778,10 @@ func tinygo_clang_struct_visitor(c, parent C.GoCXCursor, client_data C.CXClientD
 	offsetof := int64(C.clang_Type_getOffsetOf(C.tinygo_clang_getCursorType(parent), C.CString(name)))
 	alignOf := int64(C.clang_Type_getAlignOf(typ) * 8)
 	bitfieldOffset := offsetof % alignOf
-	if bitfieldOffset != 0 {
-		if C.tinygo_clang_Cursor_isBitField(c) != 1 {
-			p.addError(pos, "expected a bitfield")
-			return C.CXChildVisit_Continue
-		}
+	if bitfieldOffset != 0 && C.tinygo_clang_Cursor_isBitField(c) == 1 {
+		// A bitfield that shares its storage with the previous field. Fields
+		// that are not bitfields can also have an unaligned offset, in packed
+		// structs. These are handled in layoutStruct.
 		if !*inBitfield {
 			*bitfieldNum++
 		}
@@ -818,6 +823,12 @@ func tinygo_clang_struct_visitor(c, parent C.GoCXCursor, client_data C.CXClientD
 		},
 	}
 	fieldList.List = append(fieldList.List, field)
+	*passed.layouts = append(*passed.layouts, fieldLayout{
+		field:  field,
+		offset: offsetof / 8,
+		size:   int64(C.clang_Type_getSizeOf(typ)),
+		align:  alignOf / 8,
+	})
 	return C.CXChildVisit_Continue
 }
 
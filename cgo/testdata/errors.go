@@ -22,6 +22,11 @@ import "C"
 // #warning another warning
 import "C"
 
+// struct aligned16 {
+// 	int x;
+// } __attribute__((aligned(16)));
+import "C"
+
 // Make sure that errors for the following lines won't change with future
 // additions to the CGo preamble.
 //line errors.go:100
@@ -38,4 +43,7 @@ var (
 	_ byte = C.SOME_CONST_3
 
 	_ = C.SOME_CONST_4
+
+	// struct alignment not supported
+	_ C.struct_aligned16
 )
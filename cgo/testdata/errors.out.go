@@ -4,6 +4,7 @@
 //     testdata/errors.go:22:5: warning: another warning
 //     testdata/errors.go:13:23: unexpected token ), expected end of expression
 //     testdata/errors.go:19:26: unexpected token ), expected end of expression
+//     testdata/errors.go:25:11: struct alignment of 16 bytes is not supported
 
 // Type checking errors after CGo processing:
 //     testdata/errors.go:102: cannot use 2 << 10 (untyped int constant 2048) as uint8 value in variable declaration (overflows)
@@ -64,3 +65,4 @@ type C.point_t = struct {
 	x C.int
 	y C.int
 }
+type C.struct_aligned16 struct{ x C.int }
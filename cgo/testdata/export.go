@@ -0,0 +1,46 @@
+package main
+
+/*
+struct point {
+	int x;
+};
+*/
+import "C"
+
+import "unsafe"
+
+//export add
+func add(a, b C.int) C.int {
+	return a + b
+}
+
+//export noParams
+func noParams() {
+}
+
+//export goTypes
+func goTypes(a int, b uint8, c bool, d float64, e uintptr, f unsafe.Pointer) int32 {
+	return 0
+}
+
+//export pointers
+func pointers(p *C.struct_point, s *C.char, g *goStruct) *C.uint {
+	return nil
+}
+
+//export unsupportedParam
+func unsupportedParam(s string) {
+}
+
+//export unsupportedResult
+func unsupportedResult() (int, int) {
+	return 0, 0
+}
+
+// Not exported, so not in the header.
+func notExported(a C.int) {
+}
+
+type goStruct struct {
+	x int
+}
@@ -28,10 +28,27 @@ package main
 // This flag is not valid ldflags
 #cgo LDFLAGS: -does-not-exists
 
+// Build constraints
+#cgo arm CFLAGS: -DBUILDTAG_ARM=1
+#cgo !arm CFLAGS: -DBUILDTAG_ARM=2
+#cgo darwin linux,!amd64 CFLAGS: -DBUILDTAG_OR=1
+#cgo linux,amd64 !arm CFLAGS: -DNOTDEFINED
+
+// Package names for pkg-config must not look like flags
+#cgo pkg-config: -lfoo
+#cgo windows pkg-config: foo
+
+// CPPFLAGS are used for C and C++, CXXFLAGS only for C++
+#cgo CPPFLAGS: -DCPPFLAG=1
+#cgo CXXFLAGS: -DNOTDEFINED
+
 */
 import "C"
 
 var (
 	_ = C.BAR
+	_ = C.BUILDTAG_ARM
+	_ = C.BUILDTAG_OR
+	_ = C.CPPFLAG
 	_ = C.FOO_H
 )
@@ -2,6 +2,7 @@
 //     testdata/flags.go:5:7: invalid #cgo line: NOFLAGS
 //     testdata/flags.go:8:13: invalid flag: -fdoes-not-exist
 //     testdata/flags.go:29:14: invalid flag: -does-not-exists
+//     testdata/flags.go:38:17: invalid pkg-config package name: -lfoo
 
 package main
 
@@ -30,6 +31,9 @@ func C.GoBytes(ptr unsafe.Pointer, length C.int) []byte {
 }
 
 const C.BAR = 3
+const C.BUILDTAG_ARM = 1
+const C.BUILDTAG_OR = 1
+const C.CPPFLAG = 1
 const C.FOO_H = 1
 
 type C.int16_t = int16
@@ -0,0 +1,147 @@
+package main
+
+/*
+// Structs with a layout that is different from the natural layout of their
+// fields. The Go struct must have the same layout as the C struct, which is
+// checked against the constants below.
+
+// Field with a larger alignment than its type.
+struct aligned {
+	char a;
+	int  b __attribute__((aligned(8)));
+	char c;
+};
+
+// Packed struct: no padding at all.
+struct packed {
+	char      a;
+	int       b;
+	short     c;
+	long long d;
+} __attribute__((packed));
+
+// Reduced alignment using #pragma pack.
+#pragma pack(push, 2)
+struct pack2 {
+	char a;
+	int  b;
+	char c;
+};
+#pragma pack(pop)
+
+// A packed struct inside a regular struct.
+struct nested {
+	char          a;
+	struct packed b;
+	int           c;
+};
+
+// Struct with a larger alignment than its fields.
+struct overaligned {
+	short a;
+	char  b;
+} __attribute__((aligned(8)));
+
+// Packed union.
+union packedunion {
+	char a;
+	int  b;
+} __attribute__((packed));
+
+// Union inside a packed struct.
+struct withunion {
+	char              a;
+	union packedunion b;
+	short             c;
+} __attribute__((packed));
+
+enum {
+	sizeof_aligned          = sizeof(struct aligned),
+	alignof_aligned         = _Alignof(struct aligned),
+	offsetof_aligned__a     = __builtin_offsetof(struct aligned, a),
+	offsetof_aligned__b     = __builtin_offsetof(struct aligned, b),
+	offsetof_aligned__c     = __builtin_offsetof(struct aligned, c),
+	sizeof_packed           = sizeof(struct packed),
+	alignof_packed          = _Alignof(struct packed),
+	offsetof_packed__a      = __builtin_offsetof(struct packed, a),
+	offsetof_packed__b      = __builtin_offsetof(struct packed, b),
+	offsetof_packed__c      = __builtin_offsetof(struct packed, c),
+	offsetof_packed__d      = __builtin_offsetof(struct packed, d),
+	sizeof_pack2            = sizeof(struct pack2),
+	alignof_pack2           = _Alignof(struct pack2),
+	offsetof_pack2__a       = __builtin_offsetof(struct pack2, a),
+	offsetof_pack2__b       = __builtin_offsetof(struct pack2, b),
+	offsetof_pack2__c       = __builtin_offsetof(struct pack2, c),
+	sizeof_nested           = sizeof(struct nested),
+	alignof_nested          = _Alignof(struct nested),
+	offsetof_nested__a      = __builtin_offsetof(struct nested, a),
+	offsetof_nested__b      = __builtin_offsetof(struct nested, b),
+	offsetof_nested__c      = __builtin_offsetof(struct nested, c),
+	sizeof_overaligned      = sizeof(struct overaligned),
+	alignof_overaligned     = _Alignof(struct overaligned),
+	offsetof_overaligned__a = __builtin_offsetof(struct overaligned, a),
+	offsetof_overaligned__b = __builtin_offsetof(struct overaligned, b),
+	sizeof_packedunion      = sizeof(union packedunion),
+	alignof_packedunion     = _Alignof(union packedunion),
+	sizeof_withunion        = sizeof(struct withunion),
+	alignof_withunion       = _Alignof(struct withunion),
+	offsetof_withunion__a   = __builtin_offsetof(struct withunion, a),
+	offsetof_withunion__b   = __builtin_offsetof(struct withunion, b),
+	offsetof_withunion__c   = __builtin_offsetof(struct withunion, c),
+};
+*/
+import "C"
+
+var (
+	_ C.struct_aligned
+	_ C.struct_packed
+	_ C.struct_pack2
+	_ C.struct_nested
+	_ C.struct_overaligned
+	_ C.union_packedunion
+	_ C.struct_withunion
+)
+
+// Make sure the constants are referenced, so that they're declared.
+const (
+	_ = C.sizeof_aligned
+	_ = C.alignof_aligned
+	_ = C.offsetof_aligned__a
+	_ = C.offsetof_aligned__b
+	_ = C.offsetof_aligned__c
+	_ = C.sizeof_packed
+	_ = C.alignof_packed
+	_ = C.offsetof_packed__a
+	_ = C.offsetof_packed__b
+	_ = C.offsetof_packed__c
+	_ = C.offsetof_packed__d
+	_ = C.sizeof_pack2
+	_ = C.alignof_pack2
+	_ = C.offsetof_pack2__a
+	_ = C.offsetof_pack2__b
+	_ = C.offsetof_pack2__c
+	_ = C.sizeof_nested
+	_ = C.alignof_nested
+	_ = C.offsetof_nested__a
+	_ = C.offsetof_nested__b
+	_ = C.offsetof_nested__c
+	_ = C.sizeof_overaligned
+	_ = C.alignof_overaligned
+	_ = C.offsetof_overaligned__a
+	_ = C.offsetof_overaligned__b
+	_ = C.sizeof_packedunion
+	_ = C.alignof_packedunion
+	_ = C.sizeof_withunion
+	_ = C.alignof_withunion
+	_ = C.offsetof_withunion__a
+	_ = C.offsetof_withunion__b
+	_ = C.offsetof_withunion__c
+)
+
+// Access fields that are not aligned in Go through getters and setters.
+func accessPacked(p *C.struct_packed, u *C.struct_withunion) C.int {
+	p.set_packedfield_b(5)
+	*u.b.unionfield_b() = 3
+	u.set_packedfield_c(u.packedfield_c() + 1)
+	return p.packedfield_b() + C.int(p.a)
+}
@@ -0,0 +1,6 @@
+package main
+
+// #cgo pkg-config: tinygo-test
+import "C"
+
+var _ = C.PKGCONFIG_TEST
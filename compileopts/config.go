@@ -69,6 +69,10 @@ func (c *Config) GOARM() string {
 // BuildTags returns the complete list of build tags used during this build.
 func (c *Config) BuildTags() []string {
 	tags := append(c.Target.BuildTags, []string{"tinygo", "math_big_pure_go", "gc." + c.GC(), "scheduler." + c.Scheduler(), "serial." + c.Serial()}...)
+	if c.BuildMode() == "c-shared" {
+		// Build tags can't contain a dash.
+		tags = append(tags, "buildmode.c_shared")
+	}
 	for i := 1; i <= c.GoMinorVersion; i++ {
 		tags = append(tags, fmt.Sprintf("go1.%d", i))
 	}
@@ -126,6 +130,17 @@ func (c *Config) Scheduler() string {
 	return "none"
 }
 
+// BuildMode returns the build mode: "default" for a normal executable, or
+// "c-shared" for a library with exported functions. On WASI, a library is a
+// reactor module: it has an _initialize function that runs package
+// initializers instead of a _start function that runs main.
+func (c *Config) BuildMode() string {
+	if c.Options.BuildMode != "" {
+		return c.Options.BuildMode
+	}
+	return "default"
+}
+
 // Serial returns the serial implementation for this build configuration: uart,
 // usb (meaning USB-CDC), or none.
 func (c *Config) Serial() string {
@@ -275,6 +290,34 @@ func (c *Config) CFlags() []string {
 	for _, flag := range c.Target.CFlags {
 		cflags = append(cflags, strings.ReplaceAll(flag, "{root}", goenv.Get("TINYGOROOT")))
 	}
+	cflags = append(cflags, c.LibcCFlags()...)
+	// Always emit debug information. It is optionally stripped at link time.
+	cflags = append(cflags, "-g")
+	// Use the same optimization level as TinyGo.
+	cflags = append(cflags, "-O"+c.Options.Opt)
+	// Set the LLVM target triple.
+	cflags = append(cflags, "--target="+c.Triple())
+	// Set the -mcpu (or similar) flag.
+	if c.Target.CPU != "" {
+		if c.GOARCH() == "amd64" || c.GOARCH() == "386" {
+			// x86 prefers the -march flag (-mcpu is deprecated there).
+			cflags = append(cflags, "-march="+c.Target.CPU)
+		} else if strings.HasPrefix(c.Triple(), "avr") {
+			// AVR MCUs use -mmcu instead of -mcpu.
+			cflags = append(cflags, "-mmcu="+c.Target.CPU)
+		} else {
+			// The rest just uses -mcpu.
+			cflags = append(cflags, "-mcpu="+c.Target.CPU)
+		}
+	}
+	return cflags
+}
+
+// LibcCFlags returns the flags to find the headers of the libc of the target.
+// They are part of CFlags, and are also used to build libraries that depend on
+// the libc.
+func (c *Config) LibcCFlags() []string {
+	var cflags []string
 	switch c.Target.Libc {
 	case "darwin-libSystem":
 		root := goenv.Get("TINYGOROOT")
@@ -303,6 +346,9 @@ func (c *Config) CFlags() []string {
 	case "wasi-libc":
 		root := goenv.Get("TINYGOROOT")
 		cflags = append(cflags, "--sysroot="+root+"/lib/wasi-libc/sysroot")
+	case "wasi-libc-threads":
+		root := goenv.Get("TINYGOROOT")
+		cflags = append(cflags, "--sysroot="+root+"/lib/wasi-libc/sysroot-threads")
 	case "mingw-w64":
 		root := goenv.Get("TINYGOROOT")
 		path, _ := c.LibcPath("mingw-w64")
@@ -319,28 +365,38 @@ func (c *Config) CFlags() []string {
 		// usually this will be found by developers (not by TinyGo users).
 		panic("unknown libc: " + c.Target.Libc)
 	}
-	// Always emit debug information. It is optionally stripped at link time.
-	cflags = append(cflags, "-g")
-	// Use the same optimization level as TinyGo.
-	cflags = append(cflags, "-O"+c.Options.Opt)
-	// Set the LLVM target triple.
-	cflags = append(cflags, "--target="+c.Triple())
-	// Set the -mcpu (or similar) flag.
-	if c.Target.CPU != "" {
-		if c.GOARCH() == "amd64" || c.GOARCH() == "386" {
-			// x86 prefers the -march flag (-mcpu is deprecated there).
-			cflags = append(cflags, "-march="+c.Target.CPU)
-		} else if strings.HasPrefix(c.Triple(), "avr") {
-			// AVR MCUs use -mmcu instead of -mcpu.
-			cflags = append(cflags, "-mmcu="+c.Target.CPU)
-		} else {
-			// The rest just uses -mcpu.
-			cflags = append(cflags, "-mcpu="+c.Target.CPU)
-		}
-	}
 	return cflags
 }
 
+// HasLibCXX returns whether C++ files are built against libc++ and linked with
+// libc++ and libc++abi. This is the case on targets with a libc that TinyGo
+// builds or ships: Linux (musl), WASI and picolibc.
+func (c *Config) HasLibCXX() bool {
+	switch c.Target.Libc {
+	case "musl", "picolibc", "wasi-libc", "wasi-libc-threads":
+		return true
+	default:
+		return false
+	}
+}
+
+// CXXFlags returns the flags to pass to the C++ compiler in addition to the
+// flags returned by CFlags. They must be passed before the CFlags, so that the
+// libc++ headers are found before the libc headers they wrap.
+//
+// With libc++ (see HasLibCXX), the C++ standard library can be used, except
+// for the parts that need threads, locales (including iostreams), the
+// filesystem or a random device. Exceptions are not supported as there is no
+// unwinder, so C++ code is built with -fno-exceptions. On other targets there
+// is no C++ runtime at all: only freestanding C++ is supported, without RTTI.
+func (c *Config) CXXFlags() []string {
+	if !c.HasLibCXX() {
+		return []string{"-fno-exceptions", "-fno-rtti"}
+	}
+	path, _ := c.LibcPath("libcxx")
+	return []string{"-nostdinc++", "-isystem", filepath.Join(path, "include"), "-fno-exceptions"}
+}
+
 // LDFlags returns the flags to pass to the linker. A few more flags are needed
 // (like the one for the compiler runtime), but this represents the majority of
 // the flags.
@@ -355,6 +411,10 @@ func (c *Config) LDFlags() []string {
 	if c.Target.LinkerScript != "" {
 		ldflags = append(ldflags, "-T", c.Target.LinkerScript)
 	}
+	if c.BuildMode() == "c-shared" && c.Target.Linker == "wasm-ld" {
+		// A reactor module has no _start entry point.
+		ldflags = append(ldflags, "--no-entry")
+	}
 	return ldflags
 }
 
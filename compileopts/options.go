@@ -13,6 +13,7 @@ var (
 	validPrintSizeOptions     = []string{"none", "short", "full"}
 	validPanicStrategyOptions = []string{"print", "trap"}
 	validOptOptions           = []string{"none", "0", "1", "2", "s", "z"}
+	validBuildModeOptions     = []string{"default", "c-shared"}
 )
 
 // Options contains extra options to give to the compiler. These options are
@@ -24,6 +25,7 @@ type Options struct {
 	GOARM           string // environment variable (only used with GOARCH=arm)
 	Target          string
 	Opt             string
+	BuildMode       string
 	GC              string
 	PanicStrategy   string
 	Scheduler       string
@@ -47,6 +49,7 @@ type Options struct {
 	LLVMFeatures    string
 	Directory       string
 	PrintJSON       bool
+	AsyncifyAll     bool // instrument every function with asyncify, to compare against in tests
 }
 
 // Verify performs a validation on the given options, raising an error if options are not valid.
@@ -102,6 +105,12 @@ func (o *Options) Verify() error {
 		}
 	}
 
+	if o.BuildMode != "" {
+		if !isInArray(validBuildModeOptions, o.BuildMode) {
+			return fmt.Errorf("invalid -buildmode=%s: valid values are %s", o.BuildMode, strings.Join(validBuildModeOptions, ", "))
+		}
+	}
+
 	return nil
 }
 
@@ -13,6 +13,7 @@ func TestVerifyOptions(t *testing.T) {
 	expectedSchedulerError := errors.New(`invalid scheduler option 'incorrect': valid values are none, tasks, asyncify`)
 	expectedPrintSizeError := errors.New(`invalid size option 'incorrect': valid values are none, short, full`)
 	expectedPanicStrategyError := errors.New(`invalid panic option 'incorrect': valid values are print, trap`)
+	expectedBuildModeError := errors.New(`invalid -buildmode=incorrect: valid values are default, c-shared`)
 
 	testCases := []struct {
 		name          string
@@ -111,6 +112,25 @@ func TestVerifyOptions(t *testing.T) {
 				PanicStrategy: "trap",
 			},
 		},
+		{
+			name: "InvalidBuildModeOption",
+			opts: compileopts.Options{
+				BuildMode: "incorrect",
+			},
+			expectedError: expectedBuildModeError,
+		},
+		{
+			name: "BuildModeOptionDefault",
+			opts: compileopts.Options{
+				BuildMode: "default",
+			},
+		},
+		{
+			name: "BuildModeOptionCShared",
+			opts: compileopts.Options{
+				BuildMode: "c-shared",
+			},
+		},
 	}
 
 	for _, tc := range testCases {
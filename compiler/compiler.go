@@ -50,6 +50,7 @@ type Config struct {
 	AutomaticStackSize bool
 	DefaultStackSize   uint64
 	NeedsStackObjects  bool
+	BuildMode          string
 	Debug              bool // Whether to emit debug information in the LLVM module.
 }
 
@@ -78,6 +79,7 @@ type compilerContext struct {
 	astComments      map[string]*ast.CommentGroup
 	pkg              *types.Package
 	packageDir       string // directory for this package
+	cgoPackage       bool   // whether this package uses CGo
 	runtimePkg       *types.Package
 }
 
@@ -250,6 +252,7 @@ func Sizes(machine llvm.TargetMachine) types.Sizes {
 func CompilePackage(moduleName string, pkg *loader.Package, ssaPkg *ssa.Package, machine llvm.TargetMachine, config *Config, dumpSSA bool) (llvm.Module, []error) {
 	c := newCompilerContext(moduleName, machine, config, dumpSSA)
 	c.packageDir = pkg.OriginalDir()
+	c.cgoPackage = len(pkg.CgoFiles) != 0
 	c.pkg = pkg.Pkg
 	c.runtimePkg = ssaPkg.Prog.ImportedPackage("runtime").Pkg
 	c.program = ssaPkg.Prog
@@ -745,6 +748,18 @@ func (c *compilerContext) getDIFile(filename string) llvm.Metadata {
 	return c.difiles[filename]
 }
 
+// needsExportWrapper returns whether functions exported with //export in the
+// current package must be wrapped to start a goroutine when they're called
+// outside of a goroutine (see createExportWrapper). This is needed for CGo
+// packages when using the tasks scheduler, and for all packages except the
+// runtime in a WebAssembly library.
+func (c *compilerContext) needsExportWrapper() bool {
+	if c.cgoPackage && c.Scheduler == "tasks" {
+		return true
+	}
+	return c.BuildMode == "c-shared" && c.Scheduler != "none" && c.pkg.Path() != "runtime"
+}
+
 // createPackage builds the LLVM IR for all types, methods, and global variables
 // in the given package.
 func (c *compilerContext) createPackage(irbuilder llvm.Builder, pkg *ssa.Package) {
@@ -773,9 +788,24 @@ func (c *compilerContext) createPackage(irbuilder llvm.Builder, pkg *ssa.Package
 			// Create the function definition.
 			b := newBuilder(c, irbuilder, member)
 			if member.Blocks == nil {
+				if b.info.wasmABI != "" {
+					// Imported WebAssembly function that needs a wrapper.
+					b.createWasmABIImport()
+				}
 				continue // external function
 			}
 			b.createFunction()
+			var exportedFn llvm.Value
+			if b.info.wasmABI != "" {
+				exportedFn = b.createWasmABIExport()
+			} else if b.info.exported {
+				exportedFn = b.llvmFn
+			}
+			if !exportedFn.IsNil() && c.needsExportWrapper() {
+				// This function may be called from C or from a WebAssembly
+				// host outside of a goroutine.
+				b.createExportWrapper(exportedFn)
+			}
 		case *ssa.Type:
 			if types.IsInterface(member.Type()) {
 				// Interfaces don't have concrete methods.
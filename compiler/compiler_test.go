@@ -63,6 +63,7 @@ func TestCompiler(t *testing.T) {
 		{"intrinsics.go", "cortex-m-qemu", ""},
 		{"intrinsics.go", "wasm", ""},
 		{"gc.go", "", ""},
+		{"wasmabi.go", "", ""},
 	}
 	if llvmMajor >= 12 {
 		tests = append(tests, testCase{"intrinsics.go", "cortex-m-qemu", ""})
@@ -291,3 +291,133 @@ func (c *compilerContext) createGoroutineStartWrapper(fn llvm.Value, prefix stri
 	// Return a ptrtoint of the wrapper, not the function itself.
 	return builder.CreatePtrToInt(wrapper, c.uintptrType, "")
 }
+
+// createExportWrapper puts a wrapper in front of a Go function that is exported
+// with //export and that may be called while no goroutine is running: from C
+// code in a CGo package (for example from a C event loop), or by the host of a
+// WebAssembly library. The function may block and can therefore only run on a
+// goroutine stack. The given function (usually the function that was just
+// built) is renamed and all uses of it are replaced with a wrapper like this:
+//
+//     //export add
+//     func add(x, y int) int {
+//         if !task.OnSystemStack() {
+//             return add$goexport(x, y)
+//         }
+//         args := &struct{
+//             x, y   int
+//             result int
+//             done   bool
+//         }{x: x, y: y}
+//         task.start(add$exportwrapper, args, stackSize)
+//         runtime.exportCallbackWait(&args.done)
+//         return args.result
+//     }
+//
+// Where add$exportwrapper calls add$goexport with the arguments in args and then
+// stores the result and sets done.
+func (b *builder) createExportWrapper(fn llvm.Value) {
+	name := fn.Name()
+	fnType := fn.Type().ElementType()
+	fn.SetName(name + "$goexport")
+	fn.SetVisibility(llvm.HiddenVisibility)
+	fn.SetUnnamedAddr(true)
+	wrapper := llvm.AddFunction(b.mod, name, fnType)
+	fn.ReplaceAllUsesWith(wrapper)
+	b.addStandardDefinedAttributes(wrapper)
+	if exportName := fn.GetStringAttributeAtIndex(-1, "wasm-export-name"); !exportName.IsNil() {
+		// Export the wrapper instead of the original function.
+		fn.RemoveStringAttributeAtIndex(-1, "wasm-export-name")
+		wrapper.AddFunctionAttr(exportName)
+	}
+
+	builder := b.ctx.NewBuilder()
+	defer builder.Dispose()
+
+	// Create the argument bundle type: all parameters followed by the result
+	// (if any) and the done flag.
+	params := wrapper.Params()
+	var fieldTypes []llvm.Type
+	for _, param := range params {
+		fieldTypes = append(fieldTypes, param.Type())
+	}
+	resultType := fnType.ReturnType()
+	hasResult := resultType.TypeKind() != llvm.VoidTypeKind
+	if hasResult {
+		fieldTypes = append(fieldTypes, resultType)
+	}
+	doneIndex := len(fieldTypes)
+	fieldTypes = append(fieldTypes, b.ctx.Int1Type())
+	bundleType := b.ctx.StructType(fieldTypes, false)
+	bundlePtrType := llvm.PointerType(bundleType, 0)
+	field := func(bundle llvm.Value, i int) llvm.Value {
+		return builder.CreateInBoundsGEP(bundle, []llvm.Value{
+			llvm.ConstInt(b.ctx.Int32Type(), 0, false),
+			llvm.ConstInt(b.ctx.Int32Type(), uint64(i), false),
+		}, "")
+	}
+
+	// Create the function that runs in the new goroutine.
+	goroutineFn := llvm.AddFunction(b.mod, name+"$exportwrapper", llvm.FunctionType(b.ctx.VoidType(), []llvm.Type{b.i8ptrType}, false))
+	b.addStandardAttributes(goroutineFn)
+	goroutineFn.SetLinkage(llvm.InternalLinkage)
+	goroutineFn.SetUnnamedAddr(true)
+	goroutineFn.AddAttributeAtIndex(-1, b.ctx.CreateStringAttribute("tinygo-gowrapper", fn.Name()))
+	builder.SetInsertPointAtEnd(b.ctx.AddBasicBlock(goroutineFn, "entry"))
+	bundle := builder.CreateBitCast(goroutineFn.Param(0), bundlePtrType, "")
+	var args []llvm.Value
+	for i := range params {
+		args = append(args, builder.CreateLoad(field(bundle, i), ""))
+	}
+	result := builder.CreateCall(fn, args, "")
+	if hasResult {
+		builder.CreateStore(result, field(bundle, len(params)))
+	}
+	builder.CreateStore(llvm.ConstInt(b.ctx.Int1Type(), 1, false), field(bundle, doneIndex))
+	builder.CreateRetVoid()
+
+	// Create the wrapper itself.
+	entry := b.ctx.AddBasicBlock(wrapper, "entry")
+	direct := b.ctx.AddBasicBlock(wrapper, "direct")
+	newGoroutine := b.ctx.AddBasicBlock(wrapper, "goroutine")
+	builder.SetInsertPointAtEnd(entry)
+	onSystemStack := b.getFunction(b.program.ImportedPackage("internal/task").Members["OnSystemStack"].(*ssa.Function))
+	isSystemStack := builder.CreateCall(onSystemStack, []llvm.Value{llvm.Undef(b.i8ptrType)}, "")
+	builder.CreateCondBr(isSystemStack, newGoroutine, direct)
+
+	// Running in a goroutine, so the function can be called directly.
+	builder.SetInsertPointAtEnd(direct)
+	result = builder.CreateCall(fn, params, "")
+	if hasResult {
+		builder.CreateRet(result)
+	} else {
+		builder.CreateRetVoid()
+	}
+
+	// Running on the system stack: start a new goroutine and wait for it.
+	builder.SetInsertPointAtEnd(newGoroutine)
+	alloc := b.getFunction(b.program.ImportedPackage("runtime").Members["alloc"].(*ssa.Function))
+	size := llvm.ConstInt(b.uintptrType, b.targetData.TypeAllocSize(bundleType), false)
+	bundleAlloc := builder.CreateCall(alloc, []llvm.Value{size, llvm.ConstNull(b.i8ptrType), llvm.Undef(b.i8ptrType)}, "export.args")
+	bundle = builder.CreateBitCast(bundleAlloc, bundlePtrType, "")
+	for i, param := range params {
+		builder.CreateStore(param, field(bundle, i))
+	}
+	goroutineFnPtr := llvm.ConstPtrToInt(goroutineFn, b.uintptrType)
+	var stackSize llvm.Value
+	if b.AutomaticStackSize {
+		stackSizeFn := b.getFunction(b.program.ImportedPackage("internal/task").Members["getGoroutineStackSize"].(*ssa.Function))
+		stackSize = builder.CreateCall(stackSizeFn, []llvm.Value{goroutineFnPtr, llvm.Undef(b.i8ptrType)}, "stacksize")
+	} else {
+		stackSize = llvm.ConstInt(b.uintptrType, b.DefaultStackSize, false)
+	}
+	start := b.getFunction(b.program.ImportedPackage("internal/task").Members["start"].(*ssa.Function))
+	builder.CreateCall(start, []llvm.Value{goroutineFnPtr, bundleAlloc, stackSize, llvm.Undef(b.i8ptrType)}, "")
+	wait := b.getFunction(b.program.ImportedPackage("runtime").Members["exportCallbackWait"].(*ssa.Function))
+	builder.CreateCall(wait, []llvm.Value{field(bundle, doneIndex), llvm.Undef(b.i8ptrType)}, "")
+	if hasResult {
+		builder.CreateRet(builder.CreateLoad(field(bundle, len(params)), ""))
+	} else {
+		builder.CreateRetVoid()
+	}
+}
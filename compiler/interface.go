@@ -57,11 +57,24 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		var methodSet llvm.Value
 		var ptrTo llvm.Value
 		var typeAssert llvm.Value
+		var methods llvm.Value
+		var name llvm.Value
 		switch typ := typ.(type) {
 		case *types.Named:
 			references = c.getTypeCode(typ.Underlying())
+			name = c.makeTypeName(typ)
 		case *types.Chan:
 			references = c.getTypeCode(typ.Elem())
+			// Store the channel direction in the length field, using the
+			// values of reflect.ChanDir.
+			switch typ.Dir() {
+			case types.RecvOnly:
+				length = 1
+			case types.SendOnly:
+				length = 2
+			default:
+				length = 3
+			}
 		case *types.Pointer:
 			references = c.getTypeCode(typ.Elem())
 		case *types.Slice:
@@ -76,7 +89,17 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		case *types.Interface:
 			methodSetGlobal := c.getInterfaceMethodSet(typ)
 			references = llvm.ConstBitCast(methodSetGlobal, global.Type())
+		case *types.Signature:
+			// Store the parameter and result types, and the wrappers needed
+			// to call this function through reflection.
+			signatureGlobal := c.makeFuncTypeSignature(typ)
+			references = llvm.ConstBitCast(signatureGlobal, global.Type())
+		case *types.Map:
+			// Store the key and value type.
+			mapGlobal := c.makeMapTypeElems(typ)
+			references = llvm.ConstBitCast(mapGlobal, global.Type())
 		}
+		methods = c.getTypeReflectMethods(typ)
 		if _, ok := typ.Underlying().(*types.Interface); !ok {
 			methodSet = c.getTypeMethodSet(typ)
 		} else {
@@ -103,6 +126,12 @@ func (c *compilerContext) getTypeCode(typ types.Type) llvm.Value {
 		if !typeAssert.IsNil() {
 			globalValue = llvm.ConstInsertValue(globalValue, typeAssert, []uint32{4})
 		}
+		if !methods.IsNil() {
+			globalValue = llvm.ConstInsertValue(globalValue, methods, []uint32{5})
+		}
+		if !name.IsNil() {
+			globalValue = llvm.ConstInsertValue(globalValue, name, []uint32{6})
+		}
 		global.SetInitializer(globalValue)
 		global.SetLinkage(llvm.LinkOnceODRLinkage)
 		global.SetGlobalConstant(true)
@@ -144,6 +173,16 @@ func (c *compilerContext) makeStructTypeFields(typ *types.Struct) llvm.Value {
 			fieldEmbedded := llvm.ConstInt(c.ctx.Int1Type(), 1, false)
 			fieldGlobalValue = llvm.ConstInsertValue(fieldGlobalValue, fieldEmbedded, []uint32{3})
 		}
+		if !typ.Field(i).Exported() {
+			fieldPkgPath := c.makeGlobalArray([]byte(typ.Field(i).Pkg().Path()), "reflect/types.structFieldPkgPath", c.ctx.Int8Type())
+			fieldPkgPath.SetLinkage(llvm.PrivateLinkage)
+			fieldPkgPath.SetUnnamedAddr(true)
+			fieldPkgPath = llvm.ConstGEP(fieldPkgPath, []llvm.Value{
+				llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+				llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+			})
+			fieldGlobalValue = llvm.ConstInsertValue(fieldGlobalValue, fieldPkgPath, []uint32{4})
+		}
 		structGlobalValue = llvm.ConstInsertValue(structGlobalValue, fieldGlobalValue, []uint32{uint32(i)})
 	}
 	structGlobal.SetInitializer(structGlobalValue)
@@ -152,6 +191,24 @@ func (c *compilerContext) makeStructTypeFields(typ *types.Struct) llvm.Value {
 	return structGlobal
 }
 
+// makeTypeName creates a new global with the name of this named type as
+// returned by reflect.Type.String(), for example "json.Decoder", and returns a
+// reference to it. The package path is part of the typecode name already.
+func (c *compilerContext) makeTypeName(typ *types.Named) llvm.Value {
+	name := typ.Obj().Name()
+	if pkg := typ.Obj().Pkg(); pkg != nil {
+		name = pkg.Name() + "." + name
+	}
+	typeName := c.makeGlobalArray([]byte(name), "reflect/types.typeName", c.ctx.Int8Type())
+	typeName.SetLinkage(llvm.PrivateLinkage)
+	typeName.SetUnnamedAddr(true)
+	typeName.SetGlobalConstant(true)
+	return llvm.ConstGEP(typeName, []llvm.Value{
+		llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+		llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+	})
+}
+
 // getTypeCodeName returns a name for this type that can be used in the
 // interface lowering pass to assign type codes as expected by the reflect
 // package. See getTypeCodeNum.
@@ -205,7 +262,14 @@ func getTypeCodeName(t types.Type) string {
 		}
 		return "basic:" + kind
 	case *types.Chan:
-		return "chan:" + getTypeCodeName(t.Elem())
+		switch t.Dir() {
+		case types.RecvOnly:
+			return "chan:recv:" + getTypeCodeName(t.Elem())
+		case types.SendOnly:
+			return "chan:send:" + getTypeCodeName(t.Elem())
+		default:
+			return "chan:" + getTypeCodeName(t.Elem())
+		}
 	case *types.Interface:
 		methods := make([]string, t.NumMethods())
 		for i := 0; i < t.NumMethods(); i++ {
@@ -231,6 +295,9 @@ func getTypeCodeName(t types.Type) string {
 		for i := 0; i < t.Results().Len(); i++ {
 			results[i] = getTypeCodeName(t.Results().At(i).Type())
 		}
+		if t.Variadic() {
+			params[len(params)-1] = "..." + params[len(params)-1]
+		}
 		return "func:" + "{" + strings.Join(params, ",") + "}{" + strings.Join(results, ",") + "}"
 	case *types.Slice:
 		return "slice:" + getTypeCodeName(t.Elem())
@@ -241,7 +308,13 @@ func getTypeCodeName(t types.Type) string {
 			if t.Field(i).Embedded() {
 				embedded = "#"
 			}
-			elems[i] = embedded + t.Field(i).Name() + ":" + getTypeCodeName(t.Field(i).Type())
+			name := t.Field(i).Name()
+			if !token.IsExported(name) {
+				// Unexported fields are only identical if they are declared
+				// in the same package.
+				name = t.Field(i).Pkg().Path() + "." + name
+			}
+			elems[i] = embedded + name + ":" + getTypeCodeName(t.Field(i).Type())
 			if t.Tag(i) != "" {
 				elems[i] += "`" + t.Tag(i) + "`"
 			}
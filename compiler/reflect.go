@@ -0,0 +1,364 @@
+package compiler
+
+// This file creates the type information and wrapper functions needed to call
+// functions and methods through the reflect package: reflect.Value.Call,
+// reflect.MakeFunc and reflect.Value.Method. The type information is stored
+// in the typecodeID globals and is converted to sidetables in the reflect
+// lowering pass.
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/ssa"
+	"tinygo.org/x/go-llvm"
+)
+
+// makeFuncTypeSignature creates a new global that stores the signature of a
+// function type, together with the wrappers used to call a function of this
+// type through reflection. The global is a struct with the following fields:
+//
+//     {i1 variadic, [n x %runtime.typecodeID*] params, [m x %runtime.typecodeID*] results, uintptr callWrapper, uintptr makeFuncWrapper}
+//
+// It should be unreferenced after the reflect lowering pass.
+func (c *compilerContext) makeFuncTypeSignature(sig *types.Signature) llvm.Value {
+	// The receiver (if any) is not part of the function type.
+	sig = types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())
+
+	typecodePtrType := llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0)
+	params := make([]llvm.Value, sig.Params().Len())
+	for i := range params {
+		params[i] = c.getTypeCode(sig.Params().At(i).Type())
+	}
+	results := make([]llvm.Value, sig.Results().Len())
+	for i := range results {
+		results[i] = c.getTypeCode(sig.Results().At(i).Type())
+	}
+	variadic := uint64(0)
+	if sig.Variadic() {
+		variadic = 1
+	}
+	callWrapper := llvm.ConstPtrToInt(c.getReflectCallWrapper(sig), c.uintptrType)
+	makeFuncWrapper := llvm.ConstInt(c.uintptrType, 0, false)
+	if wrapper := c.getReflectMakeFuncWrapper(sig); !wrapper.IsNil() {
+		makeFuncWrapper = llvm.ConstPtrToInt(wrapper, c.uintptrType)
+	}
+	value := c.ctx.ConstStruct([]llvm.Value{
+		llvm.ConstInt(c.ctx.Int1Type(), variadic, false),
+		llvm.ConstArray(typecodePtrType, params),
+		llvm.ConstArray(typecodePtrType, results),
+		callWrapper,
+		makeFuncWrapper,
+	}, false)
+	global := llvm.AddGlobal(c.mod, value.Type(), "reflect/types.funcSignature")
+	global.SetInitializer(value)
+	global.SetGlobalConstant(true)
+	global.SetUnnamedAddr(true)
+	global.SetLinkage(llvm.PrivateLinkage)
+	return global
+}
+
+// makeMapTypeElems creates a new global with the key and value type of a map
+// type, as a [2 x %runtime.typecodeID*] array. It should be unreferenced after
+// the reflect lowering pass.
+func (c *compilerContext) makeMapTypeElems(typ *types.Map) llvm.Value {
+	typecodePtrType := llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0)
+	value := llvm.ConstArray(typecodePtrType, []llvm.Value{
+		c.getTypeCode(typ.Key()),
+		c.getTypeCode(typ.Elem()),
+	})
+	global := llvm.AddGlobal(c.mod, value.Type(), "reflect/types.mapTypes")
+	global.SetInitializer(value)
+	global.SetGlobalConstant(true)
+	global.SetUnnamedAddr(true)
+	global.SetLinkage(llvm.PrivateLinkage)
+	return global
+}
+
+// getTypeReflectMethods returns a reference (GEP) to a global with the methods
+// of the given type that can be called through reflection, or a nil value if
+// there are no such methods. Like in the reflect package, these are all
+// methods for interface types but only the exported methods for other types.
+// The global should be unreferenced after the reflect lowering pass.
+func (c *compilerContext) getTypeReflectMethods(typ types.Type) llvm.Value {
+	globalName := "reflect/types.methods:" + getTypeCodeName(typ)
+	global := c.mod.NamedGlobal(globalName)
+	zero := llvm.ConstInt(c.ctx.Int32Type(), 0, false)
+	if !global.IsNil() {
+		// the method list already exists
+		return llvm.ConstGEP(global, []llvm.Value{zero, zero})
+	}
+
+	reflectMethodType := c.getLLVMRuntimeType("reflectMethod")
+	typecodePtrType := llvm.PointerType(c.getLLVMRuntimeType("typecodeID"), 0)
+	var methods []llvm.Value
+	if itf, ok := typ.Underlying().(*types.Interface); ok {
+		// Interface methods can't be called directly, so only store the name
+		// and signature.
+		for i := 0; i < itf.NumMethods(); i++ {
+			method := itf.Method(i)
+			sig := method.Type().(*types.Signature)
+			methods = append(methods, llvm.ConstNamedStruct(reflectMethodType, []llvm.Value{
+				c.makeReflectMethodName(method.Name()),
+				c.getTypeCode(types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())),
+				llvm.ConstPointerNull(typecodePtrType),
+				llvm.ConstInt(c.uintptrType, 0, false),
+				llvm.ConstInt(c.uintptrType, 0, false),
+			}))
+		}
+	} else {
+		ms := c.program.MethodSets.MethodSet(typ)
+		for i := 0; i < ms.Len(); i++ {
+			method := ms.At(i)
+			if !method.Obj().Exported() {
+				// Unexported methods are not visible to the reflect package.
+				continue
+			}
+			fn := c.program.MethodValue(method)
+			llvmFn := c.getFunction(fn)
+			if llvmFn.IsNil() {
+				// compiler error, so panic
+				panic("cannot find function: " + c.getFunctionInfo(fn).linkName)
+			}
+			sig := fn.Signature
+			recvParams := []*types.Var{sig.Recv()}
+			for j := 0; j < sig.Params().Len(); j++ {
+				recvParams = append(recvParams, sig.Params().At(j))
+			}
+			funcType := types.NewSignature(nil, types.NewTuple(recvParams...), sig.Results(), sig.Variadic())
+			methods = append(methods, llvm.ConstNamedStruct(reflectMethodType, []llvm.Value{
+				c.makeReflectMethodName(method.Obj().Name()),
+				c.getTypeCode(types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic())),
+				c.getTypeCode(funcType),
+				llvm.ConstPtrToInt(c.getReflectMethodWrapper(fn, llvmFn), c.uintptrType),
+				llvm.ConstPtrToInt(llvmFn, c.uintptrType),
+			}))
+		}
+	}
+	if len(methods) == 0 {
+		return llvm.Value{}
+	}
+
+	value := llvm.ConstArray(reflectMethodType, methods)
+	global = llvm.AddGlobal(c.mod, value.Type(), globalName)
+	global.SetInitializer(value)
+	global.SetGlobalConstant(true)
+	global.SetLinkage(llvm.LinkOnceODRLinkage)
+	return llvm.ConstGEP(global, []llvm.Value{zero, zero})
+}
+
+// makeReflectMethodName returns a reference (GEP) to a new global with the
+// given method name.
+func (c *compilerContext) makeReflectMethodName(name string) llvm.Value {
+	nameGlobal := c.makeGlobalArray([]byte(name), "reflect/types.methodName", c.ctx.Int8Type())
+	nameGlobal.SetLinkage(llvm.PrivateLinkage)
+	nameGlobal.SetUnnamedAddr(true)
+	nameGlobal.SetGlobalConstant(true)
+	return llvm.ConstGEP(nameGlobal, []llvm.Value{
+		llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+		llvm.ConstInt(c.ctx.Int32Type(), 0, false),
+	})
+}
+
+// getReflectTupleType returns the type of the buffer used to pass parameters
+// or results from and to the reflect package. It is a regular struct, so that
+// the reflect package can calculate the field offsets the same way as for a
+// struct type.
+func (c *compilerContext) getReflectTupleType(tuple *types.Tuple) llvm.Type {
+	members := make([]llvm.Type, tuple.Len())
+	for i := range members {
+		members[i] = c.getLLVMType(tuple.At(i).Type())
+	}
+	return c.ctx.StructType(members, false)
+}
+
+// getReflectCallWrapper returns a wrapper function used by reflect.Value.Call
+// to call a function value of the given signature. It has the following
+// signature:
+//
+//     func(fn *func(...), args, results unsafe.Pointer)
+//
+// It loads all parameters from the args buffer, calls the function value and
+// stores the result(s) in the results buffer.
+func (c *compilerContext) getReflectCallWrapper(sig *types.Signature) llvm.Value {
+	wrapperName := "reflect/types.call:" + getTypeCodeName(sig)
+	wrapper := c.mod.NamedFunction(wrapperName)
+	if !wrapper.IsNil() {
+		// Wrapper already created. Return it directly.
+		return wrapper
+	}
+
+	// The last parameter is the (unused) context parameter.
+	wrapperType := llvm.FunctionType(c.ctx.VoidType(), []llvm.Type{c.i8ptrType, c.i8ptrType, c.i8ptrType, c.i8ptrType}, false)
+	wrapper = llvm.AddFunction(c.mod, wrapperName, wrapperType)
+	c.addStandardAttributes(wrapper)
+	wrapper.SetLinkage(llvm.LinkOnceODRLinkage)
+	wrapper.SetUnnamedAddr(true)
+
+	// Create a new builder just to create this wrapper.
+	b := builder{
+		compilerContext: c,
+		Builder:         c.ctx.NewBuilder(),
+	}
+	defer b.Builder.Dispose()
+
+	block := b.ctx.AddBasicBlock(wrapper, "entry")
+	b.SetInsertPointAtEnd(block)
+
+	// Load the function value that should be called.
+	funcValuePtr := b.CreateBitCast(wrapper.Param(0), llvm.PointerType(c.getFuncType(sig), 0), "")
+	funcPtr, context := b.decodeFuncValue(b.CreateLoad(funcValuePtr, ""), sig)
+
+	// Load all parameters from the args buffer.
+	var params []llvm.Value
+	argsType := c.getReflectTupleType(sig.Params())
+	argsPtr := b.CreateBitCast(wrapper.Param(1), llvm.PointerType(argsType, 0), "")
+	for i := 0; i < sig.Params().Len(); i++ {
+		gep := b.CreateStructGEP(argsPtr, i, "")
+		params = append(params, b.CreateLoad(gep, ""))
+	}
+	params = append(params, context)
+
+	// Call the function and store the result(s), if there are any. Multiple
+	// results are returned as a struct, with the same layout as the results
+	// buffer.
+	result := b.createCall(funcPtr, params, "")
+	if sig.Results().Len() != 0 {
+		resultsPtr := b.CreateBitCast(wrapper.Param(2), llvm.PointerType(result.Type(), 0), "")
+		b.CreateStore(result, resultsPtr)
+	}
+	b.CreateRetVoid()
+
+	return wrapper
+}
+
+// getReflectMakeFuncWrapper returns a function with the given signature that
+// is used as the function pointer in the function value created by
+// reflect.MakeFunc. It stores all parameters in a buffer, passes this buffer
+// to reflect.makeFuncStub (together with the context of the function value)
+// and returns the results stored in the results buffer.
+//
+// It returns a nil value if the reflect package is not part of the program.
+func (c *compilerContext) getReflectMakeFuncWrapper(sig *types.Signature) llvm.Value {
+	reflectPkg := c.program.ImportedPackage("reflect")
+	if reflectPkg == nil {
+		return llvm.Value{}
+	}
+	wrapperName := "reflect/types.makefunc:" + getTypeCodeName(sig)
+	wrapper := c.mod.NamedFunction(wrapperName)
+	if !wrapper.IsNil() {
+		// Wrapper already created. Return it directly.
+		return wrapper
+	}
+
+	wrapper = llvm.AddFunction(c.mod, wrapperName, c.getRawFuncType(sig).ElementType())
+	c.addStandardAttributes(wrapper)
+	wrapper.SetLinkage(llvm.LinkOnceODRLinkage)
+	wrapper.SetUnnamedAddr(true)
+
+	// Create a new builder just to create this wrapper.
+	b := builder{
+		compilerContext: c,
+		Builder:         c.ctx.NewBuilder(),
+	}
+	defer b.Builder.Dispose()
+
+	block := b.ctx.AddBasicBlock(wrapper, "entry")
+	b.SetInsertPointAtEnd(block)
+
+	// Convert the expanded parameters back to Go values.
+	var args []llvm.Value
+	llvmParams := wrapper.Params()
+	for i := 0; i < sig.Params().Len(); i++ {
+		llvmType := c.getLLVMType(sig.Params().At(i).Type())
+		numFields := len(c.expandFormalParamType(llvmType, "", nil))
+		args = append(args, b.collapseFormalParam(llvmType, llvmParams[:numFields]))
+		llvmParams = llvmParams[numFields:]
+	}
+	context := llvmParams[0]
+
+	// Allocate a single buffer for both the parameters and the results. This
+	// buffer must be allocated on the heap, as the function created with
+	// reflect.MakeFunc may keep references to it.
+	argsType := c.getReflectTupleType(sig.Params())
+	resultsType := c.getReflectTupleType(sig.Results())
+	frameType := c.ctx.StructType([]llvm.Type{argsType, resultsType}, false)
+	frameSize := llvm.ConstInt(c.uintptrType, c.targetData.TypeAllocSize(frameType), false)
+	frame := b.createRuntimeCall("alloc", []llvm.Value{frameSize, llvm.ConstNull(c.i8ptrType)}, "makefunc.frame")
+	if b.NeedsStackObjects {
+		b.trackPointer(frame)
+	}
+	frame = b.CreateBitCast(frame, llvm.PointerType(frameType, 0), "")
+	argsPtr := b.CreateStructGEP(frame, 0, "")
+	for i, arg := range args {
+		b.CreateStore(arg, b.CreateStructGEP(argsPtr, i, ""))
+	}
+	resultsPtr := b.CreateStructGEP(frame, 1, "")
+
+	// Call reflect.makeFuncStub, which calls the Go function passed to
+	// reflect.MakeFunc.
+	stub := c.getFunction(reflectPkg.Members["makeFuncStub"].(*ssa.Function))
+	impl := b.CreateBitCast(context, stub.Type().ElementType().ParamTypes()[0], "")
+	b.createCall(stub, []llvm.Value{
+		impl,
+		b.CreateBitCast(argsPtr, c.i8ptrType, ""),
+		b.CreateBitCast(resultsPtr, c.i8ptrType, ""),
+		llvm.Undef(c.i8ptrType),
+	}, "")
+
+	// Return the results stored in the results buffer.
+	switch sig.Results().Len() {
+	case 0:
+		b.CreateRetVoid()
+	case 1:
+		b.CreateRet(b.CreateLoad(b.CreateStructGEP(resultsPtr, 0, ""), ""))
+	default:
+		b.CreateRet(b.CreateLoad(resultsPtr, ""))
+	}
+
+	return wrapper
+}
+
+// getReflectMethodWrapper returns a wrapper for the given method so that it can
+// be called as a function value with the receiver stored in the context
+// parameter. This is what reflect.Value.Method returns. The receiver is packed
+// in the context the same way as it would be stored in an interface.
+func (c *compilerContext) getReflectMethodWrapper(fn *ssa.Function, llvmFn llvm.Value) llvm.Value {
+	wrapperName := llvmFn.Name() + "$reflect"
+	wrapper := c.mod.NamedFunction(wrapperName)
+	if !wrapper.IsNil() {
+		// Wrapper already created. Return it directly.
+		return wrapper
+	}
+
+	sig := types.NewSignature(nil, fn.Signature.Params(), fn.Signature.Results(), fn.Signature.Variadic())
+	wrapper = llvm.AddFunction(c.mod, wrapperName, c.getRawFuncType(sig).ElementType())
+	c.addStandardAttributes(wrapper)
+	wrapper.SetLinkage(llvm.LinkOnceODRLinkage)
+	wrapper.SetUnnamedAddr(true)
+
+	// Create a new builder just to create this wrapper.
+	b := builder{
+		compilerContext: c,
+		Builder:         c.ctx.NewBuilder(),
+	}
+	defer b.Builder.Dispose()
+
+	block := b.ctx.AddBasicBlock(wrapper, "entry")
+	b.SetInsertPointAtEnd(block)
+
+	// The receiver is stored in the context parameter.
+	params := wrapper.Params()
+	receiverType := c.getLLVMType(fn.Signature.Recv().Type())
+	receiverValue := b.emitPointerUnpack(params[len(params)-1], []llvm.Type{receiverType})[0]
+	args := append(b.expandFormalParam(receiverValue), params[:len(params)-1]...)
+	args = append(args, llvm.Undef(c.i8ptrType))
+	if llvmFn.Type().ElementType().ReturnType().TypeKind() == llvm.VoidTypeKind {
+		b.CreateCall(llvmFn, args, "")
+		b.CreateRetVoid()
+	} else {
+		ret := b.CreateCall(llvmFn, args, "ret")
+		b.CreateRet(ret)
+	}
+
+	return wrapper
+}
@@ -22,6 +22,7 @@ import (
 // present.
 type functionInfo struct {
 	module     string     // go:wasm-module
+	wasmABI    string     // go:wasm-abi
 	importName string     // go:linkname, go:export - The name the developer assigns
 	linkName   string     // go:linkname, go:export - The name that we map for the particular module -> importName
 	section    string     // go:section - object file section name
@@ -256,6 +257,13 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 					continue
 				}
 				info.module = parts[1]
+			case "//go:wasm-abi":
+				// Convert strings and byte slices to pointer/length pairs in
+				// an exported or imported function. See wasmabi.go.
+				if len(parts) != 2 || parts[1] != "ptrlen" {
+					continue
+				}
+				info.wasmABI = parts[1]
 			case "//go:inline":
 				info.inline = inlineHint
 			case "//go:noinline":
@@ -297,13 +305,22 @@ func (info *functionInfo) parsePragmas(f *ssa.Function) {
 		}
 
 		// Set the importName for our exported function if we have one
-		if importName != "" {
+		if importName != "" && info.wasmABI != "" {
+			// The function is exported or imported through a wrapper that
+			// converts between the Go ABI and the WebAssembly ABI. The Go
+			// function itself is a regular Go function.
+			info.exported = false
+			info.importName = importName
+		} else if importName != "" {
 			if info.module == "" {
 				info.linkName = importName
 			} else {
 				// WebAssembly import
 				info.importName = importName
 			}
+		} else {
+			// //go:wasm-abi only applies to exported and imported functions.
+			info.wasmABI = ""
 		}
 
 	}
@@ -374,10 +391,11 @@ func (c *compilerContext) addStandardAttributes(llvmFn llvm.Value) {
 // linkName is equal to .RelString(nil) on a global and extern is false, but for
 // some symbols this is different (due to //go:extern for example).
 type globalInfo struct {
-	linkName string // go:extern
-	extern   bool   // go:extern
-	align    int    // go:align
-	section  string // go:section
+	linkName    string // go:extern
+	extern      bool   // go:extern
+	align       int    // go:align
+	section     string // go:section
+	threadLocal bool   // go:threadlocal
 }
 
 // loadASTComments loads comments on globals from the AST, for use later in the
@@ -416,6 +434,11 @@ func (c *compilerContext) getGlobal(g *ssa.Global) llvm.Value {
 		typ := g.Type().(*types.Pointer).Elem()
 		llvmType := c.getLLVMType(typ)
 		llvmGlobal = llvm.AddGlobal(c.mod, llvmType, info.linkName)
+		if info.threadLocal {
+			// Each thread has its own copy of this global. This is only
+			// relevant on systems where goroutines run on multiple threads.
+			llvmGlobal.SetThreadLocal(true)
+		}
 
 		// Set alignment from the //go:align comment.
 		var alignInBits uint32
@@ -489,6 +512,8 @@ func (info *globalInfo) parsePragmas(doc *ast.CommentGroup) {
 			if len(parts) == 2 {
 				info.section = parts[1]
 			}
+		case "//go:threadlocal":
+			info.threadLocal = true
 		}
 	}
 }
@@ -3,23 +3,37 @@ source_filename = "interface.go"
 target datalayout = "e-m:e-p:32:32-i64:64-n32:64-S128-ni:1:10:20"
 target triple = "wasm32-unknown-wasi"
 
-%runtime.typecodeID = type { %runtime.typecodeID*, i32, %runtime.interfaceMethodInfo*, %runtime.typecodeID*, i32 }
+%runtime.typecodeID = type { %runtime.typecodeID*, i32, %runtime.interfaceMethodInfo*, %runtime.typecodeID*, i32, %runtime.reflectMethod*, i8* }
 %runtime.interfaceMethodInfo = type { i8*, i32 }
+%runtime.reflectMethod = type { i8*, %runtime.typecodeID*, %runtime.typecodeID*, i32, i32 }
 %runtime._interface = type { i32, i8* }
 %runtime._string = type { i8*, i32 }
+%reflect.makeFuncImpl = type { i32, { i8*, void ()* } }
 
-@"reflect/types.type:basic:int" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* null, i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:basic:int", i32 0 }
-@"reflect/types.type:pointer:basic:int" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:basic:int", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0 }
-@"reflect/types.type:pointer:named:error" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:named:error", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0 }
-@"reflect/types.type:named:error" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{Error:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:named:error", i32 ptrtoint (i1 (i32)* @"interface:{Error:func:{}{basic:string}}.$typeassert" to i32) }
-@"reflect/types.type:interface:{Error:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* bitcast ([1 x i8*]* @"reflect/types.interface:interface{Error() string}$interface" to %runtime.typecodeID*), i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:interface:{Error:func:{}{basic:string}}", i32 ptrtoint (i1 (i32)* @"interface:{Error:func:{}{basic:string}}.$typeassert" to i32) }
+@"reflect/types.type:basic:int" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* null, i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:basic:int", i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:pointer:basic:int" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:basic:int", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:pointer:named:error" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:named:error", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:named:error" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{Error:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:named:error", i32 ptrtoint (i1 (i32)* @"interface:{Error:func:{}{basic:string}}.$typeassert" to i32), %runtime.reflectMethod* getelementptr inbounds ([1 x %runtime.reflectMethod], [1 x %runtime.reflectMethod]* @"reflect/types.methods:named:error", i32 0, i32 0), i8* getelementptr inbounds ([5 x i8], [5 x i8]* @"reflect/types.typeName", i32 0, i32 0) }
+@"reflect/types.type:interface:{Error:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* bitcast ([1 x i8*]* @"reflect/types.interface:interface{Error() string}$interface" to %runtime.typecodeID*), i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:interface:{Error:func:{}{basic:string}}", i32 ptrtoint (i1 (i32)* @"interface:{Error:func:{}{basic:string}}.$typeassert" to i32), %runtime.reflectMethod* getelementptr inbounds ([1 x %runtime.reflectMethod], [1 x %runtime.reflectMethod]* @"reflect/types.methods:interface:{Error:func:{}{basic:string}}", i32 0, i32 0), i8* null }
 @"reflect/methods.Error() string" = linkonce_odr constant i8 0, align 1
 @"reflect/types.interface:interface{Error() string}$interface" = linkonce_odr constant [1 x i8*] [i8* @"reflect/methods.Error() string"]
-@"reflect/types.type:pointer:interface:{Error:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{Error:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0 }
-@"reflect/types.type:pointer:interface:{String:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{String:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0 }
-@"reflect/types.type:interface:{String:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* bitcast ([1 x i8*]* @"reflect/types.interface:interface{String() string}$interface" to %runtime.typecodeID*), i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:interface:{String:func:{}{basic:string}}", i32 ptrtoint (i1 (i32)* @"interface:{String:func:{}{basic:string}}.$typeassert" to i32) }
+@"reflect/types.methodName" = private unnamed_addr constant [5 x i8] c"Error"
+@"reflect/types.type:func:{}{basic:string}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* bitcast ({ i1, [0 x %runtime.typecodeID*], [1 x %runtime.typecodeID*], i32, i32 }* @"reflect/types.funcSignature" to %runtime.typecodeID*), i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:func:{}{basic:string}", i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:basic:string" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* null, i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:basic:string", i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:pointer:basic:string" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:basic:string", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.funcSignature" = private unnamed_addr constant { i1, [0 x %runtime.typecodeID*], [1 x %runtime.typecodeID*], i32, i32 } { i1 false, [0 x %runtime.typecodeID*] zeroinitializer, [1 x %runtime.typecodeID*] [%runtime.typecodeID* @"reflect/types.type:basic:string"], i32 ptrtoint (void (i8*, i8*, i8*, i8*)* @"reflect/types.call:func:{}{basic:string}" to i32), i32 ptrtoint (%runtime._string (i8*)* @"reflect/types.makefunc:func:{}{basic:string}" to i32) }
+@"reflect/types.type:pointer:func:{}{basic:string}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:func:{}{basic:string}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.methods:interface:{Error:func:{}{basic:string}}" = linkonce_odr constant [1 x %runtime.reflectMethod] [%runtime.reflectMethod { i8* getelementptr inbounds ([5 x i8], [5 x i8]* @"reflect/types.methodName", i32 0, i32 0), %runtime.typecodeID* @"reflect/types.type:func:{}{basic:string}", %runtime.typecodeID* null, i32 0, i32 0 }]
+@"reflect/types.type:pointer:interface:{Error:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{Error:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.typeName" = private unnamed_addr constant [5 x i8] c"error"
+@"reflect/types.methodName.1" = private unnamed_addr constant [5 x i8] c"Error"
+@"reflect/types.methods:named:error" = linkonce_odr constant [1 x %runtime.reflectMethod] [%runtime.reflectMethod { i8* getelementptr inbounds ([5 x i8], [5 x i8]* @"reflect/types.methodName.1", i32 0, i32 0), %runtime.typecodeID* @"reflect/types.type:func:{}{basic:string}", %runtime.typecodeID* null, i32 0, i32 0 }]
+@"reflect/types.type:pointer:interface:{String:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* @"reflect/types.type:interface:{String:func:{}{basic:string}}", i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* null, i32 0, %runtime.reflectMethod* null, i8* null }
+@"reflect/types.type:interface:{String:func:{}{basic:string}}" = linkonce_odr constant %runtime.typecodeID { %runtime.typecodeID* bitcast ([1 x i8*]* @"reflect/types.interface:interface{String() string}$interface" to %runtime.typecodeID*), i32 0, %runtime.interfaceMethodInfo* null, %runtime.typecodeID* @"reflect/types.type:pointer:interface:{String:func:{}{basic:string}}", i32 ptrtoint (i1 (i32)* @"interface:{String:func:{}{basic:string}}.$typeassert" to i32), %runtime.reflectMethod* getelementptr inbounds ([1 x %runtime.reflectMethod], [1 x %runtime.reflectMethod]* @"reflect/types.methods:interface:{String:func:{}{basic:string}}", i32 0, i32 0), i8* null }
 @"reflect/methods.String() string" = linkonce_odr constant i8 0, align 1
 @"reflect/types.interface:interface{String() string}$interface" = linkonce_odr constant [1 x i8*] [i8* @"reflect/methods.String() string"]
+@"reflect/types.methodName.2" = private unnamed_addr constant [6 x i8] c"String"
+@"reflect/types.methods:interface:{String:func:{}{basic:string}}" = linkonce_odr constant [1 x %runtime.reflectMethod] [%runtime.reflectMethod { i8* getelementptr inbounds ([6 x i8], [6 x i8]* @"reflect/types.methodName.2", i32 0, i32 0), %runtime.typecodeID* @"reflect/types.type:func:{}{basic:string}", %runtime.typecodeID* null, i32 0, i32 0 }]
 @"reflect/types.typeid:basic:int" = external constant i8
 
 declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*)
@@ -53,6 +67,44 @@ entry:
   ret %runtime._interface { i32 ptrtoint (%runtime.typecodeID* @"reflect/types.type:pointer:named:error" to i32), i8* null }
 }
 
+; Function Attrs: nounwind
+define linkonce_odr void @"reflect/types.call:func:{}{basic:string}"(i8* %0, i8* %1, i8* %2, i8* %3) unnamed_addr #0 {
+entry:
+  %.elt = bitcast i8* %0 to i8**
+  %.unpack = load i8*, i8** %.elt, align 4
+  %.elt1 = getelementptr inbounds i8, i8* %0, i32 4
+  %4 = bitcast i8* %.elt1 to %runtime._string (i8*)**
+  %.unpack23 = load %runtime._string (i8*)*, %runtime._string (i8*)** %4, align 4
+  %5 = call %runtime._string %.unpack23(i8* %.unpack) #0
+  %.repack = bitcast i8* %2 to i8**
+  %.elt4 = extractvalue %runtime._string %5, 0
+  store i8* %.elt4, i8** %.repack, align 4
+  %.repack5 = getelementptr inbounds i8, i8* %2, i32 4
+  %6 = bitcast i8* %.repack5 to i32*
+  %.elt6 = extractvalue %runtime._string %5, 1
+  store i32 %.elt6, i32* %6, align 4
+  ret void
+}
+
+; Function Attrs: nounwind
+define linkonce_odr %runtime._string @"reflect/types.makefunc:func:{}{basic:string}"(i8* %0) unnamed_addr #0 {
+entry:
+  %makefunc.frame = call i8* @runtime.alloc(i32 8, i8* null, i8* undef) #0
+  call void @runtime.trackPointer(i8* nonnull %makefunc.frame, i8* undef) #0
+  %1 = bitcast i8* %0 to %reflect.makeFuncImpl*
+  call void @reflect.makeFuncStub(%reflect.makeFuncImpl* %1, i8* nonnull %makefunc.frame, i8* nonnull %makefunc.frame, i8* undef) #0
+  %.elt = bitcast i8* %makefunc.frame to i8**
+  %.unpack = load i8*, i8** %.elt, align 4
+  %2 = insertvalue %runtime._string undef, i8* %.unpack, 0
+  %.elt1 = getelementptr inbounds i8, i8* %makefunc.frame, i32 4
+  %3 = bitcast i8* %.elt1 to i32*
+  %.unpack2 = load i32, i32* %3, align 4
+  %4 = insertvalue %runtime._string %2, i32 %.unpack2, 1
+  ret %runtime._string %4
+}
+
+declare void @reflect.makeFuncStub(%reflect.makeFuncImpl* dereferenceable_or_null(12), i8*, i8*, i8*)
+
 declare i1 @"interface:{Error:func:{}{basic:string}}.$typeassert"(i32) #1
 
 ; Function Attrs: nounwind
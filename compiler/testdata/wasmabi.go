@@ -0,0 +1,28 @@
+package main
+
+// Exported function with the ptrlen ABI: the string parameter is passed as a
+// pointer and a length, and the string result is returned as a single i64.
+//export greet
+//go:wasm-abi ptrlen
+func greet(name string) string {
+	return name
+}
+
+// Exported function with a byte slice and a regular parameter.
+//export bufferLen
+//go:wasm-abi ptrlen
+func bufferLen(buf []byte, extra int32) int32 {
+	return int32(len(buf)) + extra
+}
+
+// Imported function with the ptrlen ABI.
+//export log
+//go:wasm-module host
+//go:wasm-abi ptrlen
+func hostLog(msg string)
+
+// Imported function that returns a byte slice allocated by the host.
+//export readInput
+//go:wasm-module host
+//go:wasm-abi ptrlen
+func readInput(max int32) []byte
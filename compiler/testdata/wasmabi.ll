@@ -0,0 +1,89 @@
+; ModuleID = 'wasmabi.go'
+source_filename = "wasmabi.go"
+target datalayout = "e-m:e-p:32:32-i64:64-n32:64-S128-ni:1:10:20"
+target triple = "wasm32-unknown-wasi"
+
+%runtime._string = type { i8*, i32 }
+
+declare noalias nonnull i8* @runtime.alloc(i32, i8*, i8*)
+
+declare void @runtime.trackPointer(i8* nocapture readonly, i8*)
+
+; Function Attrs: nounwind
+define hidden void @main.init(i8* %context) unnamed_addr #0 {
+entry:
+  ret void
+}
+
+; Function Attrs: nounwind
+define hidden %runtime._string @main.greet(i8* %name.data, i32 %name.len, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = insertvalue %runtime._string undef, i8* %name.data, 0
+  %1 = insertvalue %runtime._string %0, i32 %name.len, 1
+  ret %runtime._string %1
+}
+
+; Function Attrs: nounwind
+define i64 @greet(i8* %0, i32 %1) #1 {
+entry:
+  %2 = call %runtime._string @main.greet(i8* %0, i32 %1, i8* undef)
+  %3 = extractvalue %runtime._string %2, 0
+  call void @runtime.wasmPin(i8* %3, i8* undef) #0
+  %4 = extractvalue %runtime._string %2, 1
+  %5 = ptrtoint i8* %3 to i32
+  %6 = zext i32 %5 to i64
+  %7 = shl nuw i64 %6, 32
+  %8 = zext i32 %4 to i64
+  %9 = or i64 %7, %8
+  ret i64 %9
+}
+
+declare void @runtime.wasmPin(i8*, i8*)
+
+; Function Attrs: nounwind
+define hidden i32 @main.bufferLen(i8* %buf.data, i32 %buf.len, i32 %buf.cap, i32 %extra, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = add i32 %buf.len, %extra
+  ret i32 %0
+}
+
+; Function Attrs: nounwind
+define i32 @bufferLen(i8* %0, i32 %1, i32 %2) #2 {
+entry:
+  %3 = call i32 @main.bufferLen(i8* %0, i32 %1, i32 %1, i32 %2, i8* undef)
+  ret i32 %3
+}
+
+; Function Attrs: nounwind
+define hidden void @main.hostLog(i8* %msg.data, i32 %msg.len, i8* %context) unnamed_addr #0 {
+entry:
+  call void @"main.hostLog$wasmimport"(i8* %msg.data, i32 %msg.len) #0
+  ret void
+}
+
+declare void @"main.hostLog$wasmimport"(i8*, i32) #3
+
+; Function Attrs: nounwind
+define hidden { i8*, i32, i32 } @main.readInput(i32 %max, i8* %context) unnamed_addr #0 {
+entry:
+  %0 = call i64 @"main.readInput$wasmimport"(i32 %max) #0
+  %1 = lshr i64 %0, 32
+  %2 = trunc i64 %1 to i32
+  %3 = inttoptr i32 %2 to i8*
+  %4 = trunc i64 %0 to i32
+  call void @runtime.wasmUnpin(i8* %3, i8* undef) #0
+  %5 = insertvalue { i8*, i32, i32 } undef, i8* %3, 0
+  %6 = insertvalue { i8*, i32, i32 } %5, i32 %4, 1
+  %7 = insertvalue { i8*, i32, i32 } %6, i32 %4, 2
+  ret { i8*, i32, i32 } %7
+}
+
+declare i64 @"main.readInput$wasmimport"(i32) #4
+
+declare void @runtime.wasmUnpin(i8*, i8*)
+
+attributes #0 = { nounwind }
+attributes #1 = { nounwind "wasm-export-name"="greet" }
+attributes #2 = { nounwind "wasm-export-name"="bufferLen" }
+attributes #3 = { "wasm-import-module"="host" "wasm-import-name"="log" }
+attributes #4 = { "wasm-import-module"="host" "wasm-import-name"="readInput" }
@@ -0,0 +1,245 @@
+package compiler
+
+// This file implements the ptrlen ABI for exported and imported WebAssembly
+// functions, which is enabled with the //go:wasm-abi ptrlen pragma:
+//
+//     //export greet
+//     //go:wasm-abi ptrlen
+//     func greet(name string) string {
+//         return "Hello, " + name
+//     }
+//
+// In this ABI, a string or []byte parameter is passed as a pointer and a length
+// (two i32 values). A string or []byte result is returned as a single i64 value
+// with the pointer in the upper 32 bits and the length in the lower 32 bits.
+// All other parameters and results must be booleans, integers, floats or
+// pointers, and there can be at most one result.
+//
+// Memory is shared with the host like this:
+//   - The host allocates memory for parameters of exported functions with the
+//     exported malloc function, and releases it with free after the call.
+//   - Results of exported functions are kept alive until the host passes the
+//     pointer to free.
+//   - Parameters of imported functions are only valid during the call.
+//   - Results of imported functions must be allocated with malloc. They're
+//     owned by Go after the call, so the host must not free them.
+//
+// The Go function itself uses the regular Go ABI and can be called from Go as
+// usual. A wrapper is created that converts between the two ABIs.
+
+import (
+	"go/types"
+	"strings"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// isWasmABIBuffer returns whether the given type is passed as a pointer and a
+// length in the ptrlen ABI: a string or a byte slice.
+func isWasmABIBuffer(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Basic:
+		return t.Kind() == types.String
+	case *types.Slice:
+		return types.Identical(t.Elem(), types.Typ[types.Byte])
+	}
+	return false
+}
+
+// getWasmABIParamTypes returns the LLVM types of a parameter with the given Go
+// type in the ptrlen ABI, or nil if the type is not supported.
+func (c *compilerContext) getWasmABIParamTypes(t types.Type) []llvm.Type {
+	if isWasmABIBuffer(t) {
+		return []llvm.Type{c.i8ptrType, c.uintptrType}
+	}
+	switch typ := t.Underlying().(type) {
+	case *types.Basic:
+		if typ.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat) != 0 || typ.Kind() == types.UnsafePointer {
+			return []llvm.Type{c.getLLVMType(t)}
+		}
+	case *types.Pointer:
+		return []llvm.Type{c.getLLVMType(t)}
+	}
+	return nil
+}
+
+// getWasmABIFunctionType returns the LLVM function type of the given signature
+// in the ptrlen ABI. If the signature is not supported, it returns an error
+// message instead.
+func (c *compilerContext) getWasmABIFunctionType(sig *types.Signature) (llvm.Type, string) {
+	if !strings.HasPrefix(c.Triple, "wasm") {
+		return llvm.Type{}, "//go:wasm-abi is only supported on WebAssembly"
+	}
+	if sig.Recv() != nil || sig.Variadic() {
+		return llvm.Type{}, "//go:wasm-abi: methods and variadic functions are not supported"
+	}
+	var paramTypes []llvm.Type
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		fieldTypes := c.getWasmABIParamTypes(t)
+		if fieldTypes == nil {
+			return llvm.Type{}, "//go:wasm-abi: unsupported parameter type " + t.String()
+		}
+		paramTypes = append(paramTypes, fieldTypes...)
+	}
+	returnType := c.ctx.VoidType()
+	switch sig.Results().Len() {
+	case 0:
+	case 1:
+		t := sig.Results().At(0).Type()
+		if isWasmABIBuffer(t) {
+			returnType = c.ctx.Int64Type()
+		} else if fieldTypes := c.getWasmABIParamTypes(t); fieldTypes != nil {
+			returnType = fieldTypes[0]
+		} else {
+			return llvm.Type{}, "//go:wasm-abi: unsupported result type " + t.String()
+		}
+	default:
+		return llvm.Type{}, "//go:wasm-abi: multiple results are not supported"
+	}
+	return llvm.FunctionType(returnType, paramTypes, false), ""
+}
+
+// createWasmABIExport creates the exported function for a Go function with
+// //export and //go:wasm-abi ptrlen. The exported function converts its
+// parameters to Go values, calls the Go function, and converts the result back.
+// It returns the exported function, or a nil value if the signature is not
+// supported.
+func (b *builder) createWasmABIExport() llvm.Value {
+	sig := b.fn.Signature
+	fnType, errMsg := b.getWasmABIFunctionType(sig)
+	if errMsg != "" {
+		b.addError(b.fn.Pos(), errMsg)
+		return llvm.Value{}
+	}
+	if !b.mod.NamedFunction(b.info.importName).IsNil() {
+		b.addError(b.fn.Pos(), b.info.importName+" redeclared in this program")
+		return llvm.Value{}
+	}
+	wrapper := llvm.AddFunction(b.mod, b.info.importName, fnType)
+	b.addStandardDefinedAttributes(wrapper)
+	wrapper.AddFunctionAttr(b.ctx.CreateStringAttribute("wasm-export-name", b.info.importName))
+
+	// Create a new builder just to create this wrapper.
+	wb := &builder{
+		compilerContext: b.compilerContext,
+		Builder:         b.ctx.NewBuilder(),
+	}
+	defer wb.Builder.Dispose()
+	wb.SetInsertPointAtEnd(wb.ctx.AddBasicBlock(wrapper, "entry"))
+
+	// Convert the parameters to Go values.
+	var args []llvm.Value
+	params := wrapper.Params()
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		if isWasmABIBuffer(t) {
+			args = append(args, wb.createWasmABIBuffer(t, params[0], params[1]))
+			params = params[2:]
+		} else {
+			args = append(args, params[0])
+			params = params[1:]
+		}
+	}
+	args = append(args, llvm.Undef(b.i8ptrType)) // unused context parameter
+
+	// Call the Go function and convert the result.
+	result := wb.createCall(b.llvmFn, args, "")
+	if sig.Results().Len() == 0 {
+		wb.CreateRetVoid()
+	} else if isWasmABIBuffer(sig.Results().At(0).Type()) {
+		// Keep the result alive until the host calls free.
+		ptr := wb.CreateExtractValue(result, 0, "")
+		wb.createRuntimeCall("wasmPin", []llvm.Value{ptr}, "")
+		wb.CreateRet(wb.packWasmABIBuffer(ptr, wb.CreateExtractValue(result, 1, "")))
+	} else {
+		wb.CreateRet(result)
+	}
+	return wrapper
+}
+
+// createWasmABIImport defines the body of a Go function declared with
+// //export, //go:wasm-module and //go:wasm-abi ptrlen. The body converts its
+// parameters to the ptrlen ABI, calls the imported function, and converts the
+// result back.
+func (b *builder) createWasmABIImport() {
+	sig := b.fn.Signature
+	fnType, errMsg := b.getWasmABIFunctionType(sig)
+	if errMsg != "" {
+		b.addError(b.fn.Pos(), errMsg)
+		return
+	}
+	module := b.info.module
+	if module == "" {
+		// This is the default module of wasm-ld.
+		module = "env"
+	}
+	importFn := llvm.AddFunction(b.mod, b.info.linkName+"$wasmimport", fnType)
+	importFn.AddFunctionAttr(b.ctx.CreateStringAttribute("wasm-import-module", module))
+	importFn.AddFunctionAttr(b.ctx.CreateStringAttribute("wasm-import-name", b.info.importName))
+	b.addStandardDeclaredAttributes(importFn)
+
+	b.addStandardDefinedAttributes(b.llvmFn)
+	b.llvmFn.SetVisibility(llvm.HiddenVisibility)
+	b.llvmFn.SetUnnamedAddr(true)
+
+	// Create a new builder just to create this wrapper.
+	wb := &builder{
+		compilerContext: b.compilerContext,
+		Builder:         b.ctx.NewBuilder(),
+	}
+	defer wb.Builder.Dispose()
+	wb.SetInsertPointAtEnd(wb.ctx.AddBasicBlock(b.llvmFn, "entry"))
+
+	// Convert the Go parameters to the ptrlen ABI.
+	var args []llvm.Value
+	params := b.llvmFn.Params()
+	for i := 0; i < sig.Params().Len(); i++ {
+		t := sig.Params().At(i).Type()
+		llvmType := b.getLLVMType(t)
+		numFields := len(b.expandFormalParamType(llvmType, "", t))
+		if isWasmABIBuffer(t) {
+			value := wb.collapseFormalParam(llvmType, params[:numFields])
+			args = append(args, wb.CreateExtractValue(value, 0, ""), wb.CreateExtractValue(value, 1, ""))
+		} else {
+			args = append(args, params[0])
+		}
+		params = params[numFields:]
+	}
+
+	// Call the imported function and convert the result.
+	result := wb.CreateCall(importFn, args, "")
+	if sig.Results().Len() == 0 {
+		wb.CreateRetVoid()
+	} else if t := sig.Results().At(0).Type(); isWasmABIBuffer(t) {
+		// The result was allocated by the host with malloc. From now on, it
+		// is managed by the garbage collector.
+		ptr := wb.CreateIntToPtr(wb.CreateTrunc(wb.CreateLShr(result, llvm.ConstInt(result.Type(), 32, false), ""), b.uintptrType, ""), b.i8ptrType, "")
+		length := wb.CreateTrunc(result, b.uintptrType, "")
+		wb.createRuntimeCall("wasmUnpin", []llvm.Value{ptr}, "")
+		wb.CreateRet(wb.createWasmABIBuffer(t, ptr, length))
+	} else {
+		wb.CreateRet(result)
+	}
+}
+
+// createWasmABIBuffer creates a string or byte slice of the given Go type from
+// a pointer and a length.
+func (b *builder) createWasmABIBuffer(t types.Type, ptr, length llvm.Value) llvm.Value {
+	value := llvm.Undef(b.getLLVMType(t))
+	value = b.CreateInsertValue(value, ptr, 0, "")
+	value = b.CreateInsertValue(value, length, 1, "")
+	if _, ok := t.Underlying().(*types.Slice); ok {
+		value = b.CreateInsertValue(value, length, 2, "") // cap
+	}
+	return value
+}
+
+// packWasmABIBuffer packs a pointer and a length into a single i64 value, with
+// the pointer in the upper 32 bits.
+func (b *builder) packWasmABIBuffer(ptr, length llvm.Value) llvm.Value {
+	i64Type := b.ctx.Int64Type()
+	ptrValue := b.CreateZExt(b.CreatePtrToInt(ptr, b.uintptrType, ""), i64Type, "")
+	ptrValue = b.CreateShl(ptrValue, llvm.ConstInt(i64Type, 32, false), "")
+	return b.CreateOr(ptrValue, b.CreateZExt(length, i64Type, ""), "")
+}
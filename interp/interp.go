@@ -161,6 +161,7 @@ func Run(mod llvm.Module, debug bool) error {
 			newGlobal.SetInitializer(initializer)
 			newGlobal.SetLinkage(obj.llvmGlobal.Linkage())
 			newGlobal.SetAlignment(obj.llvmGlobal.Alignment())
+			newGlobal.SetThreadLocal(obj.llvmGlobal.IsThreadLocal())
 			// TODO: copy debug info, unnamed_addr, ...
 			bitcast := llvm.ConstBitCast(newGlobal, obj.llvmGlobal.Type())
 			obj.llvmGlobal.ReplaceAllUsesWith(bitcast)
@@ -60,6 +60,8 @@ type PackageJSON struct {
 	GoFiles  []string
 	CgoFiles []string
 	CFiles   []string
+	CXXFiles []string
+	SFiles   []string
 
 	// Dependency information
 	Imports   []string
@@ -81,6 +83,7 @@ type Package struct {
 	Files      []*ast.File
 	FileHashes map[string][]byte
 	CFlags     []string // CFlags used during CGo preprocessing (only set if CGo is used)
+	CXXFlags   []string // CXXFlags used for C++ files (only set if CGo is used)
 	CGoHeaders []string // text above 'import "C"' lines
 	Pkg        *types.Package
 	info       types.Info
@@ -418,8 +421,10 @@ func (p *Package) parseFiles() ([]*ast.File, error) {
 		var initialCFlags []string
 		initialCFlags = append(initialCFlags, p.program.config.CFlags()...)
 		initialCFlags = append(initialCFlags, "-I"+p.Dir)
-		generated, headerCode, cflags, ldflags, accessedFiles, errs := cgo.Process(files, p.program.workingDir, p.program.fset, initialCFlags, p.program.clangHeaders)
+		buildTags := append([]string{p.program.config.GOOS(), p.program.config.GOARCH()}, p.program.config.BuildTags()...)
+		generated, headerCode, cflags, cxxflags, ldflags, accessedFiles, errs := cgo.Process(files, p.program.workingDir, p.program.fset, initialCFlags, buildTags, p.program.clangHeaders)
 		p.CFlags = append(initialCFlags, cflags...)
+		p.CXXFlags = append(append(p.program.config.CXXFlags(), initialCFlags...), cxxflags...)
 		p.CGoHeaders = headerCode
 		for path, hash := range accessedFiles {
 			p.FileHashes[path] = hash
@@ -1197,6 +1197,7 @@ func main() {
 	command := os.Args[1]
 
 	opt := flag.String("opt", "z", "optimization level: 0, 1, 2, s, z")
+	buildMode := flag.String("buildmode", "", "build mode: default, or c-shared to build a WASI library with _initialize instead of _start")
 	gc := flag.String("gc", "", "garbage collector to use (none, leaking, conservative)")
 	panicStrategy := flag.String("panic", "print", "panic strategy (print, trap)")
 	scheduler := flag.String("scheduler", "", "which scheduler to use (none, tasks, asyncify)")
@@ -1286,6 +1287,7 @@ func main() {
 		GOARM:           goenv.Get("GOARM"),
 		Target:          *target,
 		Opt:             *opt,
+		BuildMode:       *buildMode,
 		GC:              *gc,
 		PanicStrategy:   *panicStrategy,
 		Scheduler:       *scheduler,
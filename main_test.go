@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -121,6 +122,13 @@ func TestBuild(t *testing.T) {
 			}
 			runTestWithConfig("ldflags.go", t, opts, nil, nil)
 		})
+
+		t.Run("maps.deterministic", func(t *testing.T) {
+			t.Parallel()
+			opts := optionsFromTarget("", sema)
+			opts.Tags = "maps.deterministic"
+			runTestWithConfig("mapdeterministic.go", t, opts, nil, nil)
+		})
 	})
 
 	if testing.Short() {
@@ -203,6 +211,18 @@ func TestBuild(t *testing.T) {
 			t.Parallel()
 			runPlatTests(optionsFromTarget("wasi", sema), tests, t)
 		})
+		t.Run("WASIThreads", func(t *testing.T) {
+			t.Parallel()
+			options := optionsFromTarget("wasi-threads", sema)
+			emuCheck(t, options)
+			for _, name := range []string{"atomic.go", "gc.go", "threads.go"} {
+				name := name // redefine to avoid race condition
+				t.Run(name, func(t *testing.T) {
+					t.Parallel()
+					runTest(name, options, t, nil, nil)
+				})
+			}
+		})
 	}
 }
 
@@ -241,6 +261,21 @@ func runPlatTests(options compileopts.Options, tests []string, t *testing.T) {
 			runTest("filesystem.go", options, t, nil, nil)
 		})
 	}
+	switch spec.Libc {
+	case "musl", "picolibc", "wasi-libc":
+		// Only these targets link libc++ and libc++abi for C++ files.
+		t.Run("cgocxx/", func(t *testing.T) {
+			t.Parallel()
+			runTest("cgocxx/", options, t, nil, nil)
+		})
+	}
+	if options.Target == "" {
+		// Needs the tasks scheduler to call blocking Go functions from C.
+		t.Run("cgocallback/", func(t *testing.T) {
+			t.Parallel()
+			runTest("cgocallback/", options, t, nil, nil)
+		})
+	}
 	if options.Target == "" || options.Target == "wasi" || options.Target == "wasm" {
 		t.Run("rand.go", func(t *testing.T) {
 			t.Parallel()
@@ -465,6 +500,256 @@ func runTestWithConfig(name string, t *testing.T, options compileopts.Options, c
 	}
 }
 
+// Test that asyncify only instruments the functions that may pause a goroutine.
+// A program that never blocks should be about the same size with the asyncify
+// scheduler as with no scheduler at all, while instrumenting every function
+// (which is what wasm-opt --asyncify does by default) roughly doubles the size
+// of a binary. A program that does block should be both smaller and not slower
+// than with every function instrumented.
+func TestWasmAsyncify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("size", func(t *testing.T) {
+		t.Parallel()
+		none := buildAsyncifyTest(t, "json.go", "none", false)
+		asyncify := buildAsyncifyTest(t, "json.go", "asyncify", false)
+		all := buildAsyncifyTest(t, "json.go", "asyncify", true)
+		t.Logf("binary size: %d bytes with -scheduler=none, %d bytes with -scheduler=asyncify, %d bytes with every function instrumented",
+			none.size, asyncify.size, all.size)
+		if asyncify.size > none.size*5/4 {
+			t.Errorf("asyncify adds too much overhead: %d bytes instead of %d bytes", asyncify.size, none.size)
+		}
+		if asyncify.size >= all.size {
+			t.Errorf("binary is not smaller than with every function instrumented: %d bytes instead of %d bytes", asyncify.size, all.size)
+		}
+	})
+
+	t.Run("speed", func(t *testing.T) {
+		t.Parallel()
+		if _, err := exec.LookPath("wasmtime"); err != nil {
+			t.Skip("wasmtime not found")
+		}
+		asyncify := buildAsyncifyTest(t, "asyncifybench.go", "asyncify", false)
+		all := buildAsyncifyTest(t, "asyncifybench.go", "asyncify", true)
+		asyncifyTime := runAsyncifyTest(t, asyncify.path)
+		allTime := runAsyncifyTest(t, all.path)
+		t.Logf("binary size: %d bytes, %d bytes with every function instrumented", asyncify.size, all.size)
+		t.Logf("run time: %v, %v with every function instrumented", asyncifyTime, allTime)
+		if asyncify.size >= all.size {
+			t.Errorf("binary is not smaller than with every function instrumented: %d bytes instead of %d bytes", asyncify.size, all.size)
+		}
+		// Run times are only logged: wall-clock measurements are too noisy
+		// on shared machines to fail the test on.
+	})
+}
+
+type asyncifyBinary struct {
+	path string
+	size int64
+}
+
+// buildAsyncifyTest builds the given test program for WASI with the given
+// scheduler. If all is set, every function is instrumented by asyncify.
+func buildAsyncifyTest(t *testing.T, name, scheduler string, all bool) asyncifyBinary {
+	options := optionsFromTarget("wasi", sema)
+	options.Scheduler = scheduler
+	options.AsyncifyAll = all
+	options.Debug = false
+	binary := filepath.Join(t.TempDir(), strings.TrimSuffix(name, ".go")+".wasm")
+	err := Build(filepath.Join(TESTDATA, name), binary, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fatal("failed to build with -scheduler=" + scheduler)
+	}
+	st, err := os.Stat(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return asyncifyBinary{binary, st.Size()}
+}
+
+// runAsyncifyTest runs testdata/asyncifybench.go a few times under wasmtime,
+// checks its output and returns the fastest run time.
+func runAsyncifyTest(t *testing.T, binary string) time.Duration {
+	expected, err := ioutil.ReadFile(filepath.Join(TESTDATA, "asyncifybench.txt"))
+	if err != nil {
+		t.Fatal("could not read expected output file:", err)
+	}
+	var fastest time.Duration
+	for i := 0; i < 3; i++ {
+		cmd := exec.Command("wasmtime", binary)
+		stdout := &bytes.Buffer{}
+		cmd.Stdout = stdout
+		cmd.Stderr = os.Stderr
+		start := time.Now()
+		err := cmd.Run()
+		duration := time.Since(start)
+		if err != nil {
+			t.Fatal("failed to run:", err)
+		}
+		if !bytes.Equal(stdout.Bytes(), expected) {
+			t.Fatalf("unexpected output: %q", stdout.String())
+		}
+		if i == 0 || duration < fastest {
+			fastest = duration
+		}
+	}
+	return fastest
+}
+
+// Test that a WASI program can accept connections on a socket that was passed
+// in by wasmtime, and that it serves multiple connections at the same time.
+func TestWasiSockets(t *testing.T) {
+	t.Parallel()
+	for _, target := range []string{"wasi", "wasi-threads"} {
+		target := target // redefine to avoid race condition
+		t.Run(target, func(t *testing.T) {
+			t.Parallel()
+			runWasiSocketsTest(t, target)
+		})
+	}
+}
+
+func runWasiSocketsTest(t *testing.T, target string) {
+	options := optionsFromTarget(target, sema)
+	emuCheck(t, options)
+	spec, err := compileopts.LoadTarget(&options)
+	if err != nil {
+		t.Fatal("failed to load target spec:", err)
+	}
+	binary := filepath.Join(t.TempDir(), "netserver.wasm")
+	err = Build("./testdata/netserver.go", binary, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fatal("failed to build")
+	}
+
+	// Find a free port for wasmtime to listen on.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	config := compileopts.Config{Target: spec}
+	emulator := config.Emulator()
+	args := append(emulator[1:], "-S", "tcplisten="+addr, binary)
+	cmd := exec.CommandContext(ctx, emulator[0], args...)
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal("failed to start:", err)
+	}
+	defer cmd.Process.Kill()
+
+	dial := func() *net.TCPConn {
+		for {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				return conn.(*net.TCPConn)
+			}
+			if ctx.Err() != nil {
+				t.Fatal("could not connect:", err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	roundtrip := func(conn *net.TCPConn, msg string) {
+		if _, err := conn.Write([]byte(msg)); err != nil {
+			t.Fatal("write:", err)
+		}
+		buf := make([]byte, len(msg))
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			t.Fatal("read:", err)
+		}
+		if string(buf) != msg {
+			t.Errorf("echoed %q, expected %q", buf, msg)
+		}
+	}
+
+	// The second connection must be served while the first one is idle.
+	conn1 := dial()
+	defer conn1.Close()
+	conn2 := dial()
+	defer conn2.Close()
+	roundtrip(conn2, "second")
+	roundtrip(conn1, "first")
+	roundtrip(conn2, "again")
+
+	// Closing the writing side ends the echo loop in the server, which then
+	// closes the connection.
+	for _, conn := range []*net.TCPConn{conn1, conn2} {
+		if err := conn.CloseWrite(); err != nil {
+			t.Fatal(err)
+		}
+		rest, err := ioutil.ReadAll(conn)
+		if err != nil || len(rest) != 0 {
+			t.Errorf("unexpected data after closing the connection: %q, %v", rest, err)
+		}
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Error("failed to run:", err)
+	}
+	if !strings.HasSuffix(stdout.String(), "done\n") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+// Test that a WASI reactor built with -buildmode=c-shared keeps its state
+// between calls from the host: _initialize runs the package initializers once,
+// the heap is kept, and goroutines keep running in later calls.
+func TestWasiReactor(t *testing.T) {
+	t.Parallel()
+	runWasiReactorTest(t, "reactor")
+}
+
+// Test that a host can call functions exported with //go:wasm-abi ptrlen, and
+// that the memory it frees is collected.
+func TestWasmABI(t *testing.T) {
+	t.Parallel()
+	runWasiReactorTest(t, "wasmabi")
+}
+
+// runWasiReactorTest builds testdata/<name>.go with -buildmode=c-shared and
+// calls its exported functions from testdata/<name>.wat, which wasmtime links
+// to the reactor. The output must match testdata/<name>.txt.
+func runWasiReactorTest(t *testing.T, name string) {
+	if _, err := exec.LookPath("wasmtime"); err != nil {
+		t.Skip("wasmtime not found")
+	}
+
+	options := optionsFromTarget("wasi", sema)
+	options.BuildMode = "c-shared"
+	binary := filepath.Join(t.TempDir(), name+".wasm")
+	err := Build("./testdata/"+name+".go", binary, &options)
+	if err != nil {
+		printCompilerError(t.Log, err)
+		t.Fatal("failed to build")
+	}
+	expected, err := ioutil.ReadFile("testdata/" + name + ".txt")
+	if err != nil {
+		t.Fatal("could not read expected output file:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "wasmtime", "--preload", "reactor="+binary, "testdata/"+name+".wat")
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		t.Error("failed to run:", err)
+	}
+	if stdout.String() != string(expected) {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
 func TestTest(t *testing.T) {
 	t.Parallel()
 
@@ -4,6 +4,7 @@
 package task
 
 import (
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -29,6 +30,11 @@ type state struct {
 	stackState
 
 	launched bool
+
+	// running is set while the task is running or unwinding. When goroutines
+	// run on multiple threads, a task may be made runnable again (and picked
+	// up by another thread) before it has been fully unwound.
+	running uint32
 }
 
 // stackState is the saved state of a stack while unwound.
@@ -41,6 +47,10 @@ type stackState struct {
 	// asyncify is stack pointer of the C stack.
 	// This starts from the top and grows downwards.
 	csp uintptr
+
+	// rewinding is set while the stack is being rewound, until the call to
+	// unwind that paused the task returns.
+	rewinding bool
 }
 
 // start creates and starts a new goroutine with the given function and arguments.
@@ -76,6 +86,7 @@ func (s *state) initialize(fn uintptr, args unsafe.Pointer, stackSize uintptr) {
 func runqueuePushBack(*Task)
 
 // currentTask is the current running task, or nil if currently in the scheduler.
+//go:threadlocal
 var currentTask *Task
 
 // Current returns the current active task.
@@ -102,6 +113,11 @@ func (*stackState) unwind()
 // Resume the task until it pauses or completes.
 // This may only be called from the scheduler.
 func (t *Task) Resume() {
+	// Wait until the task has been fully unwound, if it was paused on another
+	// thread just now.
+	for !atomic.CompareAndSwapUint32(&t.state.running, 0, 1) {
+	}
+
 	// The current task must be saved and restored because this can nest on WASM with JS.
 	prevTask := currentTask
 	t.gcData.swap()
@@ -117,6 +133,7 @@ func (t *Task) Resume() {
 	if t.state.asyncifysp > t.state.csp {
 		runtimePanic("stack overflow")
 	}
+	atomic.StoreUint32(&t.state.running, 0)
 }
 
 //export tinygo_rewind
@@ -1,52 +1,93 @@
 package machine
 
 import (
+	"runtime/interrupt"
 	"runtime/volatile"
 )
 
+// bufferSize is the size of a ring buffer created by NewRingBuffer.
 const bufferSize = 128
 
+// maxBufferSize is the largest ring buffer size. The head and tail counters
+// are 16 bits wide and wrap around, so the size must divide 1<<16.
+const maxBufferSize = 1 << 15
+
 // RingBuffer is ring buffer implementation inspired by post at
 // https://www.embeddedrelated.com/showthread/comp.arch.embedded/77084-1.php
+//
+// It may be used from both a goroutine and an interrupt handler, for example
+// with the interrupt handler storing received bytes and the goroutine reading
+// them.
 type RingBuffer struct {
-	rxbuffer [bufferSize]volatile.Register8
-	head     volatile.Register8
-	tail     volatile.Register8
+	buffer []volatile.Register8
+	head   volatile.Register16
+	tail   volatile.Register16
 }
 
-// NewRingBuffer returns a new ring buffer.
+// NewRingBuffer returns a new ring buffer of 128 bytes.
 func NewRingBuffer() *RingBuffer {
-	return &RingBuffer{}
+	return NewRingBufferSize(bufferSize)
+}
+
+// NewRingBufferSize returns a new ring buffer that holds at least size bytes.
+// The size is rounded up to a power of two, with a maximum of 32768.
+func NewRingBufferSize(size int) *RingBuffer {
+	n := 1
+	for n < size && n < maxBufferSize {
+		n *= 2
+	}
+	return &RingBuffer{buffer: make([]volatile.Register8, n)}
+}
+
+// Size returns how many bytes fit in the buffer.
+func (rb *RingBuffer) Size() int {
+	return len(rb.buffer)
 }
 
 // Used returns how many bytes in buffer have been used.
-func (rb *RingBuffer) Used() uint8 {
-	return uint8(rb.head.Get() - rb.tail.Get())
+func (rb *RingBuffer) Used() int {
+	// The counters are accessed with interrupts disabled as 16-bit loads and
+	// stores are not atomic on all chips (such as AVR).
+	mask := interrupt.Disable()
+	used := rb.head.Get() - rb.tail.Get()
+	interrupt.Restore(mask)
+	return int(used)
 }
 
 // Put stores a byte in the buffer. If the buffer is already
 // full, the method will return false.
 func (rb *RingBuffer) Put(val byte) bool {
-	if rb.Used() != bufferSize {
-		rb.head.Set(rb.head.Get() + 1)
-		rb.rxbuffer[rb.head.Get()%bufferSize].Set(val)
-		return true
+	mask := interrupt.Disable()
+	head := rb.head.Get()
+	if int(head-rb.tail.Get()) == len(rb.buffer) {
+		interrupt.Restore(mask)
+		return false
 	}
-	return false
+	rb.buffer[int(head)&(len(rb.buffer)-1)].Set(val)
+	rb.head.Set(head + 1)
+	interrupt.Restore(mask)
+	return true
 }
 
 // Get returns a byte from the buffer. If the buffer is empty,
 // the method will return a false as the second value.
 func (rb *RingBuffer) Get() (byte, bool) {
-	if rb.Used() != 0 {
-		rb.tail.Set(rb.tail.Get() + 1)
-		return rb.rxbuffer[rb.tail.Get()%bufferSize].Get(), true
+	mask := interrupt.Disable()
+	tail := rb.tail.Get()
+	if rb.head.Get() == tail {
+		interrupt.Restore(mask)
+		return 0, false
 	}
-	return 0, false
+	val := rb.buffer[int(tail)&(len(rb.buffer)-1)].Get()
+	rb.tail.Set(tail + 1)
+	interrupt.Restore(mask)
+	return val, true
 }
 
 // Clear resets the head and tail pointer to zero.
 func (rb *RingBuffer) Clear() {
+	mask := interrupt.Disable()
 	rb.head.Set(0)
 	rb.tail.Set(0)
+	interrupt.Restore(mask)
 }
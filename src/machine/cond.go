@@ -0,0 +1,31 @@
+//go:build sam || rp2040 || nrf52 || nrf52840 || nrf52833
+// +build sam rp2040 nrf52 nrf52840 nrf52833
+
+package machine
+
+import "unsafe"
+
+// interruptCond wakes a goroutine waiting in a driver from an interrupt
+// handler. It has the layout of runtime.Cond, which can't be used directly as
+// the runtime imports this package.
+type interruptCond struct {
+	t unsafe.Pointer
+}
+
+// notify wakes the goroutine blocked in wait, or makes the next call to wait
+// return immediately. It may be called from an interrupt handler.
+func (c *interruptCond) notify() {
+	condNotify(unsafe.Pointer(c))
+}
+
+// wait blocks until notify is called. Other goroutines run in the meantime.
+// Only one goroutine may wait at a time.
+func (c *interruptCond) wait() {
+	condWait(unsafe.Pointer(c))
+}
+
+//go:linkname condNotify runtime.machineCondNotify
+func condNotify(c unsafe.Pointer) bool
+
+//go:linkname condWait runtime.machineCondWait
+func condWait(c unsafe.Pointer)
@@ -0,0 +1,186 @@
+//go:build sam || rp2040 || nrf52 || nrf52840 || nrf52833
+// +build sam rp2040 nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"errors"
+	"runtime/interrupt"
+	"unsafe"
+)
+
+// This file implements a small portable API on top of the general purpose DMA
+// controllers found in the SAMD21, SAMD51 and RP2040. The nRF52 chips have no
+// such controller: there, a channel drives the EasyDMA engine of the SPIM or
+// UARTE peripheral selected by its trigger (see machine_nrf528xx_dma.go).
+
+var (
+	errDMANoChannel     = errors.New("DMA: no free channel")
+	errDMATransferCount = errors.New("DMA: invalid transfer count")
+	errDMAAddress       = errors.New("DMA: buffer not accessible by DMA")
+)
+
+// dmaMinTransfer is the smallest write for which drivers hand the data to a
+// DMA channel instead of feeding the peripheral from the CPU. Below this size
+// setting up the channel costs more than it saves.
+const dmaMinTransfer = 16
+
+// DMAWidth is the size of a single DMA transfer unit (beat).
+type DMAWidth uint8
+
+const (
+	DMAWidth8 DMAWidth = iota
+	DMAWidth16
+	DMAWidth32
+)
+
+// DMAConfig is the configuration of a DMA channel.
+type DMAConfig struct {
+	// Width is the size of each transferred unit.
+	Width DMAWidth
+
+	// SrcIncrement and DstIncrement select whether the source and destination
+	// addresses advance after each unit. Peripheral data registers are
+	// usually not incremented, memory buffers are.
+	SrcIncrement bool
+	DstIncrement bool
+
+	// Trigger is the chip specific peripheral request that paces the
+	// transfer, for example DMATriggerSERCOM0_TX. Use DMATriggerSoftware for
+	// memory to memory copies that run as fast as possible.
+	Trigger DMATrigger
+}
+
+// DMAChannel is a single channel of the DMA controller. Channels are obtained
+// with AllocateDMAChannel and returned with Release.
+type DMAChannel struct {
+	id       uint8
+	config   DMAConfig
+	callback func(*DMAChannel)
+	done     interruptCond // notified by the completion interrupt, for Wait
+}
+
+var (
+	dmaChannels    [dmaChannelCount]DMAChannel
+	dmaAllocated   uint32
+	dmaInitialized bool
+)
+
+// AllocateDMAChannel reserves a free DMA channel. It returns an error when all
+// channels are in use.
+func AllocateDMAChannel() (*DMAChannel, error) {
+	mask := interrupt.Disable()
+	if !dmaInitialized {
+		dmaInit()
+		dmaInitialized = true
+	}
+	for i := range dmaChannels {
+		if dmaAllocated&(1<<i) == 0 {
+			dmaAllocated |= 1 << i
+			ch := &dmaChannels[i]
+			ch.id = uint8(i)
+			ch.callback = nil
+			interrupt.Restore(mask)
+			return ch, nil
+		}
+	}
+	interrupt.Restore(mask)
+	return nil, errDMANoChannel
+}
+
+// Release stops the channel and makes it available for AllocateDMAChannel
+// again.
+func (ch *DMAChannel) Release() {
+	mask := interrupt.Disable()
+	ch.setInterrupt(false)
+	ch.abort()
+	ch.callback = nil
+	dmaAllocated &^= 1 << ch.id
+	interrupt.Restore(mask)
+}
+
+// Configure sets the transfer width, address increments and trigger used by
+// the following calls to Start.
+func (ch *DMAChannel) Configure(config DMAConfig) {
+	ch.config = config
+	ch.configure()
+}
+
+// Start begins a transfer of count units from src to dst. It does not wait
+// for the transfer to finish: use Wait, Busy or SetInterrupt for that. The
+// memory referenced by src and dst must stay alive until the transfer is
+// done. On the nRF52, src and dst must be in RAM.
+func (ch *DMAChannel) Start(dst, src unsafe.Pointer, count int) error {
+	if count <= 0 || uint(count) > dmaMaxTransferCount {
+		return errDMATransferCount
+	}
+	if (dst != nil && !dmaCanAccess(dst)) || (src != nil && !dmaCanAccess(src)) {
+		return errDMAAddress
+	}
+	ch.start(dst, src, uint32(count))
+	return nil
+}
+
+// Busy returns whether a transfer started on this channel is still running.
+func (ch *DMAChannel) Busy() bool {
+	return ch.busy()
+}
+
+// Wait blocks until the running transfer (if any) is finished. The goroutine
+// is woken up by the completion interrupt, so other goroutines run in the
+// meantime and the CPU sleeps when there is nothing else to do.
+func (ch *DMAChannel) Wait() {
+	if !ch.busy() {
+		return
+	}
+	ch.setInterrupt(true)
+	// The transfer may have finished before the interrupt was enabled, so
+	// check again before waiting. A notification left over from an earlier
+	// transfer only makes wait return early.
+	for ch.busy() {
+		ch.done.wait()
+	}
+	if ch.callback == nil {
+		ch.setInterrupt(false)
+	}
+}
+
+// SetInterrupt registers a callback that is called from the DMA interrupt
+// handler each time a transfer on this channel completes. Pass nil to disable
+// the completion interrupt again.
+func (ch *DMAChannel) SetInterrupt(callback func(*DMAChannel)) {
+	ch.callback = callback
+	ch.setInterrupt(callback != nil)
+}
+
+// dmaComplete is called by the chip specific interrupt handler for a channel
+// that finished a transfer.
+func dmaComplete(id uint8) {
+	ch := &dmaChannels[id]
+	ch.done.notify()
+	if ch.callback != nil {
+		ch.callback(ch)
+	}
+}
+
+// dmaWrite copies buf to the peripheral data register reg, one byte per
+// peripheral request. It returns false when no channel is available, in which
+// case the caller should fall back to writing the data itself.
+func dmaWrite(reg unsafe.Pointer, trigger DMATrigger, buf []byte) bool {
+	ch, err := AllocateDMAChannel()
+	if err != nil {
+		return false
+	}
+	ch.Configure(DMAConfig{
+		Width:        DMAWidth8,
+		SrcIncrement: true,
+		Trigger:      trigger,
+	})
+	if ch.Start(reg, unsafe.Pointer(&buf[0]), len(buf)) != nil {
+		ch.Release()
+		return false
+	}
+	ch.Wait()
+	ch.Release()
+	return true
+}
@@ -0,0 +1,162 @@
+//go:build nrf52 || nrf52840 || nrf52833 || atsamd21 || atsamd51 || atsame5x || rp2040 || stm32f4 || stm32l4
+// +build nrf52 nrf52840 nrf52833 atsamd21 atsamd51 atsame5x rp2040 stm32f4 stm32l4
+
+package machine
+
+import (
+	"errors"
+	"io"
+	"unsafe"
+)
+
+// BlockDevice is the raw device that is meant to store the data of a
+// filesystem or other persistent data, such as the internal flash of a chip.
+type BlockDevice interface {
+	// ReaderAt reads from the device. Any offset and length may be used.
+	io.ReaderAt
+
+	// WriterAt writes to the device. Writes should be aligned to and a
+	// multiple of WriteBlockSize to be efficient, other writes are padded.
+	// The blocks that are written to must have been erased first.
+	io.WriterAt
+
+	// Size returns the number of bytes in this block device.
+	Size() int64
+
+	// WriteBlockSize returns the smallest number of bytes that can be written
+	// at a time.
+	WriteBlockSize() int64
+
+	// EraseBlockSize returns the smallest number of bytes that can be erased
+	// at a time. Erasing sets all bits in a block to 1.
+	EraseBlockSize() int64
+
+	// EraseBlocks erases the given number of blocks, starting at the given
+	// erase block. The block numbers are in units of EraseBlockSize.
+	EraseBlocks(start, len int64) error
+}
+
+var (
+	errFlashOutOfRange  = errors.New("flash: out of range")
+	errFlashWriteFailed = errors.New("flash: write failed")
+	errFlashEraseFailed = errors.New("flash: erase failed")
+)
+
+// The region in internal flash that is reserved for user data. It is placed at
+// the end of the FLASH_TEXT memory region. No flash is reserved by default, so
+// that programs can use all of it: a target that needs the region sets its
+// size with the _flash_data_size linker symbol, for example with a custom
+// target that inherits from the board and adds
+//
+//	"ldflags": ["--defsym=_flash_data_size=0x8000"]
+//
+// The size must be a multiple of the erase block size of the chip.
+
+//go:extern __flash_data_start
+var flashDataStartSymbol [0]byte
+
+//go:extern __flash_data_end
+var flashDataEndSymbol [0]byte
+
+// FlashDataStart returns the start address of the flash region that is not
+// used by the program and that may be used for persistent data.
+func FlashDataStart() uintptr {
+	return uintptr(unsafe.Pointer(&flashDataStartSymbol))
+}
+
+// FlashDataEnd returns the end address of the flash region that may be used
+// for persistent data.
+func FlashDataEnd() uintptr {
+	return uintptr(unsafe.Pointer(&flashDataEndSymbol))
+}
+
+// Flash is the user data region of the internal flash, as a block device.
+// Offsets are relative to FlashDataStart. Its size is zero unless the target
+// reserves a flash data region.
+var Flash flashBlockDevice
+
+type flashBlockDevice struct{}
+
+// Size returns the number of bytes in the flash data region.
+func (f flashBlockDevice) Size() int64 {
+	return int64(FlashDataEnd() - FlashDataStart())
+}
+
+// WriteBlockSize returns the smallest number of bytes that can be written to
+// the flash at a time.
+func (f flashBlockDevice) WriteBlockSize() int64 {
+	return flashWriteBlockSize
+}
+
+// EraseBlockSize returns the size of a flash page or sector, which is the
+// smallest number of bytes that can be erased at a time.
+func (f flashBlockDevice) EraseBlockSize() int64 {
+	return flashEraseBlockSize
+}
+
+// ReadAt reads len(p) bytes at the given offset in the flash data region.
+func (f flashBlockDevice) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > f.Size() {
+		return 0, errFlashOutOfRange
+	}
+	addr := FlashDataStart() + uintptr(off)
+	for i := range p {
+		p[i] = *(*byte)(unsafe.Pointer(addr + uintptr(i)))
+	}
+	return len(p), nil
+}
+
+// WriteAt writes p at the given offset in the flash data region. The region
+// that is written to must have been erased with EraseBlocks. Writes that do not
+// start or end on a write block boundary are padded with 0xff, which leaves the
+// bytes around them unchanged.
+func (f flashBlockDevice) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > f.Size() {
+		return 0, errFlashOutOfRange
+	}
+	addr := FlashDataStart() + uintptr(off)
+	for n < len(p) {
+		var block [flashWriteBlockSize]byte
+		data := p[n:]
+		blockAddr := addr &^ (flashWriteBlockSize - 1)
+		skip := int(addr - blockAddr)
+		count := len(data) &^ (flashWriteBlockSize - 1)
+		if skip != 0 || count == 0 {
+			// Partial write block.
+			for i := range block {
+				block[i] = 0xff
+			}
+			count = copy(block[skip:], data)
+			data = block[:]
+		} else {
+			data = data[:count]
+		}
+		if err := flashWrite(blockAddr, data); err != nil {
+			return n, err
+		}
+		n += count
+		addr += uintptr(count)
+	}
+	return n, nil
+}
+
+// EraseBlocks erases len blocks of EraseBlockSize bytes, starting at erase
+// block start of the flash data region.
+func (f flashBlockDevice) EraseBlocks(start, len int64) error {
+	if start < 0 || len < 0 || (start+len)*flashEraseBlockSize > f.Size() {
+		return errFlashOutOfRange
+	}
+	// The data region must start on an erase block boundary, otherwise the
+	// erase would reach into the program.
+	addr := FlashDataStart() + uintptr(start*flashEraseBlockSize)
+	if addr%flashEraseBlockSize != 0 {
+		return errFlashOutOfRange
+	}
+	for i := int64(0); i < len; i++ {
+		if err := flashErase(addr); err != nil {
+			return err
+		}
+		addr += flashEraseBlockSize
+	}
+	return nil
+}
@@ -22,6 +22,40 @@ var (
 	errI2CSignalStopTimeout  = errors.New("I2C timeout on signal stop")
 	errI2CAckExpected        = errors.New("I2C error: expected ACK not NACK")
 	errI2CBusError           = errors.New("I2C bus error")
+	errI2CWrongMode          = errors.New("I2C wrong mode")
+	errI2CNoRequest          = errors.New("I2C no pending read request")
+)
+
+// I2CMode determines whether an I2C peripheral acts as a controller (master)
+// or as a target (slave) on the bus.
+type I2CMode int
+
+const (
+	// I2CModeController is the default: the peripheral starts transactions
+	// with Tx, WriteRegister and ReadRegister.
+	I2CModeController I2CMode = iota
+
+	// I2CModeTarget makes the peripheral respond to transactions started by
+	// another controller, see Listen, WaitForEvent and Reply.
+	I2CModeTarget
+)
+
+// I2CTargetEvent is an event returned by WaitForEvent in target mode.
+type I2CTargetEvent uint8
+
+const (
+	// I2CReceive means the controller wrote data to this target. The data is
+	// stored in the buffer passed to WaitForEvent.
+	I2CReceive I2CTargetEvent = iota
+
+	// I2CRequest means the controller wants to read from this target. The
+	// bus is held until the response is sent with Reply.
+	I2CRequest
+
+	// I2CFinish means the controller ended the transaction with a stop
+	// condition. A single transaction may consist of several receive and
+	// request events separated by repeated starts.
+	I2CFinish
 )
 
 // WriteRegister transmits first the register and then the data to the
@@ -502,9 +502,14 @@ func waitADCSync() {
 // UART on the SAMD21.
 type UART struct {
 	Buffer    *RingBuffer
+	TXBuffer  *RingBuffer
 	Bus       *sam.SERCOM_USART_Type
 	SERCOM    uint8
 	Interrupt interrupt.Interrupt
+
+	// txUsed is set once a byte has been written, as TXC is only set after
+	// a transmission.
+	txUsed volatile.Register8
 }
 
 const (
@@ -526,6 +531,8 @@ func (uart *UART) Configure(config UARTConfig) error {
 		config.RX = UART_RX_PIN
 	}
 
+	uart.setBuffers(config)
+
 	// Determine transmit pinout.
 	txPinMode, txPad, ok := findPinPadMapping(uart.SERCOM, config.TX)
 	if !ok {
@@ -602,10 +609,12 @@ func (uart *UART) Configure(config UARTConfig) error {
 	for uart.Bus.SYNCBUSY.HasBits(sam.SERCOM_USART_SYNCBUSY_ENABLE) {
 	}
 
-	// setup interrupt on receive
+	// setup interrupt on receive, the DRE interrupt is enabled by WriteByte
+	// when needed.
 	uart.Bus.INTENSET.Set(sam.SERCOM_USART_INTENSET_RXC)
+	uart.txUsed.Set(0)
 
-	// Enable RX IRQ.
+	// Enable RX and TX IRQ.
 	uart.Interrupt.Enable()
 
 	return nil
@@ -627,6 +636,12 @@ func (uart *UART) SetBaudRate(br uint32) {
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
+	uart.txUsed.Set(1)
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
 	// wait until ready to receive
 	for !uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INTFLAG_DRE) {
 	}
@@ -634,18 +649,55 @@ func (uart *UART) WriteByte(c byte) error {
 	return nil
 }
 
+// writeDMA writes large buffers to the UART using a DMA channel. It returns
+// false if the data must be written byte by byte instead, which is always the
+// case with a TX buffer so that the data stays in order.
+func (uart *UART) writeDMA(data []byte) bool {
+	if uart.TXBuffer != nil || len(data) < dmaMinTransfer {
+		return false
+	}
+	uart.txUsed.Set(1)
+	return dmaWrite(unsafe.Pointer(&uart.Bus.DATA.Reg), dmaTriggerSERCOM_TX(uart.SERCOM), data)
+}
+
 // handleInterrupt should be called from the appropriate interrupt handler for
 // this UART instance.
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	// should reset IRQ
-	uart.Receive(byte((uart.Bus.DATA.Get() & 0xFF)))
-	uart.Bus.INTFLAG.SetBits(sam.SERCOM_USART_INTFLAG_RXC)
+	// The SERCOM interrupt is shared with I2C target mode.
+	if uart.Bus.CTRLA.Get()&sam.SERCOM_USART_CTRLA_MODE_Msk != sam.SERCOM_USART_CTRLA_MODE_USART_INT_CLK<<sam.SERCOM_USART_CTRLA_MODE_Pos {
+		return
+	}
+	if uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INTFLAG_RXC) {
+		// should reset IRQ
+		uart.Receive(byte((uart.Bus.DATA.Get() & 0xFF)))
+		uart.Bus.INTFLAG.SetBits(sam.SERCOM_USART_INTFLAG_RXC)
+	}
+
+	if uart.Bus.INTENSET.HasBits(sam.SERCOM_USART_INTENSET_DRE) && uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INTFLAG_DRE) {
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uart.Bus.DATA.Set(uint16(c))
+		} else {
+			uart.Bus.INTENCLR.Set(sam.SERCOM_USART_INTENCLR_DRE)
+		}
+	}
+}
+
+// startTX enables the DRE interrupt, which sends the bytes in TXBuffer.
+func (uart *UART) startTX() {
+	uart.Bus.INTENSET.Set(sam.SERCOM_USART_INTENSET_DRE)
+}
+
+// txComplete returns whether the last byte written has been sent.
+func (uart *UART) txComplete() bool {
+	return uart.txUsed.Get() == 0 || uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INTFLAG_TXC)
 }
 
 // I2C on the SAMD21.
 type I2C struct {
-	Bus    *sam.SERCOM_I2CM_Type
-	SERCOM uint8
+	Bus        *sam.SERCOM_I2CM_Type
+	SERCOM     uint8
+	mode       I2CMode
+	targetWake interruptCond
 }
 
 // I2CConfig is used to store config info for I2C.
@@ -653,6 +705,8 @@ type I2CConfig struct {
 	Frequency uint32
 	SCL       Pin
 	SDA       Pin
+	// Mode selects controller (default) or target operation.
+	Mode I2CMode
 }
 
 const (
@@ -706,6 +760,15 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 		i2c.Bus.SYNCBUSY.HasBits(sam.SERCOM_I2CM_SYNCBUSY_SWRST) {
 	}
 
+	i2c.mode = config.Mode
+	if config.Mode == I2CModeTarget {
+		// The SERCOM is enabled by Listen, once the address is known.
+		i2c.target().CTRLA.Set(wireTargetOperation << sam.SERCOM_I2CS_CTRLA_MODE_Pos)
+		config.SDA.Configure(PinConfig{Mode: sdaPinMode})
+		config.SCL.Configure(PinConfig{Mode: sclPinMode})
+		return nil
+	}
+
 	// Set i2c controller mode
 	//SERCOM_I2CM_CTRLA_MODE( I2C_MASTER_OPERATION )
 	i2c.Bus.CTRLA.Set(sam.SERCOM_I2CM_CTRLA_MODE_I2C_MASTER << sam.SERCOM_I2CM_CTRLA_MODE_Pos) // |
@@ -742,6 +805,9 @@ func (i2c *I2C) SetBaudRate(br uint32) {
 // It clocks out the given address, writes the bytes in w, reads back len(r)
 // bytes and stores them in r, and generates a stop condition on the bus.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
+	if i2c.mode != I2CModeController {
+		return errI2CWrongMode
+	}
 	var err error
 	if len(w) != 0 {
 		// send start/address for write
@@ -1319,10 +1385,14 @@ func (spi SPI) Tx(w, r []byte) error {
 }
 
 func (spi SPI) tx(tx []byte) {
-	for i := 0; i < len(tx); i++ {
-		for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPI_INTFLAG_DRE) {
+	// Large buffers are written by a DMA channel, paced by the data register
+	// empty request of the SERCOM.
+	if len(tx) < dmaMinTransfer || !dmaWrite(unsafe.Pointer(&spi.Bus.DATA.Reg), dmaTriggerSERCOM_TX(spi.SERCOM), tx) {
+		for i := 0; i < len(tx); i++ {
+			for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPI_INTFLAG_DRE) {
+			}
+			spi.Bus.DATA.Set(uint32(tx[i]))
 		}
-		spi.Bus.DATA.Set(uint32(tx[i]))
 	}
 	for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPI_INTFLAG_TXC) {
 	}
@@ -1871,15 +1941,11 @@ const (
 var (
 	usbEndpointDescriptors [8]usbDeviceDescriptor
 
-	udd_ep_in_cache_buffer  [7][128]uint8
-	udd_ep_out_cache_buffer [7][128]uint8
+	udd_ep_in_cache_buffer  [usb_EPT_NUM + 1][128]uint8
+	udd_ep_out_cache_buffer [usb_EPT_NUM + 1][128]uint8
 
 	isEndpointHalt        = false
 	isRemoteWakeUpEnabled = false
-	endPoints             = []uint32{usb_ENDPOINT_TYPE_CONTROL,
-		(usb_ENDPOINT_TYPE_INTERRUPT | usbEndpointIn),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointOut),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)}
 
 	usbConfiguration uint8
 	usbSetInterface  uint8
@@ -2014,14 +2080,12 @@ func handleUSB(intr interrupt.Interrupt) {
 		usbEndpointDescriptors[0].DeviceDescBank[0].PCKSIZE.ClearBits(usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask << usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos)
 
 		ok := false
-		if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
+		if handler := usbInterfaceHandler(setup); handler != nil {
+			// Interface Requests
+			ok = handler(setup)
+		} else if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
 			// Standard Requests
 			ok = handleStandardSetup(setup)
-		} else {
-			// Class Interface Requests
-			if setup.wIndex == usb_CDC_ACM_INTERFACE {
-				ok = cdcSetup(setup)
-			}
 		}
 
 		if ok {
@@ -2059,6 +2123,14 @@ func handleUSB(intr interrupt.Interrupt) {
 				if i == usb_CDC_ENDPOINT_IN {
 					USB.waitTxc = false
 				}
+			default:
+				if (epFlags&sam.USB_DEVICE_EPINTFLAG_TRCPT0) > 0 && usbRxHandler[i] != nil {
+					handleEndpoint(i)
+				}
+				setEPINTFLAG(i, epFlags)
+				if (epFlags&sam.USB_DEVICE_EPINTFLAG_TRCPT1) > 0 && usbTxHandler[i] != nil {
+					usbTxHandler[i]()
+				}
 			}
 		}
 	}
@@ -2229,6 +2301,13 @@ func handleStandardSetup(setup usbSetup) bool {
 			// Enable interrupt for CDC data messages from host
 			setEPINTENSET(usb_CDC_ENDPOINT_OUT, sam.USB_DEVICE_EPINTENSET_TRCPT0)
 
+			// Enable interrupt for completed IN transfers of other functions
+			for i := usb_CDC_ENDPOINT_IN + 1; i < len(endPoints); i++ {
+				if usbTxHandler[i] != nil {
+					setEPINTENSET(uint32(i), sam.USB_DEVICE_EPINTENSET_TRCPT1)
+				}
+			}
+
 			sendZlp()
 			return true
 		} else {
@@ -2308,10 +2387,19 @@ func cdcSetup(setup usbSetup) bool {
 
 //go:noinline
 func sendUSBPacket(ep uint32, data []byte) {
-	copy(udd_ep_in_cache_buffer[ep][:], data)
+	// The control endpoint has a larger buffer for descriptors, which are
+	// sent in multiple packets.
+	buf := udd_ep_in_cache_buffer[ep][:]
+	if ep == 0 {
+		buf = usbEP0InBuffer[:]
+	}
+	copy(buf, data)
+	if len(data) > len(buf) {
+		data = data[:len(buf)]
+	}
 
 	// Set endpoint address for sending data
-	usbEndpointDescriptors[ep].DeviceDescBank[1].ADDR.Set(uint32(uintptr(unsafe.Pointer(&udd_ep_in_cache_buffer[ep]))))
+	usbEndpointDescriptors[ep].DeviceDescBank[1].ADDR.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
 
 	// clear multi-packet size which is total bytes already sent
 	usbEndpointDescriptors[ep].DeviceDescBank[1].PCKSIZE.ClearBits(usb_DEVICE_PCKSIZE_MULTI_PACKET_SIZE_Mask << usb_DEVICE_PCKSIZE_MULTI_PACKET_SIZE_Pos)
@@ -2321,6 +2409,14 @@ func sendUSBPacket(ep uint32, data []byte) {
 	usbEndpointDescriptors[ep].DeviceDescBank[1].PCKSIZE.SetBits(uint32((len(data) & usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask) << usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos))
 }
 
+// sendUSBInPacket starts an IN transfer on an endpoint other than the control
+// endpoint. The TRCPT1 interrupt signals that it has completed.
+func sendUSBInPacket(ep uint32, data []byte) {
+	sendUSBPacket(ep, data)
+	setEPINTFLAG(ep, sam.USB_DEVICE_EPINTFLAG_TRCPT1)
+	setEPSTATUSSET(ep, sam.USB_DEVICE_EPSTATUSSET_BK1RDY)
+}
+
 func receiveUSBControlPacket() ([cdcLineInfoSize]byte, error) {
 	var b [cdcLineInfoSize]byte
 
@@ -2369,9 +2465,13 @@ func handleEndpoint(ep uint32) {
 	count := int((usbEndpointDescriptors[ep].DeviceDescBank[0].PCKSIZE.Get() >>
 		usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos) & usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask)
 
-	// move to ring buffer
-	for i := 0; i < count; i++ {
-		USB.Receive(byte((udd_ep_out_cache_buffer[ep][i] & 0xFF)))
+	if ep == usb_CDC_ENDPOINT_OUT {
+		// move to ring buffer
+		for i := 0; i < count; i++ {
+			USB.Receive(byte((udd_ep_out_cache_buffer[ep][i] & 0xFF)))
+		}
+	} else {
+		usbRxHandler[ep](udd_ep_out_cache_buffer[ep][:count])
 	}
 
 	// set byte count to zero
@@ -0,0 +1,143 @@
+//go:build sam && atsamd21
+// +build sam,atsamd21
+
+package machine
+
+import (
+	"device/sam"
+	"runtime/interrupt"
+	"unsafe"
+)
+
+const dmaChannelCount = 12
+
+// DMA triggers for the SERCOM peripherals.
+const (
+	DMATriggerSERCOM0_RX DMATrigger = 0x01 + iota
+	DMATriggerSERCOM0_TX
+	DMATriggerSERCOM1_RX
+	DMATriggerSERCOM1_TX
+	DMATriggerSERCOM2_RX
+	DMATriggerSERCOM2_TX
+	DMATriggerSERCOM3_RX
+	DMATriggerSERCOM3_TX
+	DMATriggerSERCOM4_RX
+	DMATriggerSERCOM4_TX
+	DMATriggerSERCOM5_RX
+	DMATriggerSERCOM5_TX
+)
+
+// dmaTriggerSERCOM_TX returns the trigger of the data register empty request
+// of the given SERCOM.
+func dmaTriggerSERCOM_TX(sercom uint8) DMATrigger {
+	return DMATriggerSERCOM0_TX + DMATrigger(sercom)*2
+}
+
+const (
+	dmaCHCTRLB_TRIGSRC_Pos = 8
+	dmaCHCTRLB_TRIGACT_Pos = 22
+)
+
+func dmaInit() {
+	sam.PM.AHBMASK.SetBits(sam.PM_AHBMASK_DMAC_)
+	sam.PM.APBBMASK.SetBits(sam.PM_APBBMASK_DMAC_)
+
+	sam.DMAC.CTRL.ClearBits(sam.DMAC_CTRL_DMAENABLE)
+	sam.DMAC.CTRL.SetBits(sam.DMAC_CTRL_SWRST)
+	for sam.DMAC.CTRL.HasBits(sam.DMAC_CTRL_SWRST) {
+	}
+
+	sam.DMAC.BASEADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaDescriptors))))
+	sam.DMAC.WRBADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaWriteback))))
+	sam.DMAC.CTRL.Set(sam.DMAC_CTRL_DMAENABLE |
+		sam.DMAC_CTRL_LVLEN0 | sam.DMAC_CTRL_LVLEN1 | sam.DMAC_CTRL_LVLEN2 | sam.DMAC_CTRL_LVLEN3)
+
+	interrupt.New(sam.IRQ_DMAC, dmaHandleInterrupt).Enable()
+}
+
+// selectChannel makes the channel registers of the DMAC refer to this
+// channel. It must be called with interrupts disabled, as the interrupt
+// handler selects channels too.
+func (ch *DMAChannel) selectChannel() {
+	sam.DMAC.CHID.Set(ch.id)
+}
+
+func (ch *DMAChannel) configure() {
+	mask := interrupt.Disable()
+	ch.selectChannel()
+	sam.DMAC.CHCTRLA.ClearBits(sam.DMAC_CHCTRLA_ENABLE)
+	for sam.DMAC.CHCTRLA.HasBits(sam.DMAC_CHCTRLA_ENABLE) {
+	}
+	sam.DMAC.CHCTRLA.Set(sam.DMAC_CHCTRLA_SWRST)
+	for sam.DMAC.CHCTRLA.HasBits(sam.DMAC_CHCTRLA_SWRST) {
+	}
+
+	// A peripheral request moves a single beat, a software trigger moves the
+	// whole block.
+	trigact := uint32(dmaTRIGACT_BURST)
+	if ch.config.Trigger == DMATriggerSoftware {
+		trigact = dmaTRIGACT_BLOCK
+	}
+	sam.DMAC.CHCTRLB.Set(uint32(ch.config.Trigger)<<dmaCHCTRLB_TRIGSRC_Pos |
+		trigact<<dmaCHCTRLB_TRIGACT_Pos)
+
+	// The reset above also cleared the interrupt enable.
+	if ch.callback != nil {
+		sam.DMAC.CHINTENSET.Set(sam.DMAC_CHINTENSET_TCMPL)
+	}
+	interrupt.Restore(mask)
+}
+
+func (ch *DMAChannel) start(dst, src unsafe.Pointer, count uint32) {
+	ch.setDescriptor(dst, src, count)
+	mask := interrupt.Disable()
+	ch.selectChannel()
+	sam.DMAC.CHINTFLAG.Set(sam.DMAC_CHINTFLAG_TERR | sam.DMAC_CHINTFLAG_TCMPL)
+	sam.DMAC.CHCTRLA.SetBits(sam.DMAC_CHCTRLA_ENABLE)
+	interrupt.Restore(mask)
+	if ch.config.Trigger == DMATriggerSoftware {
+		sam.DMAC.SWTRIGCTRL.SetBits(1 << ch.id)
+	}
+}
+
+// The DMAC disables a channel by itself once its last block is transferred.
+func (ch *DMAChannel) busy() bool {
+	mask := interrupt.Disable()
+	ch.selectChannel()
+	busy := sam.DMAC.CHCTRLA.HasBits(sam.DMAC_CHCTRLA_ENABLE)
+	interrupt.Restore(mask)
+	return busy
+}
+
+func (ch *DMAChannel) abort() {
+	mask := interrupt.Disable()
+	ch.selectChannel()
+	sam.DMAC.CHCTRLA.ClearBits(sam.DMAC_CHCTRLA_ENABLE)
+	for sam.DMAC.CHCTRLA.HasBits(sam.DMAC_CHCTRLA_ENABLE) {
+	}
+	interrupt.Restore(mask)
+}
+
+func (ch *DMAChannel) setInterrupt(enabled bool) {
+	mask := interrupt.Disable()
+	ch.selectChannel()
+	if enabled {
+		sam.DMAC.CHINTENSET.Set(sam.DMAC_CHINTENSET_TCMPL)
+	} else {
+		sam.DMAC.CHINTENCLR.Set(sam.DMAC_CHINTENCLR_TCMPL)
+	}
+	interrupt.Restore(mask)
+}
+
+func dmaHandleInterrupt(interrupt.Interrupt) {
+	// Restore the channel selected by the interrupted code afterwards.
+	chid := sam.DMAC.CHID.Get()
+	for i := range dmaChannels {
+		sam.DMAC.CHID.Set(uint8(i))
+		if sam.DMAC.CHINTFLAG.HasBits(sam.DMAC_CHINTFLAG_TCMPL) {
+			sam.DMAC.CHINTFLAG.Set(sam.DMAC_CHINTFLAG_TCMPL)
+			dmaComplete(uint8(i))
+		}
+	}
+	sam.DMAC.CHID.Set(chid)
+}
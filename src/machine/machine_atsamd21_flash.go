@@ -0,0 +1,64 @@
+//go:build sam && atsamd21
+// +build sam,atsamd21
+
+package machine
+
+import (
+	"device/sam"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The NVM controller writes pages of 64 bytes through a page buffer that is
+// filled with 32-bit writes, and erases rows of 4 pages. The runtime configures
+// it for manual page writes.
+const (
+	flashWriteBlockSize = 4
+	flashEraseBlockSize = 256
+	flashPageSize       = 64
+)
+
+// flashWrite writes data, which must be a multiple of 4 bytes long, to the
+// word aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	for len(data) > 0 {
+		// Fill the page buffer up to the end of the page, then write the page.
+		n := flashPageSize - int(addr%flashPageSize)
+		if n > len(data) {
+			n = len(data)
+		}
+		flashCommand(sam.NVMCTRL_CTRLA_CMD_PBC)
+		for i := 0; i < n; i += 4 {
+			word := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+			volatile.StoreUint32((*uint32)(unsafe.Pointer(addr+uintptr(i))), word)
+		}
+		sam.NVMCTRL.ADDR.Set(uint32(addr >> 1))
+		if !flashCommand(sam.NVMCTRL_CTRLA_CMD_WP) {
+			return errFlashWriteFailed
+		}
+		data = data[n:]
+		addr += uintptr(n)
+	}
+	return nil
+}
+
+// flashErase erases the row at the given address.
+func flashErase(addr uintptr) error {
+	sam.NVMCTRL.ADDR.Set(uint32(addr >> 1))
+	if !flashCommand(sam.NVMCTRL_CTRLA_CMD_ER) {
+		return errFlashEraseFailed
+	}
+	return nil
+}
+
+// flashCommand runs a NVM controller command and waits for it to finish. It
+// returns false if the controller reported an error.
+func flashCommand(cmd uint16) bool {
+	// Clear the error flags of a previous command.
+	sam.NVMCTRL.STATUS.Set(sam.NVMCTRL_STATUS_PROGE | sam.NVMCTRL_STATUS_LOCKE | sam.NVMCTRL_STATUS_NVME)
+	sam.NVMCTRL.INTFLAG.Set(sam.NVMCTRL_INTFLAG_ERROR)
+	sam.NVMCTRL.CTRLA.Set(cmd | sam.NVMCTRL_CTRLA_CMDEX_KEY<<sam.NVMCTRL_CTRLA_CMDEX_Pos)
+	for !sam.NVMCTRL.INTFLAG.HasBits(sam.NVMCTRL_INTFLAG_READY) {
+	}
+	return !sam.NVMCTRL.INTFLAG.HasBits(sam.NVMCTRL_INTFLAG_ERROR)
+}
@@ -0,0 +1,37 @@
+//go:build sam && atsamd21
+// +build sam,atsamd21
+
+package machine
+
+import (
+	"device/sam"
+)
+
+const hasSystemOff = false
+
+func systemOff(pins []WakePin) {}
+
+// enterDeepSleep clocks the EIC from generic clock generator 2, which the
+// runtime runs from the 32kHz oscillator in standby too, so that it can detect
+// the wake pins. The EIC is then allowed to wake up the chip from standby.
+func enterDeepSleep() {
+	if sam.EIC.CTRL.Get() == 0 {
+		return
+	}
+	sam.GCLK.CLKCTRL.Set(sam.GCLK_CLKCTRL_ID_EIC<<sam.GCLK_CLKCTRL_ID_Pos |
+		sam.GCLK_CLKCTRL_GEN_GCLK2<<sam.GCLK_CLKCTRL_GEN_Pos |
+		sam.GCLK_CLKCTRL_CLKEN)
+	sam.EIC.WAKEUP.Set(sam.EIC.INTENSET.Get())
+}
+
+// exitDeepSleep clocks the EIC from the main clock again, as configured by
+// SetInterrupt.
+func exitDeepSleep() {
+	if sam.EIC.CTRL.Get() == 0 {
+		return
+	}
+	sam.EIC.WAKEUP.Set(0)
+	sam.GCLK.CLKCTRL.Set(sam.GCLK_CLKCTRL_ID_EIC<<sam.GCLK_CLKCTRL_ID_Pos |
+		sam.GCLK_CLKCTRL_GEN_GCLK0<<sam.GCLK_CLKCTRL_GEN_Pos |
+		sam.GCLK_CLKCTRL_CLKEN)
+}
@@ -45,6 +45,37 @@ func init() {
 	sercomUSART5.Interrupt = interrupt.New(sam.IRQ_SERCOM5, sercomUSART5.handleInterrupt)
 }
 
+// enableSERCOMTargetInterrupt enables the interrupt used by I2C target mode on
+// the given SERCOM.
+func enableSERCOMTargetInterrupt(sercom uint8) {
+	switch sercom {
+	case 0:
+		interrupt.New(sam.IRQ_SERCOM0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(0)
+		}).Enable()
+	case 1:
+		interrupt.New(sam.IRQ_SERCOM1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(1)
+		}).Enable()
+	case 2:
+		interrupt.New(sam.IRQ_SERCOM2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(2)
+		}).Enable()
+	case 3:
+		interrupt.New(sam.IRQ_SERCOM3, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(3)
+		}).Enable()
+	case 4:
+		interrupt.New(sam.IRQ_SERCOM4, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(4)
+		}).Enable()
+	case 5:
+		interrupt.New(sam.IRQ_SERCOM5, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(5)
+		}).Enable()
+	}
+}
+
 // Return the register and mask to enable a given GPIO pin. This can be used to
 // implement bit-banged drivers.
 func (p Pin) PortMaskSet() (*uint32, uint32) {
@@ -948,10 +948,16 @@ func (a ADC) getADCChannel() uint8 {
 
 // UART on the SAMD51.
 type UART struct {
-	Buffer    *RingBuffer
-	Bus       *sam.SERCOM_USART_INT_Type
-	SERCOM    uint8
-	Interrupt interrupt.Interrupt // RXC interrupt
+	Buffer      *RingBuffer
+	TXBuffer    *RingBuffer
+	Bus         *sam.SERCOM_USART_INT_Type
+	SERCOM      uint8
+	Interrupt   interrupt.Interrupt // RXC interrupt
+	txInterrupt interrupt.Interrupt // DRE interrupt
+
+	// txUsed is set once a byte has been written, as TXC is only set after
+	// a transmission.
+	txUsed volatile.Register8
 }
 
 var (
@@ -965,11 +971,17 @@ var (
 
 func init() {
 	sercomUSART0.Interrupt = interrupt.New(sam.IRQ_SERCOM0_2, sercomUSART0.handleInterrupt)
+	sercomUSART0.txInterrupt = interrupt.New(sam.IRQ_SERCOM0_0, sercomUSART0.handleInterrupt)
 	sercomUSART1.Interrupt = interrupt.New(sam.IRQ_SERCOM1_2, sercomUSART1.handleInterrupt)
+	sercomUSART1.txInterrupt = interrupt.New(sam.IRQ_SERCOM1_0, sercomUSART1.handleInterrupt)
 	sercomUSART2.Interrupt = interrupt.New(sam.IRQ_SERCOM2_2, sercomUSART2.handleInterrupt)
+	sercomUSART2.txInterrupt = interrupt.New(sam.IRQ_SERCOM2_0, sercomUSART2.handleInterrupt)
 	sercomUSART3.Interrupt = interrupt.New(sam.IRQ_SERCOM3_2, sercomUSART3.handleInterrupt)
+	sercomUSART3.txInterrupt = interrupt.New(sam.IRQ_SERCOM3_0, sercomUSART3.handleInterrupt)
 	sercomUSART4.Interrupt = interrupt.New(sam.IRQ_SERCOM4_2, sercomUSART4.handleInterrupt)
+	sercomUSART4.txInterrupt = interrupt.New(sam.IRQ_SERCOM4_0, sercomUSART4.handleInterrupt)
 	sercomUSART5.Interrupt = interrupt.New(sam.IRQ_SERCOM5_2, sercomUSART5.handleInterrupt)
+	sercomUSART5.txInterrupt = interrupt.New(sam.IRQ_SERCOM5_0, sercomUSART5.handleInterrupt)
 }
 
 const (
@@ -991,6 +1003,8 @@ func (uart *UART) Configure(config UARTConfig) error {
 		config.RX = UART_RX_PIN
 	}
 
+	uart.setBuffers(config)
+
 	// Determine transmit pinout.
 	txPinMode, txPad, ok := findPinPadMapping(uart.SERCOM, config.TX)
 	if !ok {
@@ -1080,6 +1094,11 @@ func (uart *UART) Configure(config UARTConfig) error {
 	// position 2), we only need interrupt source 2 for this SERCOM device.
 	uart.Interrupt.Enable()
 
+	// Enable the DRE IRQ (source 0), used to send the bytes in TXBuffer.
+	// The DRE interrupt itself is only enabled while there is data to send.
+	uart.txUsed.Set(0)
+	uart.txInterrupt.Enable()
+
 	return nil
 }
 
@@ -1099,6 +1118,12 @@ func (uart *UART) SetBaudRate(br uint32) {
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
+	uart.txUsed.Set(1)
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
 	// wait until ready to receive
 	for !uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INT_INTFLAG_DRE) {
 	}
@@ -1106,16 +1131,54 @@ func (uart *UART) WriteByte(c byte) error {
 	return nil
 }
 
+// writeDMA writes large buffers to the UART using a DMA channel. It returns
+// false if the data must be written byte by byte instead, which is always the
+// case with a TX buffer so that the data stays in order.
+func (uart *UART) writeDMA(data []byte) bool {
+	if uart.TXBuffer != nil || len(data) < dmaMinTransfer {
+		return false
+	}
+	uart.txUsed.Set(1)
+	return dmaWrite(unsafe.Pointer(&uart.Bus.DATA.Reg), dmaTriggerSERCOM_TX(uart.SERCOM), data)
+}
+
+// handleInterrupt handles both the RXC and the DRE interrupt.
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	// should reset IRQ
-	uart.Receive(byte((uart.Bus.DATA.Get() & 0xFF)))
-	uart.Bus.INTFLAG.SetBits(sam.SERCOM_USART_INT_INTFLAG_RXC)
+	// The SERCOM interrupt is shared with I2C target mode.
+	if uart.Bus.CTRLA.Get()&sam.SERCOM_USART_INT_CTRLA_MODE_Msk != 1<<sam.SERCOM_USART_INT_CTRLA_MODE_Pos {
+		return
+	}
+	if uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INT_INTFLAG_RXC) {
+		// should reset IRQ
+		uart.Receive(byte((uart.Bus.DATA.Get() & 0xFF)))
+		uart.Bus.INTFLAG.SetBits(sam.SERCOM_USART_INT_INTFLAG_RXC)
+	}
+
+	if uart.Bus.INTENSET.HasBits(sam.SERCOM_USART_INT_INTENSET_DRE) && uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INT_INTFLAG_DRE) {
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uart.Bus.DATA.Set(uint32(c))
+		} else {
+			uart.Bus.INTENCLR.Set(sam.SERCOM_USART_INT_INTENCLR_DRE)
+		}
+	}
+}
+
+// startTX enables the DRE interrupt, which sends the bytes in TXBuffer.
+func (uart *UART) startTX() {
+	uart.Bus.INTENSET.Set(sam.SERCOM_USART_INT_INTENSET_DRE)
+}
+
+// txComplete returns whether the last byte written has been sent.
+func (uart *UART) txComplete() bool {
+	return uart.txUsed.Get() == 0 || uart.Bus.INTFLAG.HasBits(sam.SERCOM_USART_INT_INTFLAG_TXC)
 }
 
 // I2C on the SAMD51.
 type I2C struct {
-	Bus    *sam.SERCOM_I2CM_Type
-	SERCOM uint8
+	Bus        *sam.SERCOM_I2CM_Type
+	SERCOM     uint8
+	mode       I2CMode
+	targetWake interruptCond
 }
 
 // I2CConfig is used to store config info for I2C.
@@ -1123,6 +1186,8 @@ type I2CConfig struct {
 	Frequency uint32
 	SCL       Pin
 	SDA       Pin
+	// Mode selects controller (default) or target operation.
+	Mode I2CMode
 }
 
 const (
@@ -1185,6 +1250,15 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 	// set clock
 	setSERCOMClockGenerator(i2c.SERCOM, sam.GCLK_PCHCTRL_GEN_GCLK1)
 
+	i2c.mode = config.Mode
+	if config.Mode == I2CModeTarget {
+		// The SERCOM is enabled by Listen, once the address is known.
+		i2c.target().CTRLA.Set(wireTargetOperation << sam.SERCOM_I2CS_CTRLA_MODE_Pos)
+		config.SDA.Configure(PinConfig{Mode: sdaPinMode})
+		config.SCL.Configure(PinConfig{Mode: sclPinMode})
+		return nil
+	}
+
 	// Set i2c controller mode
 	//SERCOM_I2CM_CTRLA_MODE( I2C_MASTER_OPERATION )
 	// sam.SERCOM_I2CM_CTRLA_MODE_I2C_MASTER = 5?
@@ -1222,6 +1296,9 @@ func (i2c *I2C) SetBaudRate(br uint32) {
 // It clocks out the given address, writes the bytes in w, reads back len(r)
 // bytes and stores them in r, and generates a stop condition on the bus.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
+	if i2c.mode != I2CModeController {
+		return errI2CWrongMode
+	}
 	var err error
 	if len(w) != 0 {
 		// send start/address for write
@@ -1572,10 +1649,14 @@ func (spi SPI) Tx(w, r []byte) error {
 }
 
 func (spi SPI) tx(tx []byte) {
-	for i := 0; i < len(tx); i++ {
-		for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPIM_INTFLAG_DRE) {
+	// Large buffers are written by a DMA channel, paced by the data register
+	// empty request of the SERCOM.
+	if len(tx) < dmaMinTransfer || !dmaWrite(unsafe.Pointer(&spi.Bus.DATA.Reg), dmaTriggerSERCOM_TX(spi.SERCOM), tx) {
+		for i := 0; i < len(tx); i++ {
+			for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPIM_INTFLAG_DRE) {
+			}
+			spi.Bus.DATA.Set(uint32(tx[i]))
 		}
-		spi.Bus.DATA.Set(uint32(tx[i]))
 	}
 	for !spi.Bus.INTFLAG.HasBits(sam.SERCOM_SPIM_INTFLAG_TXC) {
 	}
@@ -2112,15 +2193,11 @@ const (
 var (
 	usbEndpointDescriptors [8]usbDeviceDescriptor
 
-	udd_ep_in_cache_buffer  [7][128]uint8
-	udd_ep_out_cache_buffer [7][128]uint8
+	udd_ep_in_cache_buffer  [usb_EPT_NUM + 1][128]uint8
+	udd_ep_out_cache_buffer [usb_EPT_NUM + 1][128]uint8
 
 	isEndpointHalt        = false
 	isRemoteWakeUpEnabled = false
-	endPoints             = []uint32{usb_ENDPOINT_TYPE_CONTROL,
-		(usb_ENDPOINT_TYPE_INTERRUPT | usbEndpointIn),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointOut),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)}
 
 	usbConfiguration uint8
 	usbSetInterface  uint8
@@ -2257,14 +2334,12 @@ func handleUSBIRQ(interrupt.Interrupt) {
 		usbEndpointDescriptors[0].DeviceDescBank[0].PCKSIZE.ClearBits(usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask << usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos)
 
 		ok := false
-		if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
+		if handler := usbInterfaceHandler(setup); handler != nil {
+			// Interface Requests
+			ok = handler(setup)
+		} else if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
 			// Standard Requests
 			ok = handleStandardSetup(setup)
-		} else {
-			// Class Interface Requests
-			if setup.wIndex == usb_CDC_ACM_INTERFACE {
-				ok = cdcSetup(setup)
-			}
 		}
 
 		if ok {
@@ -2302,6 +2377,14 @@ func handleUSBIRQ(interrupt.Interrupt) {
 				if i == usb_CDC_ENDPOINT_IN {
 					USB.waitTxc = false
 				}
+			default:
+				if (epFlags&sam.USB_DEVICE_ENDPOINT_EPINTFLAG_TRCPT0) > 0 && usbRxHandler[i] != nil {
+					handleEndpoint(i)
+				}
+				setEPINTFLAG(i, epFlags)
+				if (epFlags&sam.USB_DEVICE_ENDPOINT_EPINTFLAG_TRCPT1) > 0 && usbTxHandler[i] != nil {
+					usbTxHandler[i]()
+				}
 			}
 		}
 	}
@@ -2472,6 +2555,13 @@ func handleStandardSetup(setup usbSetup) bool {
 			// Enable interrupt for CDC data messages from host
 			setEPINTENSET(usb_CDC_ENDPOINT_OUT, sam.USB_DEVICE_ENDPOINT_EPINTENSET_TRCPT0)
 
+			// Enable interrupt for completed IN transfers of other functions
+			for i := usb_CDC_ENDPOINT_IN + 1; i < len(endPoints); i++ {
+				if usbTxHandler[i] != nil {
+					setEPINTENSET(uint32(i), sam.USB_DEVICE_ENDPOINT_EPINTENSET_TRCPT1)
+				}
+			}
+
 			sendZlp()
 			return true
 		} else {
@@ -2551,10 +2641,19 @@ func cdcSetup(setup usbSetup) bool {
 
 //go:noinline
 func sendUSBPacket(ep uint32, data []byte) {
-	copy(udd_ep_in_cache_buffer[ep][:], data)
+	// The control endpoint has a larger buffer for descriptors, which are
+	// sent in multiple packets.
+	buf := udd_ep_in_cache_buffer[ep][:]
+	if ep == 0 {
+		buf = usbEP0InBuffer[:]
+	}
+	copy(buf, data)
+	if len(data) > len(buf) {
+		data = data[:len(buf)]
+	}
 
 	// Set endpoint address for sending data
-	usbEndpointDescriptors[ep].DeviceDescBank[1].ADDR.Set(uint32(uintptr(unsafe.Pointer(&udd_ep_in_cache_buffer[ep]))))
+	usbEndpointDescriptors[ep].DeviceDescBank[1].ADDR.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
 
 	// clear multi-packet size which is total bytes already sent
 	usbEndpointDescriptors[ep].DeviceDescBank[1].PCKSIZE.ClearBits(usb_DEVICE_PCKSIZE_MULTI_PACKET_SIZE_Mask << usb_DEVICE_PCKSIZE_MULTI_PACKET_SIZE_Pos)
@@ -2564,6 +2663,14 @@ func sendUSBPacket(ep uint32, data []byte) {
 	usbEndpointDescriptors[ep].DeviceDescBank[1].PCKSIZE.SetBits(uint32((len(data) & usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask) << usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos))
 }
 
+// sendUSBInPacket starts an IN transfer on an endpoint other than the control
+// endpoint. The TRCPT1 interrupt signals that it has completed.
+func sendUSBInPacket(ep uint32, data []byte) {
+	sendUSBPacket(ep, data)
+	setEPINTFLAG(ep, sam.USB_DEVICE_ENDPOINT_EPINTFLAG_TRCPT1)
+	setEPSTATUSSET(ep, sam.USB_DEVICE_ENDPOINT_EPSTATUSSET_BK1RDY)
+}
+
 func receiveUSBControlPacket() ([cdcLineInfoSize]byte, error) {
 	var b [cdcLineInfoSize]byte
 
@@ -2612,9 +2719,13 @@ func handleEndpoint(ep uint32) {
 	count := int((usbEndpointDescriptors[ep].DeviceDescBank[0].PCKSIZE.Get() >>
 		usb_DEVICE_PCKSIZE_BYTE_COUNT_Pos) & usb_DEVICE_PCKSIZE_BYTE_COUNT_Mask)
 
-	// move to ring buffer
-	for i := 0; i < count; i++ {
-		USB.Receive(byte((udd_ep_out_cache_buffer[ep][i] & 0xFF)))
+	if ep == usb_CDC_ENDPOINT_OUT {
+		// move to ring buffer
+		for i := 0; i < count; i++ {
+			USB.Receive(byte((udd_ep_out_cache_buffer[ep][i] & 0xFF)))
+		}
+	} else {
+		usbRxHandler[ep](udd_ep_out_cache_buffer[ep][:count])
 	}
 
 	// set byte count to zero
@@ -0,0 +1,147 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The DMAC has 32 channels, but every channel in use needs 32 bytes of
+// descriptor memory so only the first 8 are made available.
+const dmaChannelCount = 8
+
+// DMA triggers for the SERCOM peripherals.
+const (
+	DMATriggerSERCOM0_RX DMATrigger = 0x04 + iota
+	DMATriggerSERCOM0_TX
+	DMATriggerSERCOM1_RX
+	DMATriggerSERCOM1_TX
+	DMATriggerSERCOM2_RX
+	DMATriggerSERCOM2_TX
+	DMATriggerSERCOM3_RX
+	DMATriggerSERCOM3_TX
+	DMATriggerSERCOM4_RX
+	DMATriggerSERCOM4_TX
+	DMATriggerSERCOM5_RX
+	DMATriggerSERCOM5_TX
+	DMATriggerSERCOM6_RX
+	DMATriggerSERCOM6_TX
+	DMATriggerSERCOM7_RX
+	DMATriggerSERCOM7_TX
+)
+
+// dmaTriggerSERCOM_TX returns the trigger of the data register empty request
+// of the given SERCOM.
+func dmaTriggerSERCOM_TX(sercom uint8) DMATrigger {
+	return DMATriggerSERCOM0_TX + DMATrigger(sercom)*2
+}
+
+// Channel registers, which start at offset 0x40 of the DMAC.
+type dmaChannelType struct {
+	chctrla    volatile.Register32
+	chctrlb    volatile.Register8
+	chprilvl   volatile.Register8
+	chevctrl   volatile.Register8
+	_          [5]volatile.Register8
+	chintenclr volatile.Register8
+	chintenset volatile.Register8
+	chintflag  volatile.Register8
+	chstatus   volatile.Register8
+}
+
+var dmaChannelRegs = (*[dmaChannelCount]dmaChannelType)(unsafe.Pointer(uintptr(unsafe.Pointer(sam.DMAC)) + 0x40))
+
+const (
+	dmaCHCTRLA_SWRST       = 1 << 0
+	dmaCHCTRLA_ENABLE      = 1 << 1
+	dmaCHCTRLA_TRIGSRC_Pos = 8
+	dmaCHCTRLA_TRIGACT_Pos = 20
+
+	dmaCHINTFLAG_TERR  = 1 << 0
+	dmaCHINTFLAG_TCMPL = 1 << 1
+)
+
+func dmaInit() {
+	sam.MCLK.AHBMASK.SetBits(sam.MCLK_AHBMASK_DMAC_)
+
+	sam.DMAC.CTRL.ClearBits(sam.DMAC_CTRL_DMAENABLE)
+	sam.DMAC.CTRL.SetBits(sam.DMAC_CTRL_SWRST)
+	for sam.DMAC.CTRL.HasBits(sam.DMAC_CTRL_SWRST) {
+	}
+
+	sam.DMAC.BASEADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaDescriptors))))
+	sam.DMAC.WRBADDR.Set(uint32(uintptr(unsafe.Pointer(&dmaWriteback))))
+	sam.DMAC.CTRL.Set(sam.DMAC_CTRL_DMAENABLE |
+		sam.DMAC_CTRL_LVLEN0 | sam.DMAC_CTRL_LVLEN1 | sam.DMAC_CTRL_LVLEN2 | sam.DMAC_CTRL_LVLEN3)
+
+	// Channels 0-3 have their own interrupt, the others share one.
+	interrupt.New(sam.IRQ_DMAC_0, dmaHandleInterrupt).Enable()
+	interrupt.New(sam.IRQ_DMAC_1, dmaHandleInterrupt).Enable()
+	interrupt.New(sam.IRQ_DMAC_2, dmaHandleInterrupt).Enable()
+	interrupt.New(sam.IRQ_DMAC_3, dmaHandleInterrupt).Enable()
+	interrupt.New(sam.IRQ_DMAC_OTHER, dmaHandleInterrupt).Enable()
+}
+
+func (ch *DMAChannel) configure() {
+	ch.abort()
+	regs := &dmaChannelRegs[ch.id]
+	regs.chctrla.Set(dmaCHCTRLA_SWRST)
+	for regs.chctrla.HasBits(dmaCHCTRLA_SWRST) {
+	}
+
+	// A peripheral request moves a single beat, a software trigger moves the
+	// whole block.
+	trigact := uint32(dmaTRIGACT_BURST)
+	if ch.config.Trigger == DMATriggerSoftware {
+		trigact = dmaTRIGACT_BLOCK
+	}
+	regs.chctrla.Set(uint32(ch.config.Trigger)<<dmaCHCTRLA_TRIGSRC_Pos |
+		trigact<<dmaCHCTRLA_TRIGACT_Pos)
+
+	// The reset above also cleared the interrupt enable.
+	ch.setInterrupt(ch.callback != nil)
+}
+
+func (ch *DMAChannel) start(dst, src unsafe.Pointer, count uint32) {
+	ch.setDescriptor(dst, src, count)
+	regs := &dmaChannelRegs[ch.id]
+	regs.chintflag.Set(dmaCHINTFLAG_TERR | dmaCHINTFLAG_TCMPL)
+	regs.chctrla.SetBits(dmaCHCTRLA_ENABLE)
+	if ch.config.Trigger == DMATriggerSoftware {
+		sam.DMAC.SWTRIGCTRL.SetBits(1 << ch.id)
+	}
+}
+
+// The DMAC disables a channel by itself once its last block is transferred.
+func (ch *DMAChannel) busy() bool {
+	return dmaChannelRegs[ch.id].chctrla.HasBits(dmaCHCTRLA_ENABLE)
+}
+
+func (ch *DMAChannel) abort() {
+	regs := &dmaChannelRegs[ch.id]
+	regs.chctrla.ClearBits(dmaCHCTRLA_ENABLE)
+	for regs.chctrla.HasBits(dmaCHCTRLA_ENABLE) {
+	}
+}
+
+func (ch *DMAChannel) setInterrupt(enabled bool) {
+	if enabled {
+		dmaChannelRegs[ch.id].chintenset.Set(dmaCHINTFLAG_TCMPL)
+	} else {
+		dmaChannelRegs[ch.id].chintenclr.Set(dmaCHINTFLAG_TCMPL)
+	}
+}
+
+func dmaHandleInterrupt(interrupt.Interrupt) {
+	for i := range dmaChannelRegs {
+		regs := &dmaChannelRegs[i]
+		if regs.chintflag.HasBits(dmaCHINTFLAG_TCMPL) {
+			regs.chintflag.Set(dmaCHINTFLAG_TCMPL)
+			dmaComplete(uint8(i))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The NVM controller writes quad words of 16 bytes, which it protects with an
+// ECC and which can therefore be written only once after an erase. It erases
+// blocks of 8kB. The page buffer is written with 32-bit writes.
+const (
+	flashWriteBlockSize = 16
+	flashEraseBlockSize = 8192
+)
+
+// flashWrite writes data, which must be a multiple of 16 bytes long, to the
+// quad word aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	for i := 0; i < len(data); i += flashWriteBlockSize {
+		flashCommand(sam.NVMCTRL_CTRLB_CMD_PBC)
+		for j := i; j < i+flashWriteBlockSize; j += 4 {
+			word := uint32(data[j]) | uint32(data[j+1])<<8 | uint32(data[j+2])<<16 | uint32(data[j+3])<<24
+			volatile.StoreUint32((*uint32)(unsafe.Pointer(addr+uintptr(j))), word)
+		}
+		sam.NVMCTRL.ADDR.Set(uint32(addr + uintptr(i)))
+		if !flashCommand(sam.NVMCTRL_CTRLB_CMD_WQW) {
+			return errFlashWriteFailed
+		}
+	}
+	return nil
+}
+
+// flashErase erases the block at the given address.
+func flashErase(addr uintptr) error {
+	sam.NVMCTRL.ADDR.Set(uint32(addr))
+	if !flashCommand(sam.NVMCTRL_CTRLB_CMD_EB) {
+		return errFlashEraseFailed
+	}
+	return nil
+}
+
+// flashCommand runs a NVM controller command and waits for it to finish. It
+// returns false if the controller reported an error.
+func flashCommand(cmd uint16) bool {
+	const errorFlags = sam.NVMCTRL_INTFLAG_ADDRE | sam.NVMCTRL_INTFLAG_PROGE | sam.NVMCTRL_INTFLAG_LOCKE | sam.NVMCTRL_INTFLAG_NVME
+	for !sam.NVMCTRL.STATUS.HasBits(sam.NVMCTRL_STATUS_READY) {
+	}
+	sam.NVMCTRL.INTFLAG.Set(errorFlags | sam.NVMCTRL_INTFLAG_DONE)
+	sam.NVMCTRL.CTRLB.Set(cmd | sam.NVMCTRL_CTRLB_CMDEX_KEY<<sam.NVMCTRL_CTRLB_CMDEX_Pos)
+	for !sam.NVMCTRL.INTFLAG.HasBits(sam.NVMCTRL_INTFLAG_DONE) {
+	}
+	return !sam.NVMCTRL.INTFLAG.HasBits(errorFlags)
+}
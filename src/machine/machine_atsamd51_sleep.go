@@ -0,0 +1,43 @@
+//go:build (sam && atsamd51) || (sam && atsame5x)
+// +build sam,atsamd51 sam,atsame5x
+
+package machine
+
+import (
+	"device/sam"
+)
+
+const hasSystemOff = false
+
+func systemOff(pins []WakePin) {}
+
+// enterDeepSleep clocks the EIC from the ultra low power 32kHz oscillator,
+// which keeps running in standby, so that it can detect the wake pins.
+func enterDeepSleep() {
+	setEICClockULP(true)
+}
+
+// exitDeepSleep clocks the EIC from its generic clock again, as configured by
+// SetInterrupt.
+func exitDeepSleep() {
+	setEICClockULP(false)
+}
+
+func setEICClockULP(ulp bool) {
+	if !sam.EIC.CTRLA.HasBits(sam.EIC_CTRLA_ENABLE) {
+		return
+	}
+
+	// The clock selection is enable-protected, so disable the EIC.
+	sam.EIC.CTRLA.ClearBits(sam.EIC_CTRLA_ENABLE)
+	for sam.EIC.SYNCBUSY.HasBits(sam.EIC_SYNCBUSY_ENABLE) {
+	}
+	if ulp {
+		sam.EIC.CTRLA.SetBits(sam.EIC_CTRLA_CKSEL)
+	} else {
+		sam.EIC.CTRLA.ClearBits(sam.EIC_CTRLA_CKSEL)
+	}
+	sam.EIC.CTRLA.SetBits(sam.EIC_CTRLA_ENABLE)
+	for sam.EIC.SYNCBUSY.HasBits(sam.EIC_SYNCBUSY_ENABLE) {
+	}
+}
@@ -8,7 +8,10 @@
 //
 package machine
 
-import "device/sam"
+import (
+	"device/sam"
+	"runtime/interrupt"
+)
 
 const HSRAM_SIZE = 0x00040000
 
@@ -64,6 +67,74 @@ func setSERCOMClockGenerator(sercom uint8, gclk uint32) {
 	}
 }
 
+// enableSERCOMTargetInterrupt enables the interrupts used by I2C target mode
+// on the given SERCOM. The stop, address match and data ready flags each have
+// their own interrupt.
+func enableSERCOMTargetInterrupt(sercom uint8) {
+	switch sercom {
+	case 0:
+		interrupt.New(sam.IRQ_SERCOM0_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(0)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM0_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(0)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM0_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(0)
+		}).Enable()
+	case 1:
+		interrupt.New(sam.IRQ_SERCOM1_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(1)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM1_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(1)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM1_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(1)
+		}).Enable()
+	case 2:
+		interrupt.New(sam.IRQ_SERCOM2_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(2)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM2_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(2)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM2_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(2)
+		}).Enable()
+	case 3:
+		interrupt.New(sam.IRQ_SERCOM3_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(3)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM3_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(3)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM3_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(3)
+		}).Enable()
+	case 4:
+		interrupt.New(sam.IRQ_SERCOM4_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(4)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM4_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(4)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM4_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(4)
+		}).Enable()
+	case 5:
+		interrupt.New(sam.IRQ_SERCOM5_0, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(5)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM5_1, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(5)
+		}).Enable()
+		interrupt.New(sam.IRQ_SERCOM5_2, func(interrupt.Interrupt) {
+			handleSERCOMTargetInterrupt(5)
+		}).Enable()
+	}
+}
+
 // This chip has five TCC peripherals, which have PWM as one feature.
 var (
 	TCC0 = (*TCC)(sam.TCC0)
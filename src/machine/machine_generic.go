@@ -3,7 +3,9 @@
 
 package machine
 
-// Dummy machine package that calls out to external functions.
+// Dummy machine package that calls out to external functions. On Linux hosts,
+// these are implemented by the peripheral simulator in machine/sim, which also
+// implements a few extra functions (see machine_generic_sim.go).
 
 const deviceName = "generic"
 
@@ -25,10 +27,6 @@ const (
 	PinInputPulldown
 )
 
-func (p Pin) Configure(config PinConfig) {
-	gpioConfigure(p, config)
-}
-
 func (p Pin) Set(value bool) {
 	gpioSet(p, value)
 }
@@ -37,9 +35,6 @@ func (p Pin) Get() bool {
 	return gpioGet(p)
 }
 
-//export __tinygo_gpio_configure
-func gpioConfigure(pin Pin, config PinConfig)
-
 //export __tinygo_gpio_set
 func gpioSet(pin Pin, value bool)
 
@@ -108,19 +103,9 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 	return nil
 }
 
-// Tx does a single I2C transaction at the specified address.
-func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
-	i2cTransfer(i2c.Bus, &w[0], len(w), &r[0], len(r))
-	// TODO: do something with the returned error code.
-	return nil
-}
-
 //export __tinygo_i2c_configure
 func i2cConfigure(bus uint8, scl Pin, sda Pin)
 
-//export __tinygo_i2c_transfer
-func i2cTransfer(bus uint8, w *byte, wlen int, r *byte, rlen int) int
-
 type UART struct {
 	Bus uint8
 }
@@ -132,17 +117,12 @@ func (uart *UART) Configure(config UARTConfig) {
 
 // Read from the UART.
 func (uart *UART) Read(data []byte) (n int, err error) {
-	return uartRead(uart.Bus, &data[0], len(data)), nil
+	return uartRead(uart.Bus, bufferPointer(data), len(data)), nil
 }
 
 // Write to the UART.
 func (uart *UART) Write(data []byte) (n int, err error) {
-	return uartWrite(uart.Bus, &data[0], len(data)), nil
-}
-
-// Buffered returns the number of bytes currently stored in the RX buffer.
-func (uart *UART) Buffered() int {
-	return 0
+	return uartWrite(uart.Bus, bufferPointer(data), len(data)), nil
 }
 
 // ReadByte reads a single byte from the UART.
@@ -167,6 +147,15 @@ func uartRead(bus uint8, buf *byte, bufLen int) int
 //export __tinygo_uart_write
 func uartWrite(bus uint8, buf *byte, bufLen int) int
 
+// bufferPointer returns a pointer to the first byte of buf, or nil if buf is
+// empty.
+func bufferPointer(buf []byte) *byte {
+	if len(buf) == 0 {
+		return nil
+	}
+	return &buf[0]
+}
+
 // Some objects used by Atmel SAM D chips (samd21, samd51).
 // Defined here (without build tag) for convenience.
 var (
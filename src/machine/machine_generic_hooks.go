@@ -0,0 +1,31 @@
+//go:build !baremetal && (!linux || tinygo.wasm)
+// +build !baremetal
+// +build !linux tinygo.wasm
+
+package machine
+
+// External functions of the generic machine package that are implemented by
+// the environment, for example by JavaScript in a browser simulation. Their
+// signatures must not change.
+
+func (p Pin) Configure(config PinConfig) {
+	gpioConfigure(p, config)
+}
+
+//export __tinygo_gpio_configure
+func gpioConfigure(pin Pin, config PinConfig)
+
+// Tx does a single I2C transaction at the specified address.
+func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
+	i2cTransfer(i2c.Bus, bufferPointer(w), len(w), bufferPointer(r), len(r))
+	// TODO: do something with the returned error code.
+	return nil
+}
+
+//export __tinygo_i2c_transfer
+func i2cTransfer(bus uint8, w *byte, wlen int, r *byte, rlen int) int
+
+// Buffered returns the number of bytes currently stored in the RX buffer.
+func (uart *UART) Buffered() int {
+	return 0
+}
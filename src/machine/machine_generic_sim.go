@@ -0,0 +1,44 @@
+//go:build linux && !baremetal && !tinygo.wasm
+// +build linux,!baremetal,!tinygo.wasm
+
+package machine
+
+import (
+	"errors"
+
+	// Link in the peripheral simulator, which implements the external
+	// functions used by the generic machine package.
+	_ "machine/sim"
+)
+
+// The simulator implements the functions below in addition to the external
+// functions of machine_generic.go. They have their own names so that the
+// functions implemented by other environments keep their signatures.
+
+func (p Pin) Configure(config PinConfig) {
+	simGPIOConfigure(p, config.Mode)
+}
+
+//export __tinygo_sim_gpio_configure
+func simGPIOConfigure(pin Pin, mode PinMode)
+
+var errI2CTransfer = errors.New("I2C error: transfer failed")
+
+// Tx does a single I2C transaction at the specified address.
+func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
+	if simI2CTransfer(i2c.Bus, addr, bufferPointer(w), len(w), bufferPointer(r), len(r)) != 0 {
+		return errI2CTransfer
+	}
+	return nil
+}
+
+//export __tinygo_sim_i2c_transfer
+func simI2CTransfer(bus uint8, addr uint16, w *byte, wlen int, r *byte, rlen int) int
+
+// Buffered returns the number of bytes currently stored in the RX buffer.
+func (uart *UART) Buffered() int {
+	return simUARTBuffered(uart.Bus)
+}
+
+//export __tinygo_sim_uart_buffered
+func simUARTBuffered(bus uint8) int
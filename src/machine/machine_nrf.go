@@ -7,6 +7,7 @@ import (
 	"device/nrf"
 	"errors"
 	"runtime/interrupt"
+	"runtime/volatile"
 	"unsafe"
 )
 
@@ -136,7 +137,11 @@ func (p Pin) SetInterrupt(change PinChange, callback func(Pin)) error {
 
 // UART on the NRF.
 type UART struct {
-	Buffer *RingBuffer
+	Buffer   *RingBuffer
+	TXBuffer *RingBuffer
+
+	// txActive is set while the TX interrupt is sending bytes from TXBuffer.
+	txActive volatile.Register8
 }
 
 // UART
@@ -153,6 +158,8 @@ func (uart *UART) Configure(config UARTConfig) {
 		config.BaudRate = 115200
 	}
 
+	uart.setBuffers(config)
+
 	uart.SetBaudRate(config.BaudRate)
 
 	// Set TX and RX pins
@@ -163,12 +170,9 @@ func (uart *UART) Configure(config UARTConfig) {
 		uart.setPins(config.TX, config.RX)
 	}
 
-	nrf.UART0.ENABLE.Set(nrf.UART_ENABLE_ENABLE_Enabled)
-	nrf.UART0.TASKS_STARTTX.Set(1)
-	nrf.UART0.TASKS_STARTRX.Set(1)
-	nrf.UART0.INTENSET.Set(nrf.UART_INTENSET_RXDRDY_Msk)
+	uart.start()
 
-	// Enable RX IRQ.
+	// Enable RX and TX IRQ.
 	intr := interrupt.New(nrf.IRQ_UART0, _UART0.handleInterrupt)
 	intr.SetPriority(0xc0) // low priority
 	intr.Enable()
@@ -189,22 +193,6 @@ func (uart *UART) SetBaudRate(br uint32) {
 	nrf.UART0.BAUDRATE.Set(rate)
 }
 
-// WriteByte writes a byte of data to the UART.
-func (uart *UART) WriteByte(c byte) error {
-	nrf.UART0.EVENTS_TXDRDY.Set(0)
-	nrf.UART0.TXD.Set(uint32(c))
-	for nrf.UART0.EVENTS_TXDRDY.Get() == 0 {
-	}
-	return nil
-}
-
-func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	if nrf.UART0.EVENTS_RXDRDY.Get() != 0 {
-		uart.Receive(byte(nrf.UART0.RXD.Get()))
-		nrf.UART0.EVENTS_RXDRDY.Set(0x0)
-	}
-}
-
 // I2C on the NRF.
 type I2C struct {
 	Bus nrf.TWI_Type
@@ -221,6 +209,9 @@ type I2CConfig struct {
 	Frequency uint32
 	SCL       Pin
 	SDA       Pin
+	// Mode selects controller (default) or target operation. Target mode is
+	// only supported on the nRF52 series.
+	Mode I2CMode
 }
 
 // Configure is intended to setup the I2C interface.
@@ -261,6 +252,12 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 
 	i2c.setPins(config.SCL, config.SDA)
 
+	if config.Mode == I2CModeTarget {
+		// Target mode uses the TWIS peripheral at the same address, which
+		// shares the pin configuration. It is enabled by Listen.
+		return nil
+	}
+
 	i2c.Bus.ENABLE.Set(nrf.TWI_ENABLE_ENABLE_Enabled)
 
 	return nil
@@ -270,6 +267,9 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 // It clocks out the given address, writes the bytes in w, reads back len(r)
 // bytes and stores them in r, and generates a stop condition on the bus.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) (err error) {
+	if i2c.Bus.ENABLE.Get() != nrf.TWI_ENABLE_ENABLE_Enabled {
+		return errI2CWrongMode
+	}
 
 	// Tricky stop condition.
 	// After reads, the stop condition is generated implicitly with a shortcut.
@@ -5,6 +5,7 @@ package machine
 
 import (
 	"device/nrf"
+	"runtime/interrupt"
 )
 
 func CPUFrequency() uint32 {
@@ -21,6 +22,72 @@ func (uart *UART) setPins(tx, rx Pin) {
 	nrf.UART0.PSELRXD.Set(uint32(rx))
 }
 
+// start enables the UART, with interrupts for received bytes and (if there is
+// a TX buffer) for sent bytes.
+func (uart *UART) start() {
+	nrf.UART0.ENABLE.Set(nrf.UART_ENABLE_ENABLE_Enabled)
+	nrf.UART0.TASKS_STARTTX.Set(1)
+	nrf.UART0.TASKS_STARTRX.Set(1)
+	nrf.UART0.INTENSET.Set(nrf.UART_INTENSET_RXDRDY_Msk)
+	if uart.TXBuffer != nil {
+		nrf.UART0.INTENSET.Set(nrf.UART_INTENSET_TXDRDY_Msk)
+	} else {
+		nrf.UART0.INTENCLR.Set(nrf.UART_INTENCLR_TXDRDY_Msk)
+	}
+}
+
+// WriteByte writes a byte of data to the UART.
+func (uart *UART) WriteByte(c byte) error {
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
+	nrf.UART0.EVENTS_TXDRDY.Set(0)
+	nrf.UART0.TXD.Set(uint32(c))
+	for nrf.UART0.EVENTS_TXDRDY.Get() == 0 {
+	}
+	return nil
+}
+
+func (uart *UART) handleInterrupt(interrupt.Interrupt) {
+	if nrf.UART0.EVENTS_RXDRDY.Get() != 0 {
+		uart.Receive(byte(nrf.UART0.RXD.Get()))
+		nrf.UART0.EVENTS_RXDRDY.Set(0x0)
+	}
+
+	// The TXDRDY event is also set by synchronous writes, so only handle it
+	// when the interrupt is enabled.
+	if nrf.UART0.EVENTS_TXDRDY.Get() != 0 && nrf.UART0.INTENSET.HasBits(nrf.UART_INTENSET_TXDRDY_Msk) {
+		nrf.UART0.EVENTS_TXDRDY.Set(0x0)
+		if c, ok := uart.TXBuffer.Get(); ok {
+			nrf.UART0.TXD.Set(uint32(c))
+		} else {
+			uart.txActive.Set(0)
+		}
+	}
+}
+
+// startTX sends the first byte from TXBuffer if no bytes are being sent. The
+// TXDRDY interrupt then sends the rest of the buffer.
+func (uart *UART) startTX() {
+	mask := interrupt.Disable()
+	if uart.txActive.Get() == 0 {
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uart.txActive.Set(1)
+			nrf.UART0.EVENTS_TXDRDY.Set(0x0)
+			nrf.UART0.TXD.Set(uint32(c))
+		}
+	}
+	interrupt.Restore(mask)
+}
+
+// txComplete returns whether the last byte written has been sent. The TXDRDY
+// event is only generated once a byte has left the UART.
+func (uart *UART) txComplete() bool {
+	return uart.txActive.Get() == 0
+}
+
 func (i2c *I2C) setPins(scl, sda Pin) {
 	i2c.Bus.PSELSCL.Set(uint32(scl))
 	i2c.Bus.PSELSDA.Set(uint32(sda))
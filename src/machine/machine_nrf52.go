@@ -48,6 +48,9 @@ func (p Pin) getPortPin() (*nrf.GPIO_Type, uint32) {
 	return nrf.P0, uint32(p)
 }
 
+// The EasyDMA MAXCNT registers of the nrf52832 are 8 bits wide.
+const dmaMaxTransferCount = 255
+
 func (uart *UART) setPins(tx, rx Pin) {
 	nrf.UART0.PSELTXD.Set(uint32(tx))
 	nrf.UART0.PSELRXD.Set(uint32(rx))
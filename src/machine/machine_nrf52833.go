@@ -68,6 +68,9 @@ func (p Pin) getPortPin() (*nrf.GPIO_Type, uint32) {
 	}
 }
 
+// The EasyDMA MAXCNT registers of the SPIM and UARTE are 16 bits wide.
+const dmaMaxTransferCount = 0xffff
+
 func (uart *UART) setPins(tx, rx Pin) {
 	nrf.UART0.PSEL.TXD.Set(uint32(tx))
 	nrf.UART0.PSEL.RXD.Set(uint32(rx))
@@ -126,8 +126,8 @@ var (
 
 	usbEndpointDescriptors [8]usbDeviceDescriptor
 
-	udd_ep_in_cache_buffer  [7][128]uint8
-	udd_ep_out_cache_buffer [7][128]uint8
+	udd_ep_in_cache_buffer  [usb_EPT_NUM + 1][128]uint8
+	udd_ep_out_cache_buffer [usb_EPT_NUM + 1][128]uint8
 
 	sendOnEP0DATADONE struct {
 		ptr   *byte
@@ -135,10 +135,6 @@ var (
 	}
 	isEndpointHalt        = false
 	isRemoteWakeUpEnabled = false
-	endPoints             = []uint32{usb_ENDPOINT_TYPE_CONTROL,
-		(usb_ENDPOINT_TYPE_INTERRUPT | usbEndpointIn),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointOut),
-		(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)}
 
 	usbConfiguration         uint8
 	usbSetInterface          uint8
@@ -231,15 +227,18 @@ func (usbcdc *USBCDC) handleInterrupt(interrupt.Interrupt) {
 			return
 		}
 		if sendOnEP0DATADONE.ptr != nil {
-			// previous data was too big for one packet, so send a second
-			sendViaEPIn(
-				0,
-				sendOnEP0DATADONE.ptr,
-				sendOnEP0DATADONE.count,
-			)
-
-			// clear, so we know we're done
-			sendOnEP0DATADONE.ptr = nil
+			// previous data was too big for one packet, so send the next one
+			ptr := sendOnEP0DATADONE.ptr
+			count := sendOnEP0DATADONE.count
+			if count > usbEndpointPacketSize {
+				sendOnEP0DATADONE.ptr = (*byte)(unsafe.Pointer(uintptr(unsafe.Pointer(ptr)) + usbEndpointPacketSize))
+				sendOnEP0DATADONE.count = count - usbEndpointPacketSize
+				count = usbEndpointPacketSize
+			} else {
+				// clear, so we know we're done
+				sendOnEP0DATADONE.ptr = nil
+			}
+			sendViaEPIn(0, ptr, count)
 		} else {
 			// no more data, so set status stage
 			nrf.USBD.TASKS_EP0STATUS.Set(1)
@@ -256,13 +255,12 @@ func (usbcdc *USBCDC) handleInterrupt(interrupt.Interrupt) {
 		setup := parseUSBSetupRegisters()
 
 		ok := false
-		if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
+		if handler := usbInterfaceHandler(setup); handler != nil {
+			// Interface Requests
+			ok = handler(setup)
+		} else if (setup.bmRequestType & usb_REQUEST_TYPE) == usb_REQUEST_STANDARD {
 			// Standard Requests
 			ok = handleStandardSetup(setup)
-		} else {
-			if setup.wIndex == usb_CDC_ACM_INTERFACE {
-				ok = cdcSetup(setup)
-			}
 		}
 
 		if !ok {
@@ -284,19 +282,22 @@ func (usbcdc *USBCDC) handleInterrupt(interrupt.Interrupt) {
 			if inDataDone || outDataDone {
 				switch i {
 				case usb_CDC_ENDPOINT_OUT:
-					// setup buffer to receive from host
 					if outDataDone {
-						enterCriticalSection()
-						nrf.USBD.EPOUT[i].PTR.Set(uint32(uintptr(unsafe.Pointer(&udd_ep_out_cache_buffer[i]))))
-						count := nrf.USBD.SIZE.EPOUT[i].Get()
-						nrf.USBD.EPOUT[i].MAXCNT.Set(count)
-						nrf.USBD.TASKS_STARTEPOUT[i].Set(1)
+						startEPOut(i)
 					}
 				case usb_CDC_ENDPOINT_IN: //, usb_CDC_ENDPOINT_ACM:
 					if inDataDone {
 						usbcdc.waitTxc = false
 						exitCriticalSection()
 					}
+				default:
+					if outDataDone && usbRxHandler[i] != nil {
+						startEPOut(i)
+					}
+					if inDataDone && usbTxHandler[i] != nil {
+						exitCriticalSection()
+						usbTxHandler[i]()
+					}
 				}
 			}
 		}
@@ -315,7 +316,7 @@ func (usbcdc *USBCDC) handleInterrupt(interrupt.Interrupt) {
 				}
 				nrf.USBD.TASKS_EP0STATUS.Set(1)
 			}
-			if i == usb_CDC_ENDPOINT_OUT {
+			if i == usb_CDC_ENDPOINT_OUT || usbRxHandler[i] != nil {
 				usbcdc.handleEndpoint(uint32(i))
 			}
 			exitCriticalSection()
@@ -484,27 +485,44 @@ func cdcSetup(setup usbSetup) bool {
 
 //go:noinline
 func sendUSBPacket(ep uint32, data []byte) {
-	count := len(data)
-	copy(udd_ep_in_cache_buffer[ep][:], data)
+	// The control endpoint has a larger buffer for descriptors, which are
+	// sent in multiple packets.
+	buf := udd_ep_in_cache_buffer[ep][:]
+	if ep == 0 {
+		buf = usbEP0InBuffer[:]
+	}
+	count := copy(buf, data)
 	if ep == 0 && count > usbEndpointPacketSize {
-		sendOnEP0DATADONE.ptr = &udd_ep_in_cache_buffer[ep][usbEndpointPacketSize]
+		sendOnEP0DATADONE.ptr = &buf[usbEndpointPacketSize]
 		sendOnEP0DATADONE.count = count - usbEndpointPacketSize
 		count = usbEndpointPacketSize
 	}
 	sendViaEPIn(
 		ep,
-		&udd_ep_in_cache_buffer[ep][0],
+		&buf[0],
 		count,
 	)
 }
 
+// sendUSBInPacket starts an IN transfer on an endpoint other than the control
+// endpoint. The EPDATA event signals that it has completed.
+func sendUSBInPacket(ep uint32, data []byte) {
+	enterCriticalSection()
+	count := copy(udd_ep_in_cache_buffer[ep][:], data)
+	sendViaEPIn(ep, &udd_ep_in_cache_buffer[ep][0], count)
+}
+
 func (usbcdc *USBCDC) handleEndpoint(ep uint32) {
 	// get data
 	count := int(nrf.USBD.EPOUT[ep].AMOUNT.Get())
 
-	// move to ring buffer
-	for i := 0; i < count; i++ {
-		usbcdc.Receive(byte(udd_ep_out_cache_buffer[ep][i]))
+	if ep == usb_CDC_ENDPOINT_OUT {
+		// move to ring buffer
+		for i := 0; i < count; i++ {
+			usbcdc.Receive(byte(udd_ep_out_cache_buffer[ep][i]))
+		}
+	} else {
+		usbRxHandler[ep](udd_ep_out_cache_buffer[ep][:count])
 	}
 
 	// set ready for next data
@@ -515,6 +533,16 @@ func sendZlp() {
 	nrf.USBD.TASKS_EP0STATUS.Set(1)
 }
 
+// startEPOut sets up the buffer to receive the data of an OUT transfer from
+// the host. The ENDEPOUT event signals that it has been received.
+func startEPOut(ep uint32) {
+	enterCriticalSection()
+	nrf.USBD.EPOUT[ep].PTR.Set(uint32(uintptr(unsafe.Pointer(&udd_ep_out_cache_buffer[ep]))))
+	count := nrf.USBD.SIZE.EPOUT[ep].Get()
+	nrf.USBD.EPOUT[ep].MAXCNT.Set(count)
+	nrf.USBD.TASKS_STARTEPOUT[ep].Set(1)
+}
+
 func sendViaEPIn(ep uint32, ptr *byte, count int) {
 	nrf.USBD.EPIN[ep].PTR.Set(
 		uint32(uintptr(unsafe.Pointer(ptr))),
@@ -221,44 +221,97 @@ func (spi SPI) Transfer(w byte) (byte, error) {
 // padded until they fit: if len(w) > len(r) the extra bytes received will be
 // dropped and if len(w) < len(r) extra 0 bytes will be sent.
 func (spi SPI) Tx(w, r []byte) error {
-	// Unfortunately the hardware (on the nrf52832) only supports up to 255
-	// bytes in the buffers, so if either w or r is longer than that the
-	// transfer needs to be broken up in pieces.
-	// The nrf52840 supports far larger buffers however, which isn't yet
-	// supported.
+	// Large transfers run on a DMA channel so that other goroutines can run
+	// while waiting. Short transfers, or transfers when no channel is free,
+	// wait for the SPIM directly.
+	trigger := spi.dmaTrigger()
+	var ch *DMAChannel
+	if len(w) >= dmaMinTransfer || len(r) >= dmaMinTransfer {
+		ch, _ = AllocateDMAChannel()
+		if ch != nil {
+			ch.Configure(DMAConfig{Trigger: trigger})
+		}
+	}
+
 	for len(r) != 0 || len(w) != 0 {
-		// Prepare the SPI transfer: set the DMA pointers and lengths.
-		if len(r) != 0 {
-			spi.Bus.RXD.PTR.Set(uint32(uintptr(unsafe.Pointer(&r[0]))))
-			n := uint32(len(r))
-			if n > 255 {
-				n = 255
-			}
-			spi.Bus.RXD.MAXCNT.Set(n)
-			r = r[n:]
+		// Transfer as many bytes as possible in one go. Once one of the
+		// buffers runs out, the SPIM sends the ORC byte (zero) or drops the
+		// received bytes.
+		n := len(w)
+		if n == 0 || (len(r) != 0 && len(r) < n) {
+			n = len(r)
 		}
+		if n > dmaMaxTransferCount {
+			n = dmaMaxTransferCount
+		}
+		var dst, src unsafe.Pointer
 		if len(w) != 0 {
-			spi.Bus.TXD.PTR.Set(uint32(uintptr(unsafe.Pointer(&w[0]))))
-			n := uint32(len(w))
-			if n > 255 {
-				n = 255
+			src = unsafe.Pointer(&w[0])
+			if !dmaCanAccess(src) {
+				// EasyDMA can't read from flash, so send a copy.
+				buf := &spiTxCopy[trigger]
+				n = copy(buf[:], w[:n])
+				src = unsafe.Pointer(&buf[0])
 			}
-			spi.Bus.TXD.MAXCNT.Set(n)
 			w = w[n:]
 		}
+		if len(r) != 0 {
+			dst = unsafe.Pointer(&r[0])
+			r = r[n:]
+		}
 
-		// Do the transfer.
-		// Note: this can be improved by not waiting until the transfer is
-		// finished if the transfer is send-only (a common case).
-		spi.Bus.TASKS_START.Set(1)
-		for spi.Bus.EVENTS_END.Get() == 0 {
+		if ch != nil {
+			ch.Start(dst, src, n)
+			ch.Wait()
+		} else {
+			spimStart(spi.Bus, dst, src, uint32(n))
+			for spi.Bus.EVENTS_END.Get() == 0 {
+			}
 		}
-		spi.Bus.EVENTS_END.Set(0)
 	}
 
+	if ch != nil {
+		ch.Release()
+	}
 	return nil
 }
 
+// spiTxCopy holds data from flash that is being sent on a SPI bus, indexed by
+// the DMA trigger of the bus.
+var spiTxCopy [3][32]byte
+
+// dmaTrigger returns the DMA trigger of this SPI bus.
+func (spi SPI) dmaTrigger() DMATrigger {
+	switch spi.Bus {
+	case nrf.SPIM1:
+		return DMATriggerSPIM1
+	case nrf.SPIM2:
+		return DMATriggerSPIM2
+	default:
+		return DMATriggerSPIM0
+	}
+}
+
+// spimStart starts a transfer of count bytes with EasyDMA, sending from src
+// and receiving into dst. Either of them may be nil to only receive or only
+// send. The END event is set when the transfer is done.
+func spimStart(bus *nrf.SPIM_Type, dst, src unsafe.Pointer, count uint32) {
+	if dst != nil {
+		bus.RXD.PTR.Set(uint32(uintptr(dst)))
+		bus.RXD.MAXCNT.Set(count)
+	} else {
+		bus.RXD.MAXCNT.Set(0)
+	}
+	if src != nil {
+		bus.TXD.PTR.Set(uint32(uintptr(src)))
+		bus.TXD.MAXCNT.Set(count)
+	} else {
+		bus.TXD.MAXCNT.Set(0)
+	}
+	bus.EVENTS_END.Set(0)
+	bus.TASKS_START.Set(1)
+}
+
 // PWM is one PWM peripheral, which consists of a counter and multiple output
 // channels (that can be connected to actual pins). You can set the frequency
 // using SetPeriod, but only for all the channels in this PWM peripheral at
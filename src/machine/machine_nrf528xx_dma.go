@@ -0,0 +1,158 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The nRF52 chips have no general purpose DMA controller. Instead, a DMA
+// channel drives the EasyDMA engine of the peripheral selected by its trigger:
+// Start programs the pointer and size registers of that peripheral and starts
+// it, and the END event of the peripheral signals that the transfer is done.
+// EasyDMA always transfers bytes to or from incrementing RAM addresses, so the
+// Width and increment settings of DMAConfig are ignored.
+//
+// Completion interrupts are delivered through PPI, which connects the END
+// event of the peripheral to a task of the EGU0 event generator so that the
+// interrupt handlers of the peripherals themselves are left to their drivers.
+
+const dmaChannelCount = 4
+
+// DMATrigger selects the peripheral whose EasyDMA engine runs a transfer.
+type DMATrigger uint8
+
+const (
+	// The SPIM peripherals send and receive at the same time: Start sends
+	// count bytes from src while storing the received bytes in dst. Either of
+	// them may be nil, in which case the ORC byte is sent or the received
+	// bytes are dropped.
+	DMATriggerSPIM0 DMATrigger = iota
+	DMATriggerSPIM1
+	DMATriggerSPIM2
+
+	// UARTE0 is the UART0 peripheral running in EasyDMA mode. The TX trigger
+	// sends from src, the RX trigger receives into dst. The RX trigger can't
+	// be used while UART0 is configured, as UART0 receives continuously.
+	DMATriggerUARTE0_TX
+	DMATriggerUARTE0_RX
+)
+
+// dmaPPIChannel is the first PPI channel used for completion interrupts. PPI
+// channels 17 and up are reserved by the SoftDevice.
+const dmaPPIChannel = 12
+
+// dmaRunning has a bit set for each channel that has been started and whose
+// END event has not been seen yet.
+var dmaRunning uint32
+
+func dmaInit() {
+	intr := interrupt.New(nrf.IRQ_SWI0_EGU0, func(interrupt.Interrupt) {
+		for id := uint8(0); id < dmaChannelCount; id++ {
+			if nrf.EGU0.EVENTS_TRIGGERED[id].Get() != 0 {
+				nrf.EGU0.EVENTS_TRIGGERED[id].Set(0)
+				dmaComplete(id)
+			}
+		}
+	})
+	intr.SetPriority(0xc0) // low priority
+	intr.Enable()
+}
+
+// dmaCanAccess returns whether EasyDMA can access the memory at p. It can only
+// access data RAM, not flash.
+func dmaCanAccess(p unsafe.Pointer) bool {
+	return uintptr(p)>>29 == 1 // 0x20000000..0x3fffffff
+}
+
+// spim returns the SPIM peripheral of a SPIM trigger.
+func (t DMATrigger) spim() *nrf.SPIM_Type {
+	switch t {
+	case DMATriggerSPIM1:
+		return nrf.SPIM1
+	case DMATriggerSPIM2:
+		return nrf.SPIM2
+	default:
+		return nrf.SPIM0
+	}
+}
+
+// endEvent returns the event register that is set when a transfer on this
+// channel is done.
+func (ch *DMAChannel) endEvent() *volatile.Register32 {
+	switch ch.config.Trigger {
+	case DMATriggerUARTE0_TX:
+		return &nrf.UARTE0.EVENTS_ENDTX
+	case DMATriggerUARTE0_RX:
+		return &nrf.UARTE0.EVENTS_ENDRX
+	default:
+		return &ch.config.Trigger.spim().EVENTS_END
+	}
+}
+
+// The trigger is only used by start, but the completion interrupt must follow
+// the END event of the new peripheral.
+func (ch *DMAChannel) configure() {
+	ch.abort()
+	if ch.callback != nil {
+		ch.setInterrupt(true)
+	}
+}
+
+func (ch *DMAChannel) start(dst, src unsafe.Pointer, count uint32) {
+	dmaRunning |= 1 << ch.id
+	switch ch.config.Trigger {
+	case DMATriggerUARTE0_TX:
+		uarteStartTX(src, count)
+	case DMATriggerUARTE0_RX:
+		nrf.UARTE0.RXD.PTR.Set(uint32(uintptr(dst)))
+		nrf.UARTE0.RXD.MAXCNT.Set(count)
+		nrf.UARTE0.EVENTS_ENDRX.Set(0)
+		nrf.UARTE0.TASKS_STARTRX.Set(1)
+	default:
+		spimStart(ch.config.Trigger.spim(), dst, src, count)
+	}
+}
+
+func (ch *DMAChannel) busy() bool {
+	if dmaRunning&(1<<ch.id) == 0 {
+		return false
+	}
+	if ch.endEvent().Get() == 0 {
+		return true
+	}
+	dmaRunning &^= 1 << ch.id
+	return false
+}
+
+func (ch *DMAChannel) abort() {
+	if dmaRunning&(1<<ch.id) == 0 {
+		return
+	}
+	dmaRunning &^= 1 << ch.id
+	switch ch.config.Trigger {
+	case DMATriggerUARTE0_TX:
+		nrf.UARTE0.TASKS_STOPTX.Set(1)
+	case DMATriggerUARTE0_RX:
+		nrf.UARTE0.TASKS_STOPRX.Set(1)
+	default:
+		ch.config.Trigger.spim().TASKS_STOP.Set(1)
+	}
+}
+
+func (ch *DMAChannel) setInterrupt(enabled bool) {
+	ppi := dmaPPIChannel + uint32(ch.id)
+	if enabled {
+		nrf.PPI.CH[ppi].EEP.Set(uint32(uintptr(unsafe.Pointer(ch.endEvent()))))
+		nrf.PPI.CH[ppi].TEP.Set(uint32(uintptr(unsafe.Pointer(&nrf.EGU0.TASKS_TRIGGER[ch.id]))))
+		nrf.PPI.CHENSET.Set(1 << ppi)
+		nrf.EGU0.INTENSET.Set(1 << ch.id)
+	} else {
+		nrf.PPI.CHENCLR.Set(1 << ppi)
+		nrf.EGU0.INTENCLR.Set(1 << ch.id)
+	}
+}
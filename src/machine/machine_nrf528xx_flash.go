@@ -0,0 +1,46 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The NVMC writes whole 32-bit words and erases pages of 4kB. It can't be used
+// directly while a SoftDevice is enabled: the SoftDevice owns the NVMC then.
+const (
+	flashWriteBlockSize = 4
+	flashEraseBlockSize = 4096
+)
+
+// flashWrite writes data, which must be a multiple of 4 bytes long, to the
+// word aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	nrf.NVMC.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Wen)
+	flashWaitReady()
+	for i := 0; i < len(data); i += 4 {
+		word := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		volatile.StoreUint32((*uint32)(unsafe.Pointer(addr+uintptr(i))), word)
+		flashWaitReady()
+	}
+	nrf.NVMC.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Ren)
+	return nil
+}
+
+// flashErase erases the page at the given address.
+func flashErase(addr uintptr) error {
+	nrf.NVMC.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Een)
+	flashWaitReady()
+	nrf.NVMC.ERASEPAGE.Set(uint32(addr))
+	flashWaitReady()
+	nrf.NVMC.CONFIG.Set(nrf.NVMC_CONFIG_WEN_Ren)
+	return nil
+}
+
+func flashWaitReady() {
+	for nrf.NVMC.READY.Get() == nrf.NVMC_READY_READY_Busy {
+	}
+}
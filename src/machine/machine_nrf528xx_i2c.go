@@ -0,0 +1,174 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// I2C target (slave) operation using the TWIS peripheral, which lives at the
+// same address as the TWI controller. The TWIS transfers data with EasyDMA
+// and holds the bus (using the WRITE_SUSPEND and READ_SUSPEND shortcuts)
+// until a buffer has been provided. Waiting goroutines are woken by the TWIS
+// interrupt.
+
+// The I2C type is placed directly on the peripheral registers, so the state of
+// target mode is kept here, indexed by bus.
+var (
+	twisWake  [2]interruptCond
+	twisReply [2][]byte // copy of a Reply buffer that EasyDMA can't access
+)
+
+// twisEvents are the TWIS events that end a wait in target mode.
+const twisEvents = nrf.TWIS_INTENSET_STOPPED | nrf.TWIS_INTENSET_WRITE | nrf.TWIS_INTENSET_READ
+
+// target returns the registers of the TWIS peripheral of this I2C bus.
+func (i2c *I2C) target() *nrf.TWIS_Type {
+	return (*nrf.TWIS_Type)(unsafe.Pointer(i2c))
+}
+
+// targetIndex returns the index of this bus in the target mode state.
+func (i2c *I2C) targetIndex() int {
+	if i2c == I2C0 {
+		return 0
+	}
+	return 1
+}
+
+// handleTargetInterrupt wakes the goroutine waiting for an event of the TWIS
+// of the given bus. The interrupt is disabled again until the next wait.
+func handleTargetInterrupt(index int) {
+	bus := I2C0.target()
+	if index != 0 {
+		bus = I2C1.target()
+	}
+	bus.INTENCLR.Set(twisEvents)
+	twisWake[index].notify()
+}
+
+// waitTarget blocks until one of the STOPPED, WRITE or READ events is raised.
+// The event that is already set when the interrupt gets enabled raises the
+// interrupt right away, so no event is missed.
+func (i2c *I2C) waitTarget() {
+	bus := i2c.target()
+	for bus.EVENTS_STOPPED.Get() == 0 && bus.EVENTS_WRITE.Get() == 0 && bus.EVENTS_READ.Get() == 0 {
+		bus.INTENSET.Set(twisEvents)
+		twisWake[i2c.targetIndex()].wait()
+	}
+}
+
+// Listen starts responding to transactions for the given 7-bit address. The
+// I2C peripheral must have been configured with Mode set to I2CModeTarget.
+func (i2c *I2C) Listen(addr uint8) error {
+	if i2c.Bus.ENABLE.Get() == nrf.TWI_ENABLE_ENABLE_Enabled {
+		return errI2CWrongMode
+	}
+	bus := i2c.target()
+	bus.ENABLE.Set(nrf.TWIS_ENABLE_ENABLE_Disabled)
+	bus.ADDRESS[0].Set(uint32(addr))
+	bus.CONFIG.Set(nrf.TWIS_CONFIG_ADDRESS0)
+	bus.SHORTS.Set(nrf.TWIS_SHORTS_WRITE_SUSPEND | nrf.TWIS_SHORTS_READ_SUSPEND)
+	bus.ORC.Set(0xff)
+	bus.INTENCLR.Set(twisEvents)
+	bus.ENABLE.Set(nrf.TWIS_ENABLE_ENABLE_Enabled)
+
+	if i2c.targetIndex() == 0 {
+		interrupt.New(nrf.IRQ_SPIM0_SPIS0_TWIM0_TWIS0_SPI0_TWI0, func(interrupt.Interrupt) {
+			handleTargetInterrupt(0)
+		}).Enable()
+	} else {
+		interrupt.New(nrf.IRQ_SPIM1_SPIS1_TWIM1_TWIS1_SPI1_TWI1, func(interrupt.Interrupt) {
+			handleTargetInterrupt(1)
+		}).Enable()
+	}
+	return nil
+}
+
+// WaitForEvent blocks until the controller writes data to this target, asks
+// to read from it or ends a transaction. Other goroutines run while waiting.
+//
+// For I2CReceive events the written bytes are stored in buf and count is the
+// number of bytes stored. Once buf is full further bytes are not
+// acknowledged. For I2CRequest events the response must be sent with Reply
+// before calling WaitForEvent again.
+func (i2c *I2C) WaitForEvent(buf []byte) (evt I2CTargetEvent, count int, err error) {
+	bus := i2c.target()
+	if bus.ENABLE.Get() != nrf.TWIS_ENABLE_ENABLE_Enabled {
+		return 0, 0, errI2CWrongMode
+	}
+	for {
+		if bus.EVENTS_STOPPED.Get() != 0 {
+			bus.EVENTS_STOPPED.Set(0)
+			bus.EVENTS_ERROR.Set(0)
+			bus.ERRORSRC.Set(bus.ERRORSRC.Get()) // write 1 to clear
+			return I2CFinish, 0, nil
+		}
+
+		if bus.EVENTS_WRITE.Get() != 0 {
+			bus.EVENTS_WRITE.Set(0)
+			setTargetBuffer(&bus.RXD.PTR, &bus.RXD.MAXCNT, buf)
+			bus.TASKS_PREPARERX.Set(1)
+			bus.TASKS_RESUME.Set(1)
+
+			// The write ends with a stop or a repeated start. Leave the
+			// event set so it is reported by the next call.
+			i2c.waitTarget()
+			return I2CReceive, int(bus.RXD.AMOUNT.Get()), nil
+		}
+
+		// Read request: the event is cleared by Reply.
+		if bus.EVENTS_READ.Get() != 0 {
+			return I2CRequest, 0, nil
+		}
+
+		i2c.waitTarget()
+	}
+}
+
+// Reply sends buf in response to an I2CRequest event. If the controller reads
+// fewer bytes than buf holds the rest is discarded, bytes read past the end of
+// buf are sent as 0xff. A buf that is not in RAM (such as a constant string
+// converted to a byte slice) is copied first, as EasyDMA can only read RAM.
+func (i2c *I2C) Reply(buf []byte) error {
+	bus := i2c.target()
+	if bus.ENABLE.Get() != nrf.TWIS_ENABLE_ENABLE_Enabled {
+		return errI2CWrongMode
+	}
+	if bus.EVENTS_READ.Get() == 0 {
+		return errI2CNoRequest
+	}
+	index := i2c.targetIndex()
+	if len(buf) != 0 && !dmaCanAccess(unsafe.Pointer(&buf[0])) {
+		if len(buf) > 255 {
+			buf = buf[:255] // setTargetBuffer sends no more
+		}
+		twisReply[index] = append(twisReply[index][:0], buf...)
+		buf = twisReply[index]
+	}
+	bus.EVENTS_READ.Set(0)
+	setTargetBuffer(&bus.TXD.PTR, &bus.TXD.MAXCNT, buf)
+	bus.TASKS_PREPARETX.Set(1)
+	bus.TASKS_RESUME.Set(1)
+
+	// EasyDMA reads from buf until the controller is done reading.
+	i2c.waitTarget()
+	return nil
+}
+
+// setTargetBuffer sets the EasyDMA pointer and size registers for buf. As
+// with SPI, only the first 255 bytes are used to stay within the limits of
+// the nrf52832.
+func setTargetBuffer(ptr, maxcnt *volatile.Register32, buf []byte) {
+	n := uint32(len(buf))
+	if n > 255 {
+		n = 255
+	}
+	if n != 0 {
+		ptr.Set(uint32(uintptr(unsafe.Pointer(&buf[0]))))
+	}
+	maxcnt.Set(n)
+}
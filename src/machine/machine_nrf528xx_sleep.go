@@ -0,0 +1,37 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/arm"
+	"device/nrf"
+)
+
+// The wake pins of the nRF52 can reset the chip from System OFF.
+const hasSystemOff = true
+
+// systemOff enables the sense mechanism of the wake pins and enters System OFF,
+// from which the chip resets when one of the pins gets to its level.
+func systemOff(pins []WakePin) {
+	for _, w := range pins {
+		sense := uint32(nrf.GPIO_PIN_CNF_SENSE_Low)
+		if w.High {
+			sense = nrf.GPIO_PIN_CNF_SENSE_High
+		}
+		port, pin := w.Pin.getPortPin()
+		port.PIN_CNF[pin].ReplaceBits(sense<<nrf.GPIO_PIN_CNF_SENSE_Pos, nrf.GPIO_PIN_CNF_SENSE_Msk, 0)
+	}
+	nrf.POWER.SYSTEMOFF.Set(nrf.POWER_SYSTEMOFF_SYSTEMOFF_Enter)
+
+	// System OFF is only emulated while a debugger is attached, in which case
+	// the CPU continues to run.
+	for {
+		arm.Asm("wfe")
+	}
+}
+
+// The GPIOTE, which detects the wake pins, keeps running in System ON.
+func enterDeepSleep() {}
+
+func exitDeepSleep() {}
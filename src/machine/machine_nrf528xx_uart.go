@@ -0,0 +1,142 @@
+//go:build nrf52 || nrf52840 || nrf52833
+// +build nrf52 nrf52840 nrf52833
+
+package machine
+
+import (
+	"device/nrf"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// On the nRF52, UART0 runs in EasyDMA mode (UARTE0). Large writes are sent by
+// the peripheral from a DMA channel while other goroutines run. EasyDMA can
+// only access RAM, so single bytes go through small buffers here:
+//   * Received bytes are stored in uarteRX. The ENDRX_STARTRX shortcut restarts
+//     reception after every byte, alternating between the two buffers so that
+//     the interrupt handler can read one while the next byte is received into
+//     the other.
+//   * Bytes written one at a time are copied to uarteTX first.
+
+var (
+	uarteRX      [2]volatile.Register8
+	uarteRXIndex uint8 // the buffer in uarteRX that the current byte goes to
+	uarteTX      volatile.Register8
+)
+
+// start enables the UART in EasyDMA mode and starts receiving, with
+// interrupts for received bytes and (if there is a TX buffer) for sent bytes.
+func (uart *UART) start() {
+	nrf.UARTE0.ENABLE.Set(nrf.UARTE_ENABLE_ENABLE_Enabled)
+
+	uarteRXIndex = 0
+	nrf.UARTE0.RXD.PTR.Set(uint32(uintptr(unsafe.Pointer(&uarteRX[0]))))
+	nrf.UARTE0.RXD.MAXCNT.Set(1)
+	nrf.UARTE0.SHORTS.Set(nrf.UARTE_SHORTS_ENDRX_STARTRX_Msk)
+	nrf.UARTE0.INTENSET.Set(nrf.UARTE_INTENSET_RXSTARTED_Msk | nrf.UARTE_INTENSET_ENDRX_Msk)
+	nrf.UARTE0.TASKS_STARTRX.Set(1)
+
+	if uart.TXBuffer != nil {
+		nrf.UARTE0.INTENSET.Set(nrf.UARTE_INTENSET_ENDTX_Msk)
+	} else {
+		nrf.UARTE0.INTENCLR.Set(nrf.UARTE_INTENCLR_ENDTX_Msk)
+	}
+}
+
+// WriteByte writes a byte of data to the UART.
+func (uart *UART) WriteByte(c byte) error {
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
+	uarteTX.Set(c)
+	uarteStartTX(unsafe.Pointer(&uarteTX.Reg), 1)
+	for nrf.UARTE0.EVENTS_ENDTX.Get() == 0 {
+	}
+	return nil
+}
+
+// writeDMA sends large buffers from a DMA channel, so that other goroutines
+// can run in the meantime. It returns false if the data must be written byte
+// by byte instead: with a TX buffer (to keep the data in order) and for data
+// in flash, which EasyDMA can't read.
+func (uart *UART) writeDMA(data []byte) bool {
+	if uart.TXBuffer != nil || len(data) < dmaMinTransfer || !dmaCanAccess(unsafe.Pointer(&data[0])) {
+		return false
+	}
+	ch, err := AllocateDMAChannel()
+	if err != nil {
+		return false
+	}
+	ch.Configure(DMAConfig{Trigger: DMATriggerUARTE0_TX})
+	for len(data) != 0 {
+		n := len(data)
+		if n > dmaMaxTransferCount {
+			n = dmaMaxTransferCount
+		}
+		ch.Start(nil, unsafe.Pointer(&data[0]), n)
+		ch.Wait()
+		data = data[n:]
+	}
+	ch.Release()
+	return true
+}
+
+func (uart *UART) handleInterrupt(interrupt.Interrupt) {
+	if nrf.UARTE0.EVENTS_ENDRX.Get() != 0 {
+		nrf.UARTE0.EVENTS_ENDRX.Set(0)
+		c := uarteRX[uarteRXIndex].Get()
+		uarteRXIndex ^= 1
+		uart.Receive(c)
+	}
+
+	if nrf.UARTE0.EVENTS_RXSTARTED.Get() != 0 {
+		nrf.UARTE0.EVENTS_RXSTARTED.Set(0)
+		// The pointer register is double buffered: the new value is used when
+		// the shortcut starts receiving the next byte.
+		nrf.UARTE0.RXD.PTR.Set(uint32(uintptr(unsafe.Pointer(&uarteRX[uarteRXIndex^1]))))
+	}
+
+	// The ENDTX event is also set by synchronous writes, so only handle it
+	// when the interrupt is enabled.
+	if nrf.UARTE0.EVENTS_ENDTX.Get() != 0 && nrf.UARTE0.INTENSET.HasBits(nrf.UARTE_INTENSET_ENDTX_Msk) {
+		nrf.UARTE0.EVENTS_ENDTX.Set(0)
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uarteTX.Set(c)
+			uarteStartTX(unsafe.Pointer(&uarteTX.Reg), 1)
+		} else {
+			uart.txActive.Set(0)
+		}
+	}
+}
+
+// startTX sends the first byte from TXBuffer if no bytes are being sent. The
+// ENDTX interrupt then sends the rest of the buffer.
+func (uart *UART) startTX() {
+	mask := interrupt.Disable()
+	if uart.txActive.Get() == 0 {
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uart.txActive.Set(1)
+			uarteTX.Set(c)
+			uarteStartTX(unsafe.Pointer(&uarteTX.Reg), 1)
+		}
+	}
+	interrupt.Restore(mask)
+}
+
+// txComplete returns whether the last byte written has been sent. The ENDTX
+// event is generated once EasyDMA has read the last byte, which may still be
+// in the transmitter.
+func (uart *UART) txComplete() bool {
+	return uart.txActive.Get() == 0
+}
+
+// uarteStartTX starts sending count bytes from RAM at src.
+func uarteStartTX(src unsafe.Pointer, count uint32) {
+	nrf.UARTE0.TXD.PTR.Set(uint32(uintptr(src)))
+	nrf.UARTE0.TXD.MAXCNT.Set(count)
+	nrf.UARTE0.EVENTS_ENDTX.Set(0)
+	nrf.UARTE0.TASKS_STARTTX.Set(1)
+}
@@ -0,0 +1,28 @@
+//go:build nrf
+// +build nrf
+
+package machine
+
+import "device/nrf"
+
+var rngInitDone = false
+
+// GetRNG returns 32 bits of random data from the RNG peripheral. Note that the
+// RNG peripheral is restricted while the SoftDevice is enabled.
+func GetRNG() (uint32, error) {
+	if !rngInitDone {
+		// Enable bias correction, for a uniform distribution of 0 and 1 bits.
+		nrf.RNG.CONFIG.Set(nrf.RNG_CONFIG_DERCEN_Enabled << nrf.RNG_CONFIG_DERCEN_Pos)
+		nrf.RNG.TASKS_START.Set(1)
+		rngInitDone = true
+	}
+
+	var ret uint32
+	for i := 0; i < 4; i++ {
+		for nrf.RNG.EVENTS_VALRDY.Get() == 0 {
+		}
+		nrf.RNG.EVENTS_VALRDY.Set(0)
+		ret = ret<<8 | nrf.RNG.VALUE.Get()
+	}
+	return ret, nil
+}
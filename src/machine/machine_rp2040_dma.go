@@ -0,0 +1,139 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"device/rp"
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+const dmaChannelCount = 12
+
+// TRANS_COUNT is a 32-bit register.
+const dmaMaxTransferCount = 0xffffffff
+
+// dmaCanAccess returns whether the DMA can access the memory at p, which is
+// true for both flash (through XIP) and RAM.
+func dmaCanAccess(p unsafe.Pointer) bool {
+	return true
+}
+
+// DMATrigger selects the data request (DREQ) that paces a DMA transfer.
+type DMATrigger uint8
+
+// DMA triggers (TREQ_SEL values) of the SPI and UART peripherals.
+const (
+	DMATriggerSPI0_TX  DMATrigger = 16
+	DMATriggerSPI0_RX  DMATrigger = 17
+	DMATriggerSPI1_TX  DMATrigger = 18
+	DMATriggerSPI1_RX  DMATrigger = 19
+	DMATriggerUART0_TX DMATrigger = 20
+	DMATriggerUART0_RX DMATrigger = 21
+	DMATriggerUART1_TX DMATrigger = 22
+	DMATriggerUART1_RX DMATrigger = 23
+
+	// DMATriggerSoftware runs the transfer unpaced, as fast as possible.
+	DMATriggerSoftware DMATrigger = 0x3f
+)
+
+type dmaChannelType struct {
+	readAddr   volatile.Register32
+	writeAddr  volatile.Register32
+	transCount volatile.Register32
+	ctrlTrig   volatile.Register32
+	_          [12]volatile.Register32 // alias registers
+}
+
+type dmaType struct {
+	channels         [dmaChannelCount]dmaChannelType
+	_                [64]volatile.Register32
+	intR             volatile.Register32
+	intE0            volatile.Register32
+	intF0            volatile.Register32
+	intS0            volatile.Register32
+	_                volatile.Register32
+	intE1            volatile.Register32
+	intF1            volatile.Register32
+	intS1            volatile.Register32
+	timer            [4]volatile.Register32
+	multiChanTrigger volatile.Register32
+	sniffCtrl        volatile.Register32
+	sniffData        volatile.Register32
+	_                volatile.Register32
+	fifoLevels       volatile.Register32
+	chanAbort        volatile.Register32
+}
+
+var dma = (*dmaType)(unsafe.Pointer(rp.DMA))
+
+const (
+	dmaCTRL_EN            = 1 << 0
+	dmaCTRL_DATA_SIZE_Pos = 2
+	dmaCTRL_INCR_READ     = 1 << 4
+	dmaCTRL_INCR_WRITE    = 1 << 5
+	dmaCTRL_CHAIN_TO_Pos  = 11
+	dmaCTRL_TREQ_SEL_Pos  = 15
+	dmaCTRL_BUSY          = 1 << 24
+)
+
+func dmaInit() {
+	// The DMA block is taken out of reset in machineInit, only the
+	// interrupt needs to be set up.
+	interrupt.New(rp.IRQ_DMA_IRQ_0, dmaHandleInterrupt).Enable()
+	irqSet(rp.IRQ_DMA_IRQ_0, true)
+}
+
+// The control word is only written (and the channel triggered) by start.
+func (ch *DMAChannel) configure() {
+	ch.abort()
+}
+
+func (ch *DMAChannel) start(dst, src unsafe.Pointer, count uint32) {
+	// Chaining a channel to itself disables chaining.
+	ctrl := uint32(dmaCTRL_EN) |
+		uint32(ch.config.Width)<<dmaCTRL_DATA_SIZE_Pos |
+		uint32(ch.id)<<dmaCTRL_CHAIN_TO_Pos |
+		uint32(ch.config.Trigger)<<dmaCTRL_TREQ_SEL_Pos
+	if ch.config.SrcIncrement {
+		ctrl |= dmaCTRL_INCR_READ
+	}
+	if ch.config.DstIncrement {
+		ctrl |= dmaCTRL_INCR_WRITE
+	}
+	regs := &dma.channels[ch.id]
+	regs.readAddr.Set(uint32(uintptr(src)))
+	regs.writeAddr.Set(uint32(uintptr(dst)))
+	regs.transCount.Set(count)
+	regs.ctrlTrig.Set(ctrl)
+}
+
+func (ch *DMAChannel) busy() bool {
+	return dma.channels[ch.id].ctrlTrig.HasBits(dmaCTRL_BUSY)
+}
+
+func (ch *DMAChannel) abort() {
+	dma.chanAbort.Set(1 << ch.id)
+	for dma.chanAbort.HasBits(1 << ch.id) {
+	}
+}
+
+func (ch *DMAChannel) setInterrupt(enabled bool) {
+	if enabled {
+		dma.intE0.SetBits(1 << ch.id)
+	} else {
+		dma.intE0.ClearBits(1 << ch.id)
+	}
+}
+
+func dmaHandleInterrupt(interrupt.Interrupt) {
+	status := dma.intS0.Get()
+	dma.intS0.Set(status) // write 1 to clear
+	for i := range dmaChannels {
+		if status&(1<<i) != 0 {
+			dmaComplete(uint8(i))
+		}
+	}
+}
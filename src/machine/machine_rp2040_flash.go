@@ -0,0 +1,114 @@
+//go:build rp2040
+// +build rp2040
+
+package machine
+
+import (
+	"runtime/interrupt"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The external flash is programmed in pages of 256 bytes and erased in sectors
+// of 4kB, using the flash functions in the bootrom.
+const (
+	flashWriteBlockSize = 256
+	flashEraseBlockSize = 4096
+
+	xipBase = 0x10000000
+
+	flashSectorEraseCmd = 0x20
+)
+
+// romFlashOp is passed to tinygo_rp2040_flashOp, which runs a flash operation
+// from RAM. The layout must match the offsets in machine_rp2040_flash.S.
+type romFlashOp struct {
+	connect  uintptr // connect_internal_flash
+	exitXIP  uintptr // flash_exit_xip
+	op       uintptr // flash_range_erase or flash_range_program
+	flush    uintptr // flash_flush_cache
+	enterXIP uintptr
+	args     [4]uint32
+}
+
+//export tinygo_rp2040_flashOp
+func romFlashCall(op *romFlashOp)
+
+// boot2Copy is a copy in RAM of the second stage bootloader, which is called to
+// restore the fast XIP mode after a flash operation. The bootrom only provides
+// a slow generic XIP mode.
+var (
+	boot2Copy   [64]uint32
+	boot2Copied bool
+)
+
+// flashWrite writes data, which must be a multiple of 256 bytes long, to the
+// page aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	// The data may itself be stored in flash, which can't be read during the
+	// operation. Therefore program one page at a time from a copy in RAM.
+	var page [flashWriteBlockSize]byte
+	for i := 0; i < len(data); i += flashWriteBlockSize {
+		copy(page[:], data[i:])
+		op := newROMFlashOp('R', 'P')
+		op.args[0] = uint32(addr + uintptr(i) - xipBase)
+		op.args[1] = uint32(uintptr(unsafe.Pointer(&page)))
+		op.args[2] = flashWriteBlockSize
+		runROMFlashOp(&op)
+	}
+	return nil
+}
+
+// flashErase erases the sector at the given address.
+func flashErase(addr uintptr) error {
+	op := newROMFlashOp('R', 'E')
+	op.args[0] = uint32(addr - xipBase)
+	op.args[1] = flashEraseBlockSize
+	op.args[2] = flashEraseBlockSize
+	op.args[3] = flashSectorEraseCmd
+	runROMFlashOp(&op)
+	return nil
+}
+
+// newROMFlashOp returns the functions needed to run the bootrom flash function
+// with the given code.
+func newROMFlashOp(c1, c2 byte) romFlashOp {
+	if !boot2Copied {
+		for i := range boot2Copy {
+			boot2Copy[i] = volatile.LoadUint32((*uint32)(unsafe.Pointer(uintptr(xipBase + i*4))))
+		}
+		boot2Copied = true
+	}
+	return romFlashOp{
+		connect:  romFunc('I', 'F'),
+		exitXIP:  romFunc('E', 'X'),
+		op:       romFunc(c1, c2),
+		flush:    romFunc('F', 'C'),
+		enterXIP: uintptr(unsafe.Pointer(&boot2Copy)) | 1, // Thumb code
+	}
+}
+
+// runROMFlashOp runs the flash operation with interrupts disabled, as the
+// interrupt handlers are in flash.
+func runROMFlashOp(op *romFlashOp) {
+	mask := interrupt.Disable()
+	romFlashCall(op)
+	interrupt.Restore(mask)
+}
+
+// romFunc looks up a function in the bootrom function table by its two
+// character code.
+func romFunc(c1, c2 byte) uintptr {
+	code := uint16(c1) | uint16(c2)<<8
+	table := uintptr(volatile.LoadUint16((*uint16)(unsafe.Pointer(uintptr(0x14)))))
+	for {
+		entry := volatile.LoadUint16((*uint16)(unsafe.Pointer(table)))
+		if entry == 0 {
+			return 0
+		}
+		if entry == code {
+			return uintptr(volatile.LoadUint16((*uint16)(unsafe.Pointer(table + 2))))
+		}
+		table += 4
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"device/rp"
 	"errors"
 	"internal/itoa"
+	"runtime/interrupt"
 )
 
 // I2C on the RP2040.
@@ -46,11 +47,15 @@ type I2CConfig struct {
 	// SDA/SCL Serial Data and clock pins. Refer to datasheet to see
 	// which pins match the desired bus.
 	SDA, SCL Pin
+	// Mode selects controller (default) or target operation.
+	Mode I2CMode
 }
 
 type I2C struct {
 	Bus           *rp.I2C0_Type
 	restartOnNext bool
+	mode          I2CMode
+	targetWake    interruptCond
 }
 
 var (
@@ -72,6 +77,9 @@ var (
 //  i2c.Tx(addr, w, nil)
 // Performs only a write transfer.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
+	if i2c.mode != I2CModeController {
+		return errI2CWrongMode
+	}
 	// timeout in microseconds.
 	const timeout = 40 * 1000 // 40ms is a reasonable time for a real-time system.
 	if len(w) > 0 {
@@ -200,10 +208,20 @@ func (i2c *I2C) init(config I2CConfig) error {
 		return err
 	}
 	i2c.restartOnNext = false
-	// Configure as a fast-mode master with RepStart support, 7-bit addresses
-	i2c.Bus.IC_CON.Set((rp.I2C0_IC_CON_SPEED_FAST << rp.I2C0_IC_CON_SPEED_Pos) |
-		rp.I2C0_IC_CON_MASTER_MODE | rp.I2C0_IC_CON_IC_SLAVE_DISABLE |
-		rp.I2C0_IC_CON_IC_RESTART_EN | rp.I2C0_IC_CON_TX_EMPTY_CTRL) // sets TX_EMPTY_CTRL to enable TX_EMPTY interrupt status
+	i2c.mode = config.Mode
+	if config.Mode == I2CModeTarget {
+		// Configure as a target with 7-bit addresses. Only report stop
+		// conditions of transactions addressed to us, and stretch the clock
+		// instead of dropping bytes when the RX FIFO is full.
+		i2c.Bus.IC_CON.Set((rp.I2C0_IC_CON_SPEED_FAST << rp.I2C0_IC_CON_SPEED_Pos) |
+			rp.I2C0_IC_CON_IC_RESTART_EN | rp.I2C0_IC_CON_TX_EMPTY_CTRL |
+			rp.I2C0_IC_CON_STOP_DET_IFADDRESSED | rp.I2C0_IC_CON_RX_FIFO_FULL_HLD_CTRL)
+	} else {
+		// Configure as a fast-mode master with RepStart support, 7-bit addresses
+		i2c.Bus.IC_CON.Set((rp.I2C0_IC_CON_SPEED_FAST << rp.I2C0_IC_CON_SPEED_Pos) |
+			rp.I2C0_IC_CON_MASTER_MODE | rp.I2C0_IC_CON_IC_SLAVE_DISABLE |
+			rp.I2C0_IC_CON_IC_RESTART_EN | rp.I2C0_IC_CON_TX_EMPTY_CTRL) // sets TX_EMPTY_CTRL to enable TX_EMPTY interrupt status
+	}
 
 	// Set FIFO watermarks to 1 to make things simpler. This is encoded by a register value of 0.
 	i2c.Bus.IC_TX_TL.Set(0)
@@ -402,6 +420,150 @@ func (i2c *I2C) rx(addr uint8, rx []byte, nostop bool, timeout uint64) (err erro
 	return err
 }
 
+// Listen starts responding to transactions for the given 7-bit address. The
+// I2C peripheral must have been configured with Mode set to I2CModeTarget.
+func (i2c *I2C) Listen(addr uint8) error {
+	if i2c.mode != I2CModeTarget {
+		return errI2CWrongMode
+	}
+	if addr >= 0x80 || isReservedI2CAddr(addr) {
+		return ErrInvalidTgtAddr
+	}
+	if err := i2c.disable(); err != nil {
+		return err
+	}
+	i2c.Bus.IC_SAR.Set(uint32(addr))
+	i2c.Bus.IC_INTR_MASK.Set(0)
+	i2c.enable()
+
+	switch i2c.Bus {
+	case rp.I2C0:
+		interrupt.New(rp.IRQ_I2C0_IRQ, _I2C0.handleTargetInterrupt).Enable()
+	case rp.I2C1:
+		interrupt.New(rp.IRQ_I2C1_IRQ, _I2C1.handleTargetInterrupt).Enable()
+	}
+	return nil
+}
+
+// handleTargetInterrupt wakes the goroutine waiting in target mode. All
+// interrupts are masked again until the next wait.
+func (i2c *I2C) handleTargetInterrupt(interrupt.Interrupt) {
+	i2c.Bus.IC_INTR_MASK.Set(0)
+	i2c.targetWake.notify()
+}
+
+// waitTarget blocks until one of the interrupts in mask is raised. A
+// condition that is already present when it gets unmasked raises the
+// interrupt right away, so it isn't missed.
+func (i2c *I2C) waitTarget(mask uint32) {
+	i2c.Bus.IC_INTR_MASK.Set(mask)
+	i2c.targetWake.wait()
+}
+
+// WaitForEvent blocks until the controller writes data to this target, asks
+// to read from it or ends a transaction. Other goroutines run while waiting.
+//
+// For I2CReceive events the written bytes are stored in buf and count is the
+// number of bytes stored. Bytes that do not fit in buf are dropped. For
+// I2CRequest events the response must be sent with Reply before calling
+// WaitForEvent again.
+func (i2c *I2C) WaitForEvent(buf []byte) (evt I2CTargetEvent, count int, err error) {
+	if i2c.mode != I2CModeTarget {
+		return 0, 0, errI2CWrongMode
+	}
+	for {
+		for i2c.readAvailable() != 0 {
+			b := uint8(i2c.Bus.IC_DATA_CMD.Get())
+			if count < len(buf) {
+				buf[count] = b
+				count++
+			}
+		}
+
+		stat := i2c.Bus.IC_RAW_INTR_STAT.Get()
+
+		// A stop ends the transaction. Report received data first and
+		// leave the flag set so the next call reports the finish.
+		if stat&rp.I2C0_IC_RAW_INTR_STAT_STOP_DET != 0 {
+			if count > 0 {
+				return I2CReceive, count, nil
+			}
+			i2c.Bus.IC_CLR_STOP_DET.Get()
+			return I2CFinish, 0, nil
+		}
+
+		// A repeated start ends a write message.
+		if stat&rp.I2C0_IC_RAW_INTR_STAT_START_DET != 0 {
+			i2c.Bus.IC_CLR_START_DET.Get()
+			if count > 0 {
+				return I2CReceive, count, nil
+			}
+		}
+
+		// Read request: the flag is cleared by Reply.
+		if stat&rp.I2C0_IC_RAW_INTR_STAT_RD_REQ != 0 {
+			if count > 0 {
+				return I2CReceive, count, nil
+			}
+			return I2CRequest, 0, nil
+		}
+
+		i2c.waitTarget(rp.I2C0_IC_INTR_MASK_M_RX_FULL | rp.I2C0_IC_INTR_MASK_M_STOP_DET |
+			rp.I2C0_IC_INTR_MASK_M_START_DET | rp.I2C0_IC_INTR_MASK_M_RD_REQ)
+	}
+}
+
+// Reply sends buf in response to an I2CRequest event. If the controller reads
+// fewer bytes than buf holds the rest is discarded, bytes read past the end of
+// buf are sent as 0xff.
+func (i2c *I2C) Reply(buf []byte) error {
+	if i2c.mode != I2CModeTarget {
+		return errI2CWrongMode
+	}
+	stat := i2c.Bus.IC_RAW_INTR_STAT.Get()
+	if stat&rp.I2C0_IC_RAW_INTR_STAT_RD_REQ == 0 {
+		return errI2CNoRequest
+	}
+	// Clear a TX abort left over from a previous reply before releasing
+	// the bus, otherwise the TX FIFO stays flushed.
+	if stat&rp.I2C0_IC_RAW_INTR_STAT_TX_ABRT != 0 {
+		i2c.clearAbortReason()
+	}
+	i2c.Bus.IC_CLR_RD_REQ.Get()
+
+	sent := 0
+	for {
+		stat = i2c.Bus.IC_RAW_INTR_STAT.Get()
+		// An abort is the normal way for the controller to stop reading
+		// before all data was sent.
+		if stat&rp.I2C0_IC_RAW_INTR_STAT_TX_ABRT != 0 {
+			i2c.clearAbortReason()
+			return nil
+		}
+		// The read ends with a stop or a repeated start, which are left for
+		// WaitForEvent to report.
+		if stat&(rp.I2C0_IC_RAW_INTR_STAT_STOP_DET|rp.I2C0_IC_RAW_INTR_STAT_START_DET) != 0 {
+			return nil
+		}
+		mask := uint32(rp.I2C0_IC_INTR_MASK_M_TX_ABRT | rp.I2C0_IC_INTR_MASK_M_STOP_DET | rp.I2C0_IC_INTR_MASK_M_START_DET)
+		if sent < len(buf) {
+			if i2c.writeAvailable() != 0 {
+				i2c.Bus.IC_DATA_CMD.Set(uint32(buf[sent]))
+				sent++
+				continue
+			}
+			mask |= rp.I2C0_IC_INTR_MASK_M_TX_EMPTY
+		} else if stat&rp.I2C0_IC_RAW_INTR_STAT_RD_REQ != 0 {
+			i2c.Bus.IC_CLR_RD_REQ.Get()
+			i2c.Bus.IC_DATA_CMD.Set(0xff)
+			continue
+		} else {
+			mask |= rp.I2C0_IC_INTR_MASK_M_RD_REQ
+		}
+		i2c.waitTarget(mask)
+	}
+}
+
 // writeAvailable determines non-blocking write space available
 //go:inline
 func (i2c *I2C) writeAvailable() uint32 {
@@ -6,6 +6,7 @@ package machine
 import (
 	"device/rp"
 	"errors"
+	"unsafe"
 )
 
 // SPI on the RP2040
@@ -278,13 +279,18 @@ func (spi SPI) tx(tx []byte) error {
 	// Write to TX FIFO whilst ignoring RX, then clean up afterward. When RX
 	// is full, PL022 inhibits RX pushes, and sets a sticky flag on
 	// push-on-full, but continues shifting. Safe if SSPIMSC_RORIM is not set.
-	for i := range tx {
-		for !spi.isWritable() {
-			if ticks() > deadline {
-				return ErrSPITimeout
+	// Large buffers are fed to the TX FIFO by a DMA channel instead.
+	if len(tx) >= dmaMinTransfer && dmaWrite(unsafe.Pointer(&spi.Bus.SSPDR.Reg), spi.dmaTriggerTX(), tx) {
+		deadline = ticks() + _SPITimeout
+	} else {
+		for i := range tx {
+			for !spi.isWritable() {
+				if ticks() > deadline {
+					return ErrSPITimeout
+				}
 			}
+			spi.Bus.SSPDR.Set(uint32(tx[i]))
 		}
-		spi.Bus.SSPDR.Set(uint32(tx[i]))
 	}
 	// Drain RX FIFO, then wait for shifting to finish (which may be *after*
 	// TX FIFO drains), then drain RX FIFO again
@@ -304,6 +310,14 @@ func (spi SPI) tx(tx []byte) error {
 	return nil
 }
 
+// dmaTriggerTX returns the DMA request of the TX FIFO of this SPI.
+func (spi SPI) dmaTriggerTX() DMATrigger {
+	if spi.Bus == rp.SPI1 {
+		return DMATriggerSPI1_TX
+	}
+	return DMATriggerSPI0_TX
+}
+
 // rx reads buffer to SPI ignoring x.
 // txrepeat is output repeatedly on SO as data is read in from SI.
 // Generally this can be 0, but some devices require a specific value here,
@@ -6,17 +6,21 @@ package machine
 import (
 	"device/rp"
 	"runtime/interrupt"
+	"unsafe"
 )
 
 // UART on the RP2040.
 type UART struct {
 	Buffer    *RingBuffer
+	TXBuffer  *RingBuffer
 	Bus       *rp.UART0_Type
 	Interrupt interrupt.Interrupt
 }
 
 // Configure the UART.
 func (uart *UART) Configure(config UARTConfig) error {
+	uart.setBuffers(config)
+
 	initUART(uart)
 
 	// Default baud rate to 115200.
@@ -41,6 +45,9 @@ func (uart *UART) Configure(config UARTConfig) error {
 		rp.UART0_UARTCR_RXE |
 		rp.UART0_UARTCR_TXE)
 
+	// Enable the TX DREQ signal, used by Write for large buffers.
+	uart.Bus.UARTDMACR.SetBits(rp.UART0_UARTDMACR_TXDMAE)
+
 	// set GPIO mux to UART for the pins
 	config.TX.Configure(PinConfig{Mode: PinUART})
 	config.RX.Configure(PinConfig{Mode: PinUART})
@@ -84,6 +91,11 @@ func (uart *UART) SetBaudRate(br uint32) {
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
 	// wait until buffer is not full
 	for uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_TXFF) {
 	}
@@ -93,6 +105,20 @@ func (uart *UART) WriteByte(c byte) error {
 	return nil
 }
 
+// writeDMA writes large buffers to the TX FIFO using a DMA channel. It
+// returns false if the data must be written byte by byte instead, which is
+// always the case with a TX buffer so that the data stays in order.
+func (uart *UART) writeDMA(data []byte) bool {
+	if uart.TXBuffer != nil || len(data) < dmaMinTransfer {
+		return false
+	}
+	trigger := DMATriggerUART0_TX
+	if uart.Bus == rp.UART1 {
+		trigger = DMATriggerUART1_TX
+	}
+	return dmaWrite(unsafe.Pointer(&uart.Bus.UARTDR.Reg), trigger, data)
+}
+
 // SetFormat for number of data bits, stop bits, and parity for the UART.
 func (uart *UART) SetFormat(databits, stopbits uint8, parity UARTParity) error {
 	var pen, pev uint8
@@ -128,7 +154,40 @@ func initUART(uart *UART) {
 // handleInterrupt should be called from the appropriate interrupt handler for
 // this UART instance.
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	for uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_RXFE) {
+	for !uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_RXFE) {
+		uart.Receive(byte((uart.Bus.UARTDR.Get() & 0xFF)))
 	}
-	uart.Receive(byte((uart.Bus.UARTDR.Get() & 0xFF)))
+
+	if uart.Bus.UARTMIS.HasBits(rp.UART0_UARTMIS_TXMIS) {
+		uart.Bus.UARTICR.Set(rp.UART0_UARTICR_TXIC)
+		uart.fillTX()
+	}
+}
+
+// startTX enables the TX interrupt, which sends the bytes in TXBuffer. The
+// PL011 only raises the TX interrupt when the FIFO level drops, so the first
+// bytes are written here.
+func (uart *UART) startTX() {
+	mask := interrupt.Disable()
+	uart.Bus.UARTIMSC.SetBits(rp.UART0_UARTIMSC_TXIM)
+	uart.fillTX()
+	interrupt.Restore(mask)
+}
+
+// fillTX moves bytes from TXBuffer to the TX FIFO until the FIFO is full. The
+// TX interrupt is disabled once TXBuffer is empty.
+func (uart *UART) fillTX() {
+	for !uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_TXFF) {
+		c, ok := uart.TXBuffer.Get()
+		if !ok {
+			uart.Bus.UARTIMSC.ClearBits(rp.UART0_UARTIMSC_TXIM)
+			return
+		}
+		uart.Bus.UARTDR.Set(uint32(c))
+	}
+}
+
+// txComplete returns whether the last byte written has been sent.
+func (uart *UART) txComplete() bool {
+	return !uart.Bus.UARTFR.HasBits(rp.UART0_UARTFR_BUSY)
 }
@@ -0,0 +1,84 @@
+//go:build sam
+// +build sam
+
+package machine
+
+import (
+	"runtime/volatile"
+	"unsafe"
+)
+
+// DMATrigger selects the peripheral request (TRIGSRC) that paces a DMA
+// transfer.
+type DMATrigger uint8
+
+// DMATriggerSoftware starts the whole transfer at once, without waiting for a
+// peripheral.
+const DMATriggerSoftware DMATrigger = 0
+
+// The block transfer count (BTCNT) is a 16-bit field.
+const dmaMaxTransferCount = 0xffff
+
+// dmaCanAccess returns whether the DMAC can access the memory at p, which is
+// true for both flash and RAM.
+func dmaCanAccess(p unsafe.Pointer) bool {
+	return true
+}
+
+// Bits of the BTCTRL field of a transfer descriptor.
+const (
+	dmaBTCTRL_VALID        = 1 << 0
+	dmaBTCTRL_BEATSIZE_Pos = 8
+	dmaBTCTRL_SRCINC       = 1 << 10
+	dmaBTCTRL_DSTINC       = 1 << 11
+)
+
+// Values of the TRIGACT field in the channel control register.
+const (
+	dmaTRIGACT_BLOCK = 0
+	dmaTRIGACT_BURST = 2 // called BEAT on the SAMD21
+)
+
+// dmaDescriptor is a DMAC transfer descriptor as it is stored in SRAM.
+type dmaDescriptor struct {
+	btctrl   volatile.Register16
+	btcnt    volatile.Register16
+	srcaddr  volatile.Register32
+	dstaddr  volatile.Register32
+	descaddr volatile.Register32
+}
+
+// The DMAC reads the first descriptor of each channel from dmaDescriptors and
+// stores the state of suspended or running channels in dmaWriteback. Both
+// tables must be 128-bit aligned.
+
+//go:align 16
+var dmaDescriptors [dmaChannelCount]dmaDescriptor
+
+//go:align 16
+var dmaWriteback [dmaChannelCount]dmaDescriptor
+
+// setDescriptor fills in the (only) transfer descriptor of the channel. For
+// incrementing addresses the DMAC expects the address just past the end of
+// the block, not the start.
+func (ch *DMAChannel) setDescriptor(dst, src unsafe.Pointer, count uint32) {
+	size := uintptr(count) << ch.config.Width
+	srcaddr := uintptr(src)
+	dstaddr := uintptr(dst)
+	btctrl := uint16(dmaBTCTRL_VALID) | uint16(ch.config.Width)<<dmaBTCTRL_BEATSIZE_Pos
+	if ch.config.SrcIncrement {
+		btctrl |= dmaBTCTRL_SRCINC
+		srcaddr += size
+	}
+	if ch.config.DstIncrement {
+		btctrl |= dmaBTCTRL_DSTINC
+		dstaddr += size
+	}
+
+	desc := &dmaDescriptors[ch.id]
+	desc.btcnt.Set(uint16(count))
+	desc.srcaddr.Set(uint32(srcaddr))
+	desc.dstaddr.Set(uint32(dstaddr))
+	desc.descaddr.Set(0)
+	desc.btctrl.Set(btctrl)
+}
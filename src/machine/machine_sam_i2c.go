@@ -0,0 +1,183 @@
+//go:build sam
+// +build sam
+
+package machine
+
+import (
+	"device/sam"
+	"unsafe"
+)
+
+// I2C target (slave) operation of the SERCOM, shared by the SAMD21 and SAMD51.
+// The peripheral runs in smart mode: reading DATA acknowledges a received
+// byte and writing DATA sends the next byte. Waiting goroutines are woken by
+// the SERCOM interrupt.
+
+// sercomI2CTargets holds the I2C bus listening on each SERCOM, for the
+// interrupt handler. The SERCOM interrupts are shared with the UART driver,
+// so the handler does nothing for SERCOMs that are not in this list.
+var sercomI2CTargets [8]*I2C
+
+// wireTargetInterrupts are the interrupts that end a wait in target mode.
+const wireTargetInterrupts = sam.SERCOM_I2CS_INTENSET_PREC | sam.SERCOM_I2CS_INTENSET_AMATCH | sam.SERCOM_I2CS_INTENSET_DRDY
+
+// CTRLA.MODE value of a SERCOM in I2C slave operation.
+const wireTargetOperation = 4
+
+// CTRLB.CMD values in I2C slave operation.
+const (
+	wireTargetCmdWaitStart = 2 // end the transaction and wait for a (repeated) start
+	wireTargetCmdContinue  = 3 // send the acknowledge action and continue with the next byte
+)
+
+// target returns the registers of the SERCOM as seen in I2C slave operation.
+func (i2c *I2C) target() *sam.SERCOM_I2CS_Type {
+	return (*sam.SERCOM_I2CS_Type)(unsafe.Pointer(i2c.Bus))
+}
+
+// Listen starts responding to transactions for the given 7-bit address. The
+// I2C peripheral must have been configured with Mode set to I2CModeTarget.
+func (i2c *I2C) Listen(addr uint8) error {
+	if i2c.mode != I2CModeTarget {
+		return errI2CWrongMode
+	}
+	bus := i2c.target()
+	bus.CTRLA.ClearBits(sam.SERCOM_I2CS_CTRLA_ENABLE)
+	for bus.SYNCBUSY.HasBits(sam.SERCOM_I2CS_SYNCBUSY_ENABLE) {
+	}
+
+	bus.ADDR.Set(uint32(addr) << sam.SERCOM_I2CS_ADDR_ADDR_Pos)
+	bus.CTRLB.Set(sam.SERCOM_I2CS_CTRLB_SMEN)
+	bus.INTENCLR.Set(wireTargetInterrupts)
+
+	bus.CTRLA.SetBits(sam.SERCOM_I2CS_CTRLA_ENABLE)
+	for bus.SYNCBUSY.HasBits(sam.SERCOM_I2CS_SYNCBUSY_ENABLE) {
+	}
+
+	sercomI2CTargets[i2c.SERCOM] = i2c
+	enableSERCOMTargetInterrupt(i2c.SERCOM)
+	return nil
+}
+
+// handleSERCOMTargetInterrupt wakes the goroutine waiting for the I2C bus
+// listening on the given SERCOM, if any. The interrupts are disabled again
+// until the next wait.
+func handleSERCOMTargetInterrupt(sercom uint8) {
+	i2c := sercomI2CTargets[sercom]
+	if i2c == nil {
+		return
+	}
+	i2c.target().INTENCLR.Set(wireTargetInterrupts)
+	i2c.targetWake.notify()
+}
+
+// waitTarget blocks until a stop, an address match or a data request. A flag
+// that is already set when its interrupt gets enabled raises the interrupt
+// right away, so it isn't missed.
+func (i2c *I2C) waitTarget() {
+	i2c.target().INTENSET.Set(wireTargetInterrupts)
+	i2c.targetWake.wait()
+}
+
+// WaitForEvent blocks until the controller writes data to this target, asks
+// to read from it or ends a transaction. Other goroutines run while waiting.
+//
+// For I2CReceive events the written bytes are stored in buf and count is the
+// number of bytes stored. Once buf is full further bytes are not
+// acknowledged. For I2CRequest events the response must be sent with Reply
+// before calling WaitForEvent again.
+func (i2c *I2C) WaitForEvent(buf []byte) (evt I2CTargetEvent, count int, err error) {
+	if i2c.mode != I2CModeTarget {
+		return 0, 0, errI2CWrongMode
+	}
+	bus := i2c.target()
+	for {
+		flags := bus.INTFLAG.Get()
+		read := bus.STATUS.HasBits(sam.SERCOM_I2CS_STATUS_DIR)
+
+		if flags&sam.SERCOM_I2CS_INTFLAG_DRDY != 0 {
+			if read {
+				// Data is only requested here if Reply returned early,
+				// so end the read.
+				bus.CTRLB.SetBits(wireTargetCmdWaitStart << sam.SERCOM_I2CS_CTRLB_CMD_Pos)
+				continue
+			}
+			// Reading DATA sends the acknowledge action set in CTRLB.
+			if count < len(buf) {
+				bus.CTRLB.ClearBits(sam.SERCOM_I2CS_CTRLB_ACKACT)
+				buf[count] = byte(bus.DATA.Get())
+				count++
+			} else {
+				bus.CTRLB.SetBits(sam.SERCOM_I2CS_CTRLB_ACKACT)
+				bus.DATA.Get()
+			}
+			continue
+		}
+
+		// A stop ends the transaction. Report received data first and
+		// leave the flag set so the next call reports the finish.
+		if flags&sam.SERCOM_I2CS_INTFLAG_PREC != 0 {
+			if count > 0 {
+				return I2CReceive, count, nil
+			}
+			bus.INTFLAG.Set(sam.SERCOM_I2CS_INTFLAG_PREC)
+			return I2CFinish, 0, nil
+		}
+
+		// Address match after a start or repeated start. The bus is held
+		// until the address is acknowledged.
+		if flags&sam.SERCOM_I2CS_INTFLAG_AMATCH != 0 {
+			if count > 0 {
+				return I2CReceive, count, nil
+			}
+			if read {
+				// Reply acknowledges the address.
+				return I2CRequest, 0, nil
+			}
+			bus.CTRLB.ClearBits(sam.SERCOM_I2CS_CTRLB_ACKACT)
+			bus.CTRLB.SetBits(wireTargetCmdContinue << sam.SERCOM_I2CS_CTRLB_CMD_Pos)
+			continue
+		}
+
+		i2c.waitTarget()
+	}
+}
+
+// Reply sends buf in response to an I2CRequest event. If the controller reads
+// fewer bytes than buf holds the rest is discarded, bytes read past the end of
+// buf are sent as 0xff.
+func (i2c *I2C) Reply(buf []byte) error {
+	if i2c.mode != I2CModeTarget {
+		return errI2CWrongMode
+	}
+	bus := i2c.target()
+	if !bus.INTFLAG.HasBits(sam.SERCOM_I2CS_INTFLAG_AMATCH) || !bus.STATUS.HasBits(sam.SERCOM_I2CS_STATUS_DIR) {
+		return errI2CNoRequest
+	}
+	// Acknowledge the address, the first data request follows.
+	bus.CTRLB.ClearBits(sam.SERCOM_I2CS_CTRLB_ACKACT)
+	bus.CTRLB.SetBits(wireTargetCmdContinue << sam.SERCOM_I2CS_CTRLB_CMD_Pos)
+
+	sent := 0
+	for {
+		flags := bus.INTFLAG.Get()
+		if flags&sam.SERCOM_I2CS_INTFLAG_DRDY != 0 {
+			if sent > 0 && bus.STATUS.HasBits(sam.SERCOM_I2CS_STATUS_RXNACK) {
+				// The controller does not want more data.
+				bus.CTRLB.SetBits(wireTargetCmdWaitStart << sam.SERCOM_I2CS_CTRLB_CMD_Pos)
+				return nil
+			}
+			b := byte(0xff)
+			if sent < len(buf) {
+				b = buf[sent]
+			}
+			bus.DATA.Set(b)
+			sent++
+			continue
+		}
+		if flags&(sam.SERCOM_I2CS_INTFLAG_PREC|sam.SERCOM_I2CS_INTFLAG_AMATCH) != 0 {
+			return nil
+		}
+		i2c.waitTarget()
+	}
+}
@@ -15,6 +15,7 @@ import (
 // UART representation
 type UART struct {
 	Buffer            *RingBuffer
+	TXBuffer          *RingBuffer
 	Bus               *stm32.USART_Type
 	Interrupt         interrupt.Interrupt
 	TxAltFuncSelector uint8
@@ -27,6 +28,16 @@ type UART struct {
 	txEmptyFlag uint32
 }
 
+// Register bits that are at the same position in all STM32 families, but are
+// not named the same in all of them.
+const (
+	uartCR1_TXEIE = 1 << 7
+
+	uartStatusORE  = 1 << 3
+	uartStatusRXNE = 1 << 5
+	uartStatusTC   = 1 << 6
+)
+
 // Configure the UART.
 func (uart *UART) Configure(config UARTConfig) {
 	// Default baud rate to 115200.
@@ -45,6 +56,8 @@ func (uart *UART) Configure(config UARTConfig) {
 	// into `uart`.
 	uart.setRegisters()
 
+	uart.setBuffers(config)
+
 	// Enable USART clock
 	enableAltFuncClock(unsafe.Pointer(uart.Bus))
 
@@ -56,7 +69,7 @@ func (uart *UART) Configure(config UARTConfig) {
 	// Enable USART port, tx, rx and rx interrupts
 	uart.Bus.CR1.Set(stm32.USART_CR1_TE | stm32.USART_CR1_RE | stm32.USART_CR1_RXNEIE | stm32.USART_CR1_UE)
 
-	// Enable RX IRQ
+	// Enable RX IRQ, the TX interrupt is enabled by WriteByte when needed.
 	uart.Interrupt.SetPriority(0xc0)
 	uart.Interrupt.Enable()
 }
@@ -64,7 +77,18 @@ func (uart *UART) Configure(config UARTConfig) {
 // handleInterrupt should be called from the appropriate interrupt handler for
 // this UART instance.
 func (uart *UART) handleInterrupt(interrupt.Interrupt) {
-	uart.Receive(byte((uart.rxReg.Get() & 0xFF)))
+	// Reading the data register also clears an overrun on some families.
+	if uart.statusReg.HasBits(uartStatusRXNE | uartStatusORE) {
+		uart.Receive(byte((uart.rxReg.Get() & 0xFF)))
+	}
+
+	if uart.Bus.CR1.HasBits(uartCR1_TXEIE) && uart.statusReg.HasBits(uart.txEmptyFlag) {
+		if c, ok := uart.TXBuffer.Get(); ok {
+			uart.txReg.Set(uint32(c))
+		} else {
+			uart.Bus.CR1.ClearBits(uartCR1_TXEIE)
+		}
+	}
 }
 
 // SetBaudRate sets the communication speed for the UART. Defer to chip-specific
@@ -76,9 +100,24 @@ func (uart *UART) SetBaudRate(br uint32) {
 
 // WriteByte writes a byte of data to the UART.
 func (uart *UART) WriteByte(c byte) error {
+	if uart.TXBuffer != nil {
+		uart.writeBuffered(c)
+		return nil
+	}
+
 	uart.txReg.Set(uint32(c))
 
 	for !uart.statusReg.HasBits(uart.txEmptyFlag) {
 	}
 	return nil
 }
+
+// startTX enables the TX empty interrupt, which sends the bytes in TXBuffer.
+func (uart *UART) startTX() {
+	uart.Bus.CR1.SetBits(uartCR1_TXEIE)
+}
+
+// txComplete returns whether the last byte written has been sent.
+func (uart *UART) txComplete() bool {
+	return uart.statusReg.HasBits(uartStatusTC)
+}
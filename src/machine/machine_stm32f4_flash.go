@@ -0,0 +1,104 @@
+//go:build stm32f4
+// +build stm32f4
+
+package machine
+
+import (
+	"device/stm32"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The flash of the STM32F4 is divided in sectors of different sizes: four of
+// 16kB, one of 64kB and then sectors of 128kB, per bank of 1MB. Only the 128kB
+// sectors are used for data. Words are written with a parallelism of 32 bits,
+// which requires a supply voltage of at least 2.7V.
+const (
+	flashWriteBlockSize = 4
+	flashEraseBlockSize = 128 * 1024
+
+	flashBase     = 0x08000000
+	flashBankSize = 1024 * 1024
+
+	flashKey1 = 0x45670123
+	flashKey2 = 0xCDEF89AB
+
+	flashErrorFlags = stm32.FLASH_SR_PGSERR | stm32.FLASH_SR_PGPERR | stm32.FLASH_SR_PGAERR | stm32.FLASH_SR_WRPERR | stm32.FLASH_SR_OPERR
+)
+
+// flashWrite writes data, which must be a multiple of 4 bytes long, to the
+// word aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	flashUnlock()
+	defer flashLock()
+
+	stm32.FLASH.CR.Set(stm32.FLASH_CR_PSIZE_PSIZE32<<stm32.FLASH_CR_PSIZE_Pos | stm32.FLASH_CR_PG)
+	for i := 0; i < len(data); i += 4 {
+		word := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		volatile.StoreUint32((*uint32)(unsafe.Pointer(addr+uintptr(i))), word)
+		if !flashWait() {
+			stm32.FLASH.CR.Set(0)
+			return errFlashWriteFailed
+		}
+	}
+	stm32.FLASH.CR.Set(0)
+	return nil
+}
+
+// flashErase erases the 128kB sector at the given address.
+func flashErase(addr uintptr) error {
+	offset := uint32(addr - flashBase)
+	bank := offset / flashBankSize
+	offset %= flashBankSize
+	if offset < 128*1024 {
+		// The small sectors at the start of the bank hold the program.
+		return errFlashEraseFailed
+	}
+	sector := bank<<4 | (5 + (offset-128*1024)/flashEraseBlockSize)
+
+	flashUnlock()
+	defer flashLock()
+
+	stm32.FLASH.CR.Set(stm32.FLASH_CR_PSIZE_PSIZE32<<stm32.FLASH_CR_PSIZE_Pos | sector<<stm32.FLASH_CR_SNB_Pos | stm32.FLASH_CR_SER)
+	stm32.FLASH.CR.SetBits(stm32.FLASH_CR_STRT)
+	ok := flashWait()
+	stm32.FLASH.CR.Set(0)
+
+	// The data cache may still hold the old contents of the sector. It can
+	// only be reset while it is disabled.
+	if stm32.FLASH.ACR.HasBits(stm32.FLASH_ACR_DCEN) {
+		stm32.FLASH.ACR.ClearBits(stm32.FLASH_ACR_DCEN)
+		stm32.FLASH.ACR.SetBits(stm32.FLASH_ACR_DCRST)
+		stm32.FLASH.ACR.ClearBits(stm32.FLASH_ACR_DCRST)
+		stm32.FLASH.ACR.SetBits(stm32.FLASH_ACR_DCEN)
+	}
+
+	if !ok {
+		return errFlashEraseFailed
+	}
+	return nil
+}
+
+// flashUnlock allows writes to the flash control register, and clears the
+// error flags of a previous operation.
+func flashUnlock() {
+	for stm32.FLASH.SR.HasBits(stm32.FLASH_SR_BSY) {
+	}
+	if stm32.FLASH.CR.HasBits(stm32.FLASH_CR_LOCK) {
+		stm32.FLASH.KEYR.Set(flashKey1)
+		stm32.FLASH.KEYR.Set(flashKey2)
+	}
+	stm32.FLASH.SR.Set(flashErrorFlags)
+}
+
+func flashLock() {
+	stm32.FLASH.CR.SetBits(stm32.FLASH_CR_LOCK)
+}
+
+// flashWait waits until the current operation is done, and returns false if it
+// failed.
+func flashWait() bool {
+	for stm32.FLASH.SR.HasBits(stm32.FLASH_SR_BSY) {
+	}
+	return !stm32.FLASH.SR.HasBits(flashErrorFlags)
+}
@@ -0,0 +1,101 @@
+//go:build stm32l4
+// +build stm32l4
+
+package machine
+
+import (
+	"device/stm32"
+	"runtime/volatile"
+	"unsafe"
+)
+
+// The flash of the STM32L4 is written in double words of 64 bits, which are
+// protected with an ECC and can therefore be written only once after an erase.
+// It is erased in pages, the page and bank sizes are given per chip.
+const (
+	flashWriteBlockSize = 8
+
+	flashBase = 0x08000000
+
+	// Bank selection bit of FLASH_CR, which is only present on dual bank chips.
+	flashCR_BKER_Pos = 11
+
+	flashKey1 = 0x45670123
+	flashKey2 = 0xCDEF89AB
+
+	flashErrorFlags = stm32.Flash_SR_OPERR | stm32.Flash_SR_PROGERR | stm32.Flash_SR_WRPERR | stm32.Flash_SR_PGAERR |
+		stm32.Flash_SR_SIZERR | stm32.Flash_SR_PGSERR | stm32.Flash_SR_MISERR | stm32.Flash_SR_FASTERR
+)
+
+// flashWrite writes data, which must be a multiple of 8 bytes long, to the
+// double word aligned address in flash.
+func flashWrite(addr uintptr, data []byte) error {
+	flashUnlock()
+	defer flashLock()
+
+	stm32.FLASH.CR.Set(stm32.Flash_CR_PG)
+	for i := 0; i < len(data); i += 4 {
+		// A double word is written as two consecutive words.
+		word := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		volatile.StoreUint32((*uint32)(unsafe.Pointer(addr+uintptr(i))), word)
+		if i%8 == 4 && !flashWait() {
+			stm32.FLASH.CR.Set(0)
+			return errFlashWriteFailed
+		}
+	}
+	stm32.FLASH.CR.Set(0)
+	return nil
+}
+
+// flashErase erases the page at the given address.
+func flashErase(addr uintptr) error {
+	offset := uint32(addr - flashBase)
+	bank := offset / flashBankSize
+	page := (offset % flashBankSize) / flashEraseBlockSize
+
+	flashUnlock()
+	defer flashLock()
+
+	stm32.FLASH.CR.Set(bank<<flashCR_BKER_Pos | page<<stm32.Flash_CR_PNB_Pos | stm32.Flash_CR_PER)
+	stm32.FLASH.CR.SetBits(stm32.Flash_CR_STRT)
+	ok := flashWait()
+	stm32.FLASH.CR.Set(0)
+
+	// The data cache may still hold the old contents of the page. It can only
+	// be reset while it is disabled.
+	if stm32.FLASH.ACR.HasBits(stm32.Flash_ACR_DCEN) {
+		stm32.FLASH.ACR.ClearBits(stm32.Flash_ACR_DCEN)
+		stm32.FLASH.ACR.SetBits(stm32.Flash_ACR_DCRST)
+		stm32.FLASH.ACR.ClearBits(stm32.Flash_ACR_DCRST)
+		stm32.FLASH.ACR.SetBits(stm32.Flash_ACR_DCEN)
+	}
+
+	if !ok {
+		return errFlashEraseFailed
+	}
+	return nil
+}
+
+// flashUnlock allows writes to the flash control register, and clears the
+// error flags of a previous operation.
+func flashUnlock() {
+	for stm32.FLASH.SR.HasBits(stm32.Flash_SR_BSY) {
+	}
+	if stm32.FLASH.CR.HasBits(stm32.Flash_CR_LOCK) {
+		stm32.FLASH.KEYR.Set(flashKey1)
+		stm32.FLASH.KEYR.Set(flashKey2)
+	}
+	stm32.FLASH.SR.Set(flashErrorFlags)
+}
+
+func flashLock() {
+	stm32.FLASH.CR.SetBits(stm32.Flash_CR_LOCK)
+}
+
+// flashWait waits until the current operation is done, and returns false if it
+// failed.
+func flashWait() bool {
+	for stm32.FLASH.SR.HasBits(stm32.Flash_SR_BSY) {
+	}
+	return !stm32.FLASH.SR.HasBits(flashErrorFlags)
+}
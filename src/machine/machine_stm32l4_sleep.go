@@ -0,0 +1,13 @@
+//go:build stm32l4
+// +build stm32l4
+
+package machine
+
+const hasSystemOff = false
+
+func systemOff(pins []WakePin) {}
+
+// The EXTI detects the wake pins without a clock, also in STOP 2 mode.
+func enterDeepSleep() {}
+
+func exitDeepSleep() {}
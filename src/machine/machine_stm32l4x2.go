@@ -15,6 +15,12 @@ func CPUFrequency() uint32 {
 const APB1_TIM_FREQ = 80e6 // 80MHz
 const APB2_TIM_FREQ = 80e6 // 80MHz
 
+// Flash pages are 2kB, in a single bank.
+const (
+	flashEraseBlockSize = 2048
+	flashBankSize       = 256 * 1024
+)
+
 //---------- I2C related code
 
 // Gets the value for TIMINGR register
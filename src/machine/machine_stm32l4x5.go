@@ -15,6 +15,12 @@ func CPUFrequency() uint32 {
 const APB1_TIM_FREQ = 120e6 // 120MHz
 const APB2_TIM_FREQ = 120e6 // 120MHz
 
+// Flash pages are 4kB in the default dual bank mode, with two banks of 1MB.
+const (
+	flashEraseBlockSize = 4096
+	flashBankSize       = 1024 * 1024
+)
+
 //---------- I2C related code
 
 // Gets the value for TIMINGR register
@@ -7,10 +7,19 @@ var errNoByte = errors.New("machine: no byte read")
 // UARTConfig is a struct with which a UART (or similar object) can be
 // configured. The baud rate is usually respected, but TX and RX may be ignored
 // depending on the chip and the type of object.
+//
+// RXBufferSize and TXBufferSize set the size of the receive and transmit
+// buffers, and are rounded up to a power of two. A zero RXBufferSize keeps the
+// default buffer of 128 bytes. A zero TXBufferSize makes writes wait until each
+// byte has been handed to the hardware, otherwise bytes are queued and sent
+// from an interrupt. Buffer sizes are only respected by the UARTs of the nrf,
+// sam, stm32 and rp2040 chips.
 type UARTConfig struct {
-	BaudRate uint32
-	TX       Pin
-	RX       Pin
+	BaudRate     uint32
+	TX           Pin
+	RX           Pin
+	RXBufferSize int
+	TXBufferSize int
 }
 
 // NullSerial is a serial version of /dev/null (or null router): it drops
@@ -0,0 +1,92 @@
+package sim
+
+import "unsafe"
+
+// I2CDevice is a simulated device on an I2C bus.
+type I2CDevice interface {
+	// Tx handles a single I2C transaction addressed to this device: w holds
+	// the bytes written by the program and r must be filled with the bytes
+	// read back. Returning an error makes the transaction fail, like a NACK
+	// would.
+	Tx(w, r []byte) error
+}
+
+var i2cBuses map[uint8]map[uint16]I2CDevice
+
+// AttachI2C attaches an I2C device to the given bus at the given (7-bit)
+// address, replacing any device already there. Attaching a nil device removes
+// it from the bus.
+func AttachI2C(bus uint8, addr uint16, dev I2CDevice) {
+	if i2cBuses == nil {
+		i2cBuses = make(map[uint8]map[uint16]I2CDevice)
+	}
+	devices := i2cBuses[bus]
+	if devices == nil {
+		devices = make(map[uint16]I2CDevice)
+		i2cBuses[bus] = devices
+	}
+	if dev == nil {
+		delete(devices, addr)
+		return
+	}
+	devices[addr] = dev
+}
+
+//export __tinygo_i2c_configure
+func i2cConfigure(bus uint8, scl uint8, sda uint8) {
+}
+
+//export __tinygo_sim_i2c_transfer
+func i2cTransfer(bus uint8, addr uint16, w *byte, wlen int, r *byte, rlen int) int {
+	dev := i2cBuses[bus][addr]
+	if dev == nil {
+		// Nobody acknowledged the address.
+		return 1
+	}
+	if dev.Tx(makeSlice(w, wlen), makeSlice(r, rlen)) != nil {
+		return 1
+	}
+	return 0
+}
+
+// makeSlice returns a slice for a buffer passed to one of the hooks.
+func makeSlice(buf *byte, length int) []byte {
+	if length == 0 {
+		return nil
+	}
+	return (*[1 << 30]byte)(unsafe.Pointer(buf))[:length:length]
+}
+
+// I2CRegisters is a model of a typical I2C sensor with 8-bit register
+// addresses. The first byte of a write sets the register pointer and any
+// further bytes are stored in consecutive registers. A read returns
+// consecutive registers starting at the register pointer.
+type I2CRegisters struct {
+	// Registers holds the register contents. Tests can read and modify it
+	// directly.
+	Registers [256]byte
+
+	// ReadOnly marks registers that can't be written by the program, like
+	// identification or measurement registers.
+	ReadOnly [256]bool
+
+	pointer uint8
+}
+
+// Tx implements I2CDevice.
+func (d *I2CRegisters) Tx(w, r []byte) error {
+	if len(w) != 0 {
+		d.pointer = w[0]
+		for _, b := range w[1:] {
+			if !d.ReadOnly[d.pointer] {
+				d.Registers[d.pointer] = b
+			}
+			d.pointer++
+		}
+	}
+	for i := range r {
+		r[i] = d.Registers[d.pointer]
+		d.pointer++
+	}
+	return nil
+}
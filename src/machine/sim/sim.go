@@ -0,0 +1,122 @@
+// Package sim simulates the peripherals of the generic machine package, so
+// that code using GPIO, SPI, I2C, UART and ADC peripherals can run on the host.
+//
+// The generic machine package forwards all peripheral operations to external
+// functions like __tinygo_gpio_set and __tinygo_spi_transfer. This package
+// implements them and dispatches them to device models, which can be attached
+// from a test:
+//
+//	sensor := &sim.I2CRegisters{}
+//	sim.AttachI2C(0, 0x40, sensor)
+//	sensor.Registers[0x0f] = 0x6a // WHO_AM_I
+//
+// The machine package links this package automatically on Linux host builds.
+package sim
+
+// Pin modes, as set by the program through machine.Pin.Configure. They match
+// the PinMode constants of the generic machine package.
+const (
+	PinInput uint8 = iota
+	PinOutput
+	PinInputPullup
+	PinInputPulldown
+)
+
+// NoPin indicates "not a pin", like machine.NoPin.
+const NoPin = 0xff
+
+type pinState struct {
+	mode     uint8
+	output   bool // level set by the program
+	input    bool // level driven by the simulation
+	driven   bool // whether input is valid
+	onChange func(high bool)
+}
+
+var (
+	pins [256]pinState
+	adcs [256]uint16
+)
+
+// Reset detaches all devices and returns all pins and ADC values to their
+// initial state. It is typically called at the start of each test.
+func Reset() {
+	pins = [256]pinState{}
+	adcs = [256]uint16{}
+	i2cBuses = nil
+	spiBuses = nil
+	uartBuses = nil
+}
+
+// SetPin drives the given pin high or low from outside the program, like an
+// external device or a button would. The program can read the level with
+// machine.Pin.Get while the pin is configured as an input.
+func SetPin(pin uint8, high bool) {
+	pins[pin].input = high
+	pins[pin].driven = true
+}
+
+// ReleasePin stops driving the given pin from outside the program. An input pin
+// that is not driven reads as its pull-up or pull-down level.
+func ReleasePin(pin uint8) {
+	pins[pin].driven = false
+}
+
+// Pin returns the level of the given pin, as it would be seen by the program.
+func Pin(pin uint8) bool {
+	p := &pins[pin]
+	switch {
+	case p.mode == PinOutput:
+		return p.output
+	case p.driven:
+		return p.input
+	default:
+		return p.mode == PinInputPullup
+	}
+}
+
+// PinMode returns the mode the program configured for the given pin.
+func PinMode(pin uint8) uint8 {
+	return pins[pin].mode
+}
+
+// OnPinChange registers a function that is called whenever the program sets
+// the given output pin to a different level. Only one function can be
+// registered per pin, passing nil removes it.
+func OnPinChange(pin uint8, fn func(high bool)) {
+	pins[pin].onChange = fn
+}
+
+// SetADC sets the value that the program reads from the ADC on the given pin.
+func SetADC(pin uint8, value uint16) {
+	adcs[pin] = value
+}
+
+//export __tinygo_sim_gpio_configure
+func gpioConfigure(pin uint8, mode uint8) {
+	pins[pin].mode = mode
+}
+
+//export __tinygo_gpio_set
+func gpioSet(pin uint8, value bool) {
+	p := &pins[pin]
+	changed := p.output != value
+	p.output = value
+	if !changed {
+		return
+	}
+	spiChipSelect(pin, value)
+	if p.onChange != nil {
+		p.onChange(value)
+	}
+}
+
+//export __tinygo_gpio_get
+func gpioGet(pin uint8) bool {
+	return Pin(pin)
+}
+
+//export __tinygo_adc_read
+func adcRead(pin uint8) uint16 {
+	return adcs[pin]
+}
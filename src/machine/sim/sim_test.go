@@ -0,0 +1,172 @@
+package sim_test
+
+import (
+	"bytes"
+	"machine"
+	"machine/sim"
+	"testing"
+)
+
+func TestGPIO(t *testing.T) {
+	sim.Reset()
+
+	led := machine.Pin(13)
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	var changes []bool
+	sim.OnPinChange(13, func(high bool) {
+		changes = append(changes, high)
+	})
+	led.High()
+	led.High()
+	led.Low()
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("unexpected pin changes: %v", changes)
+	}
+	if sim.PinMode(13) != sim.PinOutput {
+		t.Errorf("pin mode is %d, expected output", sim.PinMode(13))
+	}
+
+	button := machine.Pin(2)
+	button.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	if !button.Get() {
+		t.Error("pull-up input should read high")
+	}
+	sim.SetPin(2, false)
+	if button.Get() {
+		t.Error("input driven low should read low")
+	}
+	sim.ReleasePin(2)
+	if !button.Get() {
+		t.Error("released pull-up input should read high")
+	}
+}
+
+func TestADC(t *testing.T) {
+	sim.Reset()
+
+	sim.SetADC(4, 0x1234)
+	adc := machine.ADC{Pin: 4}
+	adc.Configure(machine.ADCConfig{})
+	if v := adc.Get(); v != 0x1234 {
+		t.Errorf("ADC value is %#x, expected 0x1234", v)
+	}
+}
+
+func TestI2C(t *testing.T) {
+	sim.Reset()
+
+	sensor := &sim.I2CRegisters{}
+	sensor.Registers[0x0f] = 0x6a
+	sensor.ReadOnly[0x0f] = true
+	sim.AttachI2C(0, 0x6b, sensor)
+
+	i2c := machine.I2C0
+	i2c.Configure(machine.I2CConfig{})
+	r := make([]byte, 1)
+	if err := i2c.Tx(0x6b, []byte{0x0f}, r); err != nil || r[0] != 0x6a {
+		t.Errorf("reading WHO_AM_I: %#x, %v", r[0], err)
+	}
+
+	// Write two consecutive registers and read them back.
+	if err := i2c.Tx(0x6b, []byte{0x10, 0xaa, 0x55}, nil); err != nil {
+		t.Fatal("write failed:", err)
+	}
+	r = make([]byte, 2)
+	if err := i2c.Tx(0x6b, []byte{0x10}, r); err != nil || r[0] != 0xaa || r[1] != 0x55 {
+		t.Errorf("reading back registers: %#x, %v", r, err)
+	}
+	if sensor.Registers[0x11] != 0x55 {
+		t.Errorf("register 0x11 is %#x, expected 0x55", sensor.Registers[0x11])
+	}
+
+	// Read-only registers are not modified.
+	i2c.Tx(0x6b, []byte{0x0f, 0x00}, nil)
+	if sensor.Registers[0x0f] != 0x6a {
+		t.Error("read-only register was modified")
+	}
+
+	// No device at this address.
+	if err := i2c.Tx(0x20, []byte{0}, nil); err == nil {
+		t.Error("expected an error for a missing device")
+	}
+}
+
+func TestSPIFlash(t *testing.T) {
+	sim.Reset()
+
+	const csPin = 10
+	flash := sim.NewSPIFlash(1 << 20)
+	sim.AttachSPI(0, csPin, flash)
+
+	spi := machine.SPI0
+	spi.Configure(machine.SPIConfig{})
+	cs := machine.Pin(csPin)
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.High()
+	command := func(w []byte, n int) []byte {
+		cs.Low()
+		defer cs.High()
+		for _, b := range w {
+			spi.Transfer(b)
+		}
+		r := make([]byte, n)
+		for i := range r {
+			r[i], _ = spi.Transfer(0)
+		}
+		return r
+	}
+
+	if id := command([]byte{0x9f}, 3); !bytes.Equal(id, []byte{0xef, 0x40, 0x16}) {
+		t.Errorf("unexpected JEDEC ID: %x", id)
+	}
+
+	// Programming requires write enable.
+	command([]byte{0x02, 0x00, 0x10, 0x00, 1, 2, 3}, 0)
+	if flash.Data[0x1000] != 0xff {
+		t.Error("page program without write enable modified the flash")
+	}
+	command([]byte{0x06}, 0)
+	if status := command([]byte{0x05}, 1); status[0] != 0x02 {
+		t.Errorf("status after write enable is %#x", status[0])
+	}
+	command([]byte{0x02, 0x00, 0x10, 0x00, 1, 2, 3}, 0)
+	if status := command([]byte{0x05}, 1); status[0] != 0 {
+		t.Errorf("status after page program is %#x", status[0])
+	}
+	if data := command([]byte{0x03, 0x00, 0x10, 0x00}, 4); !bytes.Equal(data, []byte{1, 2, 3, 0xff}) {
+		t.Errorf("unexpected data: %x", data)
+	}
+
+	// Not selected: the device doesn't respond.
+	if r, _ := spi.Transfer(0x9f); r != 0xff {
+		t.Errorf("unselected device responded with %#x", r)
+	}
+
+	// Erase the sector again.
+	command([]byte{0x06}, 0)
+	command([]byte{0x20, 0x00, 0x10, 0x80}, 0)
+	if data := command([]byte{0x03, 0x00, 0x10, 0x00}, 3); !bytes.Equal(data, []byte{0xff, 0xff, 0xff}) {
+		t.Errorf("data not erased: %x", data)
+	}
+}
+
+func TestUARTLoopback(t *testing.T) {
+	sim.Reset()
+
+	sim.AttachUART(1, &sim.UARTLoopback{})
+	uart := &machine.UART{Bus: 1}
+	uart.Configure(machine.UARTConfig{})
+	uart.Write([]byte("ping"))
+	if n := uart.Buffered(); n != 4 {
+		t.Errorf("expected 4 buffered bytes, got %d", n)
+	}
+	b, _ := uart.ReadByte()
+	buf := make([]byte, 10)
+	n, _ := uart.Read(buf)
+	if b != 'p' || string(buf[:n]) != "ing" {
+		t.Errorf("unexpected data: %c %q", b, buf[:n])
+	}
+	if n := uart.Buffered(); n != 0 {
+		t.Errorf("expected no buffered bytes, got %d", n)
+	}
+}
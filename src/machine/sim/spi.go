@@ -0,0 +1,201 @@
+package sim
+
+// SPIDevice is a simulated device on a SPI bus.
+type SPIDevice interface {
+	// Select is called when the chip select line of the device changes.
+	// Devices typically start a new command when selected.
+	Select(selected bool)
+
+	// Transfer is called for every byte transferred while the device is
+	// selected. It returns the byte sent back to the program.
+	Transfer(w byte) byte
+}
+
+type spiDevice struct {
+	cs  uint8
+	dev SPIDevice
+}
+
+var spiBuses map[uint8][]spiDevice
+
+// AttachSPI attaches a SPI device to the given bus. The device is selected
+// while the program drives the (active low) cs pin low, or always if cs is
+// NoPin.
+func AttachSPI(bus uint8, cs uint8, dev SPIDevice) {
+	if spiBuses == nil {
+		spiBuses = make(map[uint8][]spiDevice)
+	}
+	spiBuses[bus] = append(spiBuses[bus], spiDevice{cs: cs, dev: dev})
+	if cs == NoPin {
+		dev.Select(true)
+	}
+}
+
+// selected returns whether the device is currently selected.
+func (d spiDevice) selected() bool {
+	return d.cs == NoPin || (pins[d.cs].mode == PinOutput && !pins[d.cs].output)
+}
+
+// spiChipSelect notifies the SPI devices using the given pin as chip select
+// that it changed to the given level.
+func spiChipSelect(pin uint8, high bool) {
+	for _, devices := range spiBuses {
+		for _, d := range devices {
+			if d.cs == pin {
+				d.dev.Select(!high)
+			}
+		}
+	}
+}
+
+//export __tinygo_spi_configure
+func spiConfigure(bus uint8, sck uint8, sdo uint8, sdi uint8) {
+}
+
+//export __tinygo_spi_transfer
+func spiTransfer(bus uint8, w uint8) uint8 {
+	// Without a selected device, the data line is pulled high.
+	r := uint8(0xff)
+	for _, d := range spiBuses[bus] {
+		if d.selected() {
+			// Multiple devices driving the line at once is a bug in the
+			// program. Model it as an open-drain bus.
+			r &= d.dev.Transfer(w)
+		}
+	}
+	return r
+}
+
+// SPI NOR flash commands supported by SPIFlash.
+const (
+	flashCmdPageProgram  = 0x02
+	flashCmdRead         = 0x03
+	flashCmdWriteDisable = 0x04
+	flashCmdReadStatus   = 0x05
+	flashCmdWriteEnable  = 0x06
+	flashCmdSectorErase  = 0x20
+	flashCmdChipErase    = 0x60
+	flashCmdReadJEDECID  = 0x9f
+	flashCmdChipErase2   = 0xc7
+	flashCmdBlockErase   = 0xd8
+)
+
+// SPIFlash is a model of a SPI NOR flash chip, like the W25Q series. It
+// supports reading, page programming, sector (4kB), block (64kB) and chip
+// erase, the status register and the JEDEC ID. Operations complete instantly.
+type SPIFlash struct {
+	// Data holds the flash contents. Erased bytes are 0xff.
+	Data []byte
+
+	// JEDECID is returned by the Read JEDEC ID (0x9f) command.
+	JEDECID [3]byte
+
+	writeEnabled bool
+	cmd          uint8
+	count        int    // number of bytes transferred in this command
+	addr         uint32 // address of the current command
+}
+
+// NewSPIFlash returns a new erased SPI flash chip of the given size in bytes,
+// which should be a multiple of the 64kB block size.
+func NewSPIFlash(size int) *SPIFlash {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = 0xff
+	}
+	return &SPIFlash{
+		Data:    data,
+		JEDECID: [3]byte{0xef, 0x40, 0x16},
+	}
+}
+
+// Select implements SPIDevice.
+func (f *SPIFlash) Select(selected bool) {
+	if !selected {
+		// The command completes when the chip is deselected.
+		switch f.cmd {
+		case flashCmdSectorErase:
+			f.erase(4096)
+		case flashCmdBlockErase:
+			f.erase(65536)
+		case flashCmdChipErase, flashCmdChipErase2:
+			if f.writeEnabled {
+				for i := range f.Data {
+					f.Data[i] = 0xff
+				}
+			}
+		}
+		if f.cmd == flashCmdPageProgram || f.cmd == flashCmdSectorErase || f.cmd == flashCmdBlockErase || f.cmd == flashCmdChipErase || f.cmd == flashCmdChipErase2 {
+			f.writeEnabled = false
+		}
+	}
+	f.count = 0
+	f.cmd = 0
+}
+
+// erase erases the block of the given size around the address of the current
+// command.
+func (f *SPIFlash) erase(size uint32) {
+	if !f.writeEnabled || f.count != 4 {
+		return
+	}
+	start := f.addr &^ (size - 1)
+	for i := start; i < start+size && int(i) < len(f.Data); i++ {
+		f.Data[i] = 0xff
+	}
+}
+
+// Transfer implements SPIDevice.
+func (f *SPIFlash) Transfer(w byte) byte {
+	count := f.count
+	f.count++
+	if count == 0 {
+		f.cmd = w
+		switch w {
+		case flashCmdWriteEnable:
+			f.writeEnabled = true
+		case flashCmdWriteDisable:
+			f.writeEnabled = false
+		}
+		return 0xff
+	}
+	switch f.cmd {
+	case flashCmdReadStatus:
+		if f.writeEnabled {
+			return 0x02 // WEL
+		}
+		return 0
+	case flashCmdReadJEDECID:
+		if count <= len(f.JEDECID) {
+			return f.JEDECID[count-1]
+		}
+	case flashCmdRead, flashCmdPageProgram, flashCmdSectorErase, flashCmdBlockErase:
+		if count <= 3 {
+			// 24-bit address, most significant byte first.
+			if count == 1 {
+				f.addr = 0
+			}
+			f.addr = f.addr<<8 | uint32(w)
+			return 0xff
+		}
+		if len(f.Data) == 0 {
+			return 0xff
+		}
+		switch f.cmd {
+		case flashCmdRead:
+			// Reads continue through the whole chip.
+			addr := (f.addr + uint32(count-4)) % uint32(len(f.Data))
+			return f.Data[addr]
+		case flashCmdPageProgram:
+			if !f.writeEnabled {
+				return 0xff
+			}
+			// Programming wraps around within the 256-byte page, and can
+			// only clear bits.
+			page := f.addr &^ 0xff
+			addr := (page + ((f.addr + uint32(count-4)) & 0xff)) % uint32(len(f.Data))
+			f.Data[addr] &= w
+		}
+	}
+	return 0xff
+}
@@ -0,0 +1,91 @@
+package sim
+
+import "os"
+
+// UARTDevice is a simulated device connected to a UART.
+type UARTDevice interface {
+	// Write is called with the bytes the program sends to the device.
+	Write(p []byte) (n int, err error)
+
+	// Read fills p with bytes the device sends to the program. It must not
+	// block: it returns 0 when nothing is available.
+	Read(p []byte) (n int, err error)
+
+	// Buffered returns the number of bytes the device has available for the
+	// program to read.
+	Buffered() int
+}
+
+var uartBuses map[uint8]UARTDevice
+
+// AttachUART connects a device to the given UART, replacing any device already
+// there. Passing a nil device disconnects it: the UART then writes to standard
+// output and never receives anything.
+func AttachUART(bus uint8, dev UARTDevice) {
+	if uartBuses == nil {
+		uartBuses = make(map[uint8]UARTDevice)
+	}
+	if dev == nil {
+		delete(uartBuses, bus)
+		return
+	}
+	uartBuses[bus] = dev
+}
+
+//export __tinygo_uart_configure
+func uartConfigure(bus uint8, tx uint8, rx uint8) {
+}
+
+//export __tinygo_uart_read
+func uartRead(bus uint8, buf *byte, bufLen int) int {
+	dev := uartBuses[bus]
+	if dev == nil {
+		return 0
+	}
+	n, _ := dev.Read(makeSlice(buf, bufLen))
+	return n
+}
+
+//export __tinygo_uart_write
+func uartWrite(bus uint8, buf *byte, bufLen int) int {
+	dev := uartBuses[bus]
+	if dev == nil {
+		n, _ := os.Stdout.Write(makeSlice(buf, bufLen))
+		return n
+	}
+	n, _ := dev.Write(makeSlice(buf, bufLen))
+	return n
+}
+
+//export __tinygo_sim_uart_buffered
+func uartBuffered(bus uint8) int {
+	dev := uartBuses[bus]
+	if dev == nil {
+		return 0
+	}
+	return dev.Buffered()
+}
+
+// UARTLoopback is a UART device that sends everything the program writes back
+// to the program, like a wire between the TX and RX pins.
+type UARTLoopback struct {
+	buf []byte
+}
+
+// Write implements UARTDevice.
+func (l *UARTLoopback) Write(p []byte) (n int, err error) {
+	l.buf = append(l.buf, p...)
+	return len(p), nil
+}
+
+// Read implements UARTDevice.
+func (l *UARTLoopback) Read(p []byte) (n int, err error) {
+	n = copy(p, l.buf)
+	l.buf = l.buf[n:]
+	return n, nil
+}
+
+// Buffered implements UARTDevice.
+func (l *UARTLoopback) Buffered() int {
+	return len(l.buf)
+}
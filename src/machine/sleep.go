@@ -0,0 +1,120 @@
+//go:build nrf52 || nrf52840 || nrf52833 || (sam && atsamd21) || (sam && atsamd51) || (sam && atsame5x) || stm32l4
+// +build nrf52 nrf52840 nrf52833 sam,atsamd21 sam,atsamd51 sam,atsame5x stm32l4
+
+package machine
+
+import (
+	"errors"
+	"runtime/volatile"
+	_ "unsafe" // for go:linkname
+)
+
+var errNoWakeSource = errors.New("sleep: no wake source configured")
+
+// SleepConfig configures the sources that wake up the chip from DeepSleep.
+type SleepConfig struct {
+	// WakePins wake up the chip when one of them has the given level. The pins
+	// must already be configured as inputs, including a pull up or down if no
+	// external pull is provided, and must not have a pin interrupt set.
+	WakePins []WakePin
+
+	// Alarm wakes up the chip after the given duration in nanoseconds, using
+	// the RTC. Zero means the chip is only woken by the wake pins.
+	Alarm uint64
+}
+
+// WakePin is a pin that wakes up the chip from DeepSleep.
+type WakePin struct {
+	Pin Pin
+
+	// High is true if the chip should wake up when the pin is high, and false
+	// if it should wake up when the pin is low.
+	High bool
+}
+
+// Set by the wake pin interrupts.
+var pinWakeup volatile.Register8
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+// deepSleepTimeout is provided by the runtime. It puts the chip in the deepest
+// low-power state in which the RTC keeps running, until the timeout (in
+// nanoseconds) has passed or until an interrupt woke up the chip. A zero
+// timeout waits for an interrupt only. The runtime keeps track of the time
+// spent sleeping.
+//
+//go:linkname deepSleepTimeout runtime.deepSleep
+func deepSleepTimeout(ns int64)
+
+// DeepSleep puts the chip in the deepest low-power state that can still be
+// woken up by the given wake sources: System OFF on the nRF52, STANDBY on the
+// SAMD21 and SAMD51 and STOP 2 on the STM32L4. In STANDBY and STOP 2, most
+// peripherals (including USB) are stopped while sleeping. The clocks are
+// restored when waking up, after which DeepSleep returns.
+//
+// On the nRF52, an alarm can't wake up the chip from System OFF. Without an
+// alarm, the chip resets when a wake pin gets to its level, so DeepSleep never
+// returns. With an alarm, the chip waits in System ON with only the RTC and
+// the wake pin detection running.
+//
+// While all goroutines sleep, the chip waits in a mode in which the
+// peripherals keep running. For long sleeps, the SAMD21 also stops the AHB and
+// APB clocks when USB is not in use, and the STM32L4 enters STOP 2 when no
+// peripheral (including the serial console) is enabled. The SAMD51 and nRF52
+// don't switch modes: they already stop the clocks that no peripheral needs,
+// and their deeper modes are only entered by DeepSleep.
+func DeepSleep(config SleepConfig) error {
+	if len(config.WakePins) == 0 && config.Alarm == 0 {
+		return errNoWakeSource
+	}
+	if config.Alarm == 0 && hasSystemOff {
+		systemOff(config.WakePins)
+	}
+
+	// Use an edge triggered pin interrupt for each wake pin. The pin may
+	// already be at its level before the interrupt is enabled, so check the
+	// level afterwards.
+	pinWakeup.Set(0)
+	for i, w := range config.WakePins {
+		change := PinFalling
+		if w.High {
+			change = PinRising
+		}
+		err := w.Pin.SetInterrupt(change, func(Pin) {
+			pinWakeup.Set(1)
+		})
+		if err != nil {
+			clearWakePins(config.WakePins[:i])
+			return err
+		}
+	}
+	for _, w := range config.WakePins {
+		if w.Pin.Get() == w.High {
+			pinWakeup.Set(1)
+		}
+	}
+
+	deadline := nanotime() + int64(config.Alarm)
+	enterDeepSleep()
+	for pinWakeup.Get() == 0 {
+		timeout := int64(0)
+		if config.Alarm != 0 {
+			timeout = deadline - nanotime()
+			if timeout <= 0 {
+				break
+			}
+		}
+		deepSleepTimeout(timeout)
+	}
+	exitDeepSleep()
+
+	clearWakePins(config.WakePins)
+	return nil
+}
+
+func clearWakePins(pins []WakePin) {
+	for _, w := range pins {
+		w.Pin.SetInterrupt(0, nil)
+	}
+}
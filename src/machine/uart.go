@@ -62,6 +62,9 @@ func (uart *UART) Read(data []byte) (n int, err error) {
 
 // Write data to the UART.
 func (uart *UART) Write(data []byte) (n int, err error) {
+	if uart.writeDMA(data) {
+		return len(data), nil
+	}
 	for _, v := range data {
 		uart.WriteByte(v)
 	}
@@ -81,7 +84,7 @@ func (uart *UART) ReadByte() (byte, error) {
 
 // Buffered returns the number of bytes currently stored in the RX buffer.
 func (uart *UART) Buffered() int {
-	return int(uart.Buffer.Used())
+	return uart.Buffer.Used()
 }
 
 // Receive handles adding data to the UART's data buffer.
@@ -0,0 +1,57 @@
+//go:build nrf || sam || stm32 || rp2040
+// +build nrf sam stm32 rp2040
+
+package machine
+
+import (
+	_ "unsafe" // for go:linkname
+)
+
+// Buffered transmit, shared by the chips that send the bytes in the TX buffer
+// from their TX interrupt. Each chip implements:
+//
+//	startTX    enable the TX interrupt, which sends the next byte from TXBuffer
+//	txComplete report whether the last byte has left the shift register
+
+// setBuffers allocates the RX and TX buffers requested in the configuration.
+// It must be called before the UART interrupts are enabled.
+func (uart *UART) setBuffers(config UARTConfig) {
+	if config.RXBufferSize != 0 && config.RXBufferSize != uart.Buffer.Size() {
+		uart.Buffer = NewRingBufferSize(config.RXBufferSize)
+	}
+	if uart.TXBuffer != nil {
+		// Don't drop data queued with the previous configuration.
+		uart.Flush()
+		uart.TXBuffer = nil
+	}
+	if config.TXBufferSize != 0 {
+		uart.TXBuffer = NewRingBufferSize(config.TXBufferSize)
+	}
+}
+
+// writeBuffered queues a byte to be sent from the TX interrupt. If the buffer
+// is full it waits for room, letting other goroutines run in the meantime.
+// This means it must not be called with a full buffer from an interrupt of the
+// same or a higher priority than the UART interrupt.
+func (uart *UART) writeBuffered(c byte) {
+	for !uart.TXBuffer.Put(c) {
+		gosched()
+	}
+	uart.startTX()
+}
+
+// Flush waits until all data written to the UART has been sent, letting other
+// goroutines run in the meantime.
+func (uart *UART) Flush() {
+	if uart.TXBuffer != nil {
+		for uart.TXBuffer.Used() != 0 {
+			gosched()
+		}
+	}
+	for !uart.txComplete() {
+		gosched()
+	}
+}
+
+//go:linkname gosched runtime.Gosched
+func gosched()
@@ -0,0 +1,10 @@
+//go:build atmega || esp || nrf51 || sifive || stm32 || k210 || nxp
+// +build atmega esp nrf51 sifive stm32 k210 nxp
+
+package machine
+
+// writeDMA is not supported on these chips, so Write always sends the data
+// one byte at a time.
+func (uart *UART) writeDMA(data []byte) bool {
+	return false
+}
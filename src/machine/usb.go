@@ -15,6 +15,8 @@ var (
 	errUSBCDCWriteByteTimeout = errors.New("USB-CDC write byte timeout")
 	errUSBCDCReadTimeout      = errors.New("USB-CDC read timeout")
 	errUSBCDCBytesRead        = errors.New("USB-CDC invalid number of bytes read")
+	errUSBNoEndpoint          = errors.New("USB: no free endpoint")
+	errUSBNoInterface         = errors.New("USB: too many interfaces")
 )
 
 // DeviceDescriptor implements the USB standard device descriptor.
@@ -401,13 +403,40 @@ func (d CDCDescriptor) Bytes() [cdcSize]byte {
 	return b
 }
 
-// MSCDescriptor is not used yet.
+// MSCDescriptor is the interface descriptor of a mass storage device using
+// the bulk-only transport, followed by its two bulk endpoint descriptors.
 type MSCDescriptor struct {
 	msc InterfaceDescriptor
 	in  EndpointDescriptor
 	out EndpointDescriptor
 }
 
+// NewMSCDescriptor returns a new USB MSCDescriptor.
+func NewMSCDescriptor(m InterfaceDescriptor, outp EndpointDescriptor, inp EndpointDescriptor) MSCDescriptor {
+	return MSCDescriptor{msc: m, in: inp, out: outp}
+}
+
+const mscSize = interfaceDescriptorSize + endpointDescriptorSize*2
+
+// Bytes returns MSCDescriptor data.
+func (d MSCDescriptor) Bytes() [mscSize]byte {
+	var b [mscSize]byte
+	offset := 0
+
+	msc := d.msc.Bytes()
+	copy(b[offset:], msc[:])
+	offset += len(msc)
+
+	out := d.out.Bytes()
+	copy(b[offset:], out[:])
+	offset += len(out)
+
+	in := d.in.Bytes()
+	copy(b[offset:], in[:])
+
+	return b
+}
+
 const cdcLineInfoSize = 7
 
 type cdcLineInfo struct {
@@ -438,6 +467,41 @@ var (
 	usb_STRING_LANGUAGE = [2]uint16{(3 << 8) | (2 + 2), 0x0409} // English
 )
 
+var (
+	// endPoints holds the type and direction of each endpoint, indexed by
+	// endpoint number.
+	endPoints = []uint32{usb_ENDPOINT_TYPE_CONTROL,
+		(usb_ENDPOINT_TYPE_INTERRUPT | usbEndpointIn),
+		(usb_ENDPOINT_TYPE_BULK | usbEndpointOut),
+		(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)}
+
+	// usbConfigDescriptor is the configuration descriptor followed by the
+	// descriptors of all interfaces, as sent to the host.
+	usbConfigDescriptor = newUSBConfigDescriptor()
+	usbInterfaceCount   = uint8(usb_CDC_INTERFACE_COUNT)
+
+	// usbSetupHandler holds the handlers of requests addressed to an
+	// interface, indexed by interface number.
+	usbSetupHandler = [usbNumberOfInterfaces]func(usbSetup) bool{
+		usb_CDC_ACM_INTERFACE: cdcSetup,
+	}
+
+	// usbTxHandler holds the functions that are called from the USB
+	// interrupt when an IN transfer has completed, indexed by endpoint
+	// number.
+	usbTxHandler [usb_EPT_NUM + 1]func()
+
+	// usbRxHandler holds the functions that are called from the USB
+	// interrupt with the data of a completed OUT transfer, indexed by
+	// endpoint number.
+	usbRxHandler [usb_EPT_NUM + 1]func([]byte)
+)
+
+// usbEP0InBuffer holds the data sent on the control endpoint, which can be
+// larger than a packet.
+//go:align 4
+var usbEP0InBuffer [usbEP0BufferSize]byte
+
 const (
 	usb_IMANUFACTURER = 1
 	usb_IPRODUCT      = 2
@@ -492,6 +556,16 @@ const (
 	usb_CDC_ENDPOINT_OUT   = 2
 	usb_CDC_ENDPOINT_IN    = 3
 
+	usb_CDC_INTERFACE_COUNT = 2
+
+	// usbNumberOfInterfaces is the maximum number of interfaces of the
+	// composite device.
+	usbNumberOfInterfaces = 8
+
+	// usbEP0BufferSize is the size of the buffer for data sent on the
+	// control endpoint, which limits the size of the descriptors.
+	usbEP0BufferSize = 512
+
 	// bmRequestType
 	usb_REQUEST_HOSTTODEVICE = 0x00
 	usb_REQUEST_DEVICETOHOST = 0x80
@@ -647,7 +721,7 @@ func (usbcdc *USBCDC) ReadByte() (byte, error) {
 
 // Buffered returns the number of bytes currently stored in the RX buffer.
 func (usbcdc *USBCDC) Buffered() int {
-	return int(usbcdc.Buffer.Used())
+	return usbcdc.Buffer.Used()
 }
 
 // Receive handles adding data to the UART's data buffer.
@@ -702,54 +776,113 @@ func sendDescriptor(setup usbSetup) {
 	return
 }
 
-// sendConfiguration creates and sends the configuration packet to the host.
+// sendConfiguration sends the configuration descriptor, followed by the
+// descriptors of all interfaces, to the host.
 func sendConfiguration(setup usbSetup) {
-	if setup.wLength == 9 {
-		sz := uint16(configDescriptorSize + cdcSize)
-		config := NewConfigDescriptor(sz, 2)
-		configBuf := config.Bytes()
-		sendUSBPacket(0, configBuf[:])
-	} else {
-		iad := NewIADDescriptor(0, 2, usb_CDC_COMMUNICATION_INTERFACE_CLASS, usb_CDC_ABSTRACT_CONTROL_MODEL, 0)
+	sendUSBPacket(0, limitUSBSetupLength(usbConfigDescriptor, setup))
+}
+
+// limitUSBSetupLength truncates data to the length requested by the host.
+func limitUSBSetupLength(data []byte, setup usbSetup) []byte {
+	if int(setup.wLength) < len(data) {
+		return data[:setup.wLength]
+	}
+	return data
+}
 
-		cif := NewInterfaceDescriptor(usb_CDC_ACM_INTERFACE, 1, usb_CDC_COMMUNICATION_INTERFACE_CLASS, usb_CDC_ABSTRACT_CONTROL_MODEL, 0)
+// newUSBConfigDescriptor returns the configuration descriptor of a device
+// with only the CDC interfaces.
+func newUSBConfigDescriptor() []byte {
+	iad := NewIADDescriptor(0, 2, usb_CDC_COMMUNICATION_INTERFACE_CLASS, usb_CDC_ABSTRACT_CONTROL_MODEL, 0)
 
-		header := NewCDCCSInterfaceDescriptor(usb_CDC_HEADER, usb_CDC_V1_10&0xFF, (usb_CDC_V1_10>>8)&0x0FF)
+	cif := NewInterfaceDescriptor(usb_CDC_ACM_INTERFACE, 1, usb_CDC_COMMUNICATION_INTERFACE_CLASS, usb_CDC_ABSTRACT_CONTROL_MODEL, 0)
 
-		controlManagement := NewACMFunctionalDescriptor(usb_CDC_ABSTRACT_CONTROL_MANAGEMENT, 6)
+	header := NewCDCCSInterfaceDescriptor(usb_CDC_HEADER, usb_CDC_V1_10&0xFF, (usb_CDC_V1_10>>8)&0x0FF)
 
-		functionalDescriptor := NewCDCCSInterfaceDescriptor(usb_CDC_UNION, usb_CDC_ACM_INTERFACE, usb_CDC_DATA_INTERFACE)
+	controlManagement := NewACMFunctionalDescriptor(usb_CDC_ABSTRACT_CONTROL_MANAGEMENT, 6)
 
-		callManagement := NewCMFunctionalDescriptor(usb_CDC_CALL_MANAGEMENT, 1, 1)
+	functionalDescriptor := NewCDCCSInterfaceDescriptor(usb_CDC_UNION, usb_CDC_ACM_INTERFACE, usb_CDC_DATA_INTERFACE)
 
-		cifin := NewEndpointDescriptor((usb_CDC_ENDPOINT_ACM | usbEndpointIn), usb_ENDPOINT_TYPE_INTERRUPT, 0x10, 0x10)
+	callManagement := NewCMFunctionalDescriptor(usb_CDC_CALL_MANAGEMENT, 1, 1)
 
-		dif := NewInterfaceDescriptor(usb_CDC_DATA_INTERFACE, 2, usb_CDC_DATA_INTERFACE_CLASS, 0, 0)
+	cifin := NewEndpointDescriptor((usb_CDC_ENDPOINT_ACM | usbEndpointIn), usb_ENDPOINT_TYPE_INTERRUPT, 0x10, 0x10)
 
-		out := NewEndpointDescriptor((usb_CDC_ENDPOINT_OUT | usbEndpointOut), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
+	dif := NewInterfaceDescriptor(usb_CDC_DATA_INTERFACE, 2, usb_CDC_DATA_INTERFACE_CLASS, 0, 0)
 
-		in := NewEndpointDescriptor((usb_CDC_ENDPOINT_IN | usbEndpointIn), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
+	out := NewEndpointDescriptor((usb_CDC_ENDPOINT_OUT | usbEndpointOut), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
 
-		cdc := NewCDCDescriptor(iad,
-			cif,
-			header,
-			controlManagement,
-			functionalDescriptor,
-			callManagement,
-			cifin,
-			dif,
-			out,
-			in)
+	in := NewEndpointDescriptor((usb_CDC_ENDPOINT_IN | usbEndpointIn), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
 
-		sz := uint16(configDescriptorSize + cdcSize)
-		config := NewConfigDescriptor(sz, 2)
+	cdc := NewCDCDescriptor(iad,
+		cif,
+		header,
+		controlManagement,
+		functionalDescriptor,
+		callManagement,
+		cifin,
+		dif,
+		out,
+		in)
 
-		configBuf := config.Bytes()
-		cdcBuf := cdc.Bytes()
-		var buf [configDescriptorSize + cdcSize]byte
-		copy(buf[0:], configBuf[:])
-		copy(buf[configDescriptorSize:], cdcBuf[:])
+	sz := uint16(configDescriptorSize + cdcSize)
+	config := NewConfigDescriptor(sz, usb_CDC_INTERFACE_COUNT)
 
-		sendUSBPacket(0, buf[:])
+	configBuf := config.Bytes()
+	cdcBuf := cdc.Bytes()
+	buf := make([]byte, 0, configDescriptorSize+cdcSize)
+	buf = append(buf, configBuf[:]...)
+	buf = append(buf, cdcBuf[:]...)
+	return buf
+}
+
+// addUSBInterfaces adds a function with count interfaces, such as HID, to
+// the composite device. The interfaces are numbered from usbInterfaceCount,
+// descriptors holds their interface, class and endpoint descriptors and setup
+// handles the requests addressed to them, or is nil if there are none. It must
+// be called before the host enumerates the device.
+func addUSBInterfaces(count uint8, descriptors []byte, setup func(usbSetup) bool) error {
+	if int(usbInterfaceCount)+int(count) > usbNumberOfInterfaces {
+		return errUSBNoInterface
+	}
+	for i := uint8(0); i < count; i++ {
+		usbSetupHandler[usbInterfaceCount+i] = setup
+	}
+	usbInterfaceCount += count
+	usbConfigDescriptor = append(usbConfigDescriptor, descriptors...)
+
+	// Update wTotalLength and bNumInterfaces.
+	usbConfigDescriptor[2] = byte(len(usbConfigDescriptor))
+	usbConfigDescriptor[3] = byte(len(usbConfigDescriptor) >> 8)
+	usbConfigDescriptor[4] = usbInterfaceCount
+	return nil
+}
+
+// addUSBEndpoint allocates the next free endpoint with the given type and
+// direction and returns its number. The endpoint is initialized when the host
+// selects the configuration.
+func addUSBEndpoint(config uint32) (uint32, error) {
+	ep := uint32(len(endPoints))
+	if ep > usb_EPT_NUM {
+		return 0, errUSBNoEndpoint
+	}
+	endPoints = append(endPoints, config)
+	return ep, nil
+}
+
+// usbInterfaceHandler returns the handler of a request addressed to one of
+// the interfaces, or nil if it is not such a request. Standard requests to an
+// interface other than GET_DESCRIPTOR (used for class descriptors) are
+// handled by handleStandardSetup.
+func usbInterfaceHandler(setup usbSetup) func(usbSetup) bool {
+	if setup.bmRequestType&usb_REQUEST_RECIPIENT != usb_REQUEST_INTERFACE {
+		return nil
+	}
+	if setup.bmRequestType&usb_REQUEST_TYPE == usb_REQUEST_STANDARD && setup.bRequest != usb_GET_DESCRIPTOR {
+		return nil
+	}
+	n := uint8(setup.wIndex)
+	if n >= usbInterfaceCount {
+		return nil
 	}
+	return usbSetupHandler[n]
 }
@@ -0,0 +1,221 @@
+// Package hid implements the USB Human Interface Device class on top of the
+// USB device stack of the machine package. It contains a builder for report
+// descriptors and ready-made keyboard, mouse and gamepad devices:
+//
+//	var kb hid.Keyboard
+//
+//	func init() {
+//		hid.Enable(hid.KeyboardDescriptor, hid.MouseDescriptor)
+//	}
+//
+//	func main() {
+//		kb.Write([]byte("hello\n"))
+//	}
+//
+// Enable must be called before the host enumerates the device, for example
+// from an init function. The device is supported on SAMD21, SAMD51 and
+// nRF52840 chips.
+package hid
+
+// Usage pages.
+const (
+	PageGenericDesktop = 0x01
+	PageSimulation     = 0x02
+	PageKeyboard       = 0x07
+	PageLED            = 0x08
+	PageButton         = 0x09
+	PageConsumer       = 0x0c
+)
+
+// Usages of the generic desktop page.
+const (
+	UsagePointer   = 0x01
+	UsageMouse     = 0x02
+	UsageJoystick  = 0x04
+	UsageGamepad   = 0x05
+	UsageKeyboard  = 0x06
+	UsageKeypad    = 0x07
+	UsageX         = 0x30
+	UsageY         = 0x31
+	UsageZ         = 0x32
+	UsageRx        = 0x33
+	UsageRy        = 0x34
+	UsageRz        = 0x35
+	UsageWheel     = 0x38
+	UsageHatSwitch = 0x39
+)
+
+// Collection types.
+const (
+	CollectionPhysical    = 0x00
+	CollectionApplication = 0x01
+	CollectionLogical     = 0x02
+)
+
+// Flags of the Input, Output and Feature items. The zero value describes an
+// array of absolute data values.
+const (
+	Constant      = 1 << 0
+	Variable      = 1 << 1
+	Relative      = 1 << 2
+	Wrap          = 1 << 3
+	NonLinear     = 1 << 4
+	NoPreferred   = 1 << 5
+	NullState     = 1 << 6
+	Volatile      = 1 << 7
+	BufferedBytes = 1 << 8
+)
+
+// Item prefixes, without the size bits.
+const (
+	itemInput         = 0x80
+	itemOutput        = 0x90
+	itemFeature       = 0xb0
+	itemCollection    = 0xa0
+	itemEndCollection = 0xc0
+
+	itemUsagePage       = 0x04
+	itemLogicalMinimum  = 0x14
+	itemLogicalMaximum  = 0x24
+	itemPhysicalMinimum = 0x34
+	itemPhysicalMaximum = 0x44
+	itemUnitExponent    = 0x54
+	itemUnit            = 0x64
+	itemReportSize      = 0x74
+	itemReportID        = 0x84
+	itemReportCount     = 0x94
+
+	itemUsage        = 0x08
+	itemUsageMinimum = 0x18
+	itemUsageMaximum = 0x28
+)
+
+// Descriptor is a HID report descriptor. The methods append an item to the
+// descriptor and return the result, so that a descriptor can be built in a
+// single expression:
+//
+//	desc := hid.Descriptor{}.
+//		UsagePage(hid.PageGenericDesktop).
+//		Usage(hid.UsageMouse).
+//		Collection(hid.CollectionApplication).
+//		...
+//		EndCollection()
+//
+// Values are encoded in the smallest possible size.
+type Descriptor []byte
+
+// item appends a short item with an unsigned value.
+func (d Descriptor) item(prefix byte, value uint32) Descriptor {
+	switch {
+	case value <= 0xff:
+		return append(d, prefix|1, byte(value))
+	case value <= 0xffff:
+		return append(d, prefix|2, byte(value), byte(value>>8))
+	default:
+		return append(d, prefix|3, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+	}
+}
+
+// signedItem appends a short item with a signed value.
+func (d Descriptor) signedItem(prefix byte, value int32) Descriptor {
+	switch {
+	case value >= -0x80 && value < 0x80:
+		return append(d, prefix|1, byte(value))
+	case value >= -0x8000 && value < 0x8000:
+		return append(d, prefix|2, byte(value), byte(value>>8))
+	default:
+		return append(d, prefix|3, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+	}
+}
+
+// UsagePage sets the usage page of the following usages.
+func (d Descriptor) UsagePage(page uint16) Descriptor {
+	return d.item(itemUsagePage, uint32(page))
+}
+
+// Usage adds a usage for the next main item.
+func (d Descriptor) Usage(usage uint16) Descriptor {
+	return d.item(itemUsage, uint32(usage))
+}
+
+// UsageMinimum sets the first of a range of usages for the next main item.
+func (d Descriptor) UsageMinimum(usage uint16) Descriptor {
+	return d.item(itemUsageMinimum, uint32(usage))
+}
+
+// UsageMaximum sets the last of a range of usages for the next main item.
+func (d Descriptor) UsageMaximum(usage uint16) Descriptor {
+	return d.item(itemUsageMaximum, uint32(usage))
+}
+
+// LogicalMinimum sets the smallest value of the following fields.
+func (d Descriptor) LogicalMinimum(min int32) Descriptor {
+	return d.signedItem(itemLogicalMinimum, min)
+}
+
+// LogicalMaximum sets the largest value of the following fields.
+func (d Descriptor) LogicalMaximum(max int32) Descriptor {
+	return d.signedItem(itemLogicalMaximum, max)
+}
+
+// PhysicalMinimum sets the physical value of the logical minimum.
+func (d Descriptor) PhysicalMinimum(min int32) Descriptor {
+	return d.signedItem(itemPhysicalMinimum, min)
+}
+
+// PhysicalMaximum sets the physical value of the logical maximum.
+func (d Descriptor) PhysicalMaximum(max int32) Descriptor {
+	return d.signedItem(itemPhysicalMaximum, max)
+}
+
+// Unit sets the unit of the physical values, for example 0x14 for degrees.
+func (d Descriptor) Unit(unit uint32) Descriptor {
+	return d.item(itemUnit, unit)
+}
+
+// UnitExponent sets the base 10 exponent of the physical values.
+func (d Descriptor) UnitExponent(exp uint8) Descriptor {
+	return d.item(itemUnitExponent, uint32(exp))
+}
+
+// ReportID sets the report ID of the following fields. A report ID is sent
+// as the first byte of every report once it is used in a descriptor.
+func (d Descriptor) ReportID(id uint8) Descriptor {
+	return d.item(itemReportID, uint32(id))
+}
+
+// ReportSize sets the size in bits of the following fields.
+func (d Descriptor) ReportSize(bits uint8) Descriptor {
+	return d.item(itemReportSize, uint32(bits))
+}
+
+// ReportCount sets the number of the following fields.
+func (d Descriptor) ReportCount(count uint8) Descriptor {
+	return d.item(itemReportCount, uint32(count))
+}
+
+// Input adds fields to the input report, which is sent to the host.
+func (d Descriptor) Input(flags uint16) Descriptor {
+	return d.item(itemInput, uint32(flags))
+}
+
+// Output adds fields to the output report, which is received from the host.
+func (d Descriptor) Output(flags uint16) Descriptor {
+	return d.item(itemOutput, uint32(flags))
+}
+
+// Feature adds fields to the feature report.
+func (d Descriptor) Feature(flags uint16) Descriptor {
+	return d.item(itemFeature, uint32(flags))
+}
+
+// Collection starts a collection of the given type, which ends with
+// EndCollection.
+func (d Descriptor) Collection(kind uint8) Descriptor {
+	return d.item(itemCollection, uint32(kind))
+}
+
+// EndCollection ends the last collection.
+func (d Descriptor) EndCollection() Descriptor {
+	return append(d, itemEndCollection)
+}
@@ -0,0 +1,121 @@
+package hid
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDescriptorItems(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		desc Descriptor
+		want []byte
+	}{
+		{"UsagePage", Descriptor{}.UsagePage(PageGenericDesktop), []byte{0x05, 0x01}},
+		{"Usage16", Descriptor{}.Usage(0x0238), []byte{0x0a, 0x38, 0x02}},
+		{"LogicalMinimum", Descriptor{}.LogicalMinimum(-127), []byte{0x15, 0x81}},
+		{"LogicalMaximum8", Descriptor{}.LogicalMaximum(127), []byte{0x25, 0x7f}},
+		{"LogicalMaximum16", Descriptor{}.LogicalMaximum(255), []byte{0x26, 0xff, 0x00}},
+		{"LogicalMinimum16", Descriptor{}.LogicalMinimum(-32768), []byte{0x16, 0x00, 0x80}},
+		{"LogicalMaximum32", Descriptor{}.LogicalMaximum(65535), []byte{0x27, 0xff, 0xff, 0x00, 0x00}},
+		{"Input", Descriptor{}.Input(Variable | Relative), []byte{0x81, 0x06}},
+		{"InputBuffered", Descriptor{}.Input(BufferedBytes), []byte{0x82, 0x00, 0x01}},
+		{"Collection", Descriptor{}.Collection(CollectionApplication).EndCollection(), []byte{0xa1, 0x01, 0xc0}},
+	} {
+		if !bytes.Equal(tc.desc, tc.want) {
+			t.Errorf("%s: got % x, expected % x", tc.name, []byte(tc.desc), tc.want)
+		}
+	}
+}
+
+func TestMouseDescriptor(t *testing.T) {
+	// The mouse descriptor from appendix E.10 of the HID specification,
+	// extended with a report ID, five buttons and a wheel.
+	want := []byte{
+		0x05, 0x01, // Usage Page (Generic Desktop)
+		0x09, 0x02, // Usage (Mouse)
+		0xa1, 0x01, // Collection (Application)
+		0x85, 0x02, //   Report ID (2)
+		0x09, 0x01, //   Usage (Pointer)
+		0xa1, 0x00, //   Collection (Physical)
+		0x05, 0x09, //     Usage Page (Buttons)
+		0x19, 0x01, //     Usage Minimum (1)
+		0x29, 0x05, //     Usage Maximum (5)
+		0x15, 0x00, //     Logical Minimum (0)
+		0x25, 0x01, //     Logical Maximum (1)
+		0x75, 0x01, //     Report Size (1)
+		0x95, 0x05, //     Report Count (5)
+		0x81, 0x02, //     Input (Data, Variable, Absolute)
+		0x75, 0x03, //     Report Size (3)
+		0x95, 0x01, //     Report Count (1)
+		0x81, 0x01, //     Input (Constant)
+		0x05, 0x01, //     Usage Page (Generic Desktop)
+		0x09, 0x30, //     Usage (X)
+		0x09, 0x31, //     Usage (Y)
+		0x09, 0x38, //     Usage (Wheel)
+		0x15, 0x81, //     Logical Minimum (-127)
+		0x25, 0x7f, //     Logical Maximum (127)
+		0x75, 0x08, //     Report Size (8)
+		0x95, 0x03, //     Report Count (3)
+		0x81, 0x06, //     Input (Data, Variable, Relative)
+		0xc0, //   End Collection
+		0xc0, // End Collection
+	}
+	if !bytes.Equal(MouseDescriptor, want) {
+		t.Errorf("mouse descriptor:\ngot      % x\nexpected % x", []byte(MouseDescriptor), want)
+	}
+}
+
+// reportBits returns the total size in bits of the input report with the
+// given ID, by interpreting the Report Size, Report Count, Report ID and
+// Input items of a descriptor.
+func reportBits(t *testing.T, desc []byte, id uint8) int {
+	var size, count, current uint32
+	bits := 0
+	for i := 0; i < len(desc); {
+		prefix := desc[i]
+		n := int(prefix & 3)
+		if n == 3 {
+			n = 4
+		}
+		if i+1+n > len(desc) {
+			t.Fatalf("truncated item at offset %d", i)
+		}
+		var value uint32
+		for j := 0; j < n; j++ {
+			value |= uint32(desc[i+1+j]) << (8 * j)
+		}
+		switch prefix &^ 3 {
+		case itemReportSize:
+			size = value
+		case itemReportCount:
+			count = value
+		case itemReportID:
+			current = value
+		case itemInput:
+			if current == uint32(id) {
+				bits += int(size * count)
+			}
+		}
+		i += 1 + n
+	}
+	return bits
+}
+
+func TestReportSizes(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		desc Descriptor
+		id   uint8
+		size int
+	}{
+		{"keyboard", KeyboardDescriptor, KeyboardReportID, keyboardReportSize},
+		{"mouse", MouseDescriptor, MouseReportID, mouseReportSize},
+		{"gamepad", GamepadDescriptor, GamepadReportID, gamepadReportSize},
+	} {
+		// The report ID takes the first byte of the report.
+		if bits := reportBits(t, tc.desc, tc.id); bits != (tc.size-1)*8 {
+			t.Errorf("%s: descriptor has %d bits, report has %d bytes", tc.name, bits, tc.size)
+		}
+	}
+}
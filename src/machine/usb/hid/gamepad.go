@@ -0,0 +1,128 @@
+package hid
+
+// Hat switch directions, clockwise starting at the top.
+const (
+	HatUp = iota
+	HatUpRight
+	HatRight
+	HatDownRight
+	HatDown
+	HatDownLeft
+	HatLeft
+	HatUpLeft
+	HatCentered // null state: the hat is not pressed
+)
+
+// Gamepad axes.
+const (
+	AxisX = iota
+	AxisY
+	AxisZ
+	AxisRz
+	gamepadAxes
+)
+
+// GamepadReportID is the report ID used by GamepadDescriptor.
+const GamepadReportID = 3
+
+// gamepadReportSize is the size of a gamepad report: the report ID, 16
+// buttons, the hat switch and four axes.
+const gamepadReportSize = 4 + gamepadAxes
+
+// GamepadDescriptor describes a gamepad with 16 buttons, a hat switch and
+// four axes.
+var GamepadDescriptor = Descriptor{}.
+	UsagePage(PageGenericDesktop).
+	Usage(UsageGamepad).
+	Collection(CollectionApplication).
+	ReportID(GamepadReportID).
+	// Buttons
+	UsagePage(PageButton).
+	UsageMinimum(1).
+	UsageMaximum(16).
+	LogicalMinimum(0).
+	LogicalMaximum(1).
+	ReportSize(1).
+	ReportCount(16).
+	Input(Variable).
+	// Hat switch
+	UsagePage(PageGenericDesktop).
+	Usage(UsageHatSwitch).
+	LogicalMinimum(0).
+	LogicalMaximum(7).
+	PhysicalMinimum(0).
+	PhysicalMaximum(315).
+	Unit(0x14). // degrees
+	ReportSize(4).
+	ReportCount(1).
+	Input(Variable | NullState).
+	Unit(0).
+	ReportCount(1).
+	Input(Constant).
+	// Axes
+	Usage(UsageX).
+	Usage(UsageY).
+	Usage(UsageZ).
+	Usage(UsageRz).
+	LogicalMinimum(-127).
+	LogicalMaximum(127).
+	ReportSize(8).
+	ReportCount(gamepadAxes).
+	Input(Variable).
+	EndCollection()
+
+// Gamepad is a gamepad that sends reports as described by GamepadDescriptor.
+// The state is changed with SetButton, SetHat and SetAxis and sent to the
+// host with Send. The zero value is ready to use, with the hat centered.
+type Gamepad struct {
+	buttons uint16
+	hat     uint8 // direction + 1, so that the zero value is centered
+	axes    [gamepadAxes]int8
+}
+
+// SetButton changes the state of a button, numbered from 0 to 15.
+func (g *Gamepad) SetButton(n int, pressed bool) {
+	if n < 0 || n >= 16 {
+		return
+	}
+	if pressed {
+		g.buttons |= 1 << n
+	} else {
+		g.buttons &^= 1 << n
+	}
+}
+
+// SetHat changes the direction of the hat switch, such as HatUp or
+// HatCentered.
+func (g *Gamepad) SetHat(direction int) {
+	if direction < HatUp || direction >= HatCentered {
+		g.hat = 0
+		return
+	}
+	g.hat = uint8(direction) + 1
+}
+
+// SetAxis changes the position of an axis, such as AxisX.
+func (g *Gamepad) SetAxis(axis int, value int8) {
+	if axis < 0 || axis >= gamepadAxes {
+		return
+	}
+	if value == -128 {
+		// Outside of the logical range.
+		value = -127
+	}
+	g.axes[axis] = value
+}
+
+// report returns the input report with the current state.
+func (g *Gamepad) report() [gamepadReportSize]byte {
+	hat := uint8(HatCentered)
+	if g.hat != 0 {
+		hat = g.hat - 1
+	}
+	b := [gamepadReportSize]byte{GamepadReportID, byte(g.buttons), byte(g.buttons >> 8), hat}
+	for i, value := range g.axes {
+		b[4+i] = byte(value)
+	}
+	return b
+}
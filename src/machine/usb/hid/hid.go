@@ -0,0 +1,130 @@
+//go:build sam || nrf52840
+// +build sam nrf52840
+
+package hid
+
+import (
+	"errors"
+	"machine"
+	"runtime"
+	"runtime/volatile"
+)
+
+var (
+	ErrNotConfigured = errors.New("hid: USB device not configured by host")
+	ErrTooManyKeys   = errors.New("hid: too many keys pressed")
+	ErrUnknownChar   = errors.New("hid: character cannot be typed")
+)
+
+// sending is set while a report is being transferred to the host.
+var sending volatile.Register8
+
+// Enable adds a HID interface to the USB device, with a report descriptor
+// made of the given descriptors. Each descriptor must use a different report
+// ID, such as KeyboardDescriptor and MouseDescriptor.
+func Enable(descriptors ...[]byte) error {
+	var desc []byte
+	for _, d := range descriptors {
+		desc = append(desc, d...)
+	}
+	return machine.EnableHID(desc, txHandler)
+}
+
+func txHandler() {
+	sending.Set(0)
+}
+
+// SendReport sends an input report to the host, starting with the report ID.
+// It waits until the previous report has been transferred.
+func SendReport(report []byte) error {
+	for sending.Get() != 0 {
+		runtime.Gosched()
+	}
+	sending.Set(1)
+	if !machine.SendUSBHIDPacket(report) {
+		sending.Set(0)
+		return ErrNotConfigured
+	}
+	return nil
+}
+
+func (k *Keyboard) send() error {
+	report := k.report()
+	return SendReport(report[:])
+}
+
+// Press presses a key and sends the new state to the host.
+func (k *Keyboard) Press(key Keycode) error {
+	if !k.press(key) {
+		return ErrTooManyKeys
+	}
+	return k.send()
+}
+
+// Release releases a key and sends the new state to the host.
+func (k *Keyboard) Release(key Keycode) error {
+	k.release(key)
+	return k.send()
+}
+
+// ReleaseAll releases all keys and sends the new state to the host.
+func (k *Keyboard) ReleaseAll() error {
+	k.releaseAll()
+	return k.send()
+}
+
+// Write types the given text, using a US keyboard layout. Keys that were
+// pressed before are released.
+func (k *Keyboard) Write(p []byte) (n int, err error) {
+	for _, c := range p {
+		key, shift, ok := asciiKey(c)
+		if !ok {
+			return n, ErrUnknownChar
+		}
+		k.releaseAll()
+		if shift {
+			k.press(KeyLeftShift)
+		}
+		k.press(key)
+		if err := k.send(); err != nil {
+			return n, err
+		}
+		if err := k.ReleaseAll(); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Move moves the mouse and scrolls the wheel by the given amounts.
+func (m *Mouse) Move(dx, dy, wheel int8) error {
+	report := m.report(dx, dy, wheel)
+	return SendReport(report[:])
+}
+
+// Press presses the given buttons, such as MouseLeft.
+func (m *Mouse) Press(buttons uint8) error {
+	m.buttons |= buttons
+	return m.Move(0, 0, 0)
+}
+
+// Release releases the given buttons.
+func (m *Mouse) Release(buttons uint8) error {
+	m.buttons &^= buttons
+	return m.Move(0, 0, 0)
+}
+
+// Click presses and releases the given buttons.
+func (m *Mouse) Click(buttons uint8) error {
+	if err := m.Press(buttons); err != nil {
+		return err
+	}
+	return m.Release(buttons)
+}
+
+// Send sends the current state to the host.
+func (g *Gamepad) Send() error {
+	report := g.report()
+	return SendReport(report[:])
+}
@@ -0,0 +1,267 @@
+package hid
+
+// Keycode is a usage of the keyboard page. The modifier keys, from
+// KeyLeftCtrl to KeyRightGUI, are sent as bits in the modifier byte of the
+// report.
+type Keycode uint8
+
+// Keycodes, as defined in the HID usage tables.
+const (
+	KeyA Keycode = 0x04 + iota
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+	Key0
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeySpace
+	KeyMinus
+	KeyEqual
+	KeyLeftBrace
+	KeyRightBrace
+	KeyBackslash
+	KeyNonUSHash
+	KeySemicolon
+	KeyApostrophe
+	KeyGrave
+	KeyComma
+	KeyPeriod
+	KeySlash
+	KeyCapsLock
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyPrintScreen
+	KeyScrollLock
+	KeyPause
+	KeyInsert
+	KeyHome
+	KeyPageUp
+	KeyDelete
+	KeyEnd
+	KeyPageDown
+	KeyRight
+	KeyLeft
+	KeyDown
+	KeyUp
+)
+
+// Modifier keys.
+const (
+	KeyLeftCtrl Keycode = 0xe0 + iota
+	KeyLeftShift
+	KeyLeftAlt
+	KeyLeftGUI
+	KeyRightCtrl
+	KeyRightShift
+	KeyRightAlt
+	KeyRightGUI
+)
+
+// KeyboardReportID is the report ID used by KeyboardDescriptor.
+const KeyboardReportID = 1
+
+// keyboardReportSize is the size of a keyboard report: the report ID, the
+// modifier byte, a reserved byte and six keys.
+const keyboardReportSize = 9
+
+// KeyboardDescriptor describes a boot protocol compatible keyboard with six
+// key rollover.
+var KeyboardDescriptor = Descriptor{}.
+	UsagePage(PageGenericDesktop).
+	Usage(UsageKeyboard).
+	Collection(CollectionApplication).
+	ReportID(KeyboardReportID).
+	// Modifier keys
+	UsagePage(PageKeyboard).
+	UsageMinimum(uint16(KeyLeftCtrl)).
+	UsageMaximum(uint16(KeyRightGUI)).
+	LogicalMinimum(0).
+	LogicalMaximum(1).
+	ReportSize(1).
+	ReportCount(8).
+	Input(Variable).
+	// Reserved byte
+	ReportSize(8).
+	ReportCount(1).
+	Input(Constant).
+	// Keys
+	UsageMinimum(0).
+	UsageMaximum(0xff).
+	LogicalMinimum(0).
+	LogicalMaximum(0xff).
+	ReportSize(8).
+	ReportCount(6).
+	Input(0).
+	EndCollection()
+
+// Keyboard is a keyboard that sends reports as described by
+// KeyboardDescriptor. The zero value is ready to use.
+type Keyboard struct {
+	modifiers uint8
+	keys      [6]Keycode
+}
+
+// press adds a key to the pressed keys. It returns false if six keys are
+// already pressed.
+func (k *Keyboard) press(key Keycode) bool {
+	if key >= KeyLeftCtrl && key <= KeyRightGUI {
+		k.modifiers |= 1 << (key - KeyLeftCtrl)
+		return true
+	}
+	free := -1
+	for i, pressed := range k.keys {
+		if pressed == key {
+			return true
+		}
+		if pressed == 0 && free < 0 {
+			free = i
+		}
+	}
+	if free < 0 {
+		return false
+	}
+	k.keys[free] = key
+	return true
+}
+
+// release removes a key from the pressed keys.
+func (k *Keyboard) release(key Keycode) {
+	if key >= KeyLeftCtrl && key <= KeyRightGUI {
+		k.modifiers &^= 1 << (key - KeyLeftCtrl)
+		return
+	}
+	for i, pressed := range k.keys {
+		if pressed == key {
+			k.keys[i] = 0
+		}
+	}
+}
+
+// releaseAll releases all keys, including the modifier keys.
+func (k *Keyboard) releaseAll() {
+	*k = Keyboard{}
+}
+
+// report returns the input report with the currently pressed keys.
+func (k *Keyboard) report() [keyboardReportSize]byte {
+	b := [keyboardReportSize]byte{KeyboardReportID, k.modifiers}
+	for i, key := range k.keys {
+		b[3+i] = byte(key)
+	}
+	return b
+}
+
+// asciiKey returns the key that types the given character on a US keyboard
+// layout and whether shift must be pressed with it.
+func asciiKey(c byte) (key Keycode, shift bool, ok bool) {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return KeyA + Keycode(c-'a'), false, true
+	case c >= 'A' && c <= 'Z':
+		return KeyA + Keycode(c-'A'), true, true
+	case c == '0':
+		return Key0, false, true
+	case c >= '1' && c <= '9':
+		return Key1 + Keycode(c-'1'), false, true
+	}
+	switch c {
+	case '\n':
+		return KeyEnter, false, true
+	case '\b':
+		return KeyBackspace, false, true
+	case '\t':
+		return KeyTab, false, true
+	case 0x1b:
+		return KeyEscape, false, true
+	case 0x7f:
+		return KeyDelete, false, true
+	case ' ':
+		return KeySpace, false, true
+	case '!':
+		return Key1, true, true
+	case '@':
+		return Key2, true, true
+	case '#':
+		return Key3, true, true
+	case '$':
+		return Key4, true, true
+	case '%':
+		return Key5, true, true
+	case '^':
+		return Key6, true, true
+	case '&':
+		return Key7, true, true
+	case '*':
+		return Key8, true, true
+	case '(':
+		return Key9, true, true
+	case ')':
+		return Key0, true, true
+	case '-', '_':
+		return KeyMinus, c == '_', true
+	case '=', '+':
+		return KeyEqual, c == '+', true
+	case '[', '{':
+		return KeyLeftBrace, c == '{', true
+	case ']', '}':
+		return KeyRightBrace, c == '}', true
+	case '\\', '|':
+		return KeyBackslash, c == '|', true
+	case ';', ':':
+		return KeySemicolon, c == ':', true
+	case '\'', '"':
+		return KeyApostrophe, c == '"', true
+	case '`', '~':
+		return KeyGrave, c == '~', true
+	case ',', '<':
+		return KeyComma, c == '<', true
+	case '.', '>':
+		return KeyPeriod, c == '>', true
+	case '/', '?':
+		return KeySlash, c == '?', true
+	}
+	return 0, false, false
+}
@@ -0,0 +1,62 @@
+package hid
+
+// Mouse buttons.
+const (
+	MouseLeft    = 1 << 0
+	MouseRight   = 1 << 1
+	MouseMiddle  = 1 << 2
+	MouseBack    = 1 << 3
+	MouseForward = 1 << 4
+)
+
+// MouseReportID is the report ID used by MouseDescriptor.
+const MouseReportID = 2
+
+// mouseReportSize is the size of a mouse report: the report ID, the buttons,
+// the movement along the X and Y axes and the wheel movement.
+const mouseReportSize = 5
+
+// MouseDescriptor describes a mouse with five buttons and a wheel.
+var MouseDescriptor = Descriptor{}.
+	UsagePage(PageGenericDesktop).
+	Usage(UsageMouse).
+	Collection(CollectionApplication).
+	ReportID(MouseReportID).
+	Usage(UsagePointer).
+	Collection(CollectionPhysical).
+	// Buttons
+	UsagePage(PageButton).
+	UsageMinimum(1).
+	UsageMaximum(5).
+	LogicalMinimum(0).
+	LogicalMaximum(1).
+	ReportSize(1).
+	ReportCount(5).
+	Input(Variable).
+	ReportSize(3).
+	ReportCount(1).
+	Input(Constant).
+	// Movement
+	UsagePage(PageGenericDesktop).
+	Usage(UsageX).
+	Usage(UsageY).
+	Usage(UsageWheel).
+	LogicalMinimum(-127).
+	LogicalMaximum(127).
+	ReportSize(8).
+	ReportCount(3).
+	Input(Variable | Relative).
+	EndCollection().
+	EndCollection()
+
+// Mouse is a mouse that sends reports as described by MouseDescriptor. The
+// zero value is ready to use.
+type Mouse struct {
+	buttons uint8
+}
+
+// report returns the input report with the currently pressed buttons and the
+// given movement.
+func (m *Mouse) report(dx, dy, wheel int8) [mouseReportSize]byte {
+	return [mouseReportSize]byte{MouseReportID, m.buttons, byte(dx), byte(dy), byte(wheel)}
+}
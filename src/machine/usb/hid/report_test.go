@@ -0,0 +1,101 @@
+package hid
+
+import "testing"
+
+func TestKeyboardReport(t *testing.T) {
+	var k Keyboard
+	k.press(KeyLeftShift)
+	k.press(KeyA)
+	k.press(KeyB)
+	k.press(KeyA) // already pressed
+	want := [keyboardReportSize]byte{KeyboardReportID, 0x02, 0, byte(KeyA), byte(KeyB)}
+	if got := k.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+
+	k.release(KeyA)
+	k.release(KeyLeftShift)
+	want = [keyboardReportSize]byte{KeyboardReportID, 0, 0, 0, byte(KeyB)}
+	if got := k.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+
+	for key := KeyC; key < KeyC+5; key++ {
+		if !k.press(key) {
+			t.Errorf("could not press key %d", key)
+		}
+	}
+	if k.press(KeyZ) {
+		t.Error("pressed a seventh key")
+	}
+
+	k.releaseAll()
+	want = [keyboardReportSize]byte{KeyboardReportID}
+	if got := k.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+}
+
+func TestASCIIKey(t *testing.T) {
+	for _, tc := range []struct {
+		c     byte
+		key   Keycode
+		shift bool
+	}{
+		{'a', KeyA, false},
+		{'Z', KeyZ, true},
+		{'0', Key0, false},
+		{'1', Key1, false},
+		{'!', Key1, true},
+		{')', Key0, true},
+		{'\n', KeyEnter, false},
+		{' ', KeySpace, false},
+		{'?', KeySlash, true},
+		{'~', KeyGrave, true},
+		{'"', KeyApostrophe, true},
+	} {
+		key, shift, ok := asciiKey(tc.c)
+		if !ok || key != tc.key || shift != tc.shift {
+			t.Errorf("%q: got key %#x shift %v ok %v, expected key %#x shift %v", tc.c, key, shift, ok, tc.key, tc.shift)
+		}
+	}
+	for _, c := range []byte{0, 0x80, 0xff} {
+		if _, _, ok := asciiKey(c); ok {
+			t.Errorf("%#x: expected no key", c)
+		}
+	}
+}
+
+func TestMouseReport(t *testing.T) {
+	m := Mouse{buttons: MouseLeft | MouseMiddle}
+	want := [mouseReportSize]byte{MouseReportID, 0x05, 0xff, 0x10, 0x01}
+	if got := m.report(-1, 16, 1); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+}
+
+func TestGamepadReport(t *testing.T) {
+	var g Gamepad
+	want := [gamepadReportSize]byte{GamepadReportID, 0, 0, HatCentered}
+	if got := g.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+
+	g.SetButton(0, true)
+	g.SetButton(9, true)
+	g.SetButton(16, true) // out of range
+	g.SetHat(HatLeft)
+	g.SetAxis(AxisX, -128)
+	g.SetAxis(AxisRz, 100)
+	want = [gamepadReportSize]byte{GamepadReportID, 0x01, 0x02, HatLeft, 0x81, 0, 0, 100}
+	if got := g.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+
+	g.SetButton(0, false)
+	g.SetHat(HatCentered)
+	want = [gamepadReportSize]byte{GamepadReportID, 0, 0x02, HatCentered, 0x81, 0, 0, 100}
+	if got := g.report(); got != want {
+		t.Errorf("got report % x, expected % x", got, want)
+	}
+}
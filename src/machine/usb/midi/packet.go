@@ -0,0 +1,130 @@
+// Package midi implements the event packets of the USB MIDI 1.0 class, which
+// carry MIDI messages over the bulk endpoints of a USB MIDI function. It is
+// used by machine.USBMIDI and doesn't depend on any hardware, so that it can
+// be tested on the host.
+//
+// Each event packet is 4 bytes long. The first byte holds the cable number in
+// the upper 4 bits and the code index number (CIN) in the lower 4 bits. The CIN
+// tells the kind of MIDI message and thus how many of the following 3 bytes
+// are used.
+package midi
+
+// PacketSize is the size of a USB MIDI event packet.
+const PacketSize = 4
+
+// Code index numbers.
+const (
+	CINMisc            = 0x0 // reserved for future extensions
+	CINCableEvent      = 0x1 // reserved for future extensions
+	CINSystemCommon2   = 0x2 // two-byte system common message, like MTC
+	CINSystemCommon3   = 0x3 // three-byte system common message, like SPP
+	CINSysExStart      = 0x4 // system exclusive message starts or continues
+	CINSysExEnd1       = 0x5 // single-byte system common message, or SysEx ends with one byte
+	CINSysExEnd2       = 0x6 // system exclusive message ends with two bytes
+	CINSysExEnd3       = 0x7 // system exclusive message ends with three bytes
+	CINNoteOff         = 0x8
+	CINNoteOn          = 0x9
+	CINPolyKeyPress    = 0xa
+	CINControlChange   = 0xb
+	CINProgramChange   = 0xc
+	CINChannelPressure = 0xd
+	CINPitchBend       = 0xe
+	CINSingleByte      = 0xf // single byte, like a system real-time message
+)
+
+// cinLength holds the number of MIDI bytes in an event packet, indexed by its
+// code index number. The reserved CINs carry no data that can be passed on.
+var cinLength = [16]uint8{0, 0, 2, 3, 3, 1, 2, 3, 3, 3, 3, 3, 2, 2, 3, 1}
+
+// Packet is a USB MIDI event packet.
+type Packet [PacketSize]byte
+
+// Cable returns the virtual cable number of the packet, which selects the
+// embedded jack the message belongs to.
+func (p *Packet) Cable() uint8 {
+	return p[0] >> 4
+}
+
+// CIN returns the code index number of the packet.
+func (p *Packet) CIN() uint8 {
+	return p[0] & 0x0f
+}
+
+// Message returns the MIDI bytes in the packet. For a packet that is part of a
+// system exclusive message, this is only the part in this packet.
+func (p *Packet) Message() []byte {
+	return p[1 : 1+cinLength[p.CIN()]]
+}
+
+// Encode stores the start of a MIDI message in the packet, addressed to the
+// given cable. The message may also be the remainder of a system exclusive
+// message, which must end with 0xF7. Running status is not supported. It
+// returns the number of message bytes in the packet, or 0 if the message is
+// not valid: call Encode again with the rest of the message until all of it
+// has been encoded.
+func (p *Packet) Encode(cable uint8, msg []byte) int {
+	if len(msg) == 0 || cable > 0x0f {
+		return 0
+	}
+	var cin, size uint8
+	status := msg[0]
+	switch {
+	case status == 0xf0 || status == 0xf7 || status < 0x80:
+		// System exclusive message, or the continuation of one.
+		if msg[len(msg)-1] != 0xf7 {
+			return 0
+		}
+		if len(msg) > 3 {
+			cin, size = CINSysExStart, 3
+		} else {
+			cin, size = CINSysExEnd1+uint8(len(msg))-1, uint8(len(msg))
+		}
+	case status < 0xf0:
+		// Channel voice message, the CIN is the upper nibble of the status.
+		cin = status >> 4
+		size = cinLength[cin]
+	case status == 0xf1 || status == 0xf3:
+		cin, size = CINSystemCommon2, 2
+	case status == 0xf2:
+		cin, size = CINSystemCommon3, 3
+	case status == 0xf6:
+		cin, size = CINSysExEnd1, 1
+	case status >= 0xf8:
+		// System real-time message
+		cin, size = CINSingleByte, 1
+	default:
+		// Undefined status bytes 0xF4 and 0xF5.
+		return 0
+	}
+	if len(msg) < int(size) {
+		return 0
+	}
+	p[0] = cable<<4 | cin
+	p[1], p[2], p[3] = 0, 0, 0
+	copy(p[1:], msg[:size])
+	return int(size)
+}
+
+// Buffer receives the MIDI bytes of decoded packets, such as a
+// machine.RingBuffer.
+type Buffer interface {
+	Put(c byte) bool
+}
+
+// Receive decodes the event packets in data, as received from the bulk OUT
+// endpoint, and stores the MIDI bytes of the packets for the given cable in
+// buf. Packets for other cables and an incomplete packet at the end of data
+// are ignored.
+func Receive(buf Buffer, data []byte, cable uint8) {
+	for len(data) >= PacketSize {
+		var p Packet
+		copy(p[:], data)
+		data = data[PacketSize:]
+		if p.Cable() != cable {
+			continue
+		}
+		for _, c := range p.Message() {
+			buf.Put(c)
+		}
+	}
+}
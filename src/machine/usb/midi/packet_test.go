@@ -0,0 +1,140 @@
+package midi
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cable  uint8
+		msg    []byte
+		packet Packet
+		size   int
+	}{
+		{"note off", 0, []byte{0x80, 60, 0}, Packet{0x08, 0x80, 60, 0}, 3},
+		{"note on", 0, []byte{0x93, 60, 100}, Packet{0x09, 0x93, 60, 100}, 3},
+		{"poly key pressure", 0, []byte{0xa0, 60, 10}, Packet{0x0a, 0xa0, 60, 10}, 3},
+		{"control change", 0, []byte{0xb1, 7, 127}, Packet{0x0b, 0xb1, 7, 127}, 3},
+		{"program change", 0, []byte{0xc2, 5}, Packet{0x0c, 0xc2, 5, 0}, 2},
+		{"channel pressure", 0, []byte{0xd0, 64}, Packet{0x0d, 0xd0, 64, 0}, 2},
+		{"pitch bend", 0, []byte{0xe0, 0, 64}, Packet{0x0e, 0xe0, 0, 64}, 3},
+		{"other cable", 5, []byte{0x90, 60, 100}, Packet{0x59, 0x90, 60, 100}, 3},
+		{"highest cable", 15, []byte{0xf8}, Packet{0xff, 0xf8, 0, 0}, 1},
+		{"time code", 0, []byte{0xf1, 0x12}, Packet{0x02, 0xf1, 0x12, 0}, 2},
+		{"song position", 0, []byte{0xf2, 1, 2}, Packet{0x03, 0xf2, 1, 2}, 3},
+		{"song select", 0, []byte{0xf3, 3}, Packet{0x02, 0xf3, 3, 0}, 2},
+		{"tune request", 0, []byte{0xf6}, Packet{0x05, 0xf6, 0, 0}, 1},
+		{"timing clock", 0, []byte{0xf8}, Packet{0x0f, 0xf8, 0, 0}, 1},
+		{"active sensing", 0, []byte{0xfe}, Packet{0x0f, 0xfe, 0, 0}, 1},
+		{"only extra bytes used", 0, []byte{0xc0, 1, 2, 3}, Packet{0x0c, 0xc0, 1, 0}, 2},
+		{"sysex start", 0, []byte{0xf0, 1, 2, 3, 0xf7}, Packet{0x04, 0xf0, 1, 2}, 3},
+		{"sysex of two bytes", 0, []byte{0xf0, 0xf7}, Packet{0x06, 0xf0, 0xf7, 0}, 2},
+		{"sysex of three bytes", 0, []byte{0xf0, 1, 0xf7}, Packet{0x07, 0xf0, 1, 0xf7}, 3},
+		{"sysex ends with one byte", 0, []byte{0xf7}, Packet{0x05, 0xf7, 0, 0}, 1},
+		{"sysex ends with two bytes", 0, []byte{1, 0xf7}, Packet{0x06, 1, 0xf7, 0}, 2},
+		{"sysex continues", 0, []byte{1, 2, 3, 4, 0xf7}, Packet{0x04, 1, 2, 3}, 3},
+		{"empty message", 0, nil, Packet{}, 0},
+		{"truncated note on", 0, []byte{0x90, 60}, Packet{}, 0},
+		{"unterminated sysex", 0, []byte{0xf0, 1, 2}, Packet{}, 0},
+		{"undefined status", 0, []byte{0xf4}, Packet{}, 0},
+		{"invalid cable", 16, []byte{0xf8}, Packet{}, 0},
+	} {
+		var p Packet
+		size := p.Encode(tc.cable, tc.msg)
+		if size != tc.size {
+			t.Errorf("%s: encoded %d bytes, expected %d", tc.name, size, tc.size)
+		}
+		if p != tc.packet {
+			t.Errorf("%s: got packet % x, expected % x", tc.name, p, tc.packet)
+		}
+	}
+}
+
+// Encoding a message and decoding the packets must return the same message.
+func TestEncodeMessage(t *testing.T) {
+	for _, msg := range [][]byte{
+		{0x90, 60, 100},
+		{0xf0, 0xf7},
+		{0xf0, 0x7e, 0x7f, 0x06, 0x01, 0xf7},
+		{0xf0, 0x41, 0x10, 0x42, 0x12, 0x40, 0x00, 0x7f, 0x00, 0x41, 0xf7},
+	} {
+		var data []byte
+		rest := msg
+		for len(rest) != 0 {
+			var p Packet
+			size := p.Encode(3, rest)
+			if size == 0 {
+				t.Fatalf("% x: could not encode % x", msg, rest)
+			}
+			rest = rest[size:]
+			data = append(data, p[:]...)
+		}
+		var buf sliceBuffer
+		Receive(&buf, data, 3)
+		if !bytes.Equal(buf, msg) {
+			t.Errorf("% x: decoded as % x", msg, []byte(buf))
+		}
+	}
+}
+
+func TestPacket(t *testing.T) {
+	for _, tc := range []struct {
+		packet Packet
+		cable  uint8
+		cin    uint8
+		msg    []byte
+	}{
+		{Packet{0x09, 0x90, 60, 100}, 0, CINNoteOn, []byte{0x90, 60, 100}},
+		{Packet{0x2c, 0xc0, 5, 0}, 2, CINProgramChange, []byte{0xc0, 5}},
+		{Packet{0xf5, 0xf7, 0, 0}, 15, CINSysExEnd1, []byte{0xf7}},
+		{Packet{0x0f, 0xfa, 0, 0}, 0, CINSingleByte, []byte{0xfa}},
+		{Packet{0x00, 1, 2, 3}, 0, CINMisc, []byte{}},
+		{Packet{0x01, 1, 2, 3}, 0, CINCableEvent, []byte{}},
+	} {
+		p := tc.packet
+		if cable := p.Cable(); cable != tc.cable {
+			t.Errorf("% x: got cable %d, expected %d", p, cable, tc.cable)
+		}
+		if cin := p.CIN(); cin != tc.cin {
+			t.Errorf("% x: got CIN %#x, expected %#x", p, cin, tc.cin)
+		}
+		if msg := p.Message(); !bytes.Equal(msg, tc.msg) {
+			t.Errorf("% x: got message % x, expected % x", p, msg, tc.msg)
+		}
+	}
+}
+
+func TestReceive(t *testing.T) {
+	data := []byte{
+		0x09, 0x90, 60, 100, // note on, cable 0
+		0x19, 0x91, 61, 101, // note on, cable 1
+		0x0f, 0xf8, 0, 0, // timing clock, cable 0
+		0x00, 1, 2, 3, // reserved CIN
+		0x0c, 0xc0, 5, 0, // program change, cable 0
+		0x09, 0x90, // incomplete packet
+	}
+	for _, tc := range []struct {
+		cable uint8
+		want  []byte
+	}{
+		{0, []byte{0x90, 60, 100, 0xf8, 0xc0, 5}},
+		{1, []byte{0x91, 61, 101}},
+		{2, nil},
+	} {
+		var buf sliceBuffer
+		Receive(&buf, data, tc.cable)
+		if !bytes.Equal(buf, tc.want) {
+			t.Errorf("cable %d: received % x, expected % x", tc.cable, []byte(buf), tc.want)
+		}
+	}
+}
+
+// sliceBuffer is a Buffer that stores all bytes in a slice.
+type sliceBuffer []byte
+
+func (b *sliceBuffer) Put(c byte) bool {
+	*b = append(*b, c)
+	return true
+}
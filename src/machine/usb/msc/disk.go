@@ -0,0 +1,321 @@
+// Package msc implements the USB Mass Storage class on top of the USB device
+// stack of the machine package, so that a block device appears as a USB drive
+// on the host:
+//
+//	func init() {
+//		msc.Enable(machine.Flash)
+//	}
+//
+// The device uses the bulk-only transport and supports the subset of SCSI
+// commands that is needed by common operating systems. Enable must be called
+// before the host enumerates the device, for example from an init function.
+// The device is supported on SAMD21, SAMD51 and nRF52840 chips.
+package msc
+
+// blockDevice holds the methods of machine.BlockDevice that are used by the
+// disk. It is declared here so that the disk can be tested on the host. As with
+// machine.BlockDevice, blocks must be erased before they are written: the disk
+// reads, erases and writes back whole erase blocks. The methods are called
+// from the USB interrupt.
+type blockDevice interface {
+	ReadAt(p []byte, off int64) (n int, err error)
+	WriteAt(p []byte, off int64) (n int, err error)
+	Size() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// blockSize is the size of the logical blocks as seen by the host.
+const blockSize = 512
+
+// packetSize is the size of the bulk endpoint packets.
+const packetSize = 64
+
+// Command block wrapper (CBW) and command status wrapper (CSW) of the
+// bulk-only transport.
+const (
+	cbwSignature = 0x43425355 // "USBC"
+	cbwSize      = 31
+	cbwFlagIn    = 0x80
+
+	cswSignature = 0x53425355 // "USBS"
+	cswSize      = 13
+
+	statusPassed     = 0
+	statusFailed     = 1
+	statusPhaseError = 2
+)
+
+// States of the bulk-only transport.
+const (
+	stateCommand = iota // waiting for a CBW
+	stateDataIn         // sending data to the host
+	stateDataOut        // receiving data from the host
+	stateStatus         // sending the CSW
+)
+
+// disk implements the bulk-only transport and the SCSI commands for a block
+// device. The host sends packets to handleOut, packets are sent to the host
+// with send and handleIn is called once such a packet has been transferred.
+type disk struct {
+	dev    blockDevice
+	send   func([]byte) bool
+	blocks uint32
+
+	state  uint8
+	tag    uint32
+	length uint32 // dCBWDataTransferLength
+	in     bool   // direction of the data stage as indicated by the host
+
+	// Data stage
+	remaining uint32 // bytes of the data stage left to transfer
+	residue   uint32 // bytes of the data stage that do not carry data
+	status    uint8
+	data      []byte // response data, sent before the device data
+	devBytes  uint32 // bytes left to read from or write to the device
+	offset    int64  // device offset of the next read or write
+	fill      int    // bytes in sector
+
+	// Sense data of the last failed command
+	senseKey uint8
+	asc      uint8
+
+	packet   [packetSize]byte
+	response [36]byte
+	sector   [blockSize]byte
+
+	// Erase blocks larger than a logical block are modified in this buffer
+	// and written back once a write moves to another erase block or the
+	// command ends. It is nil if sectors can be erased on their own.
+	eraseBlock []byte
+	eraseIndex int64 // erase block in eraseBlock, or -1
+	eraseDirty bool
+}
+
+func newDisk(dev blockDevice, send func([]byte) bool) *disk {
+	d := &disk{
+		dev:        dev,
+		send:       send,
+		blocks:     uint32(dev.Size() / blockSize),
+		eraseIndex: -1,
+	}
+	if size := dev.EraseBlockSize(); size > blockSize {
+		d.eraseBlock = make([]byte, size)
+	}
+	return d
+}
+
+// handleOut handles a packet received from the host.
+func (d *disk) handleOut(p []byte) {
+	switch d.state {
+	case stateCommand:
+		d.command(p)
+	case stateDataOut:
+		d.receiveData(p)
+	}
+}
+
+// handleIn continues the transfer after a packet has been sent to the host.
+func (d *disk) handleIn() {
+	switch d.state {
+	case stateDataIn:
+		if d.remaining > 0 {
+			d.sendData()
+		} else {
+			d.sendStatus()
+		}
+	case stateStatus:
+		d.state = stateCommand
+	}
+}
+
+// reset aborts the current command, after a bulk-only mass storage reset
+// request.
+func (d *disk) reset() {
+	d.state = stateCommand
+	d.data = nil
+	d.devBytes = 0
+	d.fill = 0
+	// The aborted write was not acknowledged, so it may be dropped.
+	d.eraseIndex = -1
+	d.eraseDirty = false
+}
+
+// command handles a CBW. Invalid CBWs are ignored.
+func (d *disk) command(p []byte) {
+	if len(p) != cbwSize || le32(p[0:]) != cbwSignature {
+		return
+	}
+	d.tag = le32(p[4:])
+	d.length = le32(p[8:])
+	d.in = p[12]&cbwFlagIn != 0
+	d.status = statusPassed
+	d.data = nil
+	d.devBytes = 0
+	d.fill = 0
+	d.scsiCommand(p[15:31])
+}
+
+// start starts the data stage of a command that transfers n bytes in the
+// given direction. The data stage always has the length requested by the
+// host: data that the command does not provide is padded with zeros and data
+// that it does not expect is discarded, as allowed by the bulk-only
+// transport.
+func (d *disk) start(in bool, n uint32) {
+	if n > d.length || (n > 0 && in != d.in) {
+		// The host and the device disagree on the data stage.
+		d.status = statusPhaseError
+		d.data = nil
+		d.devBytes = 0
+		n = 0
+	}
+	d.residue = d.length - n
+	d.remaining = d.length
+	switch {
+	case d.remaining == 0:
+		d.sendStatus()
+	case d.in:
+		d.state = stateDataIn
+		d.sendData()
+	default:
+		d.state = stateDataOut
+	}
+}
+
+// sendData sends the next packet of the data stage to the host.
+func (d *disk) sendData() {
+	n := uint32(packetSize)
+	if n > d.remaining {
+		n = d.remaining
+	}
+	p := d.packet[:n]
+	i := uint32(copy(p, d.data))
+	d.data = d.data[i:]
+	if i < n && d.devBytes > 0 {
+		m := n - i
+		if m > d.devBytes {
+			m = d.devBytes
+		}
+		if _, err := d.dev.ReadAt(p[i:i+m], d.offset); err != nil && d.status == statusPassed {
+			d.fail(senseMediumError, ascUnrecoveredReadError)
+		}
+		d.offset += int64(m)
+		d.devBytes -= m
+		i += m
+	}
+	for ; i < n; i++ {
+		p[i] = 0
+	}
+	d.remaining -= n
+	d.send(p)
+}
+
+// receiveData handles a packet of the data stage received from the host.
+func (d *disk) receiveData(p []byte) {
+	if uint32(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	d.remaining -= uint32(len(p))
+	for len(p) > 0 && d.devBytes > 0 {
+		n := copy(d.sector[d.fill:], p)
+		if uint32(n) > d.devBytes {
+			n = int(d.devBytes)
+		}
+		d.fill += n
+		d.devBytes -= uint32(n)
+		p = p[n:]
+		if d.fill == len(d.sector) || d.devBytes == 0 {
+			if d.status == statusPassed {
+				if err := d.write(d.sector[:d.fill], d.offset); err != nil {
+					d.fail(senseMediumError, ascWriteError)
+				}
+			}
+			d.offset += int64(d.fill)
+			d.fill = 0
+			if d.devBytes == 0 {
+				if err := d.flush(); err != nil && d.status == statusPassed {
+					d.fail(senseMediumError, ascWriteError)
+				}
+			}
+		}
+	}
+	if d.remaining == 0 {
+		d.sendStatus()
+	}
+}
+
+// write writes a logical block to the device at offset off. Erase blocks that
+// are no larger than a logical block are erased and written directly, larger
+// ones are read into eraseBlock and written by flush.
+func (d *disk) write(p []byte, off int64) error {
+	size := d.dev.EraseBlockSize()
+	if d.eraseBlock == nil {
+		if err := d.dev.EraseBlocks(off/size, (int64(len(p))+size-1)/size); err != nil {
+			return err
+		}
+		_, err := d.dev.WriteAt(p, off)
+		return err
+	}
+	index := off / size
+	if index != d.eraseIndex {
+		if err := d.flush(); err != nil {
+			return err
+		}
+		d.eraseIndex = -1
+		if _, err := d.dev.ReadAt(d.eraseBlock, index*size); err != nil {
+			return err
+		}
+		d.eraseIndex = index
+	}
+	copy(d.eraseBlock[off-index*size:], p)
+	d.eraseDirty = true
+	return nil
+}
+
+// flush erases the erase block in eraseBlock and writes it back, if it was
+// modified.
+func (d *disk) flush() error {
+	if !d.eraseDirty {
+		return nil
+	}
+	d.eraseDirty = false
+	size := int64(len(d.eraseBlock))
+	err := d.dev.EraseBlocks(d.eraseIndex, 1)
+	if err == nil {
+		_, err = d.dev.WriteAt(d.eraseBlock, d.eraseIndex*size)
+	}
+	if err != nil {
+		// The contents of the erase block on the device are unknown.
+		d.eraseIndex = -1
+	}
+	return err
+}
+
+// sendStatus sends the CSW of the current command.
+func (d *disk) sendStatus() {
+	p := d.packet[:cswSize]
+	putLE32(p[0:], cswSignature)
+	putLE32(p[4:], d.tag)
+	putLE32(p[8:], d.residue)
+	p[12] = d.status
+	d.state = stateStatus
+	d.send(p)
+}
+
+// fail marks the current command as failed, with the given sense data.
+func (d *disk) fail(key, asc uint8) {
+	d.status = statusFailed
+	d.senseKey = key
+	d.asc = asc
+}
+
+func le32(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func putLE32(b []byte, v uint32) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+}
@@ -0,0 +1,317 @@
+package msc
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// ramDevice is a block device backed by RAM that behaves like flash: writes can
+// only clear bits, so blocks must be erased before they are written.
+type ramDevice struct {
+	data      []byte
+	eraseSize int64
+	failWrite bool
+}
+
+func (r *ramDevice) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > int64(len(r.data)) {
+		return 0, errors.New("read out of range")
+	}
+	return copy(p, r.data[off:]), nil
+}
+
+func (r *ramDevice) WriteAt(p []byte, off int64) (int, error) {
+	if r.failWrite {
+		return 0, errors.New("write failed")
+	}
+	if off+int64(len(p)) > int64(len(r.data)) {
+		return 0, errors.New("write out of range")
+	}
+	for i, b := range p {
+		r.data[off+int64(i)] &= b
+	}
+	return len(p), nil
+}
+
+func (r *ramDevice) Size() int64 {
+	return int64(len(r.data))
+}
+
+func (r *ramDevice) EraseBlockSize() int64 {
+	if r.eraseSize == 0 {
+		return 1
+	}
+	return r.eraseSize
+}
+
+func (r *ramDevice) EraseBlocks(start, len int64) error {
+	size := r.EraseBlockSize()
+	if (start+len)*size > r.Size() {
+		return errors.New("erase out of range")
+	}
+	for i := start * size; i < (start+len)*size; i++ {
+		r.data[i] = 0xff
+	}
+	return nil
+}
+
+// host simulates the USB host side of the bulk-only transport.
+type host struct {
+	t    *testing.T
+	disk *disk
+	sent [][]byte
+	tag  uint32
+}
+
+func newHost(t *testing.T, dev blockDevice) *host {
+	h := &host{t: t}
+	h.disk = newDisk(dev, func(p []byte) bool {
+		h.sent = append(h.sent, append([]byte(nil), p...))
+		return true
+	})
+	return h
+}
+
+// transfer sends a command to the disk, with the data of an OUT data stage,
+// and returns the data of an IN data stage and the CSW.
+func (h *host) transfer(in bool, length uint32, cb []byte, out []byte) (data []byte, status uint8, residue uint32) {
+	h.tag++
+	cbw := make([]byte, cbwSize)
+	putLE32(cbw[0:], cbwSignature)
+	putLE32(cbw[4:], h.tag)
+	putLE32(cbw[8:], length)
+	if in {
+		cbw[12] = cbwFlagIn
+	}
+	cbw[14] = uint8(len(cb))
+	copy(cbw[15:], cb)
+	h.disk.handleOut(cbw)
+
+	for len(out) > 0 {
+		n := packetSize
+		if n > len(out) {
+			n = len(out)
+		}
+		h.disk.handleOut(out[:n])
+		out = out[n:]
+	}
+
+	for len(h.sent) > 0 {
+		p := h.sent[0]
+		h.sent = h.sent[1:]
+		h.disk.handleIn()
+		if len(h.sent) == 0 && h.disk.state == stateCommand {
+			// The last packet is the CSW.
+			if len(p) != cswSize || le32(p[0:]) != cswSignature {
+				h.t.Fatalf("invalid CSW: % x", p)
+			}
+			if tag := le32(p[4:]); tag != h.tag {
+				h.t.Errorf("CSW tag %d, expected %d", tag, h.tag)
+			}
+			if uint32(len(data)) != length && in {
+				h.t.Errorf("data stage has %d bytes, expected %d", len(data), length)
+			}
+			return data, p[12], le32(p[8:])
+		}
+		if len(p) > packetSize {
+			h.t.Fatalf("packet of %d bytes", len(p))
+		}
+		data = append(data, p...)
+	}
+	h.t.Fatal("no CSW received")
+	return
+}
+
+func (h *host) sense() (key, asc uint8) {
+	data, status, _ := h.transfer(true, 18, []byte{scsiRequestSense, 0, 0, 0, 18, 0}, nil)
+	if status != statusPassed {
+		h.t.Fatalf("REQUEST SENSE failed with status %d", status)
+	}
+	return data[2], data[12]
+}
+
+func rw10(op uint8, lba uint32, count uint16) []byte {
+	cb := []byte{op, 0, 0, 0, 0, 0, 0, byte(count >> 8), byte(count), 0}
+	putBE32(cb[2:], lba)
+	return cb
+}
+
+func TestInquiry(t *testing.T) {
+	h := newHost(t, &ramDevice{data: make([]byte, 64*blockSize)})
+	data, status, residue := h.transfer(true, 36, []byte{scsiInquiry, 0, 0, 0, 36, 0}, nil)
+	if status != statusPassed || residue != 0 {
+		t.Fatalf("status %d residue %d", status, residue)
+	}
+	if data[0] != 0 || data[1] != 0x80 {
+		t.Errorf("unexpected device type: % x", data[:2])
+	}
+	if string(data[8:36]) != inquiryIdentification {
+		t.Errorf("unexpected identification: %q", data[8:36])
+	}
+
+	// A short allocation length truncates the response, a longer transfer
+	// length is padded.
+	data, status, residue = h.transfer(true, 64, []byte{scsiInquiry, 0, 0, 0, 5, 0}, nil)
+	if status != statusPassed || residue != 59 || len(data) != 64 {
+		t.Errorf("status %d residue %d length %d", status, residue, len(data))
+	}
+
+	// Vital product data is not supported.
+	_, status, _ = h.transfer(true, 36, []byte{scsiInquiry, 1, 0x80, 0, 36, 0}, nil)
+	if status != statusFailed {
+		t.Errorf("VPD inquiry: status %d", status)
+	}
+	if key, asc := h.sense(); key != senseIllegalRequest || asc != ascInvalidFieldInCDB {
+		t.Errorf("sense %#x/%#x", key, asc)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	h := newHost(t, &ramDevice{data: make([]byte, 64*blockSize)})
+	data, status, _ := h.transfer(true, 8, []byte{scsiReadCapacity10, 0, 0, 0, 0, 0, 0, 0, 0, 0}, nil)
+	if status != statusPassed {
+		t.Fatalf("status %d", status)
+	}
+	if lba, size := be32(data[0:]), be32(data[4:]); lba != 63 || size != blockSize {
+		t.Errorf("last LBA %d, block size %d", lba, size)
+	}
+
+	data, status, _ = h.transfer(true, 12, []byte{scsiReadFormatCapacities, 0, 0, 0, 0, 0, 0, 0, 12, 0}, nil)
+	if status != statusPassed {
+		t.Fatalf("status %d", status)
+	}
+	if blocks := be32(data[4:]); blocks != 64 || data[8] != 0x02 {
+		t.Errorf("blocks %d, descriptor type %#x", blocks, data[8])
+	}
+
+	if _, status, _ := h.transfer(false, 0, []byte{scsiTestUnitReady, 0, 0, 0, 0, 0}, nil); status != statusPassed {
+		t.Errorf("TEST UNIT READY: status %d", status)
+	}
+	if _, status, _ := h.transfer(true, 4, []byte{scsiModeSense6, 0, 0x3f, 0, 4, 0}, nil); status != statusPassed {
+		t.Errorf("MODE SENSE: status %d", status)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	dev := &ramDevice{data: make([]byte, 64*blockSize)}
+	h := newHost(t, dev)
+
+	out := make([]byte, 2*blockSize)
+	for i := range out {
+		out[i] = byte(i * 7)
+	}
+	_, status, residue := h.transfer(false, uint32(len(out)), rw10(scsiWrite10, 3, 2), out)
+	if status != statusPassed || residue != 0 {
+		t.Fatalf("WRITE: status %d residue %d", status, residue)
+	}
+	if !bytes.Equal(dev.data[3*blockSize:5*blockSize], out) {
+		t.Error("written data does not match")
+	}
+
+	data, status, residue := h.transfer(true, uint32(len(out)), rw10(scsiRead10, 3, 2), nil)
+	if status != statusPassed || residue != 0 {
+		t.Fatalf("READ: status %d residue %d", status, residue)
+	}
+	if !bytes.Equal(data, out) {
+		t.Error("read data does not match")
+	}
+
+	// The last block is readable, the block after it is not.
+	if _, status, _ := h.transfer(true, blockSize, rw10(scsiRead10, 63, 1), nil); status != statusPassed {
+		t.Errorf("READ of last block: status %d", status)
+	}
+	data, status, residue = h.transfer(true, blockSize, rw10(scsiRead10, 64, 1), nil)
+	if status != statusFailed || residue != blockSize || len(data) != blockSize {
+		t.Errorf("READ out of range: status %d residue %d length %d", status, residue, len(data))
+	}
+	if key, asc := h.sense(); key != senseIllegalRequest || asc != ascLBAOutOfRange {
+		t.Errorf("sense %#x/%#x", key, asc)
+	}
+	if key, asc := h.sense(); key != senseNone || asc != 0 {
+		t.Errorf("sense not cleared: %#x/%#x", key, asc)
+	}
+
+	// Write errors are reported after the data stage.
+	dev.failWrite = true
+	_, status, _ = h.transfer(false, blockSize, rw10(scsiWrite10, 0, 1), make([]byte, blockSize))
+	if status != statusFailed {
+		t.Errorf("failed WRITE: status %d", status)
+	}
+	if key, asc := h.sense(); key != senseMediumError || asc != ascWriteError {
+		t.Errorf("sense %#x/%#x", key, asc)
+	}
+}
+
+func TestEraseBlocks(t *testing.T) {
+	for _, eraseSize := range []int64{256, 4096} {
+		dev := &ramDevice{data: make([]byte, 64*blockSize), eraseSize: eraseSize}
+		for i := range dev.data {
+			dev.data[i] = byte(i * 3)
+		}
+		want := append([]byte(nil), dev.data...)
+		h := newHost(t, dev)
+
+		// Write blocks that span two erase blocks of 4096 bytes, the data
+		// around them must be kept.
+		out := make([]byte, 3*blockSize)
+		for i := range out {
+			out[i] = byte(i * 5)
+		}
+		copy(want[6*blockSize:], out)
+		_, status, _ := h.transfer(false, uint32(len(out)), rw10(scsiWrite10, 6, 3), out)
+		if status != statusPassed {
+			t.Fatalf("erase size %d: WRITE: status %d", eraseSize, status)
+		}
+		if !bytes.Equal(dev.data, want) {
+			t.Errorf("erase size %d: device data does not match", eraseSize)
+		}
+
+		// The write is visible to a following read.
+		data, status, _ := h.transfer(true, uint32(len(out)), rw10(scsiRead10, 6, 3), nil)
+		if status != statusPassed || !bytes.Equal(data, out) {
+			t.Errorf("erase size %d: READ: status %d, data matches: %v", eraseSize, status, bytes.Equal(data, out))
+		}
+	}
+}
+
+func TestInvalidCommands(t *testing.T) {
+	h := newHost(t, &ramDevice{data: make([]byte, 64*blockSize)})
+
+	// Unsupported command with a data stage: the data is padded.
+	data, status, residue := h.transfer(true, 20, []byte{0x9e, 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 20, 0, 0, 0}, nil)
+	if status != statusFailed || residue != 20 || len(data) != 20 {
+		t.Errorf("status %d residue %d length %d", status, residue, len(data))
+	}
+	if key, asc := h.sense(); key != senseIllegalRequest || asc != ascInvalidCommand {
+		t.Errorf("sense %#x/%#x", key, asc)
+	}
+
+	// The host expects less data than the command transfers.
+	data, status, _ = h.transfer(true, blockSize, rw10(scsiRead10, 0, 2), nil)
+	if status != statusPhaseError || len(data) != blockSize {
+		t.Errorf("status %d length %d", status, len(data))
+	}
+
+	// Invalid CBWs are ignored.
+	h.disk.handleOut(make([]byte, cbwSize))
+	h.disk.handleOut([]byte{1, 2, 3})
+	if len(h.sent) != 0 || h.disk.state != stateCommand {
+		t.Errorf("invalid CBW was handled")
+	}
+
+	// A reset aborts a transfer.
+	h.tag++
+	cbw := make([]byte, cbwSize)
+	putLE32(cbw[0:], cbwSignature)
+	putLE32(cbw[8:], blockSize)
+	copy(cbw[15:], rw10(scsiWrite10, 0, 1))
+	h.disk.handleOut(cbw)
+	if h.disk.state != stateDataOut {
+		t.Fatalf("state %d, expected data out", h.disk.state)
+	}
+	h.disk.reset()
+	if _, status, _ := h.transfer(false, 0, []byte{scsiTestUnitReady, 0, 0, 0, 0, 0}, nil); status != statusPassed {
+		t.Errorf("TEST UNIT READY after reset: status %d", status)
+	}
+}
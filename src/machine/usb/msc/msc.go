@@ -0,0 +1,16 @@
+//go:build sam || nrf52840
+// +build sam nrf52840
+
+package msc
+
+import "machine"
+
+// Enable adds a mass storage interface to the USB device that exposes the
+// given block device, such as machine.Flash, to the host as a drive with
+// 512-byte blocks. The erase block size of the device must be a power of two.
+// If it is larger than 512 bytes, a buffer of that size is allocated to
+// rewrite partially written erase blocks.
+func Enable(dev machine.BlockDevice) error {
+	d := newDisk(dev, machine.SendUSBMSCPacket)
+	return machine.EnableMSC(d.handleOut, d.handleIn, d.reset)
+}
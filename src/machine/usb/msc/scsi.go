@@ -0,0 +1,152 @@
+package msc
+
+// SCSI operation codes.
+const (
+	scsiTestUnitReady        = 0x00
+	scsiRequestSense         = 0x03
+	scsiInquiry              = 0x12
+	scsiModeSense6           = 0x1a
+	scsiStartStopUnit        = 0x1b
+	scsiPreventAllowRemoval  = 0x1e
+	scsiReadFormatCapacities = 0x23
+	scsiReadCapacity10       = 0x25
+	scsiRead10               = 0x28
+	scsiWrite10              = 0x2a
+	scsiVerify10             = 0x2f
+	scsiSynchronizeCache10   = 0x35
+	scsiModeSense10          = 0x5a
+)
+
+// Sizes of the INQUIRY and REQUEST SENSE responses.
+const (
+	scsiInquiryResponseLength = 36
+	scsiSenseResponseLength   = 18
+)
+
+// Sense keys and additional sense codes.
+const (
+	senseNone           = 0x00
+	senseMediumError    = 0x03
+	senseIllegalRequest = 0x05
+
+	ascWriteError           = 0x0c
+	ascUnrecoveredReadError = 0x11
+	ascInvalidCommand       = 0x20
+	ascLBAOutOfRange        = 0x21
+	ascInvalidFieldInCDB    = 0x24
+)
+
+// inquiryIdentification holds the vendor (8 bytes), product (16 bytes) and
+// revision (4 bytes) returned by INQUIRY.
+const inquiryIdentification = "TinyGo  " + "Mass Storage    " + "1.0 "
+
+// scsiCommand handles the command block of a CBW.
+func (d *disk) scsiCommand(cb []byte) {
+	switch cb[0] {
+	case scsiTestUnitReady, scsiStartStopUnit, scsiPreventAllowRemoval, scsiVerify10, scsiSynchronizeCache10:
+		d.start(d.in, 0)
+
+	case scsiRequestSense:
+		b := d.response[:scsiSenseResponseLength]
+		for i := range b {
+			b[i] = 0
+		}
+		b[0] = 0x70 // current error, fixed format
+		b[2] = d.senseKey
+		b[7] = scsiSenseResponseLength - 8 // additional sense length
+		b[12] = d.asc
+		d.senseKey = senseNone
+		d.asc = 0
+		d.respond(b, uint32(cb[4]))
+
+	case scsiInquiry:
+		if cb[1]&0x01 != 0 {
+			// Vital product data pages are not supported.
+			d.fail(senseIllegalRequest, ascInvalidFieldInCDB)
+			d.start(d.in, 0)
+			return
+		}
+		b := d.response[:scsiInquiryResponseLength]
+		b[0] = 0x00 // direct access block device
+		b[1] = 0x80 // removable medium
+		b[2] = 0x04 // SPC-2
+		b[3] = 0x02 // response data format
+		b[4] = scsiInquiryResponseLength - 5
+		b[5], b[6], b[7] = 0, 0, 0
+		copy(b[8:], inquiryIdentification)
+		d.respond(b, uint32(cb[4]))
+
+	case scsiModeSense6:
+		// Header without block descriptors or pages.
+		b := d.response[:4]
+		b[0], b[1], b[2], b[3] = 3, 0, 0, 0
+		d.respond(b, uint32(cb[4]))
+
+	case scsiModeSense10:
+		b := d.response[:8]
+		for i := range b {
+			b[i] = 0
+		}
+		b[1] = 6
+		d.respond(b, be16(cb[7:]))
+
+	case scsiReadFormatCapacities:
+		b := d.response[:12]
+		b[0], b[1], b[2], b[3] = 0, 0, 0, 8 // capacity list length
+		putBE32(b[4:], d.blocks)
+		putBE32(b[8:], blockSize)
+		b[8] = 0x02 // formatted media
+		d.respond(b, be16(cb[7:]))
+
+	case scsiReadCapacity10:
+		b := d.response[:8]
+		putBE32(b[0:], d.blocks-1) // last logical block address
+		putBE32(b[4:], blockSize)
+		d.respond(b, 8)
+
+	case scsiRead10, scsiWrite10:
+		lba := be32(cb[2:])
+		count := be16(cb[7:])
+		if uint64(lba)+uint64(count) > uint64(d.blocks) {
+			d.fail(senseIllegalRequest, ascLBAOutOfRange)
+			d.start(d.in, 0)
+			return
+		}
+		d.offset = int64(lba) * blockSize
+		d.devBytes = count * blockSize
+		d.start(cb[0] == scsiRead10, d.devBytes)
+
+	default:
+		d.fail(senseIllegalRequest, ascInvalidCommand)
+		d.start(d.in, 0)
+	}
+}
+
+// respond sends the response of a command, truncated to the allocation length
+// of the command.
+func (d *disk) respond(b []byte, allocationLength uint32) {
+	if uint32(len(b)) > allocationLength {
+		b = b[:allocationLength]
+	}
+	if uint32(len(b)) > d.length {
+		// The host may request less than the allocation length.
+		b = b[:d.length]
+	}
+	d.data = b
+	d.start(true, uint32(len(b)))
+}
+
+func be16(b []byte) uint32 {
+	return uint32(b[0])<<8 | uint32(b[1])
+}
+
+func be32(b []byte) uint32 {
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+}
+
+func putBE32(b []byte, v uint32) {
+	b[0] = byte(v >> 24)
+	b[1] = byte(v >> 16)
+	b[2] = byte(v >> 8)
+	b[3] = byte(v)
+}
@@ -0,0 +1,168 @@
+//go:build sam || nrf52840
+// +build sam nrf52840
+
+package machine
+
+// USB Human Interface Device (HID) class. The HID interface has a single
+// interrupt IN endpoint on which the reports are sent. The report descriptor,
+// which describes the layout of the reports, is provided by the application;
+// see the machine/usb/hid package for a builder and ready-made descriptors.
+
+const (
+	usb_HID_DESCRIPTOR_TYPE = 0x21
+	usb_HID_REPORT_TYPE     = 0x22
+
+	// HID class requests
+	usb_HID_GET_REPORT   = 0x01
+	usb_HID_GET_IDLE     = 0x02
+	usb_HID_GET_PROTOCOL = 0x03
+	usb_HID_SET_REPORT   = 0x09
+	usb_HID_SET_IDLE     = 0x0a
+	usb_HID_SET_PROTOCOL = 0x0b
+
+	usb_HID_V1_11 = 0x0111
+)
+
+const hidDescriptorSize = 9
+
+// HIDDescriptor is the HID class descriptor, which follows the HID interface
+// descriptor and gives the size of the report descriptor.
+//
+// bLength, bDescriptorType, bcdHID, bCountryCode, bNumDescriptors, bDescriptorType,
+// wDescriptorLength
+//
+type HIDDescriptor struct {
+	bLength           uint8 // 9
+	bDescriptorType   uint8 // 0x21
+	bcdHID            uint16
+	bCountryCode      uint8
+	bNumDescriptors   uint8
+	bReportType       uint8 // 0x22
+	wReportDescLength uint16
+}
+
+// NewHIDDescriptor returns a new USB HIDDescriptor for a report descriptor of
+// the given length.
+func NewHIDDescriptor(reportLength uint16) HIDDescriptor {
+	return HIDDescriptor{hidDescriptorSize, usb_HID_DESCRIPTOR_TYPE, usb_HID_V1_11, 0, 1, usb_HID_REPORT_TYPE, reportLength}
+}
+
+// Bytes returns HIDDescriptor data.
+func (d HIDDescriptor) Bytes() [hidDescriptorSize]byte {
+	var b [hidDescriptorSize]byte
+	b[0] = byte(d.bLength)
+	b[1] = byte(d.bDescriptorType)
+	b[2] = byte(d.bcdHID)
+	b[3] = byte(d.bcdHID >> 8)
+	b[4] = byte(d.bCountryCode)
+	b[5] = byte(d.bNumDescriptors)
+	b[6] = byte(d.bReportType)
+	b[7] = byte(d.wReportDescLength)
+	b[8] = byte(d.wReportDescLength >> 8)
+	return b
+}
+
+var (
+	hidEnabled          bool
+	hidEndpointIn       uint32
+	hidReportDescriptor []byte
+	hidIdleRate         uint8
+	hidProtocol         uint8 = 1 // report protocol
+)
+
+// EnableHID adds a HID interface with the given report descriptor to the USB
+// device. The txHandler is called from the USB interrupt each time a report
+// sent with SendUSBHIDPacket has been transferred to the host, it may be nil.
+//
+// EnableHID must be called before the host enumerates the device, for example
+// from an init function. Only one HID interface is supported, further calls
+// are ignored. It returns an error if the USB device has no free endpoint or
+// interface left.
+func EnableHID(reportDescriptor []byte, txHandler func()) error {
+	if hidEnabled {
+		return nil
+	}
+	ep, err := addUSBEndpoint(usb_ENDPOINT_TYPE_INTERRUPT | usbEndpointIn)
+	if err != nil {
+		return err
+	}
+
+	iface := NewInterfaceDescriptor(usbInterfaceCount, 1, usb_DEVICE_CLASS_HUMAN_INTERFACE, 0, 0)
+	hid := NewHIDDescriptor(uint16(len(reportDescriptor)))
+	in := NewEndpointDescriptor(uint8(ep|usbEndpointIn), usb_ENDPOINT_TYPE_INTERRUPT, usbEndpointPacketSize, 1)
+
+	ifaceBuf := iface.Bytes()
+	hidBuf := hid.Bytes()
+	inBuf := in.Bytes()
+	buf := make([]byte, 0, interfaceDescriptorSize+hidDescriptorSize+endpointDescriptorSize)
+	buf = append(buf, ifaceBuf[:]...)
+	buf = append(buf, hidBuf[:]...)
+	buf = append(buf, inBuf[:]...)
+
+	if err := addUSBInterfaces(1, buf, hidSetup); err != nil {
+		return err
+	}
+	usbTxHandler[ep] = txHandler
+	hidEndpointIn = ep
+	hidReportDescriptor = reportDescriptor
+	hidEnabled = true
+	return nil
+}
+
+// SendUSBHIDPacket sends a report of at most 64 bytes to the host. It returns
+// false if the host has not configured the device yet. Only one report can be
+// in flight at a time: the next report must be sent after the txHandler passed
+// to EnableHID has been called.
+func SendUSBHIDPacket(report []byte) bool {
+	if !hidEnabled || usbConfiguration == 0 {
+		return false
+	}
+	sendUSBInPacket(hidEndpointIn, report)
+	return true
+}
+
+// hidSetup handles the requests addressed to the HID interface.
+func hidSetup(setup usbSetup) bool {
+	switch setup.bmRequestType {
+	case usb_REQUEST_DEVICETOHOST_STANDARD_INTERFACE:
+		if setup.bRequest != usb_GET_DESCRIPTOR {
+			return false
+		}
+		switch setup.wValueH {
+		case usb_HID_DESCRIPTOR_TYPE:
+			hid := NewHIDDescriptor(uint16(len(hidReportDescriptor)))
+			buf := hid.Bytes()
+			sendUSBPacket(0, limitUSBSetupLength(buf[:], setup))
+			return true
+		case usb_HID_REPORT_TYPE:
+			sendUSBPacket(0, limitUSBSetupLength(hidReportDescriptor, setup))
+			return true
+		}
+
+	case usb_REQUEST_DEVICETOHOST_CLASS_INTERFACE:
+		switch setup.bRequest {
+		case usb_HID_GET_IDLE:
+			sendUSBPacket(0, []byte{hidIdleRate})
+			return true
+		case usb_HID_GET_PROTOCOL:
+			sendUSBPacket(0, []byte{hidProtocol})
+			return true
+		}
+
+	case usb_REQUEST_HOSTTODEVICE_CLASS_INTERFACE:
+		switch setup.bRequest {
+		case usb_HID_SET_IDLE:
+			hidIdleRate = setup.wValueH
+			sendZlp()
+			return true
+		case usb_HID_SET_PROTOCOL:
+			hidProtocol = setup.wValueL
+			sendZlp()
+			return true
+		}
+	}
+
+	// GET_REPORT and SET_REPORT are not supported: reports are only sent
+	// on the interrupt endpoint.
+	return false
+}
@@ -0,0 +1,205 @@
+//go:build sam || nrf52840
+// +build sam nrf52840
+
+package machine
+
+import (
+	"errors"
+	"machine/usb/midi"
+	"runtime/volatile"
+)
+
+// USB MIDI 1.0 class. The MIDI function consists of an audio control
+// interface and a MIDI streaming interface with one embedded MIDI IN jack,
+// connected to the bulk OUT endpoint, and one embedded MIDI OUT jack,
+// connected to the bulk IN endpoint. MIDI messages are transferred as 4-byte
+// event packets on these endpoints, see the machine/usb/midi package. Both
+// jacks are on cable 0.
+
+const (
+	usb_AUDIO_CLASS                  = 0x01
+	usb_AUDIO_CONTROL_SUBCLASS       = 0x01
+	usb_AUDIO_MIDISTREAMING_SUBCLASS = 0x03
+
+	usb_AUDIO_CS_INTERFACE = 0x24
+	usb_AUDIO_CS_ENDPOINT  = 0x25
+
+	usb_MIDI_JACK_EMBEDDED = 0x01
+	usb_MIDI_JACK_EXTERNAL = 0x02
+
+	// Jack IDs
+	usb_MIDI_IN_JACK_EMBEDDED  = 1
+	usb_MIDI_IN_JACK_EXTERNAL  = 2
+	usb_MIDI_OUT_JACK_EMBEDDED = 3
+	usb_MIDI_OUT_JACK_EXTERNAL = 4
+
+	usbMIDICable = 0
+)
+
+var (
+	errMIDINotConfigured  = errors.New("USB MIDI not configured by host")
+	errMIDIInvalidMessage = errors.New("USB MIDI invalid message")
+)
+
+// USBMIDI is a USB MIDI device. Received MIDI messages are stored as a stream
+// of MIDI bytes in Buffer.
+type USBMIDI struct {
+	Buffer  *RingBuffer
+	enabled bool
+	sending volatile.Register8
+	epIn    uint32
+}
+
+// MIDI is the USB MIDI device. It is added to the USB device by Configure.
+var MIDI = &USBMIDI{Buffer: NewRingBuffer()}
+
+// Configure adds the MIDI function to the USB device. It must be called
+// before the host enumerates the device, for example from an init function.
+// It returns an error if the USB device has no free endpoints or interfaces
+// left.
+func (m *USBMIDI) Configure() error {
+	if m.enabled {
+		return nil
+	}
+	epOut, err := addUSBEndpoint(usb_ENDPOINT_TYPE_BULK | usbEndpointOut)
+	if err != nil {
+		return err
+	}
+	epIn, err := addUSBEndpoint(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)
+	if err != nil {
+		return err
+	}
+
+	ac := usbInterfaceCount
+	ms := ac + 1
+	iad := NewIADDescriptor(ac, 2, usb_AUDIO_CLASS, usb_AUDIO_CONTROL_SUBCLASS, 0)
+	iadBuf := iad.Bytes()
+	desc := append(iadBuf[:],
+		// Audio control interface
+		interfaceDescriptorSize, 4, ac, 0, 0, usb_AUDIO_CLASS, usb_AUDIO_CONTROL_SUBCLASS, 0, 0,
+		// Class-specific audio control header, bcdADC 1.0
+		9, usb_AUDIO_CS_INTERFACE, 0x01, 0x00, 0x01, 9, 0, 1, ms,
+
+		// MIDI streaming interface
+		interfaceDescriptorSize, 4, ms, 0, 2, usb_AUDIO_CLASS, usb_AUDIO_MIDISTREAMING_SUBCLASS, 0, 0,
+		// Class-specific MIDI streaming header, bcdMSC 1.0, total length of
+		// the class-specific descriptors including jacks and endpoints
+		7, usb_AUDIO_CS_INTERFACE, 0x01, 0x00, 0x01, 65, 0,
+		// MIDI IN jacks
+		6, usb_AUDIO_CS_INTERFACE, 0x02, usb_MIDI_JACK_EMBEDDED, usb_MIDI_IN_JACK_EMBEDDED, 0,
+		6, usb_AUDIO_CS_INTERFACE, 0x02, usb_MIDI_JACK_EXTERNAL, usb_MIDI_IN_JACK_EXTERNAL, 0,
+		// MIDI OUT jacks, each with one input pin connected to an IN jack
+		9, usb_AUDIO_CS_INTERFACE, 0x03, usb_MIDI_JACK_EMBEDDED, usb_MIDI_OUT_JACK_EMBEDDED, 1, usb_MIDI_IN_JACK_EXTERNAL, 1, 0,
+		9, usb_AUDIO_CS_INTERFACE, 0x03, usb_MIDI_JACK_EXTERNAL, usb_MIDI_OUT_JACK_EXTERNAL, 1, usb_MIDI_IN_JACK_EMBEDDED, 1, 0,
+		// Bulk OUT endpoint, with the audio class bRefresh and bSynchAddress
+		9, 5, uint8(epOut|usbEndpointOut), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0, 0, 0, 0,
+		5, usb_AUDIO_CS_ENDPOINT, 0x01, 1, usb_MIDI_IN_JACK_EMBEDDED,
+		// Bulk IN endpoint
+		9, 5, uint8(epIn|usbEndpointIn), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0, 0, 0, 0,
+		5, usb_AUDIO_CS_ENDPOINT, 0x01, 1, usb_MIDI_OUT_JACK_EMBEDDED,
+	)
+
+	if err := addUSBInterfaces(2, desc, nil); err != nil {
+		return err
+	}
+	usbRxHandler[epOut] = m.handleRx
+	usbTxHandler[epIn] = m.handleTx
+	m.epIn = epIn
+	m.enabled = true
+	return nil
+}
+
+// handleRx stores the MIDI bytes of the received event packets in the
+// buffer.
+func (m *USBMIDI) handleRx(data []byte) {
+	midi.Receive(m.Buffer, data, usbMIDICable)
+}
+
+func (m *USBMIDI) handleTx() {
+	m.sending.Set(0)
+}
+
+// Read from the buffer of received MIDI bytes.
+func (m *USBMIDI) Read(data []byte) (n int, err error) {
+	for n < len(data) {
+		c, ok := m.Buffer.Get()
+		if !ok {
+			break
+		}
+		data[n] = c
+		n++
+	}
+	return n, nil
+}
+
+// ReadByte reads a single received MIDI byte. It returns an error if there is
+// none.
+func (m *USBMIDI) ReadByte() (byte, error) {
+	c, ok := m.Buffer.Get()
+	if !ok {
+		return 0, errNoByte
+	}
+	return c, nil
+}
+
+// Buffered returns the number of received MIDI bytes in the buffer.
+func (m *USBMIDI) Buffered() int {
+	return m.Buffer.Used()
+}
+
+// SendMessage sends a single complete MIDI message to the host, such as a
+// note on message or a system exclusive message starting with 0xF0 and
+// ending with 0xF7. Running status is not supported.
+func (m *USBMIDI) SendMessage(msg []byte) error {
+	if len(msg) == 0 || msg[0] < 0x80 || msg[0] == 0xf7 {
+		return errMIDIInvalidMessage
+	}
+	var packets [usbEndpointPacketSize]byte
+	n := 0
+	for len(msg) > 0 {
+		if n == len(packets) {
+			if err := m.send(packets[:n]); err != nil {
+				return err
+			}
+			n = 0
+		}
+		var packet midi.Packet
+		size := packet.Encode(usbMIDICable, msg)
+		if size == 0 {
+			return errMIDIInvalidMessage
+		}
+		copy(packets[n:], packet[:])
+		msg = msg[size:]
+		n += midi.PacketSize
+	}
+	return m.send(packets[:n])
+}
+
+// NoteOn sends a note on message. The channel is numbered from 0 to 15.
+func (m *USBMIDI) NoteOn(channel, note, velocity uint8) error {
+	return m.SendMessage([]byte{0x90 | channel&0x0f, note & 0x7f, velocity & 0x7f})
+}
+
+// NoteOff sends a note off message.
+func (m *USBMIDI) NoteOff(channel, note, velocity uint8) error {
+	return m.SendMessage([]byte{0x80 | channel&0x0f, note & 0x7f, velocity & 0x7f})
+}
+
+// ControlChange sends a control change message.
+func (m *USBMIDI) ControlChange(channel, control, value uint8) error {
+	return m.SendMessage([]byte{0xb0 | channel&0x0f, control & 0x7f, value & 0x7f})
+}
+
+// send transfers event packets to the host, after the previous transfer has
+// completed.
+func (m *USBMIDI) send(packets []byte) error {
+	if !m.enabled || usbConfiguration == 0 {
+		return errMIDINotConfigured
+	}
+	for m.sending.Get() != 0 {
+		gosched()
+	}
+	m.sending.Set(1)
+	sendUSBInPacket(m.epIn, packets)
+	return nil
+}
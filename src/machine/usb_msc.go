@@ -0,0 +1,95 @@
+//go:build sam || nrf52840
+// +build sam nrf52840
+
+package machine
+
+// USB Mass Storage class, using the bulk-only transport (BOT). The interface
+// has a bulk OUT endpoint on which the host sends commands and data, and a
+// bulk IN endpoint on which the device sends data and status. The commands
+// themselves are handled outside of this package, see machine/usb/msc.
+
+const (
+	usb_MSC_SUBCLASS_SCSI      = 0x06
+	usb_MSC_PROTOCOL_BULK_ONLY = 0x50
+
+	// MSC class requests
+	usb_MSC_GET_MAX_LUN = 0xfe
+	usb_MSC_RESET       = 0xff
+)
+
+var (
+	mscEnabled      bool
+	mscEndpointIn   uint32
+	mscResetHandler func()
+)
+
+// EnableMSC adds a mass storage interface to the USB device. The rxHandler is
+// called from the USB interrupt with each packet received from the host, the
+// txHandler each time a packet sent with SendUSBMSCPacket has been
+// transferred and the resetHandler when the host resets the interface.
+//
+// EnableMSC must be called before the host enumerates the device, for example
+// from an init function. Only one mass storage interface with a single
+// logical unit is supported, further calls are ignored.
+func EnableMSC(rxHandler func([]byte), txHandler func(), resetHandler func()) error {
+	if mscEnabled {
+		return nil
+	}
+	epOut, err := addUSBEndpoint(usb_ENDPOINT_TYPE_BULK | usbEndpointOut)
+	if err != nil {
+		return err
+	}
+	epIn, err := addUSBEndpoint(usb_ENDPOINT_TYPE_BULK | usbEndpointIn)
+	if err != nil {
+		return err
+	}
+
+	iface := NewInterfaceDescriptor(usbInterfaceCount, 2, usb_DEVICE_CLASS_STORAGE, usb_MSC_SUBCLASS_SCSI, usb_MSC_PROTOCOL_BULK_ONLY)
+	out := NewEndpointDescriptor(uint8(epOut|usbEndpointOut), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
+	in := NewEndpointDescriptor(uint8(epIn|usbEndpointIn), usb_ENDPOINT_TYPE_BULK, usbEndpointPacketSize, 0)
+	msc := NewMSCDescriptor(iface, out, in)
+	buf := msc.Bytes()
+	if err := addUSBInterfaces(1, buf[:], mscSetup); err != nil {
+		return err
+	}
+
+	usbRxHandler[epOut] = rxHandler
+	usbTxHandler[epIn] = txHandler
+	mscEndpointIn = epIn
+	mscResetHandler = resetHandler
+	mscEnabled = true
+	return nil
+}
+
+// SendUSBMSCPacket sends a packet of at most 64 bytes to the host. It returns
+// false if the host has not configured the device yet. Only one packet can be
+// in flight at a time: the next packet must be sent after the txHandler
+// passed to EnableMSC has been called.
+func SendUSBMSCPacket(data []byte) bool {
+	if !mscEnabled || usbConfiguration == 0 {
+		return false
+	}
+	sendUSBInPacket(mscEndpointIn, data)
+	return true
+}
+
+// mscSetup handles the requests addressed to the mass storage interface.
+func mscSetup(setup usbSetup) bool {
+	switch setup.bmRequestType {
+	case usb_REQUEST_DEVICETOHOST_CLASS_INTERFACE:
+		if setup.bRequest == usb_MSC_GET_MAX_LUN {
+			sendUSBPacket(0, []byte{0})
+			return true
+		}
+
+	case usb_REQUEST_HOSTTODEVICE_CLASS_INTERFACE:
+		if setup.bRequest == usb_MSC_RESET {
+			if mscResetHandler != nil {
+				mscResetHandler()
+			}
+			sendZlp()
+			return true
+		}
+	}
+	return false
+}
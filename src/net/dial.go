@@ -16,6 +16,8 @@ func Dial(network, address string) (Conn, error) {
 	return nil, ErrNotImplemented
 }
 
+// Listen is not yet implemented. On WASI, a program can't open a socket by
+// itself: use FileListener with a socket that was passed in by the host.
 func Listen(network, address string) (Listener, error) {
 	return nil, ErrNotImplemented
 }
@@ -0,0 +1,50 @@
+//go:build !wasi
+// +build !wasi
+
+package net
+
+import "time"
+
+// Network file descriptor. Network connections are not yet supported on this
+// platform, so a netFD is never created.
+type netFD struct {
+	net   string
+	laddr Addr
+	raddr Addr
+}
+
+func (fd *netFD) Read(p []byte) (int, error) {
+	return 0, ErrNotImplemented
+}
+
+func (fd *netFD) Write(p []byte) (int, error) {
+	return 0, ErrNotImplemented
+}
+
+func (fd *netFD) Close() error {
+	return ErrNotImplemented
+}
+
+func (fd *netFD) closeRead() error {
+	return ErrNotImplemented
+}
+
+func (fd *netFD) closeWrite() error {
+	return ErrNotImplemented
+}
+
+func (fd *netFD) accept() (*netFD, error) {
+	return nil, ErrNotImplemented
+}
+
+func (fd *netFD) SetDeadline(t time.Time) error {
+	return ErrNotImplemented
+}
+
+func (fd *netFD) SetReadDeadline(t time.Time) error {
+	return ErrNotImplemented
+}
+
+func (fd *netFD) SetWriteDeadline(t time.Time) error {
+	return ErrNotImplemented
+}
@@ -0,0 +1,171 @@
+//go:build wasi
+// +build wasi
+
+package net
+
+// This file implements network connections on WASI. WASI programs can't create
+// sockets themselves, but the host may pass listening sockets to the program
+// as preopened file descriptors, for example with wasmtime -S tcplisten=...
+// See FileListener.
+//
+// Sockets are put in non-blocking mode. When an operation would block, the
+// goroutine waits in the runtime until the socket is ready, so that other
+// goroutines can run in the meantime.
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// Network file descriptor.
+type netFD struct {
+	sysfd  int
+	closed bool
+
+	// immutable until Close
+	net   string
+	laddr Addr
+	raddr Addr
+}
+
+func newFD(sysfd int, net string, laddr, raddr Addr) (*netFD, error) {
+	if err := syscall.SetNonblock(sysfd, true); err != nil {
+		return nil, os.NewSyscallError("setnonblock", err)
+	}
+	return &netFD{sysfd: sysfd, net: net, laddr: laddr, raddr: raddr}, nil
+}
+
+func (fd *netFD) Read(p []byte) (int, error) {
+	for {
+		if fd.closed {
+			return 0, ErrClosed
+		}
+		n, err := syscall.Read(fd.sysfd, p)
+		if err == syscall.EAGAIN {
+			runtime_pollWait(int32(fd.sysfd), 'r')
+			continue
+		}
+		if err != nil {
+			return 0, os.NewSyscallError("read", err)
+		}
+		if n == 0 && len(p) != 0 {
+			return 0, io.EOF
+		}
+		return n, nil
+	}
+}
+
+func (fd *netFD) Write(p []byte) (int, error) {
+	nn := 0
+	for nn < len(p) {
+		if fd.closed {
+			return nn, ErrClosed
+		}
+		n, err := syscall.Write(fd.sysfd, p[nn:])
+		if err == syscall.EAGAIN {
+			runtime_pollWait(int32(fd.sysfd), 'w')
+			continue
+		}
+		if err != nil {
+			return nn, os.NewSyscallError("write", err)
+		}
+		nn += n
+	}
+	return nn, nil
+}
+
+func (fd *netFD) Close() error {
+	if fd.closed {
+		return ErrClosed
+	}
+	fd.closed = true
+	// Wake up the goroutines that wait for this socket before the file
+	// descriptor is closed (and possibly reused).
+	runtime_pollUnblock(int32(fd.sysfd))
+	if err := syscall.Close(fd.sysfd); err != nil {
+		return os.NewSyscallError("close", err)
+	}
+	return nil
+}
+
+func (fd *netFD) closeRead() error {
+	return fd.shutdown(syscall.SHUT_RD)
+}
+
+func (fd *netFD) closeWrite() error {
+	return fd.shutdown(syscall.SHUT_WR)
+}
+
+func (fd *netFD) shutdown(how int) error {
+	if fd.closed {
+		return ErrClosed
+	}
+	if err := syscall.Shutdown(fd.sysfd, how); err != nil {
+		return os.NewSyscallError("shutdown", err)
+	}
+	return nil
+}
+
+func (fd *netFD) accept() (*netFD, error) {
+	for {
+		if fd.closed {
+			return nil, ErrClosed
+		}
+		var nfd int32
+		errno := sock_accept(int32(fd.sysfd), 0, &nfd)
+		if syscall.Errno(errno) == syscall.EAGAIN {
+			runtime_pollWait(int32(fd.sysfd), 'r')
+			continue
+		}
+		if errno != 0 {
+			return nil, os.NewSyscallError("accept", syscall.Errno(errno))
+		}
+		// WASI doesn't provide the address of the peer.
+		netfd, err := newFD(int(nfd), fd.net, fd.laddr, &TCPAddr{})
+		if err != nil {
+			syscall.Close(int(nfd))
+			return nil, err
+		}
+		return netfd, nil
+	}
+}
+
+// Deadlines are not supported yet. Clearing a deadline (with the zero time) is
+// allowed, because that's what a connection starts with.
+
+func (fd *netFD) SetDeadline(t time.Time) error {
+	return setDeadline(t)
+}
+
+func (fd *netFD) SetReadDeadline(t time.Time) error {
+	return setDeadline(t)
+}
+
+func (fd *netFD) SetWriteDeadline(t time.Time) error {
+	return setDeadline(t)
+}
+
+func setDeadline(t time.Time) error {
+	if t.IsZero() {
+		return nil
+	}
+	return ErrNotImplemented
+}
+
+// Implemented in the runtime package (runtime_wasm_wasi_poll.go).
+
+// runtime_pollWait blocks the current goroutine until the file descriptor may
+// be ready for reading (mode 'r') or writing (mode 'w').
+func runtime_pollWait(fd int32, mode int32)
+
+// runtime_pollUnblock wakes up all goroutines that wait for the file
+// descriptor.
+func runtime_pollUnblock(fd int32)
+
+// sock_accept is not provided through the syscall package because
+// syscall.Accept returns the address of the peer, which WASI doesn't provide.
+//go:wasm-module wasi_snapshot_preview1
+//export sock_accept
+func sock_accept(fd int32, flags uint16, newfd *int32) (errno uint16)
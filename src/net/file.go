@@ -0,0 +1,45 @@
+// The following is copied from Go 1.16 official implementation and
+// modified to accommodate TinyGo.
+
+// Copyright 2011 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package net
+
+import "os"
+
+type fileAddr string
+
+func (fileAddr) Network() string  { return "file+net" }
+func (f fileAddr) String() string { return string(f) }
+
+// FileConn returns a network connection corresponding to the open file f,
+// which must be a connected stream socket.
+//
+// Unlike the Go standard library, the connection takes over the file
+// descriptor of f instead of using a copy, because file descriptors can't be
+// duplicated on WASI. Afterwards f acts as if it was closed: closing it again
+// is harmless and doesn't affect c.
+func FileConn(f *os.File) (c Conn, err error) {
+	c, err = fileConn(f)
+	if err != nil {
+		err = &OpError{Op: "file", Net: "file+net", Source: nil, Addr: fileAddr(f.Name()), Err: err}
+	}
+	return
+}
+
+// FileListener returns a network listener corresponding to the open file f,
+// which must be a listening stream socket. On WASI, this is the way to get a
+// Listener: the host passes listening sockets as preopened file descriptors,
+// for example with wasmtime -S tcplisten=127.0.0.1:8080.
+//
+// Like FileConn, the listener takes over the file descriptor of f, so closing
+// f afterwards doesn't affect l.
+func FileListener(f *os.File) (ln Listener, err error) {
+	ln, err = fileListener(f)
+	if err != nil {
+		err = &OpError{Op: "file", Net: "file+net", Source: nil, Addr: fileAddr(f.Name()), Err: err}
+	}
+	return
+}
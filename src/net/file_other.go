@@ -0,0 +1,14 @@
+//go:build !wasi
+// +build !wasi
+
+package net
+
+import "os"
+
+func fileConn(f *os.File) (Conn, error) {
+	return nil, ErrNotImplemented
+}
+
+func fileListener(f *os.File) (Listener, error) {
+	return nil, ErrNotImplemented
+}
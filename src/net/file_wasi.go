@@ -0,0 +1,35 @@
+//go:build wasi
+// +build wasi
+
+package net
+
+import (
+	"os"
+	_ "unsafe"
+)
+
+// WASI doesn't provide the addresses of sockets, so both are reported as the
+// zero TCPAddr.
+
+func fileConn(f *os.File) (Conn, error) {
+	fd, err := newFD(int(f.Fd()), "tcp", &TCPAddr{}, &TCPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	detachFile(f)
+	return &TCPConn{conn{fd}}, nil
+}
+
+func fileListener(f *os.File) (Listener, error) {
+	fd, err := newFD(int(f.Fd()), "tcp", &TCPAddr{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	detachFile(f)
+	return &TCPListener{fd}, nil
+}
+
+// detachFile marks f as closed without closing its file descriptor, which is
+// now used by the returned connection or listener.
+//go:linkname detachFile os.detachFile
+func detachFile(f *os.File)
@@ -0,0 +1,146 @@
+//go:build go1.16 && wasi
+// +build go1.16,wasi
+
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package os
+
+import (
+	"io"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// Auxiliary information if the File describes a directory
+type dirInfo struct {
+	dir uintptr // Pointer to DIR structure from dirent.h
+	eof bool    // the end of the directory was reached and dir was freed
+}
+
+func (d *dirInfo) close() {
+	if d.dir == 0 {
+		return
+	}
+	fdclosedir(d.dir)
+	d.dir = 0
+}
+
+func (f *File) readdir(n int, mode readdirMode) (names []string, dirents []DirEntry, infos []FileInfo, err error) {
+	if f.dirinfo == nil {
+		// WASI has no dup, so use the file descriptor of the file itself.
+		// The directory stream is freed with fdclosedir, which leaves the
+		// file descriptor open. Reading a directory in wasi-libc doesn't
+		// depend on the file offset.
+		handle, ok := f.handle.(unixFileHandle)
+		if !ok {
+			// The file was detached, see detachFile.
+			return nil, nil, nil, &PathError{Op: "fdopendir", Path: f.name, Err: ErrClosed}
+		}
+		dir, errno := syscall.Fdopendir(syscallFd(handle))
+		if errno != nil {
+			return nil, nil, nil, &PathError{Op: "fdopendir", Path: f.name, Err: errno}
+		}
+		f.dirinfo = &dirInfo{
+			dir: dir,
+		}
+	}
+	d := f.dirinfo
+
+	size := n
+	if size <= 0 {
+		size = 100
+		n = -1
+	}
+
+	for !d.eof && (len(names)+len(dirents)+len(infos) < size || n == -1) {
+		dirent, errno := readdir(d.dir)
+		if errno != nil {
+			if errno == syscall.EINTR {
+				continue
+			}
+			return names, dirents, infos, &PathError{Op: "readdir", Path: f.name, Err: errno}
+		}
+		if dirent == nil { // EOF
+			// Free the directory stream right away, most directories are
+			// read until the end. Otherwise it is freed when the file is
+			// closed.
+			d.close()
+			d.eof = true
+			break
+		}
+		// The name is a zero-terminated flexible array member, only read up
+		// to the terminating zero byte.
+		name := (*[len(syscall.Dirent{}.Name)]byte)(unsafe.Pointer(&dirent.Name))[:]
+		for i, c := range name {
+			if c == 0 {
+				name = name[:i]
+				break
+			}
+		}
+		// Check for useless names before allocating a string.
+		if string(name) == "." || string(name) == ".." {
+			continue
+		}
+		if mode == readdirName {
+			names = append(names, string(name))
+		} else if mode == readdirDirEntry {
+			de, err := newUnixDirent(f.name, string(name), dtToType(dirent.Type))
+			if IsNotExist(err) {
+				// File disappeared between readdir and stat.
+				// Treat as if it didn't exist.
+				continue
+			}
+			if err != nil {
+				return nil, dirents, nil, err
+			}
+			dirents = append(dirents, de)
+		} else {
+			info, err := lstat(f.name + "/" + string(name))
+			if IsNotExist(err) {
+				// File disappeared between readdir + stat.
+				// Treat as if it didn't exist.
+				continue
+			}
+			if err != nil {
+				return nil, nil, infos, err
+			}
+			infos = append(infos, info)
+		}
+		runtime.KeepAlive(f)
+	}
+
+	if n > 0 && len(names)+len(dirents)+len(infos) == 0 {
+		return nil, nil, nil, io.EOF
+	}
+	return names, dirents, infos, nil
+}
+
+func dtToType(typ uint8) FileMode {
+	switch typ {
+	case syscall.DT_BLK:
+		return ModeDevice
+	case syscall.DT_CHR:
+		return ModeDevice | ModeCharDevice
+	case syscall.DT_DIR:
+		return ModeDir
+	case syscall.DT_LNK:
+		return ModeSymlink
+	case syscall.DT_REG:
+		return 0
+	case syscall.DT_FIFO, syscall.DT_SOCK:
+		// WASI has no named pipes, wasi-libc uses DT_FIFO for stream sockets.
+		return ModeSocket
+	}
+	return ^FileMode(0)
+}
+
+// Implemented in syscall/syscall_libc_wasi.go.
+
+//go:linkname fdclosedir syscall.fdclosedir
+func fdclosedir(dir uintptr) (err error)
+
+//go:linkname readdir syscall.readdir
+func readdir(dir uintptr) (entry *syscall.Dirent, err error)
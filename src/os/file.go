@@ -156,7 +156,7 @@ func (f *File) WriteAt(b []byte, off int64) (n int, err error) {
 
 // Close closes the File, rendering it unusable for I/O.
 func (f *File) Close() (err error) {
-	err = f.handle.Close()
+	err = f.close()
 	if err != nil {
 		err = &PathError{"close", f.name, err}
 	}
@@ -38,6 +38,10 @@ func NewFile(fd uintptr, name string) *File {
 	return &File{&file{stdioFileHandle(fd), name}}
 }
 
+func (f *file) close() error {
+	return f.handle.Close()
+}
+
 // Read is unsupported on this system.
 func (f stdioFileHandle) Read(b []byte) (n int, err error) {
 	return 0, ErrUnsupported
@@ -47,6 +47,16 @@ func NewFile(fd uintptr, name string) *File {
 	return &File{&file{unixFileHandle(fd), name, nil}}
 }
 
+// close frees the directory stream of the file, if there is one, and closes
+// the underlying file handle.
+func (f *file) close() error {
+	if f.dirinfo != nil {
+		f.dirinfo.close()
+		f.dirinfo = nil
+	}
+	return f.handle.Close()
+}
+
 func Pipe() (r *File, w *File, err error) {
 	var p [2]int
 	err = handleSyscallError(syscall.Pipe2(p[:], syscall.O_CLOEXEC))
@@ -0,0 +1,46 @@
+//go:build wasi
+// +build wasi
+
+package os
+
+// closedFileHandle is the handle of a File whose file descriptor was taken over
+// by the net package, see detachFile. The File acts as if it was closed.
+type closedFileHandle struct{}
+
+func (closedFileHandle) Read(b []byte) (n int, err error) {
+	return 0, ErrClosed
+}
+
+func (closedFileHandle) ReadAt(b []byte, offset int64) (n int, err error) {
+	return 0, ErrClosed
+}
+
+func (closedFileHandle) Seek(offset int64, whence int) (newoffset int64, err error) {
+	return 0, ErrClosed
+}
+
+func (closedFileHandle) Write(b []byte) (n int, err error) {
+	return 0, ErrClosed
+}
+
+// Fd returns an invalid file descriptor, like Fd of a closed File does in Go.
+func (closedFileHandle) Fd() uintptr {
+	return ^uintptr(0)
+}
+
+// Close does nothing: the file descriptor is closed by its new owner.
+func (closedFileHandle) Close() error {
+	return nil
+}
+
+// detachFile marks f as closed without closing its file descriptor, which now
+// belongs to the caller. File descriptors can't be duplicated on WASI, so this
+// is how net.FileConn and net.FileListener take over the file descriptor while
+// still allowing f to be closed as usual.
+func detachFile(f *File) {
+	if f.dirinfo != nil {
+		f.dirinfo.close()
+		f.dirinfo = nil
+	}
+	f.handle = closedFileHandle{}
+}
@@ -44,6 +44,10 @@ func NewFile(fd uintptr, name string) *File {
 	return &File{&file{unixFileHandle(fd), name}}
 }
 
+func (f *file) close() error {
+	return f.handle.Close()
+}
+
 func Pipe() (r *File, w *File, err error) {
 	var p [2]syscall.Handle
 	e := handleSyscallError(syscall.Pipe(p[:]))
@@ -19,9 +19,14 @@ func (f *File) Sync() error {
 // Stat returns the FileInfo structure describing file.
 // If there is an error, it will be of type *PathError.
 func (f *File) Stat() (FileInfo, error) {
+	handle, ok := f.handle.(unixFileHandle)
+	if !ok {
+		// On WASI, the file may have been detached, see detachFile.
+		return nil, &PathError{Op: "fstat", Path: f.name, Err: ErrClosed}
+	}
 	var fs fileStat
 	err := ignoringEINTR(func() error {
-		return syscall.Fstat(int(f.handle.(unixFileHandle)), &fs.sys)
+		return syscall.Fstat(int(handle), &fs.sys)
 	})
 	if err != nil {
 		return nil, &PathError{Op: "fstat", Path: f.name, Err: err}
@@ -1,5 +1,64 @@
 package reflect
 
+import (
+	"unsafe"
+)
+
+// makeFuncImpl is the context of a function value created by MakeFunc.
+type makeFuncImpl struct {
+	typ rawType
+	fn  func([]Value) []Value
+}
+
+// MakeFunc returns a new function of the given Type that wraps the function fn.
+// When called, that new function converts its arguments to a slice of Values,
+// runs fn, and returns the results of fn converted back to regular values.
 func MakeFunc(typ Type, fn func(args []Value) (results []Value)) Value {
-	panic("unimplemented: reflect.MakeFunc()")
+	t := typ.(rawType)
+	if t.Kind() != Func {
+		panic("reflect: call of MakeFunc with non-Func type")
+	}
+
+	// The function pointer is a wrapper generated by the compiler that stores
+	// all parameters in a buffer and calls makeFuncStub with the context.
+	_, makeFuncWrapper := t.funcWrappers()
+	return Value{
+		typecode: t,
+		value: unsafe.Pointer(&funcHeader{
+			Context: unsafe.Pointer(&makeFuncImpl{typ: t, fn: fn}),
+			Code:    makeFuncWrapper,
+		}),
+		flags: valueFlagExported,
+	}
+}
+
+// makeFuncStub is called by the compiler-generated wrapper of a function
+// created with MakeFunc. The args and results buffers are laid out like a
+// struct with all parameters or results as fields.
+func makeFuncStub(impl *makeFuncImpl, args, results unsafe.Pointer) {
+	numIn, numOut, _, p := impl.typ.funcSignature("MakeFunc")
+
+	// Read all parameters from the args buffer.
+	in := make([]Value, numIn)
+	offset := uintptr(0)
+	for i := range in {
+		typ := funcParam(p, uintptr(i))
+		offset = align(offset, uintptr(typ.Align()))
+		in[i] = loadFrom(typ, unsafe.Pointer(uintptr(args)+offset))
+		offset += typ.Size()
+	}
+
+	out := impl.fn(in)
+
+	// Store the results in the results buffer.
+	if uintptr(len(out)) != numOut {
+		panic("reflect: wrong return count from function created by MakeFunc")
+	}
+	offset = 0
+	for i, v := range out {
+		typ := funcParam(p, numIn+uintptr(i))
+		offset = align(offset, uintptr(typ.Align()))
+		v.assignTo("MakeFunc", typ, unsafe.Pointer(uintptr(results)+offset))
+		offset += typ.Size()
+	}
 }
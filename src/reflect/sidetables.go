@@ -4,12 +4,18 @@ import (
 	"unsafe"
 )
 
-// This stores a varint for each named type. Named types are identified by their
-// name instead of by their type. The named types stored in this struct are
-// non-basic types: pointer, struct, and channel.
+// This stores three integers for each named type: the underlying type, and the
+// type name and package path (as indices into structNamesSidetable). Named
+// types are identified by their name instead of by their type. The named types
+// stored in this struct are non-basic types: pointer, struct, and channel.
 //go:extern reflect.namedNonBasicTypesSidetable
 var namedNonBasicTypesSidetable uintptr
 
+// This stores the type name and package path (as indices into
+// structNamesSidetable) of each named basic type.
+//go:extern reflect.namedBasicTypesSidetable
+var namedBasicTypesSidetable uintptr
+
 //go:extern reflect.structTypesSidetable
 var structTypesSidetable byte
 
@@ -19,6 +25,41 @@ var structNamesSidetable byte
 //go:extern reflect.arrayTypesSidetable
 var arrayTypesSidetable byte
 
+//go:extern reflect.funcTypesSidetable
+var funcTypesSidetable byte
+
+// This stores two function pointers for each func type: one to call a function
+// of this type (used by Value.Call) and one that is used as the function
+// pointer of functions created with MakeFunc.
+//go:extern reflect.funcWrappersSidetable
+var funcWrappersSidetable uintptr
+
+//go:extern reflect.mapTypesSidetable
+var mapTypesSidetable byte
+
+// The method sets of all types with methods that can be called through
+// reflection. The index starts with the number of method sets, followed by a
+// {type code, offset in methodSetsSidetable} pair for each method set, sorted
+// by type code.
+//go:extern reflect.methodSetsSidetable
+var methodSetsSidetable byte
+
+//go:extern reflect.methodSetsIndexSidetable
+var methodSetsIndexSidetable uintptr
+
+// This stores two function pointers for each method: one that takes the
+// receiver in the context parameter (for method values) and the method itself,
+// which takes the receiver as the first parameter.
+//go:extern reflect.methodFuncsSidetable
+var methodFuncsSidetable uintptr
+
+// The type assert functions of all interface types, used to check whether a
+// type implements an interface. Like methodSetsIndexSidetable, it starts with
+// the number of entries, followed by a {type code, function pointer} pair for
+// each interface type, sorted by type code.
+//go:extern reflect.typeAssertsSidetable
+var typeAssertsSidetable uintptr
+
 // readStringSidetable reads a string from the given table (like
 // structNamesSidetable) and returns this string. No heap allocation is
 // necessary because it makes the string point directly to the raw bytes of the
@@ -246,3 +246,64 @@ func indexByteString(s string, c byte) int {
 	}
 	return -1
 }
+
+const lowerhex = "0123456789abcdef"
+
+// quote returns a double-quoted Go string literal representing s, like
+// strconv.Quote. Unlike strconv.Quote, it does not escape non-ASCII runes.
+func quote(s string) string {
+	buf := make([]byte, 0, len(s)+2)
+	buf = append(buf, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\a':
+			buf = append(buf, '\\', 'a')
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		case '\v':
+			buf = append(buf, '\\', 'v')
+		default:
+			if c < ' ' || c == 0x7f {
+				buf = append(buf, '\\', 'x', lowerhex[c>>4], lowerhex[c&0xf])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+	}
+	buf = append(buf, '"')
+	return string(buf)
+}
+
+// itoa converts the integer to a decimal string, like strconv.Itoa.
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	var buf [20]byte
+	i := len(buf)
+	u := uint(n)
+	if n < 0 {
+		u = uint(-n)
+	}
+	for u != 0 {
+		i--
+		buf[i] = byte('0' + u%10)
+		u /= 10
+	}
+	if n < 0 {
+		i--
+		buf[i] = '-'
+	}
+	return string(buf[i:])
+}
@@ -26,7 +26,8 @@ import (
 //             7 (1111): Struct
 //         The higher bits are either the contents of the type depending on the
 //         type (if n is clear) or indicate the number of the named type (if n
-//         is set).
+//         is set). For channels, the lowest two of these bits contain the
+//         channel direction (see ChanDir) followed by the element type.
 
 type Kind uintptr
 
@@ -195,7 +196,7 @@ type Type interface {
 	//
 	// Only exported methods are accessible and they are sorted in
 	// lexicographic order.
-	//Method(int) Method
+	Method(int) Method
 
 	// MethodByName returns the method with that name in the type's
 	// method set and a boolean indicating if the method was found.
@@ -295,7 +296,7 @@ type Type interface {
 	// to the index sequence. It is equivalent to calling Field
 	// successively for each index i.
 	// It panics if the type's Kind is not Struct.
-	//FieldByIndex(index []int) StructField
+	FieldByIndex(index []int) StructField
 
 	// FieldByName returns the struct field with the given name
 	// and a boolean indicating if the field was found.
@@ -366,8 +367,167 @@ func PointerTo(t Type) Type {
 	return ptrType
 }
 
+// String returns a string representation of this type, like "[]int" or
+// "json.Decoder".
 func (t rawType) String() string {
-	return "T"
+	if name, _, ok := t.namedType(); ok {
+		return name
+	}
+	switch t.Kind() {
+	case Chan:
+		switch t.ChanDir() {
+		case RecvDir:
+			return "<-chan " + t.elem().String()
+		case SendDir:
+			return "chan<- " + t.elem().String()
+		}
+		if elem := t.elem(); elem.Kind() == Chan && elem.ChanDir() == RecvDir {
+			// Avoid the ambiguous "chan <-chan T".
+			return "chan (" + elem.String() + ")"
+		}
+		return "chan " + t.elem().String()
+	case Pointer:
+		return "*" + t.elem().String()
+	case Slice:
+		return "[]" + t.elem().String()
+	case Array:
+		return "[" + itoa(t.Len()) + "]" + t.elem().String()
+	case Map:
+		return "map[" + t.Key().String() + "]" + t.elem().String()
+	case Func:
+		return "func" + t.signatureString()
+	case Interface:
+		numMethod := t.NumMethod()
+		if numMethod == 0 {
+			return "interface {}"
+		}
+		s := "interface {"
+		for i := 0; i < numMethod; i++ {
+			if i != 0 {
+				s += ";"
+			}
+			m := t.rawMethod(i)
+			s += " " + m.name + m.typ.signatureString()
+		}
+		return s + " }"
+	case Struct:
+		numField := t.NumField()
+		if numField == 0 {
+			return "struct {}"
+		}
+		s := "struct {"
+		for i := 0; i < numField; i++ {
+			if i != 0 {
+				s += ";"
+			}
+			field := t.rawField(i)
+			if field.Anonymous {
+				s += " " + field.Type.String()
+			} else {
+				s += " " + field.Name + " " + field.Type.String()
+			}
+			if field.Tag != "" {
+				s += " " + quote(string(field.Tag))
+			}
+		}
+		return s + " }"
+	default:
+		// Basic type.
+		return t.Kind().String()
+	}
+}
+
+// signatureString returns the string representation of this func type without
+// the "func" keyword, like "(int, ...string) error".
+func (t rawType) signatureString() string {
+	numIn, numOut, variadic, p := t.funcSignature("String")
+	s := "("
+	for i := uintptr(0); i < numIn; i++ {
+		if i != 0 {
+			s += ", "
+		}
+		param := funcParam(p, i)
+		if variadic && i == numIn-1 {
+			s += "..." + param.elem().String()
+		} else {
+			s += param.String()
+		}
+	}
+	s += ")"
+	if numOut == 1 {
+		return s + " " + funcParam(p, numIn).String()
+	}
+	if numOut > 1 {
+		s += " ("
+		for i := uintptr(0); i < numOut; i++ {
+			if i != 0 {
+				s += ", "
+			}
+			s += funcParam(p, numIn+i).String()
+		}
+		s += ")"
+	}
+	return s
+}
+
+// namedType returns the name of a named type as returned by String (for
+// example "json.Decoder") and its package path. The ok result is false for
+// types that are not named.
+func (t rawType) namedType() (name, pkgPath string, ok bool) {
+	var entry uintptr
+	if t%2 == 0 {
+		// Basic type. The upper bits contain the number of the named type.
+		namedTypeNum := uintptr(t >> 6)
+		if namedTypeNum == 0 {
+			return "", "", false
+		}
+		entry = uintptr(unsafe.Pointer(&namedBasicTypesSidetable)) + (namedTypeNum-1)*2*unsafe.Sizeof(uintptr(0))
+	} else {
+		// Non-basic type. Look at the 'n' bit (see the top of this file).
+		if (t>>4)%2 == 0 {
+			return "", "", false
+		}
+		namedTypeNum := uintptr(t >> 5)
+		entry = uintptr(unsafe.Pointer(&namedNonBasicTypesSidetable)) + (namedTypeNum*3+1)*unsafe.Sizeof(uintptr(0))
+	}
+	nameNum := *(*uintptr)(unsafe.Pointer(entry))
+	pkgPathNum := *(*uintptr)(unsafe.Pointer(entry + unsafe.Sizeof(uintptr(0))))
+	name = readStringSidetable(unsafe.Pointer(&structNamesSidetable), nameNum)
+	pkgPath = readStringSidetable(unsafe.Pointer(&structNamesSidetable), pkgPathNum)
+	return name, pkgPath, true
+}
+
+// Name returns the name of this type within its package, or the empty string
+// for types that are not named.
+func (t rawType) Name() string {
+	if name, _, ok := t.namedType(); ok {
+		// Strip the package name.
+		for i := len(name) - 1; i >= 0; i-- {
+			if name[i] == '.' {
+				return name[i+1:]
+			}
+		}
+		return name
+	}
+	if t%2 == 0 && t != 0 {
+		// Predeclared basic types are also named types.
+		if t.Kind() == UnsafePointer {
+			return "Pointer"
+		}
+		return t.Kind().String()
+	}
+	return ""
+}
+
+// PkgPath returns the package path of a named type, or the empty string for
+// most predeclared types and types that are not named.
+func (t rawType) PkgPath() string {
+	if t == UnsafePointer.basicType() {
+		// The only predeclared type with a package path.
+		return "unsafe"
+	}
+	_, pkgPath, _ := t.namedType()
+	return pkgPath
 }
 
 func (t rawType) Kind() Kind {
@@ -387,14 +547,61 @@ func (t rawType) Elem() Type {
 
 func (t rawType) elem() rawType {
 	switch t.Kind() {
-	case Chan, Pointer, Slice:
+	case Chan:
+		// The lowest two bits contain the channel direction.
+		return t.stripPrefix() >> 2
+	case Pointer, Slice:
 		return t.stripPrefix()
 	case Array:
 		index := t.stripPrefix()
 		elem, _ := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&arrayTypesSidetable)) + uintptr(index)))
 		return rawType(elem)
-	default: // not implemented: Map
-		panic("unimplemented: (reflect.Type).Elem()")
+	case Map:
+		// The value type is stored right after the key type.
+		index := t.stripPrefix()
+		_, p := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&mapTypesSidetable)) + uintptr(index)))
+		elem, _ := readVarint(p)
+		return rawType(elem)
+	default:
+		panic(&TypeError{"Elem"})
+	}
+}
+
+// Key returns the key type of this map type. It panics for other type kinds.
+func (t rawType) Key() Type {
+	return t.key()
+}
+
+func (t rawType) key() rawType {
+	if t.Kind() != Map {
+		panic(&TypeError{"Key"})
+	}
+	index := t.stripPrefix()
+	key, _ := readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&mapTypesSidetable)) + uintptr(index)))
+	return rawType(key)
+}
+
+// isBinaryKey returns whether map keys of this type can be hashed and compared
+// as plain memory. This must match hashmapIsBinaryKey in the compiler, as it
+// determines how the keys are stored in the runtime hashmap.
+func (t rawType) isBinaryKey() bool {
+	switch t.Kind() {
+	case Bool, Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		return true
+	case Pointer:
+		return true
+	case Struct:
+		numField := t.NumField()
+		for i := 0; i < numField; i++ {
+			if !t.rawField(i).Type.isBinaryKey() {
+				return false
+			}
+		}
+		return true
+	case Array:
+		return t.elem().isBinaryKey()
+	default:
+		return false
 	}
 }
 
@@ -410,7 +617,7 @@ func (t rawType) stripPrefix() rawType {
 	if (t>>4)%2 != 0 {
 		// This is a named type. The data is stored in a sidetable.
 		namedTypeNum := t >> 5
-		n := *(*uintptr)(unsafe.Pointer(uintptr(unsafe.Pointer(&namedNonBasicTypesSidetable)) + uintptr(namedTypeNum)*unsafe.Sizeof(uintptr(0))))
+		n := *(*uintptr)(unsafe.Pointer(uintptr(unsafe.Pointer(&namedNonBasicTypesSidetable)) + uintptr(namedTypeNum)*3*unsafe.Sizeof(uintptr(0))))
 		return rawType(n)
 	}
 	// Not a named type, so the value is stored directly in the type code.
@@ -428,7 +635,25 @@ func (t rawType) Field(i int) StructField {
 		Tag:       field.Tag,
 		Anonymous: field.Anonymous,
 		Offset:    field.Offset,
+		Index:     []int{i},
+	}
+}
+
+// FieldByIndex returns the nested field corresponding to the index sequence,
+// stepping through embedded struct pointers where necessary.
+func (t rawType) FieldByIndex(index []int) StructField {
+	var f StructField
+	typ := t
+	for i, x := range index {
+		if i > 0 {
+			typ = f.Type.(rawType)
+			if typ.Kind() == Ptr && typ.elem().Kind() == Struct {
+				typ = typ.elem()
+			}
+		}
+		f = typ.Field(x)
 	}
+	return f
 }
 
 // rawField returns nearly the same value as Field but without converting the
@@ -496,11 +721,10 @@ func (t rawType) rawField(i int) rawStructField {
 			// This field is exported.
 			field.PkgPath = ""
 		} else {
-			// This field is unexported.
-			// TODO: list the real package path here. Storing it should not
-			// significantly impact binary size as there is only a limited
-			// number of packages in any program.
-			field.PkgPath = "<unimplemented>"
+			// This field is unexported, so the package path follows.
+			var pkgPathNum uintptr
+			pkgPathNum, p = readVarint(p)
+			field.PkgPath = readStringSidetable(unsafe.Pointer(&structNamesSidetable), pkgPathNum)
 		}
 	}
 
@@ -655,20 +879,88 @@ func (t rawType) FieldAlign() int {
 // AssignableTo returns whether a value of type t can be assigned to a variable
 // of type u.
 func (t rawType) AssignableTo(u Type) bool {
-	if t == u.(rawType) {
+	if u == nil {
+		panic("reflect: nil type passed to Type.AssignableTo")
+	}
+	v := u.(rawType)
+	if t == v {
 		return true
 	}
-	if u.Kind() == Interface {
-		panic("reflect: unimplemented: AssignableTo with interface")
+	if v.Kind() == Interface {
+		return t.implements(v)
 	}
-	return false
+	if t.Name() != "" && v.Name() != "" || t.Kind() != v.Kind() {
+		// Named types are only assignable to themselves.
+		return false
+	}
+	if t.Kind() == Chan && t.ChanDir() == BothDir && t.elem() == v.elem() {
+		// A bidirectional channel can be assigned to any channel type with
+		// the same element type.
+		return true
+	}
+	return t.underlying() == v.underlying()
 }
 
+// Implements returns whether type t implements the interface type u.
 func (t rawType) Implements(u Type) bool {
+	if u == nil {
+		panic("reflect: nil type passed to Type.Implements")
+	}
 	if u.Kind() != Interface {
 		panic("reflect: non-interface type passed to Type.Implements")
 	}
-	return t.AssignableTo(u)
+	return t.implements(u.(rawType))
+}
+
+// implements returns whether type t implements the interface type u. For
+// interface types, this compares the method sets. For other types, it calls
+// the type assert function of the interface created by the compiler.
+func (t rawType) implements(u rawType) bool {
+	numMethod := u.NumMethod()
+	if numMethod == 0 {
+		// Every type implements the empty interface.
+		return true
+	}
+	if t.Kind() == Interface {
+		// All methods of u must also be in the method set of t.
+		tNumMethod := t.NumMethod()
+		for i := 0; i < numMethod; i++ {
+			method := u.rawMethod(i)
+			found := false
+			for j := 0; j < tNumMethod; j++ {
+				m := t.rawMethod(j)
+				if m.name == method.name && m.typ == method.typ {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	fn, ok := lookupTypeIndex(unsafe.Pointer(&typeAssertsSidetable), u)
+	if !ok {
+		return false
+	}
+	typeAssert := funcHeader{Code: unsafe.Pointer(fn)}
+	return (*(*func(rawType) bool)(unsafe.Pointer(&typeAssert)))(t)
+}
+
+// underlying returns the type code of the underlying type of t. This is the
+// type itself for types that are not named.
+func (t rawType) underlying() rawType {
+	if t%2 == 0 {
+		// Basic type. Strip the number of the named type.
+		return t % 64
+	}
+	if (t>>4)%2 != 0 {
+		// Named non-basic type. The underlying type has the same type kind
+		// but with the 'n' bit cleared.
+		return t.stripPrefix()<<5 | t%16
+	}
+	return t
 }
 
 // Comparable returns whether values of this type can be compared to each other.
@@ -709,56 +1001,364 @@ func (t rawType) Comparable() bool {
 	}
 }
 
+// ChanDir returns the direction of this channel type. It panics for other type
+// kinds.
 func (t rawType) ChanDir() ChanDir {
-	panic("unimplemented: (reflect.Type).ChanDir()")
+	if t.Kind() != Chan {
+		panic(&TypeError{"ChanDir"})
+	}
+	return ChanDir(t.stripPrefix() % 4)
 }
 
+// ConvertibleTo returns whether a value of type t can be converted to type u,
+// following the conversion rules of the Go language.
 func (t rawType) ConvertibleTo(u Type) bool {
-	panic("unimplemented: (reflect.Type).ConvertibleTo()")
+	if u == nil {
+		panic("reflect: nil type passed to Type.ConvertibleTo")
+	}
+	v := u.(rawType)
+	switch t.Kind() {
+	case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+		switch v.Kind() {
+		case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+			return true
+		case Float32, Float64:
+			return true
+		case String:
+			// Integers can be converted to a string containing that rune.
+			return true
+		}
+	case Float32, Float64:
+		switch v.Kind() {
+		case Int, Int8, Int16, Int32, Int64, Uint, Uint8, Uint16, Uint32, Uint64, Uintptr:
+			return true
+		case Float32, Float64:
+			return true
+		}
+	case Complex64, Complex128:
+		switch v.Kind() {
+		case Complex64, Complex128:
+			return true
+		}
+	case String:
+		if v.Kind() == Slice && v.elem().isBytesOrRunes() {
+			return true
+		}
+	case Slice:
+		if v.Kind() == String && t.elem().isBytesOrRunes() {
+			return true
+		}
+		if v.Kind() == Pointer && v.elem().Kind() == Array && v.elem().elem() == t.elem() {
+			// Slice to array pointer conversion.
+			return true
+		}
+	}
+	if t.underlying() == v.underlying() {
+		return true
+	}
+	if t.Kind() == Pointer && v.Kind() == Pointer && t.Name() == "" && v.Name() == "" {
+		// Unnamed pointers whose base types have the same underlying type.
+		if t.elem().underlying() == v.elem().underlying() {
+			return true
+		}
+	}
+	if v.Kind() == Interface {
+		return t.implements(v)
+	}
+	return false
+}
+
+// isBytesOrRunes returns whether this is the byte or rune type, which are the
+// element types of slices that can be converted to and from strings.
+func (t rawType) isBytesOrRunes() bool {
+	return t == Uint8.basicType() || t == Int32.basicType()
 }
 
+// funcSignature returns the number of parameters and results of this func
+// type, whether it is variadic, and a pointer to the list of parameter and
+// result types in the func types sidetable. It panics for other type kinds.
+func (t rawType) funcSignature(method string) (numIn, numOut uintptr, variadic bool, p unsafe.Pointer) {
+	if t.Kind() != Func {
+		panic(&TypeError{method})
+	}
+	funcIdentifier := t.stripPrefix()
+	numIn, p = readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&funcTypesSidetable)) + uintptr(funcIdentifier)))
+	numOut, p = readVarint(p)
+	return numIn >> 1, numOut, numIn&1 != 0, p
+}
+
+// funcParam returns the i'th type in the list of parameter and result types
+// of a func type, as returned by funcSignature. Results follow the
+// parameters.
+func funcParam(p unsafe.Pointer, i uintptr) rawType {
+	var typ uintptr
+	for ; ; i-- {
+		typ, p = readVarint(p)
+		if i == 0 {
+			return rawType(typ)
+		}
+	}
+}
+
+// funcWrappers returns the wrapper functions used to call a function of this
+// type through reflection (see Value.Call) and to implement a function of this
+// type in Go (see MakeFunc).
+func (t rawType) funcWrappers() (call, makeFunc unsafe.Pointer) {
+	numIn, numOut, _, p := t.funcSignature("funcWrappers")
+	// Skip the parameter and result types.
+	for i := uintptr(0); i < numIn+numOut; i++ {
+		_, p = readVarint(p)
+	}
+	index, _ := readVarint(p)
+	wrappers := uintptr(unsafe.Pointer(&funcWrappersSidetable)) + index*2*unsafe.Sizeof(uintptr(0))
+	call = *(*unsafe.Pointer)(unsafe.Pointer(wrappers))
+	makeFunc = *(*unsafe.Pointer)(unsafe.Pointer(wrappers + unsafe.Sizeof(uintptr(0))))
+	return
+}
+
+// IsVariadic returns whether the last parameter of this func type is a "..."
+// parameter. It panics for other type kinds.
 func (t rawType) IsVariadic() bool {
-	panic("unimplemented: (reflect.Type).IsVariadic()")
+	_, _, variadic, _ := t.funcSignature("IsVariadic")
+	return variadic
 }
 
+// NumIn returns the number of parameters of this func type. It panics for
+// other type kinds.
 func (t rawType) NumIn() int {
-	panic("unimplemented: (reflect.Type).NumIn()")
+	numIn, _, _, _ := t.funcSignature("NumIn")
+	return int(numIn)
 }
 
+// NumOut returns the number of results of this func type. It panics for other
+// type kinds.
 func (t rawType) NumOut() int {
-	panic("unimplemented: (reflect.Type).NumOut()")
+	_, numOut, _, _ := t.funcSignature("NumOut")
+	return int(numOut)
 }
 
-func (t rawType) NumMethod() int {
-	panic("unimplemented: (reflect.Type).NumMethod()")
+// In returns the type of the i'th parameter of this func type. It panics for
+// other type kinds.
+func (t rawType) In(i int) Type {
+	numIn, _, _, p := t.funcSignature("In")
+	if uint(i) >= uint(numIn) {
+		panic("reflect: parameter index out of range")
+	}
+	return funcParam(p, uintptr(i))
 }
 
-func (t rawType) Name() string {
-	panic("unimplemented: (reflect.Type).Name()")
+// Out returns the type of the i'th result of this func type. It panics for
+// other type kinds.
+func (t rawType) Out(i int) Type {
+	numIn, numOut, _, p := t.funcSignature("Out")
+	if uint(i) >= uint(numOut) {
+		panic("reflect: result index out of range")
+	}
+	return funcParam(p, numIn+uintptr(i))
 }
 
-func (t rawType) Key() Type {
-	panic("unimplemented: (reflect.Type).Key()")
+// methodSet returns the number of methods in the method set of this type and
+// a pointer to the first method in the method sets sidetable. Only methods
+// that can be called through reflection are included: all methods of
+// interface types and the exported methods of other types.
+func (t rawType) methodSet() (uintptr, unsafe.Pointer) {
+	offset, ok := lookupTypeIndex(unsafe.Pointer(&methodSetsIndexSidetable), t)
+	if !ok {
+		// This type has no methods.
+		return 0, nil
+	}
+	return readVarint(unsafe.Pointer(uintptr(unsafe.Pointer(&methodSetsSidetable)) + offset))
 }
 
-func (t rawType) In(i int) Type {
-	panic("unimplemented: (reflect.Type).In()")
+// lookupTypeIndex looks up the type t in a sidetable that starts with the
+// number of entries, followed by {type code, value} pairs sorted by type code.
+// It returns the value for this type, if it exists.
+func lookupTypeIndex(table unsafe.Pointer, t rawType) (uintptr, bool) {
+	// The entries are sorted by type code, so do a binary search. Entries
+	// start after the number of entries.
+	index := uintptr(table)
+	n := *(*uintptr)(unsafe.Pointer(index))
+	low, high := uintptr(0), n
+	for low < high {
+		mid := (low + high) / 2
+		entry := (*[2]uintptr)(unsafe.Pointer(index + (mid*2+1)*unsafe.Sizeof(uintptr(0))))
+		if rawType(entry[0]) < t {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	if low == n {
+		return 0, false
+	}
+	entry := (*[2]uintptr)(unsafe.Pointer(index + (low*2+1)*unsafe.Sizeof(uintptr(0))))
+	if rawType(entry[0]) != t {
+		return 0, false
+	}
+	return entry[1], true
 }
 
-func (t rawType) Out(i int) Type {
-	panic("unimplemented: (reflect.Type).Out()")
+// rawMethod is a method as stored in the method sets sidetable.
+type rawMethod struct {
+	name      string
+	typ       rawType // signature without receiver
+	funcType  rawType // signature with the receiver as first parameter
+	funcIndex uintptr // index into methodFuncsSidetable
 }
 
-func (t rawType) MethodByName(name string) (Method, bool) {
-	panic("unimplemented: (reflect.Type).MethodByName()")
+// rawMethod returns the i'th method in the method set of this type, see
+// methodSet.
+func (t rawType) rawMethod(i int) rawMethod {
+	numMethod, p := t.methodSet()
+	if uint(i) >= uint(numMethod) {
+		panic("reflect: method index out of range")
+	}
+	isInterface := t.Kind() == Interface
+
+	// Iterate over the methods until the target method has been reached, just
+	// like rawField does for struct fields.
+	var method rawMethod
+	for methodNum := 0; methodNum <= i; methodNum++ {
+		var nameNum, typ uintptr
+		nameNum, p = readVarint(p)
+		typ, p = readVarint(p)
+		method.name = readStringSidetable(unsafe.Pointer(&structNamesSidetable), nameNum)
+		method.typ = rawType(typ)
+		if !isInterface {
+			// Concrete methods can also be called directly.
+			var funcType uintptr
+			funcType, p = readVarint(p)
+			method.funcType = rawType(funcType)
+			method.funcIndex, p = readVarint(p)
+		}
+	}
+	return method
 }
 
-func (t rawType) PkgPath() string {
-	panic("unimplemented: (reflect.Type).PkgPath()")
+// methodFuncs returns the functions to call this method: a function that takes
+// the receiver in the context parameter (like a method value) and the method
+// itself, which takes the receiver as the first parameter.
+func (m rawMethod) methodFuncs() (bound, fn unsafe.Pointer) {
+	funcs := uintptr(unsafe.Pointer(&methodFuncsSidetable)) + m.funcIndex*2*unsafe.Sizeof(uintptr(0))
+	bound = *(*unsafe.Pointer)(unsafe.Pointer(funcs))
+	fn = *(*unsafe.Pointer)(unsafe.Pointer(funcs + unsafe.Sizeof(uintptr(0))))
+	return
+}
+
+// NumMethod returns the number of methods in the method set of this type. For
+// interface types, this includes unexported methods.
+func (t rawType) NumMethod() int {
+	numMethod, _ := t.methodSet()
+	return int(numMethod)
 }
 
+// Method returns the i'th method in the method set of this type, sorted by
+// name. For interface types, the Func field is the zero Value.
+func (t rawType) Method(i int) Method {
+	return t.method(t.rawMethod(i), i)
+}
+
+// method converts a rawMethod into a Method.
+func (t rawType) method(m rawMethod, i int) Method {
+	method := Method{
+		Name:  m.name,
+		Index: i,
+	}
+	if t.Kind() == Interface {
+		method.Type = m.typ
+		return method
+	}
+	_, fn := m.methodFuncs()
+	method.Type = m.funcType
+	method.Func = Value{
+		typecode: m.funcType,
+		value:    unsafe.Pointer(&funcHeader{Code: fn}),
+		flags:    valueFlagExported,
+	}
+	return method
+}
+
+// MethodByName returns the method with the given name in the method set of
+// this type, and whether it was found.
+func (t rawType) MethodByName(name string) (Method, bool) {
+	numMethod := t.NumMethod()
+	for i := 0; i < numMethod; i++ {
+		m := t.rawMethod(i)
+		if m.name == name {
+			return t.method(m, i), true
+		}
+	}
+	return Method{}, false
+}
+
+// FieldByName returns the struct field with the given name, looking through
+// embedded structs the same way the Go selector expression x.name does: the
+// shallowest field wins, and multiple fields at the same depth cancel each
+// other out.
 func (t rawType) FieldByName(name string) (StructField, bool) {
-	panic("unimplemented: (reflect.Type).FieldByName()")
+	if t.Kind() != Struct {
+		panic(&TypeError{"FieldByName"})
+	}
+
+	type fieldScan struct {
+		typ   rawType
+		index []int
+	}
+
+	// Do a breadth-first search through the embedded structs.
+	var current []fieldScan
+	next := []fieldScan{{typ: t}}
+	visited := map[rawType]bool{}
+	for len(next) > 0 {
+		current, next = next, nil
+		// Number of times a struct type is embedded at this depth. A field
+		// in a struct that is embedded multiple times is ambiguous.
+		count := map[rawType]int{}
+		for _, scan := range current {
+			count[scan.typ]++
+		}
+
+		var result StructField
+		found := false
+		for _, scan := range current {
+			if visited[scan.typ] {
+				// Already searched at a shallower depth (or a duplicate at
+				// this depth).
+				continue
+			}
+			visited[scan.typ] = true
+			numField := scan.typ.NumField()
+			for i := 0; i < numField; i++ {
+				field := scan.typ.rawField(i)
+				if field.Name == name {
+					if found || count[scan.typ] > 1 {
+						// Ambiguous: there are multiple fields with this
+						// name at the same depth.
+						return StructField{}, false
+					}
+					result = scan.typ.Field(i)
+					result.Index = append(append([]int(nil), scan.index...), i)
+					found = true
+					continue
+				}
+				if !field.Anonymous || found {
+					continue
+				}
+				typ := field.Type
+				if typ.Kind() == Ptr {
+					typ = typ.elem()
+				}
+				if typ.Kind() == Struct {
+					index := append(append([]int(nil), scan.index...), i)
+					next = append(next, fieldScan{typ: typ, index: index})
+				}
+			}
+		}
+		if found {
+			return result, true
+		}
+	}
+	return StructField{}, false
 }
 
 // A StructField describes a single field in a struct.
@@ -325,9 +325,11 @@ func (v Value) String() string {
 		// A string value is always bigger than a pointer as it is made of a
 		// pointer and a length.
 		return *(*string)(v.value)
+	case Invalid:
+		return "<invalid Value>"
 	default:
 		// Special case because of the special treatment of .String() in Go.
-		return "<T>"
+		return "<" + v.typecode.String() + " Value>"
 	}
 }
 
@@ -461,7 +463,7 @@ func (v Value) Field(i int) Value {
 		ptr := unsafe.Pointer(uintptr(v.value) + structField.Offset)
 		value := unsafe.Pointer(loadValue(ptr, fieldSize))
 		return Value{
-			flags:    0,
+			flags:    flags,
 			typecode: fieldType,
 			value:    value,
 		}
@@ -588,45 +590,191 @@ func (v Value) OverflowFloat(x float64) bool {
 	panic("unimplemented: (reflect.Value).OverflowFloat()")
 }
 
+//go:linkname hashmapMake runtime.hashmapMakeUnsafePointer
+func hashmapMake(keySize, valueSize uint8, sizeHint uintptr) unsafe.Pointer
+
+//go:linkname hashmapNewIterator runtime.hashmapNewIteratorUnsafePointer
+func hashmapNewIterator() unsafe.Pointer
+
+//go:linkname hashmapNext runtime.hashmapNextUnsafePointer
+func hashmapNext(m, it, key, value unsafe.Pointer) bool
+
+//go:linkname hashmapBinarySet runtime.hashmapBinarySetUnsafePointer
+func hashmapBinarySet(m, key, value unsafe.Pointer)
+
+//go:linkname hashmapBinaryGet runtime.hashmapBinaryGetUnsafePointer
+func hashmapBinaryGet(m, key, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapBinaryDelete runtime.hashmapBinaryDeleteUnsafePointer
+func hashmapBinaryDelete(m, key unsafe.Pointer)
+
+//go:linkname hashmapStringSet runtime.hashmapStringSetUnsafePointer
+func hashmapStringSet(m unsafe.Pointer, key string, value unsafe.Pointer)
+
+//go:linkname hashmapStringGet runtime.hashmapStringGetUnsafePointer
+func hashmapStringGet(m unsafe.Pointer, key string, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapStringDelete runtime.hashmapStringDeleteUnsafePointer
+func hashmapStringDelete(m unsafe.Pointer, key string)
+
+//go:linkname hashmapInterfaceSet runtime.hashmapInterfaceSetUnsafePointer
+func hashmapInterfaceSet(m unsafe.Pointer, key interface{}, value unsafe.Pointer)
+
+//go:linkname hashmapInterfaceGet runtime.hashmapInterfaceGetUnsafePointer
+func hashmapInterfaceGet(m unsafe.Pointer, key interface{}, value unsafe.Pointer, valueSize uintptr) bool
+
+//go:linkname hashmapInterfaceDelete runtime.hashmapInterfaceDeleteUnsafePointer
+func hashmapInterfaceDelete(m unsafe.Pointer, key interface{})
+
+// mapKeySize returns the size of a map key of the given type, as it is stored
+// in the runtime hashmap. This matches the compiler: strings and binary keys
+// are stored as-is, all other keys are stored in an interface.
+func mapKeySize(keyType rawType) uintptr {
+	if keyType.Kind() == String || keyType.isBinaryKey() {
+		return keyType.Size()
+	}
+	return unsafe.Sizeof(interface{}(nil))
+}
+
+// mapKey converts key to the key type of map v and returns a pointer to it in
+// the form in which it is stored in the runtime hashmap.
+func (v Value) mapKey(op string, key Value) unsafe.Pointer {
+	keyType := v.typecode.key()
+	ptr := alloc(keyType.Size(), nil)
+	key.assignTo(op, keyType, ptr)
+	if keyType.Kind() == String || keyType.isBinaryKey() || keyType.Kind() == Interface {
+		return ptr
+	}
+	// Other keys are stored in an interface, like the compiler does. Note that
+	// the compiler uses the underlying type as the dynamic type.
+	k := loadFrom(keyType, ptr)
+	k.typecode = keyType.underlying()
+	itf := valueInterfaceUnsafe(k)
+	return unsafe.Pointer(&itf)
+}
+
+// loadMapKey returns the map key of map v stored at ptr, which is in the form
+// returned by mapKey.
+func (v Value) loadMapKey(ptr unsafe.Pointer) Value {
+	keyType := v.typecode.key()
+	var key Value
+	if keyType.Kind() == String || keyType.isBinaryKey() || keyType.Kind() == Interface {
+		key = loadFrom(keyType, ptr)
+	} else {
+		key = ValueOf(*(*interface{})(ptr))
+		key.typecode = keyType
+	}
+	key.flags = v.flags & valueFlagExported
+	return key
+}
+
+// MapKeys returns a slice with all keys of this map, in unspecified order. It
+// panics if v is not a map.
 func (v Value) MapKeys() []Value {
-	panic("unimplemented: (reflect.Value).MapKeys()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapKeys", Kind: v.Kind()})
+	}
+	keys := make([]Value, 0, v.Len())
+	it := v.MapRange()
+	for it.Next() {
+		keys = append(keys, it.Key())
+	}
+	return keys
 }
 
+// MapIndex returns the value stored under the given key in map v, or the zero
+// Value if the key is not present. It panics if v is not a map.
 func (v Value) MapIndex(key Value) Value {
-	panic("unimplemented: (reflect.Value).MapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapIndex", Kind: v.Kind()})
+	}
+	keyType := v.typecode.key()
+	elemType := v.typecode.elem()
+	m := v.pointer()
+	k := v.mapKey("MapIndex", key)
+	elem := alloc(elemType.Size(), nil)
+	var ok bool
+	switch {
+	case keyType.Kind() == String:
+		ok = hashmapStringGet(m, *(*string)(k), elem, elemType.Size())
+	case keyType.isBinaryKey():
+		ok = hashmapBinaryGet(m, k, elem, elemType.Size())
+	default:
+		ok = hashmapInterfaceGet(m, *(*interface{})(k), elem, elemType.Size())
+	}
+	if !ok {
+		return Value{}
+	}
+	value := loadFrom(elemType, elem)
+	value.flags = v.flags & key.flags & valueFlagExported
+	return value
 }
 
+// MapRange returns an iterator over the entries of map v. It panics if v is not
+// a map.
 func (v Value) MapRange() *MapIter {
-	panic("unimplemented: (reflect.Value).MapRange()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "MapRange", Kind: v.Kind()})
+	}
+	return &MapIter{m: v, it: hashmapNewIterator()}
 }
 
+// MapIter is an iterator over a map, created with Value.MapRange. Like a range
+// statement, it must be advanced with Next before the first entry can be read.
 type MapIter struct {
+	m     Value
+	it    unsafe.Pointer
+	key   Value
+	value Value
+	done  bool
 }
 
+// Key returns the key of the current map entry.
 func (it *MapIter) Key() Value {
-	panic("unimplemented: (*reflect.MapIter).Key()")
+	if it.done {
+		panic("reflect: MapIter.Key called on exhausted iterator")
+	}
+	if !it.key.IsValid() {
+		panic("reflect: MapIter.Key called before Next")
+	}
+	return it.key
 }
 
+// Value returns the value of the current map entry.
 func (it *MapIter) Value() Value {
-	panic("unimplemented: (*reflect.MapIter).Value()")
+	if it.done {
+		panic("reflect: MapIter.Value called on exhausted iterator")
+	}
+	if !it.key.IsValid() {
+		panic("reflect: MapIter.Value called before Next")
+	}
+	return it.value
 }
 
+// Next advances the iterator to the next map entry. It returns false when there
+// are no more entries.
 func (it *MapIter) Next() bool {
-	panic("unimplemented: (*reflect.MapIter).Next()")
+	if it.done {
+		panic("reflect: MapIter.Next called on exhausted iterator")
+	}
+	elemType := it.m.typecode.elem()
+	key := alloc(mapKeySize(it.m.typecode.key()), nil)
+	elem := alloc(elemType.Size(), nil)
+	if !hashmapNext(it.m.pointer(), it.it, key, elem) {
+		it.done = true
+		it.key = Value{}
+		it.value = Value{}
+		return false
+	}
+	it.key = it.m.loadMapKey(key)
+	it.value = loadFrom(elemType, elem)
+	it.value.flags = it.m.flags & valueFlagExported
+	return true
 }
 
 func (v Value) Set(x Value) {
 	v.checkAddressable()
-	if !v.typecode.AssignableTo(x.typecode) {
-		panic("reflect: cannot set")
-	}
-	size := v.typecode.Size()
-	xptr := x.value
-	if size <= unsafe.Sizeof(uintptr(0)) && !x.isIndirect() {
-		value := x.value
-		xptr = unsafe.Pointer(&value)
-	}
-	memcpy(v.value, xptr, size)
+	x.assignTo("Set", v.typecode, v.value)
 }
 
 func (v Value) SetBool(x bool) {
@@ -737,21 +885,162 @@ func (v Value) OverflowUint(x uint64) bool {
 	panic("unimplemented: reflect.OverflowUint()")
 }
 
+// Convert returns the value v converted to type t, following the conversion
+// rules of the Go language. It panics if v cannot be converted to t.
 func (v Value) Convert(t Type) Value {
-	panic("unimplemented: (reflect.Value).Convert()")
+	if !v.IsValid() {
+		panic(&ValueError{Method: "Convert"})
+	}
+	typ := t.(rawType)
+	if !v.typecode.ConvertibleTo(typ) {
+		panic("reflect.Value.Convert: value of type " + v.typecode.String() + " cannot be converted to type " + typ.String())
+	}
+
+	// The result is written to newly allocated memory. It is not addressable
+	// so small values are loaded from there at the end.
+	ptr := alloc(typ.Size(), nil)
+	result := Value{
+		typecode: typ,
+		value:    ptr,
+		flags:    valueFlagIndirect,
+	}
+	switch src, dst := v.Kind(), typ.Kind(); {
+	case dst == Interface:
+		*(*interface{})(ptr) = valueInterfaceUnsafe(v)
+	case isIntKind(src) && isNumberKind(dst):
+		result.setNumber(float64(v.Int()), v.Int(), uint64(v.Int()))
+	case isUintKind(src) && isNumberKind(dst):
+		result.setNumber(float64(v.Uint()), int64(v.Uint()), v.Uint())
+	case (src == Float32 || src == Float64) && isNumberKind(dst):
+		result.setNumber(v.Float(), int64(v.Float()), uint64(v.Float()))
+	case (src == Complex64 || src == Complex128) && (dst == Complex64 || dst == Complex128):
+		result.SetComplex(v.Complex())
+	case isIntKind(src) && dst == String:
+		x := v.Int()
+		if x < 0 || x > 0x10ffff {
+			x = 0xfffd // invalid code points are converted to "\uFFFD"
+		}
+		result.SetString(string(rune(x)))
+	case isUintKind(src) && dst == String:
+		x := v.Uint()
+		if x > 0x10ffff {
+			x = 0xfffd // invalid code points are converted to "\uFFFD"
+		}
+		result.SetString(string(rune(x)))
+	case src == Slice && dst == String:
+		if v.typecode.elem().Kind() == Uint8 {
+			result.SetString(string(*(*[]byte)(v.value)))
+		} else {
+			result.SetString(string(*(*[]rune)(v.value)))
+		}
+	case src == String && dst == Slice:
+		if typ.elem().Kind() == Uint8 {
+			*(*[]byte)(ptr) = []byte(v.String())
+		} else {
+			*(*[]rune)(ptr) = []rune(v.String())
+		}
+	case src == Slice && dst == Pointer:
+		// Slice to array pointer conversion.
+		slice := (*sliceHeader)(v.value)
+		if n := typ.elem().Len(); uintptr(n) > slice.len {
+			panic("reflect: cannot convert slice with length " + itoa(int(slice.len)) + " to pointer to array with length " + itoa(n))
+		}
+		*(*unsafe.Pointer)(ptr) = slice.data
+	default:
+		// The underlying types are identical, so only the type changes.
+		size := typ.Size()
+		src := v.value
+		if size <= unsafe.Sizeof(uintptr(0)) && !v.isIndirect() {
+			value := v.value
+			src = unsafe.Pointer(&value)
+		}
+		memcpy(ptr, src, size)
+	}
+
+	result = loadFrom(typ, ptr)
+	result.flags = v.flags & valueFlagExported
+	return result
+}
+
+// setNumber stores a number in v, which must be an addressable integer or
+// floating point value. The number is given in all forms it may be converted
+// to, as the conversion depends on the source type.
+func (v Value) setNumber(f float64, i int64, u uint64) {
+	switch kind := v.Kind(); {
+	case isIntKind(kind):
+		v.SetInt(i)
+	case isUintKind(kind):
+		v.SetUint(u)
+	default:
+		v.SetFloat(f)
+	}
+}
+
+// isIntKind returns whether this is one of the signed integer kinds.
+func isIntKind(kind Kind) bool {
+	return kind >= Int && kind <= Int64
+}
+
+// isUintKind returns whether this is one of the unsigned integer kinds.
+func isUintKind(kind Kind) bool {
+	return kind >= Uint && kind <= Uintptr
+}
+
+// isNumberKind returns whether this is one of the integer or (non-complex)
+// floating point kinds.
+func isNumberKind(kind Kind) bool {
+	return kind >= Int && kind <= Float64
 }
 
 func MakeSlice(typ Type, len, cap int) Value {
-	panic("unimplemented: reflect.MakeSlice()")
+	t := typ.(rawType)
+	if t.Kind() != Slice {
+		panic("reflect.MakeSlice of non-slice type")
+	}
+	if len < 0 {
+		panic("reflect.MakeSlice: negative len")
+	}
+	if cap < 0 {
+		panic("reflect.MakeSlice: negative cap")
+	}
+	if len > cap {
+		panic("reflect.MakeSlice: len > cap")
+	}
+	slice := &sliceHeader{
+		data: alloc(t.elem().Size()*uintptr(cap), nil),
+		len:  uintptr(len),
+		cap:  uintptr(cap),
+	}
+	return Value{
+		typecode: t,
+		value:    unsafe.Pointer(slice),
+		flags:    valueFlagExported,
+	}
 }
 
 func Zero(typ Type) Value {
-	panic("unimplemented: reflect.Zero()")
+	if typ == nil {
+		panic("reflect: Zero(nil)")
+	}
+	t := typ.(rawType)
+	var value unsafe.Pointer
+	if size := t.Size(); size > unsafe.Sizeof(uintptr(0)) {
+		// Values this big are not stored directly in the Value.
+		value = alloc(size, nil)
+	}
+	return Value{
+		typecode: t,
+		value:    value,
+		flags:    valueFlagExported,
+	}
 }
 
 // New is the reflect equivalent of the new(T) keyword, returning a pointer to a
 // new value of the given type.
 func New(typ Type) Value {
+	if typ == nil {
+		panic("reflect: New(nil)")
+	}
 	return Value{
 		typecode: PtrTo(typ).(rawType),
 		value:    alloc(typ.Size(), nil),
@@ -802,6 +1091,14 @@ func (e *ValueError) Error() string {
 	return "reflect: call of " + e.Method + " on " + e.Kind.String() + " Value"
 }
 
+// errorString is a trivial error type. The errors package can't be used here
+// as it depends on this package.
+type errorString string
+
+func (e errorString) Error() string {
+	return string(e)
+}
+
 // Calls to this function are converted to LLVM intrinsic calls such as
 // llvm.memcpy.p0i8.p0i8.i32().
 func memcpy(dst, src unsafe.Pointer, size uintptr)
@@ -812,16 +1109,87 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer
 //go:linkname sliceAppend runtime.sliceAppend
 func sliceAppend(srcBuf, elemsBuf unsafe.Pointer, srcLen, srcCap, elemsLen uintptr, elemSize uintptr) (unsafe.Pointer, uintptr, uintptr)
 
+//go:linkname sliceCopy runtime.sliceCopy
+func sliceCopy(dst, src unsafe.Pointer, dstLen, srcLen uintptr, elemSize uintptr) int
+
 // Copy copies the contents of src into dst until either
 // dst has been filled or src has been exhausted.
 func Copy(dst, src Value) int {
-	panic("unimplemented: reflect.Copy()")
+	dstKind := dst.Kind()
+	if dstKind != Array && dstKind != Slice {
+		panic(&ValueError{Method: "Copy", Kind: dstKind})
+	}
+	if dstKind == Array {
+		dst.checkAddressable()
+	}
+	if !dst.isExported() || !src.isExported() {
+		panic("reflect.Copy: unexported")
+	}
+	srcKind := src.Kind()
+	elemType := dst.typecode.elem()
+	if srcKind == String {
+		if elemType.Kind() != Uint8 {
+			panic("reflect.Copy: string source requires a byte slice destination")
+		}
+	} else if srcKind != Array && srcKind != Slice {
+		panic(&ValueError{Method: "Copy", Kind: srcKind})
+	} else if elemType != src.typecode.elem() {
+		panic("reflect.Copy: " + dst.typecode.String() + " != " + src.typecode.String())
+	}
+	dstBuf, dstLen := dst.sliceData()
+	srcBuf, srcLen := src.sliceData()
+	return sliceCopy(dstBuf, srcBuf, dstLen, srcLen, elemType.Size())
+}
+
+// sliceData returns a pointer to the elements of the array, slice or string v
+// and their number.
+func (v Value) sliceData() (unsafe.Pointer, uintptr) {
+	switch v.Kind() {
+	case Array:
+		// Arrays that fit in a pointer are stored directly in a Value that is
+		// not addressable.
+		if !v.isIndirect() && v.typecode.Size() <= unsafe.Sizeof(uintptr(0)) {
+			value := v.value
+			return unsafe.Pointer(&value), uintptr(v.typecode.Len())
+		}
+		return v.value, uintptr(v.typecode.Len())
+	case String:
+		header := (*stringHeader)(v.value)
+		return header.data, header.len
+	default:
+		header := (*sliceHeader)(v.value)
+		return header.data, header.len
+	}
 }
 
 // Append appends the values x to a slice s and returns the resulting slice.
 // As in Go, each x's value must be assignable to the slice's element type.
 func Append(s Value, x ...Value) Value {
-	panic("unimplemented: reflect.Append()")
+	if s.typecode.Kind() != Slice {
+		panic(&ValueError{Method: "Append", Kind: s.Kind()})
+	}
+	if !s.isExported() {
+		panic("reflect.Append: unexported")
+	}
+	// Store the new elements in a temporary buffer and append that buffer.
+	elemType := s.typecode.elem()
+	elemSize := elemType.Size()
+	elems := alloc(elemSize*uintptr(len(x)), nil)
+	for i, elem := range x {
+		elem.assignTo("Append", elemType, unsafe.Pointer(uintptr(elems)+elemSize*uintptr(i)))
+	}
+	sSlice := (*sliceHeader)(s.value)
+	ptr, len, cap := sliceAppend(sSlice.data, elems, sSlice.len, sSlice.cap, uintptr(len(x)), elemSize)
+	result := &sliceHeader{
+		data: ptr,
+		len:  len,
+		cap:  cap,
+	}
+	return Value{
+		typecode: s.typecode,
+		value:    unsafe.Pointer(result),
+		flags:    valueFlagExported,
+	}
 }
 
 // AppendSlice appends a slice t to a slice s and returns the resulting slice.
@@ -852,39 +1220,552 @@ func AppendSlice(s, t Value) Value {
 	}
 }
 
+// SetMapIndex sets the value under the given key in map v to elem. If elem is
+// the zero Value, the key is deleted from the map instead.
 func (v Value) SetMapIndex(key, elem Value) {
-	panic("unimplemented: (reflect.Value).SetMapIndex()")
+	if v.Kind() != Map {
+		panic(&ValueError{Method: "SetMapIndex", Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect: SetMapIndex using value obtained using unexported field")
+	}
+	keyType := v.typecode.key()
+	m := v.pointer()
+	k := v.mapKey("SetMapIndex", key)
+	if !elem.IsValid() {
+		switch {
+		case keyType.Kind() == String:
+			hashmapStringDelete(m, *(*string)(k))
+		case keyType.isBinaryKey():
+			hashmapBinaryDelete(m, k)
+		default:
+			hashmapInterfaceDelete(m, *(*interface{})(k))
+		}
+		return
+	}
+	if m == nil {
+		panic("assignment to entry in nil map")
+	}
+	elemType := v.typecode.elem()
+	ptr := alloc(elemType.Size(), nil)
+	elem.assignTo("SetMapIndex", elemType, ptr)
+	switch {
+	case keyType.Kind() == String:
+		hashmapStringSet(m, *(*string)(k), ptr)
+	case keyType.isBinaryKey():
+		hashmapBinarySet(m, k, ptr)
+	default:
+		hashmapInterfaceSet(m, *(*interface{})(k), ptr)
+	}
 }
 
-// FieldByIndex returns the nested field corresponding to index.
+// FieldByIndex returns the nested field corresponding to index. It panics if
+// evaluation requires stepping through a nil pointer.
 func (v Value) FieldByIndex(index []int) Value {
-	panic("unimplemented: (reflect.Value).FieldByIndex()")
+	if len(index) == 1 {
+		return v.Field(index[0])
+	}
+	if v.Kind() != Struct {
+		panic(&ValueError{Method: "FieldByIndex", Kind: v.Kind()})
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == Ptr && v.typecode.elem().Kind() == Struct {
+			if v.IsNil() {
+				panic("reflect: indirection through nil pointer to embedded struct")
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
 }
 
-// FieldByIndexErr returns the nested field corresponding to index.
+// FieldByIndexErr returns the nested field corresponding to index. Unlike
+// FieldByIndex, it returns an error instead of panicking when evaluation
+// requires stepping through a nil pointer.
 func (v Value) FieldByIndexErr(index []int) (Value, error) {
-	return Value{}, &ValueError{Method: "FieldByIndexErr"}
+	if len(index) == 1 {
+		return v.Field(index[0]), nil
+	}
+	if v.Kind() != Struct {
+		panic(&ValueError{Method: "FieldByIndexErr", Kind: v.Kind()})
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == Ptr && v.typecode.elem().Kind() == Struct {
+			if v.IsNil() {
+				return Value{}, errorString("reflect: indirection through nil pointer to embedded struct field " + v.typecode.elem().Name())
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
 }
 
 func (v Value) FieldByName(name string) Value {
-	panic("unimplemented: (reflect.Value).FieldByName()")
+	if v.Kind() != Struct {
+		panic(&ValueError{Method: "FieldByName", Kind: v.Kind()})
+	}
+	if field, ok := v.typecode.FieldByName(name); ok {
+		return v.FieldByIndex(field.Index)
+	}
+	return Value{}
 }
 
 // MakeMap creates a new map with the specified type.
 func MakeMap(typ Type) Value {
-	panic("unimplemented: reflect.MakeMap()")
+	return makeMap(typ.(rawType), 8)
+}
+
+// MakeMapWithSize creates a new map with the specified type and initial space
+// for approximately n elements.
+func MakeMapWithSize(typ Type, n int) Value {
+	if n < 0 {
+		panic("reflect.MakeMapWithSize: negative size hint")
+	}
+	return makeMap(typ.(rawType), uintptr(n))
+}
+
+func makeMap(t rawType, sizeHint uintptr) Value {
+	if t.Kind() != Map {
+		panic("reflect.MakeMapWithSize of non-map type")
+	}
+	keySize := mapKeySize(t.key())
+	valueSize := t.elem().Size()
+	if keySize > 255 || valueSize > 255 {
+		// The runtime hashmap stores these sizes in a byte.
+		panic("unimplemented: reflect.MakeMap with large key or value type")
+	}
+	return Value{
+		typecode: t,
+		value:    hashmapMake(uint8(keySize), uint8(valueSize), sizeHint),
+		flags:    valueFlagExported,
+	}
 }
 
+// Call calls the function v with the input arguments in. For variadic
+// functions, the variadic arguments are packed into a slice first.
 func (v Value) Call(in []Value) []Value {
-	panic("unimplemented: (reflect.Value).Call()")
+	return v.call("Call", in, false)
+}
+
+// CallSlice calls the variadic function v with the input arguments in, where
+// the last argument is the slice of variadic arguments.
+func (v Value) CallSlice(in []Value) []Value {
+	return v.call("CallSlice", in, true)
+}
+
+func (v Value) call(op string, in []Value, isSlice bool) []Value {
+	if v.Kind() != Func {
+		panic(&ValueError{Method: op, Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect: " + op + " using value obtained using unexported field")
+	}
+	fn := (*funcHeader)(v.value)
+	if fn.Code == nil {
+		panic("reflect: call of nil function")
+	}
+	numIn, numOut, variadic, p := v.typecode.funcSignature(op)
+	if isSlice && !variadic {
+		panic("reflect: CallSlice of non-variadic function")
+	}
+	if variadic && !isSlice {
+		// Pack the variadic arguments into a new slice.
+		n := int(numIn) - 1
+		if len(in) < n {
+			panic("reflect: Call with too few input arguments")
+		}
+		sliceType := funcParam(p, uintptr(n))
+		elemType := sliceType.elem()
+		elemSize := elemType.Size()
+		extra := in[n:]
+		slice := &sliceHeader{
+			data: alloc(elemSize*uintptr(len(extra)), nil),
+			len:  uintptr(len(extra)),
+			cap:  uintptr(len(extra)),
+		}
+		for i, x := range extra {
+			x.assignTo(op, elemType, unsafe.Pointer(uintptr(slice.data)+elemSize*uintptr(i)))
+		}
+		in = append(in[:n:n], Value{
+			typecode: sliceType,
+			value:    unsafe.Pointer(slice),
+			flags:    valueFlagExported,
+		})
+	}
+	if uintptr(len(in)) < numIn {
+		panic("reflect: " + op + " with too few input arguments")
+	}
+	if uintptr(len(in)) > numIn {
+		panic("reflect: " + op + " with too many input arguments")
+	}
+
+	// Store the arguments in a buffer, laid out like a struct with all
+	// parameters as fields.
+	args := alloc(tupleSize(p, numIn), nil)
+	offset := uintptr(0)
+	for i, x := range in {
+		typ := funcParam(p, uintptr(i))
+		offset = align(offset, uintptr(typ.Align()))
+		x.assignTo(op, typ, unsafe.Pointer(uintptr(args)+offset))
+		offset += typ.Size()
+	}
+
+	// Call the function through a wrapper, which loads the arguments from the
+	// args buffer and stores the results in the results buffer.
+	resultsPtr := p
+	for i := uintptr(0); i < numIn; i++ {
+		_, resultsPtr = readVarint(resultsPtr)
+	}
+	results := alloc(tupleSize(resultsPtr, numOut), nil)
+	callWrapper, _ := v.typecode.funcWrappers()
+	wrapper := funcHeader{Code: callWrapper}
+	(*(*func(fn, args, results unsafe.Pointer))(unsafe.Pointer(&wrapper)))(v.value, args, results)
+
+	// Read the results from the results buffer.
+	out := make([]Value, numOut)
+	offset = 0
+	for i := range out {
+		typ := funcParam(resultsPtr, uintptr(i))
+		offset = align(offset, uintptr(typ.Align()))
+		out[i] = loadFrom(typ, unsafe.Pointer(uintptr(results)+offset))
+		offset += typ.Size()
+	}
+	return out
+}
+
+// tupleSize returns the size of a buffer that contains the n types starting at
+// p (see funcSignature), laid out like a struct.
+func tupleSize(p unsafe.Pointer, n uintptr) uintptr {
+	size := uintptr(0)
+	alignment := uintptr(1)
+	for i := uintptr(0); i < n; i++ {
+		var typeNum uintptr
+		typeNum, p = readVarint(p)
+		typ := rawType(typeNum)
+		typeAlign := uintptr(typ.Align())
+		if typeAlign > alignment {
+			alignment = typeAlign
+		}
+		size = align(size, typeAlign) + typ.Size()
+	}
+	return align(size, alignment)
+}
+
+// assignTo stores the value v as a value of type typ in memory at ptr, as in
+// an assignment. It panics if v is not assignable to typ. Types that are
+// assignable to each other without being interfaces, such as a named slice type
+// and its underlying type, share the same memory layout.
+func (v Value) assignTo(op string, typ rawType, ptr unsafe.Pointer) {
+	if !v.IsValid() {
+		panic("reflect: " + op + " using zero Value argument")
+	}
+	if !v.isExported() {
+		panic("reflect: " + op + " using value obtained using unexported field")
+	}
+	if v.typecode != typ {
+		if !v.typecode.AssignableTo(typ) {
+			panic("reflect: " + op + " using " + v.typecode.String() + " as type " + typ.String())
+		}
+		if typ.Kind() == Interface {
+			// Store the value in an interface.
+			*(*interface{})(ptr) = valueInterfaceUnsafe(v)
+			return
+		}
+	}
+	size := typ.Size()
+	src := v.value
+	if size <= unsafe.Sizeof(uintptr(0)) && !v.isIndirect() {
+		value := v.value
+		src = unsafe.Pointer(&value)
+	}
+	memcpy(ptr, src, size)
+}
+
+// loadFrom returns the value of type typ stored in memory at ptr. The memory
+// at ptr must not be modified afterwards.
+func loadFrom(typ rawType, ptr unsafe.Pointer) Value {
+	v := Value{
+		typecode: typ,
+		value:    ptr,
+		flags:    valueFlagExported,
+	}
+	if size := typ.Size(); size <= unsafe.Sizeof(uintptr(0)) {
+		// The value must be stored directly in the Value.
+		v.value = unsafe.Pointer(loadValue(ptr, size))
+	}
+	return v
+}
+
+// Method returns a function value for the i'th method of v, with v bound as
+// the receiver. The returned function takes the method arguments without the
+// receiver.
+func (v Value) Method(i int) Value {
+	if v.typecode.Kind() == Interface {
+		// Look up the method in the dynamic type.
+		if v.IsNil() {
+			panic("reflect: Method on nil interface value")
+		}
+		return v.Elem().MethodByName(v.typecode.rawMethod(i).name)
+	}
+	return v.methodValue(v.typecode.rawMethod(i))
 }
 
+// MethodByName returns a function value for the method of v with the given
+// name, with v bound as the receiver. It returns the zero Value if there is no
+// such method.
 func (v Value) MethodByName(name string) Value {
-	panic("unimplemented: (reflect.Value).MethodByName()")
+	numMethod := v.typecode.NumMethod()
+	for i := 0; i < numMethod; i++ {
+		if v.typecode.rawMethod(i).name == name {
+			return v.Method(i)
+		}
+	}
+	return Value{}
 }
 
+// methodValue returns the method value (a function value with the receiver
+// bound) for the given method of v.
+func (v Value) methodValue(m rawMethod) Value {
+	if !v.isExported() {
+		panic("reflect: Method using value obtained using unexported field")
+	}
+
+	// The receiver is stored in the context of the function value, the same
+	// way as it would be stored in an interface.
+	var receiver unsafe.Pointer
+	size := v.typecode.Size()
+	if size > unsafe.Sizeof(uintptr(0)) {
+		// The method value has its own copy of the receiver.
+		receiver = alloc(size, nil)
+		memcpy(receiver, v.value, size)
+	} else if v.isIndirect() {
+		receiver = unsafe.Pointer(loadValue(v.value, size))
+	} else {
+		receiver = v.value
+	}
+	bound, _ := m.methodFuncs()
+	return Value{
+		typecode: m.typ,
+		value: unsafe.Pointer(&funcHeader{
+			Context: receiver,
+			Code:    bound,
+		}),
+		flags: valueFlagExported,
+	}
+}
+
+//go:linkname chanMake runtime.chanMakeUnsafePointer
+func chanMake(elementSize uintptr, bufSize uintptr) unsafe.Pointer
+
+//go:linkname chanClose runtime.chanCloseUnsafePointer
+func chanClose(ch unsafe.Pointer)
+
+//go:linkname chanSelect runtime.chanSelectUnsafePointer
+func chanSelect(recvbuf, states unsafe.Pointer, block bool) (uintptr, bool)
+
+// chanSelectState has the same layout as runtime.chanSelectState.
+type chanSelectState struct {
+	ch    unsafe.Pointer
+	value unsafe.Pointer
+}
+
+// MakeChan creates a new channel with the specified type and buffer size.
+func MakeChan(typ Type, buffer int) Value {
+	t := typ.(rawType)
+	if t.Kind() != Chan {
+		panic("reflect.MakeChan of non-chan type")
+	}
+	if buffer < 0 {
+		panic("reflect.MakeChan: negative buffer size")
+	}
+	if t.ChanDir() != BothDir {
+		panic("reflect.MakeChan: unidirectional channel type")
+	}
+	return Value{
+		typecode: t,
+		value:    chanMake(t.elem().Size(), uintptr(buffer)),
+		flags:    valueFlagExported,
+	}
+}
+
+// Close closes the channel v. It panics if v is not a channel.
+func (v Value) Close() {
+	if v.Kind() != Chan {
+		panic(&ValueError{Method: "Close", Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect: Close using value obtained using unexported field")
+	}
+	if v.typecode.ChanDir()&SendDir == 0 {
+		panic("reflect: close of receive-only channel")
+	}
+	chanClose(v.pointer())
+}
+
+// Send sends x on the channel v, blocking until the value has been sent. It
+// panics if v is not a channel or if x is not assignable to its element type.
+func (v Value) Send(x Value) {
+	v.send("Send", x, false)
+}
+
+// TrySend attempts to send x on the channel v without blocking. It reports
+// whether the value was sent.
+func (v Value) TrySend(x Value) bool {
+	return v.send("TrySend", x, true)
+}
+
+func (v Value) send(op string, x Value, nb bool) bool {
+	if v.Kind() != Chan {
+		panic(&ValueError{Method: op, Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect: " + op + " using value obtained using unexported field")
+	}
+	if v.typecode.ChanDir()&SendDir == 0 {
+		panic("reflect: send on recv-only channel")
+	}
+	chosen, _, _ := selectCases(op, []SelectCase{{Dir: SelectSend, Chan: v, Send: x}}, nb)
+	return chosen == 0
+}
+
+// Recv receives a value from the channel v, blocking until a value is
+// available. The ok value is false if the channel was closed.
 func (v Value) Recv() (x Value, ok bool) {
-	panic("unimplemented: (reflect.Value).Recv()")
+	return v.recv("Recv", false)
+}
+
+// TryRecv attempts to receive a value from the channel v without blocking. If
+// no value is available, it returns the zero Value.
+func (v Value) TryRecv() (x Value, ok bool) {
+	return v.recv("TryRecv", true)
+}
+
+func (v Value) recv(op string, nb bool) (Value, bool) {
+	if v.Kind() != Chan {
+		panic(&ValueError{Method: op, Kind: v.Kind()})
+	}
+	if !v.isExported() {
+		panic("reflect: " + op + " using value obtained using unexported field")
+	}
+	if v.typecode.ChanDir()&RecvDir == 0 {
+		panic("reflect: recv on send-only channel")
+	}
+	_, x, ok := selectCases(op, []SelectCase{{Dir: SelectRecv, Chan: v}}, nb)
+	return x, ok
+}
+
+// A SelectDir describes the communication direction of a select case.
+type SelectDir int
+
+const (
+	_             SelectDir = iota
+	SelectSend              // case Chan <- Send
+	SelectRecv              // case <-Chan:
+	SelectDefault           // default
+)
+
+// A SelectCase describes a single case in a select operation. For a send,
+// Send is the value to send. For receives and the default case, Send must be
+// the zero Value. A case with a zero Chan is ignored.
+type SelectCase struct {
+	Dir  SelectDir // direction of case
+	Chan Value     // channel to use (for send or receive)
+	Send Value     // value to send (for send)
+}
+
+// Select executes a select operation described by the list of cases. Like the
+// Go select statement, it blocks until at least one of the cases can proceed.
+// It returns the index of the chosen case and, if that case was a receive, the
+// value received and whether it was sent on the channel (as opposed to being a
+// zero value received because the channel is closed).
+func Select(cases []SelectCase) (chosen int, recv Value, recvOK bool) {
+	return selectCases("Select", cases, false)
+}
+
+// selectCases implements Select on top of the runtime select implementation.
+// If nb is set, the select does not block and chosen is -1 when no case could
+// proceed.
+func selectCases(op string, cases []SelectCase, nb bool) (chosen int, recv Value, recvOK bool) {
+	states := make([]chanSelectState, 0, len(cases))
+	indices := make([]int, 0, len(cases))
+	defaultIndex := -1
+	var recvbufSize uintptr
+	for i, c := range cases {
+		switch c.Dir {
+		case SelectDefault:
+			if defaultIndex >= 0 {
+				panic("reflect.Select: multiple default cases")
+			}
+			if c.Chan.IsValid() {
+				panic("reflect.Select: default case has Chan value")
+			}
+			if c.Send.IsValid() {
+				panic("reflect.Select: default case has Send value")
+			}
+			defaultIndex = i
+			continue
+		case SelectSend:
+			if !c.Chan.IsValid() {
+				continue
+			}
+			if c.Chan.Kind() != Chan {
+				panic(&ValueError{Method: op, Kind: c.Chan.Kind()})
+			}
+			if c.Chan.typecode.ChanDir()&SendDir == 0 {
+				panic("reflect.Select: SendDir case using recv-only channel")
+			}
+			elemType := c.Chan.typecode.elem()
+			value := alloc(elemType.Size(), nil)
+			c.Send.assignTo(op, elemType, value)
+			states = append(states, chanSelectState{ch: c.Chan.pointer(), value: value})
+		case SelectRecv:
+			if c.Send.IsValid() {
+				panic("reflect.Select: RecvDir case has Send value")
+			}
+			if !c.Chan.IsValid() {
+				continue
+			}
+			if c.Chan.Kind() != Chan {
+				panic(&ValueError{Method: op, Kind: c.Chan.Kind()})
+			}
+			if c.Chan.typecode.ChanDir()&RecvDir == 0 {
+				panic("reflect.Select: RecvDir case using send-only channel")
+			}
+			if size := c.Chan.typecode.elem().Size(); size > recvbufSize {
+				recvbufSize = size
+			}
+			states = append(states, chanSelectState{ch: c.Chan.pointer()})
+		default:
+			panic("reflect.Select: invalid Dir")
+		}
+		indices = append(indices, i)
+	}
+
+	if len(states) == 0 && defaultIndex < 0 && !nb {
+		// Like an empty select statement, this blocks forever.
+		select {}
+	}
+
+	// The receive buffer must be freshly allocated, as the received value is
+	// returned as-is.
+	recvbuf := alloc(recvbufSize, nil)
+	// TrySend and TryRecv behave as if there was a default case.
+	block := !nb && defaultIndex < 0
+	selected, recvOK := chanSelect(recvbuf, unsafe.Pointer(&states), block)
+	if selected == ^uintptr(0) {
+		return defaultIndex, Value{}, false
+	}
+	chosen = indices[selected]
+	if cases[chosen].Dir == SelectRecv {
+		ch := cases[chosen].Chan
+		recv = loadFrom(ch.typecode.elem(), recvbuf)
+		recv.flags = ch.flags & valueFlagExported
+	} else {
+		recvOK = false
+	}
+	return chosen, recv, recvOK
 }
 
 func NewAt(typ Type, p unsafe.Pointer) Value {
@@ -5,13 +5,24 @@ package runtime
 
 import "unsafe"
 
-// This function is used by hash/maphash.
+// This function is used by hash/maphash and for map iteration.
 func fastrand() uint32 {
+	if xorshift32State == 0 {
+		// Seed the generator on first use, from the hardware random number
+		// generator or the operating system when available.
+		seed, ok := hardwareRand()
+		xorshift32State = uint32(seed) ^ uint32(seed>>32)
+		if !ok || xorshift32State == 0 {
+			xorshift32State = 1
+		}
+	}
 	xorshift32State = xorshift32(xorshift32State)
 	return xorshift32State
 }
 
-var xorshift32State uint32 = 1
+// State of the fastrand generator. Zero means it hasn't been seeded yet (a
+// xorshift generator can never reach zero by itself).
+var xorshift32State uint32
 
 func xorshift32(x uint32) uint32 {
 	// Algorithm "xor" from p. 4 of Marsaglia, "Xorshift RNGs".
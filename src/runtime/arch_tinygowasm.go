@@ -59,19 +59,69 @@ func growHeap() bool {
 	return true
 }
 
-// The below functions override the default allocator of wasi-libc.
-// Most functions are defined but unimplemented to make sure that if there is
-// any code using them, they will get an error instead of (incorrectly) using
-// the wasi-libc dlmalloc heap implementation instead. If they are needed by any
-// program, they can certainly be implemented.
+// Memory that is only referenced by the host or by C code can't be seen by the
+// garbage collector. Such memory is kept alive by a reference in this map,
+// together with the number of times it has been pinned.
+var (
+	wasmPinned  map[unsafe.Pointer]uintptr
+	wasmPinLock mutex // protects wasmPinned when there are multiple threads
+)
+
+// lockWasmPinned acquires wasmPinLock. Updating the map may allocate and thus
+// run the garbage collector, so a thread waiting for the lock is marked as
+// stopped to let a collection cycle started by the lock holder finish.
+func lockWasmPinned() {
+	gcBlockingStart()
+	wasmPinLock.Lock()
+	gcBlockingEnd()
+}
+
+// wasmPin keeps the memory ptr points to alive until wasmUnpin is called with
+// the same pointer. It is used by malloc and for string and []byte results of
+// functions exported with //go:wasm-abi ptrlen.
+func wasmPin(ptr unsafe.Pointer) {
+	if ptr == nil {
+		return
+	}
+	lockWasmPinned()
+	if wasmPinned == nil {
+		wasmPinned = make(map[unsafe.Pointer]uintptr)
+	}
+	wasmPinned[ptr]++
+	wasmPinLock.Unlock()
+}
+
+// wasmUnpin undoes a call to wasmPin. After that, the memory is freed by the
+// garbage collector once Go code doesn't reference it anymore. It does nothing
+// if the pointer isn't pinned.
+func wasmUnpin(ptr unsafe.Pointer) {
+	lockWasmPinned()
+	count := wasmPinned[ptr]
+	if count <= 1 {
+		delete(wasmPinned, ptr)
+	} else {
+		wasmPinned[ptr] = count - 1
+	}
+	wasmPinLock.Unlock()
+}
+
+// The below functions override the default allocator of wasi-libc. They are
+// also used by the host to allocate memory for strings and slices that are
+// passed to functions exported with //go:wasm-abi ptrlen, and to release
+// strings and slices returned by them. Memory returned by malloc stays alive
+// until it is passed to free. Because the memory is managed by the garbage
+// collector, free doesn't invalidate references to it from Go.
 
 //export malloc
 func libc_malloc(size uintptr) unsafe.Pointer {
-	return alloc(size, nil)
+	ptr := alloc(size, nil)
+	wasmPin(ptr)
+	return ptr
 }
 
 //export free
 func libc_free(ptr unsafe.Pointer) {
+	wasmUnpin(ptr)
 	free(ptr)
 }
 
@@ -80,10 +130,15 @@ func libc_calloc(nmemb, size uintptr) unsafe.Pointer {
 	// Note: we could be even more correct here and check that nmemb * size
 	// doesn't overflow. However the current implementation should normally work
 	// fine.
-	return alloc(nmemb*size, nil)
+	ptr := alloc(nmemb*size, nil)
+	wasmPin(ptr)
+	return ptr
 }
 
 //export realloc
 func libc_realloc(ptr unsafe.Pointer, size uintptr) unsafe.Pointer {
-	return realloc(ptr, size)
+	newPtr := realloc(ptr, size)
+	wasmUnpin(ptr)
+	wasmPin(newPtr)
+	return newPtr
 }
@@ -137,6 +137,11 @@ func chanMake(elementSize uintptr, bufSize uintptr) *channel {
 	}
 }
 
+// wrapper for use in reflect
+func chanMakeUnsafePointer(elementSize uintptr, bufSize uintptr) unsafe.Pointer {
+	return unsafe.Pointer(chanMake(elementSize, bufSize))
+}
+
 // Return the number of entries in this chan, called from the len builtin.
 // A nil chan is defined as having length 0.
 //go:inline
@@ -194,7 +199,7 @@ func (ch *channel) resumeRX(ok bool) unsafe.Pointer {
 	}
 
 	// push task onto runqueue
-	runqueue.Push(b.t)
+	runqueuePushBack(b.t)
 
 	return dst
 }
@@ -221,7 +226,7 @@ func (ch *channel) resumeTX() unsafe.Pointer {
 	}
 
 	// push task onto runqueue
-	runqueue.Push(b.t)
+	runqueuePushBack(b.t)
 
 	return src
 }
@@ -566,6 +571,11 @@ func chanClose(ch *channel) {
 	chanDebug(ch)
 }
 
+// wrapper for use in reflect
+func chanCloseUnsafePointer(p unsafe.Pointer) {
+	chanClose((*channel)(p))
+}
+
 // chanSelect is the runtime implementation of the select statement. This is
 // perhaps the most complicated statement in the Go spec. It returns the
 // selected index and the 'comma-ok' value.
@@ -664,3 +674,13 @@ func tryChanSelect(recvbuf unsafe.Pointer, states []chanSelectState) (uintptr, b
 	interrupt.Restore(istate)
 	return ^uintptr(0), false
 }
+
+// wrapper for use in reflect: states points to a []chanSelectState. If block is
+// false, this is a non-blocking select.
+func chanSelectUnsafePointer(recvbuf, states unsafe.Pointer, block bool) (uintptr, bool) {
+	s := *(*[]chanSelectState)(states)
+	if !block {
+		return tryChanSelect(recvbuf, s)
+	}
+	return chanSelect(recvbuf, s, make([]channelBlockedList, len(s)))
+}
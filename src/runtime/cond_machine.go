@@ -0,0 +1,15 @@
+package runtime
+
+import "unsafe"
+
+// machineCondNotify and machineCondWait give package machine, which can't
+// import the runtime, access to Cond. Drivers use them to wake a goroutine
+// waiting for a peripheral from its interrupt handler.
+
+func machineCondNotify(c unsafe.Pointer) bool {
+	return (*Cond)(c).Notify()
+}
+
+func machineCondWait(c unsafe.Pointer) {
+	(*Cond)(c).Wait()
+}
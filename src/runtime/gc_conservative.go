@@ -55,6 +55,7 @@ var (
 	metadataStart unsafe.Pointer // pointer to the start of the heap metadata
 	nextAlloc     gcBlock        // the next block that should be tried by the allocator
 	endBlock      gcBlock        // the block just past the end of the available space
+	gcLock        mutex          // protects the heap metadata when there are multiple threads
 )
 
 // zeroSizedAlloc is just a sentinel that gets returned when allocating 0 bytes.
@@ -275,6 +276,11 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 
 	neededBlocks := (size + (bytesPerBlock - 1)) / bytesPerBlock
 
+	// Let a garbage collection cycle on another thread finish first, if there
+	// is one.
+	gcSafepoint()
+	gcLock.Lock()
+
 	// Continue looping until a run of free blocks has been found that fits the
 	// requested size.
 	index := nextAlloc
@@ -289,7 +295,9 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 				// could be found. Run a garbage collection cycle to reclaim
 				// free memory and try again.
 				heapScanCount = 2
+				gcLock.Unlock()
 				GC()
+				gcLock.Lock()
 			} else {
 				// Even after garbage collection, no free memory could be found.
 				// Try to increase heap size.
@@ -339,6 +347,7 @@ func alloc(size uintptr, layout unsafe.Pointer) unsafe.Pointer {
 
 			// Return a pointer to this allocation.
 			pointer := thisAlloc.pointer()
+			gcLock.Unlock()
 			memzero(pointer, size)
 			return pointer
 		}
@@ -351,7 +360,9 @@ func realloc(ptr unsafe.Pointer, size uintptr) unsafe.Pointer {
 	}
 
 	ptrAddress := uintptr(ptr)
+	gcLock.Lock()
 	endOfTailAddress := blockFromAddr(ptrAddress).findNext().address()
+	gcLock.Unlock()
 
 	// this might be a few bytes longer than the original size of
 	// ptr, because we align to full blocks of size bytesPerBlock
@@ -373,6 +384,10 @@ func free(ptr unsafe.Pointer) {
 
 // GC performs a garbage collection cycle.
 func GC() {
+	// Make sure no other thread modifies the heap during the collection.
+	gcStopTheWorld()
+	gcLock.Lock()
+
 	if gcDebug {
 		println("running collection cycle...")
 	}
@@ -420,6 +435,9 @@ func GC() {
 	if gcDebug {
 		dumpHeap()
 	}
+
+	gcLock.Unlock()
+	gcStartTheWorld()
 }
 
 // markRoots reads all pointers from start to end (exclusive) and if they look
@@ -8,6 +8,7 @@ import (
 )
 
 //go:extern runtime.stackChainStart
+//go:threadlocal
 var stackChainStart *stackChainObject
 
 type stackChainObject struct {
@@ -24,7 +25,13 @@ type stackChainObject struct {
 // optimizations, but it has the big advantage of being portable to basically
 // any ISA, including WebAssembly.
 func markStack() {
-	stackObject := stackChainStart
+	markStackChain(stackChainStart)
+	markOtherThreadStacks()
+}
+
+// markStackChain marks all root pointers in the stack objects of the given
+// stack chain.
+func markStackChain(stackObject *stackChainObject) {
 	for stackObject != nil {
 		start := uintptr(unsafe.Pointer(stackObject)) + unsafe.Sizeof(uintptr(0))*2
 		end := start + stackObject.numSlots*unsafe.Alignof(uintptr(0))
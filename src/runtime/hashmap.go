@@ -32,9 +32,11 @@ type hashmapBucket struct {
 }
 
 type hashmapIterator struct {
-	bucketNumber uintptr
+	bucketNumber uintptr // number of buckets visited so far
 	bucket       *hashmapBucket
-	bucketIndex  uint8
+	bucketIndex  uint8   // number of slots visited in the current bucket
+	startBucket  uintptr // bucket where iteration started
+	startIndex   uint8   // slot offset within each bucket
 }
 
 // Get the topmost 8 bits of the hash, without using a special value (like 0).
@@ -256,6 +258,13 @@ func hashmapNext(m *hashmap, it *hashmapIterator, key, value unsafe.Pointer) boo
 	}
 
 	numBuckets := uintptr(1) << m.bucketBits
+	if it.bucketNumber == 0 && it.bucket == nil {
+		// Start iterating at a random bucket and slot, like the Go runtime
+		// does, so that programs don't accidentally depend on map order.
+		r := hashmapIteratorSeed()
+		it.startBucket = uintptr(r) & (numBuckets - 1)
+		it.startIndex = uint8(r>>29) & 7
+	}
 	for {
 		if it.bucketIndex >= 8 {
 			// end of bucket, move to the next in the chain
@@ -267,21 +276,23 @@ func hashmapNext(m *hashmap, it *hashmapIterator, key, value unsafe.Pointer) boo
 				// went through all buckets
 				return false
 			}
+			bucketNumber := (it.startBucket + it.bucketNumber) & (numBuckets - 1)
 			bucketSize := unsafe.Sizeof(hashmapBucket{}) + uintptr(m.keySize)*8 + uintptr(m.valueSize)*8
-			bucketAddr := uintptr(m.buckets) + bucketSize*it.bucketNumber
+			bucketAddr := uintptr(m.buckets) + bucketSize*bucketNumber
 			it.bucket = (*hashmapBucket)(unsafe.Pointer(bucketAddr))
 			it.bucketNumber++ // next bucket
 		}
-		if it.bucket.tophash[it.bucketIndex] == 0 {
+		slot := (it.bucketIndex + it.startIndex) & 7
+		if it.bucket.tophash[slot] == 0 {
 			// slot is empty - move on
 			it.bucketIndex++
 			continue
 		}
 
 		bucketAddr := uintptr(unsafe.Pointer(it.bucket))
-		slotKeyOffset := unsafe.Sizeof(hashmapBucket{}) + uintptr(m.keySize)*uintptr(it.bucketIndex)
+		slotKeyOffset := unsafe.Sizeof(hashmapBucket{}) + uintptr(m.keySize)*uintptr(slot)
 		slotKey := unsafe.Pointer(bucketAddr + slotKeyOffset)
-		slotValueOffset := unsafe.Sizeof(hashmapBucket{}) + uintptr(m.keySize)*8 + uintptr(m.valueSize)*uintptr(it.bucketIndex)
+		slotValueOffset := unsafe.Sizeof(hashmapBucket{}) + uintptr(m.keySize)*8 + uintptr(m.valueSize)*uintptr(slot)
 		slotValue := unsafe.Pointer(bucketAddr + slotValueOffset)
 		memcpy(key, slotKey, uintptr(m.keySize))
 		memcpy(value, slotValue, uintptr(m.valueSize))
@@ -445,3 +456,54 @@ func hashmapInterfaceDelete(m *hashmap, key interface{}) {
 	hash := hashmapInterfaceHash(key)
 	hashmapDelete(m, unsafe.Pointer(&key), hash, hashmapInterfaceEqual)
 }
+
+// Wrappers for use in reflect. The reflect package cannot refer to the hashmap
+// type, so it passes hashmaps and iterators as unsafe.Pointer.
+
+func hashmapMakeUnsafePointer(keySize, valueSize uint8, sizeHint uintptr) unsafe.Pointer {
+	return unsafe.Pointer(hashmapMake(keySize, valueSize, sizeHint))
+}
+
+func hashmapNewIteratorUnsafePointer() unsafe.Pointer {
+	return unsafe.Pointer(new(hashmapIterator))
+}
+
+func hashmapNextUnsafePointer(m, it, key, value unsafe.Pointer) bool {
+	return hashmapNext((*hashmap)(m), (*hashmapIterator)(it), key, value)
+}
+
+func hashmapBinarySetUnsafePointer(m, key, value unsafe.Pointer) {
+	hashmapBinarySet((*hashmap)(m), key, value)
+}
+
+func hashmapBinaryGetUnsafePointer(m, key, value unsafe.Pointer, valueSize uintptr) bool {
+	return hashmapBinaryGet((*hashmap)(m), key, value, valueSize)
+}
+
+func hashmapBinaryDeleteUnsafePointer(m, key unsafe.Pointer) {
+	hashmapBinaryDelete((*hashmap)(m), key)
+}
+
+func hashmapStringSetUnsafePointer(m unsafe.Pointer, key string, value unsafe.Pointer) {
+	hashmapStringSet((*hashmap)(m), key, value)
+}
+
+func hashmapStringGetUnsafePointer(m unsafe.Pointer, key string, value unsafe.Pointer, valueSize uintptr) bool {
+	return hashmapStringGet((*hashmap)(m), key, value, valueSize)
+}
+
+func hashmapStringDeleteUnsafePointer(m unsafe.Pointer, key string) {
+	hashmapStringDelete((*hashmap)(m), key)
+}
+
+func hashmapInterfaceSetUnsafePointer(m unsafe.Pointer, key interface{}, value unsafe.Pointer) {
+	hashmapInterfaceSet((*hashmap)(m), key, value)
+}
+
+func hashmapInterfaceGetUnsafePointer(m unsafe.Pointer, key interface{}, value unsafe.Pointer, valueSize uintptr) bool {
+	return hashmapInterfaceGet((*hashmap)(m), key, value, valueSize)
+}
+
+func hashmapInterfaceDeleteUnsafePointer(m unsafe.Pointer, key interface{}) {
+	hashmapInterfaceDelete((*hashmap)(m), key)
+}
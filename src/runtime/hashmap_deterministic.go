@@ -0,0 +1,10 @@
+//go:build maps.deterministic
+// +build maps.deterministic
+
+package runtime
+
+// Always start map iteration at the first bucket, so that iteration order only
+// depends on the order of insertion and deletion.
+func hashmapIteratorSeed() uint32 {
+	return 0
+}
@@ -0,0 +1,11 @@
+//go:build !maps.deterministic
+// +build !maps.deterministic
+
+package runtime
+
+// Return a random number used to pick the point where map iteration starts.
+// Build with -tags=maps.deterministic to always iterate in the same order,
+// which can be useful for reproducible firmware.
+func hashmapIteratorSeed() uint32 {
+	return fastrand()
+}
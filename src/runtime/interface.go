@@ -107,10 +107,12 @@ type typecodeID struct {
 	// * interface: null
 	// * chan/pointer/slice/array: the element type
 	// * struct: bitcast of global with structField array
-	// * func/map: TODO
+	// * func: bitcast of global with the signature (see compiler/reflect.go)
+	// * map: bitcast of global with the key and value type
 	references *typecodeID
 
-	// The array length, for array types.
+	// The array length, for array types. For channel types, this is the
+	// channel direction (as a reflect.ChanDir).
 	length uintptr
 
 	methodSet *interfaceMethodInfo // nil or a GEP of an array
@@ -121,8 +123,27 @@ type typecodeID struct {
 	ptrTo *typecodeID
 
 	// typeAssert is a ptrtoint of a declared interface assert function.
-	// It only exists to make the rtcalls pass easier.
+	// It is used by the rtcalls pass and, to implement Type.Implements at
+	// runtime, by the reflect lowering pass.
 	typeAssert uintptr
+
+	// List of methods that can be called through the reflect package: the
+	// exported methods of concrete types and all methods of interface types.
+	methods *reflectMethod // nil or a GEP of an array
+
+	// The name of a named type as returned by reflect.Type.String(), for
+	// example "json.Decoder". It is nil for other types.
+	name *uint8 // pointer to char array
+}
+
+// reflectMethod is used by the compiler to pass information about a method to
+// the reflect lowering pass. It is not used in the final binary.
+type reflectMethod struct {
+	name      *uint8      // pointer to char array
+	typecode  *typecodeID // method signature, without receiver
+	funcType  *typecodeID // signature with the receiver as first parameter, nil for interfaces
+	boundFunc uintptr     // ptrtoint of a wrapper that takes the receiver as context
+	funcptr   uintptr     // ptrtoint of the method itself
 }
 
 // structField is used by the compiler to pass information to the interface
@@ -132,6 +153,7 @@ type structField struct {
 	name     *uint8      // pointer to char array
 	tag      *uint8      // pointer to char array, or nil
 	embedded bool
+	pkgPath  *uint8 // pointer to char array, or nil for exported fields
 }
 
 // Pseudo function call used during a type assert. It is used during interface
@@ -1,5 +1,5 @@
-//go:build !baremetal
-// +build !baremetal
+//go:build !baremetal && !wasi_threads
+// +build !baremetal,!wasi_threads
 
 package interrupt
 
@@ -0,0 +1,75 @@
+//go:build wasi_threads
+// +build wasi_threads
+
+package interrupt
+
+// There are no interrupts on WebAssembly, but goroutines may run in parallel
+// on multiple threads. Therefore, a critical section is implemented with a
+// single global lock that is shared by all threads. The lock may be taken
+// recursively by the same thread, just like interrupts can be disabled while
+// they are already disabled.
+//
+// The lock must not be held while pausing the current goroutine or while
+// allocating heap memory, because that could deadlock with another thread.
+
+import (
+	"sync/atomic"
+)
+
+// State represents the previous global interrupt state.
+type State uintptr
+
+// State of the lock: 0 means unlocked, 1 means locked, and 2 means locked
+// while other threads may be waiting for it.
+var lockState uint32
+
+// Whether the current thread holds the lock.
+//go:threadlocal
+var lockHeld bool
+
+//export llvm.wasm.memory.atomic.wait32
+func wasmMemoryAtomicWait32(ptr *uint32, expected uint32, timeout int64) int32
+
+//export llvm.wasm.memory.atomic.notify
+func wasmMemoryAtomicNotify(ptr *uint32, count uint32) uint32
+
+// Disable disables all interrupts and returns the previous interrupt state. It
+// can be used in a critical section like this:
+//
+//     state := interrupt.Disable()
+//     // critical section
+//     interrupt.Restore(state)
+//
+// Critical sections can be nested. Make sure to call Restore in the same order
+// as you called Disable (this happens naturally with the pattern above).
+func Disable() (state State) {
+	if lockHeld {
+		// Nested critical section.
+		return 1
+	}
+	if !atomic.CompareAndSwapUint32(&lockState, 0, 1) {
+		// The lock is held by another thread. Mark it as contended and wait
+		// until it is released.
+		for atomic.SwapUint32(&lockState, 2) != 0 {
+			wasmMemoryAtomicWait32(&lockState, 2, -1)
+		}
+	}
+	lockHeld = true
+	return 0
+}
+
+// Restore restores interrupts to what they were before. Give the previous state
+// returned by Disable as a parameter. If interrupts were disabled before
+// calling Disable, this will not re-enable interrupts, allowing for nested
+// cricital sections.
+func Restore(state State) {
+	if state != 0 {
+		// Still in a critical section.
+		return
+	}
+	lockHeld = false
+	if atomic.SwapUint32(&lockState, 0) == 2 {
+		// Wake up one of the waiting threads.
+		wasmMemoryAtomicNotify(&lockState, 1)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build baremetal && ((nrf && !softdevice) || (stm32 && !(stm32f103 || stm32l0x1)) || (sam && atsamd51) || (sam && atsame5x))
+// +build baremetal
+// +build nrf,!softdevice stm32,!stm32f103,!stm32l0x1 sam,atsamd51 sam,atsame5x
+
+package runtime
+
+import "machine"
+
+// Read 64 bits of random data from the on-chip random number generator.
+func hardwareRand() (n uint64, ok bool) {
+	n1, err1 := machine.GetRNG()
+	n2, err2 := machine.GetRNG()
+	n = uint64(n1)<<32 | uint64(n2)
+	ok = err1 == nil && err2 == nil
+	return
+}
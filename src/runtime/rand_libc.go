@@ -0,0 +1,17 @@
+//go:build (darwin || (linux && !baremetal) || tinygo.wasm) && !nintendoswitch
+// +build darwin linux,!baremetal tinygo.wasm
+// +build !nintendoswitch
+
+package runtime
+
+import "unsafe"
+
+// Read 64 bits of random data from the operating system.
+func hardwareRand() (n uint64, ok bool) {
+	result := libc_getentropy(unsafe.Pointer(&n), 8)
+	return n, result == 0
+}
+
+// int getentropy(void *buffer, size_t length);
+//export getentropy
+func libc_getentropy(buffer unsafe.Pointer, length uint) int32
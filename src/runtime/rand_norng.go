@@ -0,0 +1,14 @@
+//go:build baremetal && !((nrf && !softdevice) || (stm32 && !(stm32f103 || stm32l0x1)) || (sam && atsamd51) || (sam && atsame5x))
+// +build baremetal
+// +build !nrf softdevice
+// +build !stm32 stm32f103 stm32l0x1
+// +build !sam !atsamd51
+// +build !sam !atsame5x
+
+package runtime
+
+// This chip has no (supported) random number generator, so map iteration
+// order and hash/maphash seeds are only pseudo-random.
+func hardwareRand() (n uint64, ok bool) {
+	return 0, false
+}
@@ -15,11 +15,6 @@ func initAll()
 //go:linkname callMain main.main
 func callMain()
 
-func GOMAXPROCS(n int) int {
-	// Note: setting GOMAXPROCS is ignored.
-	return 1
-}
-
 func GOROOT() string {
 	// TODO: don't hardcode but take the one at compile time.
 	return "/usr/local/go"
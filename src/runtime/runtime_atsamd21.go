@@ -66,10 +66,12 @@ func initClocks() {
 	// SYSCTRL_OSC32K_CALIB(calib) |
 	//  SYSCTRL_OSC32K_STARTUP(0x6u) |
 	//  SYSCTRL_OSC32K_EN32K | SYSCTRL_OSC32K_ENABLE;
+	// It keeps running in standby, for the RTC.
 	sam.SYSCTRL.OSC32K.Set((calib << sam.SYSCTRL_OSC32K_CALIB_Pos) |
 		(0x6 << sam.SYSCTRL_OSC32K_STARTUP_Pos) |
 		sam.SYSCTRL_OSC32K_EN32K |
 		sam.SYSCTRL_OSC32K_EN1K |
+		sam.SYSCTRL_OSC32K_RUNSTDBY |
 		sam.SYSCTRL_OSC32K_ENABLE)
 	// Wait for oscillator stabilization
 	for !sam.SYSCTRL.PCLKSR.HasBits(sam.SYSCTRL_PCLKSR_OSC32KRDY) {
@@ -172,12 +174,13 @@ func initClocks() {
 	waitForSync()
 
 	// Use OSC32K as source for Generic Clock Generator 2
-	// OSC32K/1 -> GCLK2 at 32KHz
+	// OSC32K/1 -> GCLK2 at 32KHz, also in standby
 	sam.GCLK.GENDIV.Set(2 << sam.GCLK_GENDIV_ID_Pos)
 	waitForSync()
 
 	sam.GCLK.GENCTRL.Set((2 << sam.GCLK_GENCTRL_ID_Pos) |
 		(sam.GCLK_GENCTRL_SRC_OSC32K << sam.GCLK_GENCTRL_SRC_Pos) |
+		sam.GCLK_GENCTRL_RUNSTDBY |
 		sam.GCLK_GENCTRL_GENEN)
 	waitForSync()
 
@@ -306,11 +309,46 @@ func readRTC() uint32 {
 	return sam.RTC_MODE0.COUNT.Get()
 }
 
+// lightSleepTicks is the sleep duration (about 10ms) from which it is worth
+// stopping the AHB and APB clocks while sleeping.
+const lightSleepTicks = 328
+
 // ticks are in microseconds
 // Returns true if the timer completed.
 // Returns false if another interrupt occured which requires an early return to scheduler.
 func timerSleep(ticks uint32) bool {
+	// For long sleeps, also stop the AHB and APB clocks (IDLE2) unless USB is
+	// in use, which needs them to respond to the host. Peripherals keep
+	// running from their generic clock and their interrupts still wake up the
+	// chip.
+	idle := uint8(sam.PM_SLEEP_IDLE_CPU)
+	if ticks >= lightSleepTicks && !sam.USB_DEVICE.CTRLA.HasBits(sam.USB_DEVICE_CTRLA_ENABLE) {
+		idle = sam.PM_SLEEP_IDLE_APB
+	}
+	sam.PM.SLEEP.Set(idle << sam.PM_SLEEP_IDLE_Pos)
+
 	timerWakeup.Set(0)
+	setAlarm(ticks)
+
+wait:
+	waitForEvents()
+	if timerWakeup.Get() != 0 {
+		return true
+	}
+	if hasScheduler {
+		// The interurpt may have awoken a goroutine, so bail out early.
+		// Disable IRQ for CMP0 compare.
+		sam.RTC_MODE0.INTENCLR.Set(sam.RTC_MODE0_INTENSET_CMP0)
+		return false
+	} else {
+		// This is running without a scheduler.
+		// The application expects this to sleep the whole time.
+		goto wait
+	}
+}
+
+// setAlarm sets timerWakeup after the given number of ticks.
+func setAlarm(ticks uint32) {
 	if ticks < 7 {
 		// Due to around 6 clock ticks delay waiting for the register value to
 		// sync, the minimum sleep value for the SAMD21 is 214us.
@@ -330,22 +368,29 @@ func timerSleep(ticks uint32) bool {
 
 	// enable IRQ for CMP0 compare
 	sam.RTC_MODE0.INTENSET.Set(sam.RTC_MODE0_INTENSET_CMP0)
+}
 
-wait:
-	waitForEvents()
-	if timerWakeup.Get() != 0 {
-		return true
-	}
-	if hasScheduler {
-		// The interurpt may have awoken a goroutine, so bail out early.
-		// Disable IRQ for CMP0 compare.
-		sam.RTC_MODE0.INTENCLR.Set(sam.RTC_MODE0_INTENSET_CMP0)
-		return false
-	} else {
-		// This is running without a scheduler.
-		// The application expects this to sleep the whole time.
-		goto wait
+// deepSleep puts the chip in standby until an interrupt or until the timeout
+// (in nanoseconds) has passed, for machine.DeepSleep. The RTC keeps running in
+// standby, so no time is lost.
+func deepSleep(ns int64) {
+	if ns != 0 {
+		ticks := nanosecondsToTicks(ns)
+		if ticks > 0x7fffffff {
+			ticks = 0x7fffffff
+		}
+		timerWakeup.Set(0)
+		setAlarm(uint32(ticks))
 	}
+
+	// Keep the NVM powered in standby. Waking up with it powered down may fail
+	// on early chip revisions (errata 13140).
+	sam.NVMCTRL.CTRLB.SetBits(sam.NVMCTRL_CTRLB_SLEEPPRM_DISABLED << sam.NVMCTRL_CTRLB_SLEEPPRM_Pos)
+
+	arm.SCB.SCR.SetBits(arm.SCB_SCR_SLEEPDEEP)
+	arm.Asm("dsb")
+	arm.Asm("wfi")
+	arm.SCB.SCR.ClearBits(arm.SCB_SCR_SLEEPDEEP)
 }
 
 func initUSBClock() {
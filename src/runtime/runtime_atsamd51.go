@@ -294,8 +294,35 @@ func readRTC() uint32 {
 // ticks are in microseconds
 // Returns true if the timer completed.
 // Returns false if another interrupt occured which requires an early return to scheduler.
+//
+// Unlike on the SAMD21, long sleeps don't pick a deeper sleep mode. The only
+// idle mode of the SAMD51, which is selected at reset, already stops the CPU,
+// AHB and APB clocks. STANDBY would also stop the generic clocks of the
+// peripherals that don't run in standby (such as the SERCOMs, timers and USB),
+// which loses received data, so it is only used by machine.DeepSleep.
 func timerSleep(ticks uint32) bool {
 	timerWakeup.Set(0)
+	setAlarm(ticks)
+
+wait:
+	waitForEvents()
+	if timerWakeup.Get() != 0 {
+		return true
+	}
+	if hasScheduler {
+		// The interurpt may have awoken a goroutine, so bail out early.
+		// Disable IRQ for CMP0 compare.
+		sam.RTC_MODE0.INTENCLR.Set(sam.RTC_MODE0_INTENSET_CMP0)
+		return false
+	} else {
+		// This is running without a scheduler.
+		// The application expects this to sleep the whole time.
+		goto wait
+	}
+}
+
+// setAlarm sets timerWakeup after the given number of ticks.
+func setAlarm(ticks uint32) {
 	if ticks < 8 {
 		// due to delay waiting for the register value to sync, the minimum sleep value
 		// for the SAMD51 is 260us.
@@ -314,21 +341,32 @@ func timerSleep(ticks uint32) bool {
 
 	// enable IRQ for CMP0 compare
 	sam.RTC_MODE0.INTENSET.Set(sam.RTC_MODE0_INTENSET_CMP0)
+}
 
-wait:
-	waitForEvents()
-	if timerWakeup.Get() != 0 {
-		return true
+// deepSleep puts the chip in standby until an interrupt or until the timeout
+// (in nanoseconds) has passed, for machine.DeepSleep. The RTC runs from the
+// ultra low power oscillator, which keeps running in standby, so no time is
+// lost.
+func deepSleep(ns int64) {
+	if ns != 0 {
+		ticks := nanosecondsToTicks(ns)
+		if ticks > 0x7fffffff {
+			ticks = 0x7fffffff
+		}
+		timerWakeup.Set(0)
+		setAlarm(uint32(ticks))
 	}
-	if hasScheduler {
-		// The interurpt may have awoken a goroutine, so bail out early.
-		// Disable IRQ for CMP0 compare.
-		sam.RTC_MODE0.INTENCLR.Set(sam.RTC_MODE0_INTENSET_CMP0)
-		return false
-	} else {
-		// This is running without a scheduler.
-		// The application expects this to sleep the whole time.
-		goto wait
+
+	// The sleep mode must be read back before it takes effect.
+	sam.PM.SLEEPCFG.Set(sam.PM_SLEEPCFG_SLEEPMODE_STANDBY << sam.PM_SLEEPCFG_SLEEPMODE_Pos)
+	for sam.PM.SLEEPCFG.Get() != sam.PM_SLEEPCFG_SLEEPMODE_STANDBY<<sam.PM_SLEEPCFG_SLEEPMODE_Pos {
+	}
+	arm.Asm("dsb")
+	arm.Asm("wfi")
+
+	// Go back to the default idle mode, which is used by waitForEvents.
+	sam.PM.SLEEPCFG.Set(sam.PM_SLEEPCFG_SLEEPMODE_IDLE2 << sam.PM_SLEEPCFG_SLEEPMODE_Pos)
+	for sam.PM.SLEEPCFG.Get() != sam.PM_SLEEPCFG_SLEEPMODE_IDLE2<<sam.PM_SLEEPCFG_SLEEPMODE_Pos {
 	}
 }
 
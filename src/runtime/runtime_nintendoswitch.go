@@ -104,6 +104,11 @@ func abort() {
 	}
 }
 
+// There is no source of randomness implemented yet for the Nintendo Switch.
+func hardwareRand() (n uint64, ok bool) {
+	return 0, false
+}
+
 //export write
 func write(fd int32, buf *byte, count int) int {
 	// TODO: Proper handling write
@@ -120,7 +120,19 @@ func ticks() timeUnit {
 
 var rtc_wakeup volatile.Register8
 
+// rtc_sleep waits in System ON, in the low power sub-mode that is selected at
+// reset. There is no deeper mode for long sleeps: the chip already turns off
+// the clocks and regulators that no running peripheral needs, and the only
+// deeper mode, System OFF, can't be woken up by the RTC and resets the chip.
 func rtc_sleep(ticks uint32) {
+	rtc_setAlarm(ticks)
+	for rtc_wakeup.Get() == 0 {
+		waitForEvents()
+	}
+}
+
+// rtc_setAlarm sets rtc_wakeup after the given number of ticks.
+func rtc_setAlarm(ticks uint32) {
 	nrf.RTC1.INTENSET.Set(nrf.RTC_INTENSET_COMPARE0)
 	rtc_wakeup.Set(0)
 	if ticks == 1 {
@@ -130,7 +142,21 @@ func rtc_sleep(ticks uint32) {
 		ticks = 2
 	}
 	nrf.RTC1.CC[0].Set((nrf.RTC1.COUNTER.Get() + ticks) & 0x00ffffff)
-	for rtc_wakeup.Get() == 0 {
-		waitForEvents()
+}
+
+// deepSleep waits for an interrupt or until the timeout (in nanoseconds) has
+// passed, for machine.DeepSleep. The chip stays in System ON, as the RTC can't
+// wake it up from System OFF. In System ON, the chip already turns off all
+// clocks and regulators that aren't needed while waiting for an event.
+func deepSleep(ns int64) {
+	if ns != 0 {
+		ticks := nanosecondsToTicks(ns)
+		if ticks < 2 {
+			ticks = 2
+		} else if ticks > 0x7fffff {
+			ticks = 0x7fffff
+		}
+		rtc_setAlarm(uint32(ticks))
 	}
+	waitForEvents()
 }
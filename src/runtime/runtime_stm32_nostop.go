@@ -0,0 +1,10 @@
+//go:build stm32 && !stm32l4
+// +build stm32,!stm32l4
+
+package runtime
+
+// stopSleep is only implemented on the STM32L4, other chips wait for the next
+// tick interrupt in sleep mode.
+func stopSleep(ticks uint64) bool {
+	return false
+}
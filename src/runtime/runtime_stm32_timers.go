@@ -46,6 +46,9 @@ var (
 	// Tick count since boot
 	tickCount volatile.Register64
 
+	// Ticks spent in a low-power mode in which the tick timer doesn't run
+	stoppedTicks volatile.Register64
+
 	// The timer used for counting ticks
 	tickTimer *machine.TIM
 
@@ -79,7 +82,7 @@ func ticks() timeUnit {
 			continue
 		}
 
-		return timeUnit(overflows*TICK_PER_INTR + countToTicks(counter))
+		return timeUnit(overflows*TICK_PER_INTR + countToTicks(counter) + stoppedTicks.Get())
 	}
 }
 
@@ -144,8 +147,12 @@ func timerSleep(ticks uint64) {
 
 	// If the sleep is long, the tick interrupt will occur before
 	// the sleep expires, so just use that.  This routine will be
-	// called again if the sleep is incomplete.
+	// called again if the sleep is incomplete. Chips that can stop
+	// their clocks do so for the whole sleep instead.
 	if ticks >= TICK_PER_INTR {
+		if stopSleep(ticks) {
+			return
+		}
 		waitForEvents()
 		return
 	}
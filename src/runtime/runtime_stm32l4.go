@@ -4,8 +4,10 @@
 package runtime
 
 import (
+	"device/arm"
 	"device/stm32"
 	"machine"
+	"runtime/interrupt"
 )
 
 const (
@@ -31,6 +33,14 @@ const (
 	RCC_PLLSOURCE_MSI = 1
 
 	RCC_PLL_SYSCLK = stm32.RCC_PLLCFGR_PLLREN
+
+	RCC_CCIPR_LPTIM1SEL_LSI = 1 << stm32.RCC_CCIPR_LPTIM1SEL_Pos
+
+	PWR_CR1_LPMS_STOP2 = 2 << stm32.PWR_CR1_LPMS_Pos
+
+	// LPTIM1 runs from the LSI at 32kHz, divided by 16.
+	LPTIM_CFGR_PRESC_DIV16 = 4 << stm32.LPTIM_CFGR_PRESC_Pos
+	LPTIM_FREQ             = 32000 / 16
 )
 
 type arrtype = uint32
@@ -217,3 +227,114 @@ func pwrClkDisable() {
 func pwrExGetVoltageRange() uint32 {
 	return stm32.PWR.CR1.Get() & stm32.PWR_CR1_VOS_Msk
 }
+
+// lptimInterrupt wakes up the chip from STOP 2 mode, in which the tick timer
+// doesn't run.
+var lptimInterrupt interrupt.Interrupt
+
+// initLPTIM starts LPTIM1 as a free running 16-bit counter. It measures the
+// time spent in STOP 2 mode and wakes up the chip with its compare match.
+func initLPTIM() {
+	stm32.RCC.CSR.SetBits(stm32.RCC_CSR_LSION)
+	for !stm32.RCC.CSR.HasBits(stm32.RCC_CSR_LSIRDY) {
+	}
+	stm32.RCC.CCIPR.ReplaceBits(RCC_CCIPR_LPTIM1SEL_LSI, stm32.RCC_CCIPR_LPTIM1SEL_Msk, 0)
+	stm32.RCC.APB1ENR1.SetBits(stm32.RCC_APB1ENR1_LPTIM1EN)
+
+	// CFGR and IER can only be written while the timer is disabled, ARR and
+	// CMP only while it is enabled.
+	stm32.LPTIM1.CFGR.Set(LPTIM_CFGR_PRESC_DIV16)
+	stm32.LPTIM1.IER.Set(stm32.LPTIM_IER_CMPMIE)
+	stm32.LPTIM1.CR.Set(stm32.LPTIM_CR_ENABLE)
+	stm32.LPTIM1.ARR.Set(0xffff)
+	for !stm32.LPTIM1.ISR.HasBits(stm32.LPTIM_ISR_ARROK) {
+	}
+	stm32.LPTIM1.ICR.Set(stm32.LPTIM_ICR_ARROKCF)
+	stm32.LPTIM1.CR.SetBits(stm32.LPTIM_CR_CNTSTRT)
+
+	// EXTI line 32 wakes up the chip on the LPTIM1 interrupt.
+	stm32.EXTI.IMR2.SetBits(1 << 0)
+	lptimInterrupt = interrupt.New(stm32.IRQ_LPTIM1, func(interrupt.Interrupt) {
+		stm32.LPTIM1.ICR.Set(stm32.LPTIM_ICR_CMPMCF)
+	})
+}
+
+// lptimCount reads the LPTIM1 counter. It runs from a different clock, so
+// it must be read until two reads return the same value.
+func lptimCount() uint32 {
+	for {
+		count := stm32.LPTIM1.CNT.Get()
+		if stm32.LPTIM1.CNT.Get() == count {
+			return count
+		}
+	}
+}
+
+// Peripherals that stop working in STOP 2 mode, by their clock enable bits.
+// The tick timer (TIM15) is left out: the time spent in STOP 2 is measured
+// with LPTIM1 instead.
+const (
+	stopAPB1ENR1 = stm32.RCC_APB1ENR1_TIM2EN | stm32.RCC_APB1ENR1_TIM3EN |
+		stm32.RCC_APB1ENR1_TIM6EN | stm32.RCC_APB1ENR1_TIM7EN |
+		stm32.RCC_APB1ENR1_SPI2EN | stm32.RCC_APB1ENR1_SPI3EN |
+		stm32.RCC_APB1ENR1_USART2EN | stm32.RCC_APB1ENR1_USART3EN | stm32.RCC_APB1ENR1_UART4EN |
+		stm32.RCC_APB1ENR1_I2C1EN | stm32.RCC_APB1ENR1_I2C2EN | stm32.RCC_APB1ENR1_I2C3EN
+	stopAPB1ENR2 = stm32.RCC_APB1ENR2_LPUART1EN | stm32.RCC_APB1ENR2_LPTIM2EN
+	stopAPB2ENR  = stm32.RCC_APB2ENR_TIM1EN | stm32.RCC_APB2ENR_TIM16EN |
+		stm32.RCC_APB2ENR_SPI1EN | stm32.RCC_APB2ENR_USART1EN
+)
+
+// stopSleep puts the chip in STOP 2 mode for a long sleep of the scheduler, if
+// no peripheral needs its clock while sleeping. Any interrupt still wakes up
+// the chip early, like in sleep mode.
+//
+// A peripheral needs its clock once the machine package has enabled it: the
+// timers, SPI and I2C would stop in the middle of their work and the UARTs
+// would drop received bytes. This includes the serial console, which is
+// configured at startup unless the program is built with -serial=none.
+func stopSleep(ticks uint64) bool {
+	if stm32.RCC.APB1ENR1.HasBits(stopAPB1ENR1) ||
+		stm32.RCC.APB1ENR2.HasBits(stopAPB1ENR2) ||
+		stm32.RCC.APB2ENR.HasBits(stopAPB2ENR) {
+		return false
+	}
+	deepSleep(ticksToNanoseconds(timeUnit(ticks)))
+	return true
+}
+
+// deepSleep puts the chip in STOP 2 mode until an interrupt or until the
+// timeout (in nanoseconds) has passed, for machine.DeepSleep and long sleeps of
+// the scheduler. The chip wakes up at least every 30 seconds, which keeps the
+// time spent sleeping measurable.
+func deepSleep(ns int64) {
+	if !stm32.LPTIM1.CR.HasBits(stm32.LPTIM_CR_ENABLE) {
+		initLPTIM()
+	}
+
+	count := uint32(0xf000)
+	if ns != 0 && ns < int64(count)*1e9/LPTIM_FREQ {
+		count = uint32(ns * LPTIM_FREQ / 1e9)
+		if count < 2 {
+			count = 2
+		}
+	}
+	start := lptimCount()
+	stm32.LPTIM1.ICR.Set(stm32.LPTIM_ICR_CMPOKCF | stm32.LPTIM_ICR_CMPMCF)
+	stm32.LPTIM1.CMP.Set((start + count) & 0xffff)
+	for !stm32.LPTIM1.ISR.HasBits(stm32.LPTIM_ISR_CMPOK) {
+	}
+	lptimInterrupt.Enable()
+
+	stm32.PWR.CR1.ReplaceBits(PWR_CR1_LPMS_STOP2, stm32.PWR_CR1_LPMS_Msk, 0)
+	arm.SCB.SCR.SetBits(arm.SCB_SCR_SLEEPDEEP)
+	arm.Asm("dsb")
+	arm.Asm("wfi")
+	arm.SCB.SCR.ClearBits(arm.SCB_SCR_SLEEPDEEP)
+
+	// The chip wakes up running from the MSI, and the tick timer didn't count
+	// while it was stopped.
+	initCLK()
+	lptimInterrupt.Disable()
+	elapsed := (lptimCount() - start) & 0xffff
+	stoppedTicks.Set(stoppedTicks.Get() + uint64(nanosecondsToTicks(int64(elapsed)*1e9/LPTIM_FREQ)))
+}
@@ -4,6 +4,7 @@
 package runtime
 
 import (
+	"runtime/interrupt"
 	"unsafe"
 )
 
@@ -39,6 +40,8 @@ var (
 )
 
 func putchar(c byte) {
+	// The buffer is shared between threads, if there are any.
+	i := interrupt.Disable()
 	putcharBuffer[putcharPosition] = c
 	putcharPosition++
 
@@ -47,6 +50,7 @@ func putchar(c byte) {
 		fd_write(stdout, &putcharIOVec, 1, &putcharNWritten)
 		putcharPosition = 0
 	}
+	interrupt.Restore(i)
 }
 
 //go:linkname now time.now
@@ -67,8 +71,8 @@ func syscall_Exit(code int) {
 	proc_exit(uint32(code))
 }
 
-// TinyGo does not yet support any form of parallelism on WebAssembly, so these
-// can be left empty.
+// These can be left empty: on a single thread there is no parallelism, and with
+// the wasi-threads target sync/atomic uses real atomic instructions.
 
 //go:linkname procPin sync/atomic.runtime_procPin
 func procPin() {
@@ -13,14 +13,6 @@ type timeUnit int64
 //export __wasm_call_ctors
 func __wasm_call_ctors()
 
-//export _start
-func _start() {
-	// These need to be initialized early so that the heap can be initialized.
-	heapStart = uintptr(unsafe.Pointer(&heapStartSymbol))
-	heapEnd = uintptr(wasm_memory_size(0) * wasmPageSize)
-	run()
-}
-
 // Read the command line arguments from WASI.
 // For example, they can be passed to a program with wasmtime like this:
 //
@@ -89,10 +81,25 @@ var (
 )
 
 func sleepTicks(d timeUnit) {
+	if pollQueue != nil {
+		// Goroutines are waiting for I/O, wait for that at the same time.
+		pollIO(d, true)
+		return
+	}
 	sleepTicksSubscription.u.u.timeout = uint64(d)
 	poll_oneoff(&sleepTicksSubscription, &sleepTicksResult, 1, &sleepTicksNEvents)
 }
 
+// waitForEvents is called by the scheduler when no goroutine is runnable or
+// sleeping. The only events that can make a goroutine runnable again are I/O
+// events.
+func waitForEvents() {
+	if pollQueue == nil {
+		runtimePanic("deadlocked: no event source")
+	}
+	pollIO(0, false)
+}
+
 func ticks() timeUnit {
 	var nano uint64
 	clock_time_get(0, timePrecisionNanoseconds, &nano)
@@ -120,9 +127,9 @@ func poll_oneoff(in *__wasi_subscription_t, out *__wasi_event_t, nsubscriptions
 type __wasi_eventtype_t = uint8
 
 const (
-	__wasi_eventtype_t_clock __wasi_eventtype_t = 0
-	// TODO: __wasi_eventtype_t_fd_read  __wasi_eventtype_t = 1
-	// TODO: __wasi_eventtype_t_fd_write __wasi_eventtype_t = 2
+	__wasi_eventtype_t_clock    __wasi_eventtype_t = 0
+	__wasi_eventtype_t_fd_read  __wasi_eventtype_t = 1
+	__wasi_eventtype_t_fd_write __wasi_eventtype_t = 2
 )
 
 type (
@@ -135,7 +142,9 @@ type (
 	__wasi_subscription_u_t struct {
 		tag __wasi_eventtype_t
 
-		// TODO: support fd_read/fd_write event
+		// For fd_read and fd_write events, this is a subscription_fd_readwrite
+		// record instead. Its only field is the file descriptor, which is
+		// stored in the id field.
 		u __wasi_subscription_clock_t
 	}
 
@@ -156,8 +165,7 @@ type (
 		eventType __wasi_eventtype_t
 
 		// only used for fd_read or fd_write events
-		// TODO: support fd_read/fd_write event
-		_ struct {
+		fdReadwrite struct {
 			nBytes uint64
 			flags  uint16
 		}
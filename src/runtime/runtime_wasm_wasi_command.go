@@ -0,0 +1,16 @@
+//go:build tinygo.wasm && wasi && !buildmode.c_shared
+// +build tinygo.wasm,wasi,!buildmode.c_shared
+
+package runtime
+
+import "unsafe"
+
+// _start is the entry point of a WASI command: it runs the program and returns
+// when the main function has returned.
+//export _start
+func _start() {
+	// These need to be initialized early so that the heap can be initialized.
+	heapStart = uintptr(unsafe.Pointer(&heapStartSymbol))
+	heapEnd = uintptr(wasm_memory_size(0) * wasmPageSize)
+	run()
+}
@@ -0,0 +1,156 @@
+//go:build tinygo.wasm && wasi
+// +build tinygo.wasm,wasi
+
+package runtime
+
+// This file implements waiting for I/O on file descriptors (such as sockets),
+// for the net package. A goroutine that has to wait for I/O is paused and put
+// in the poll queue. When no goroutine is runnable, the scheduler waits for
+// both I/O events and the next sleeping goroutine using poll_oneoff, and makes
+// the goroutines whose file descriptor is ready runnable again.
+
+import (
+	"internal/task"
+	"unsafe"
+)
+
+// Goroutines that wait for I/O, linked using task.Next. The file descriptor is
+// stored in the upper bits of task.Data and the event type in the lowest byte.
+var pollQueue *task.Task
+
+// How long a thread blocks in netpollWait before it checks again whether the
+// file descriptor was closed, when there are multiple threads.
+const netpollThreadTimeout = 100 * 1000 * 1000 // 100ms in nanoseconds
+
+// Buffers for poll_oneoff, reused to avoid allocating on every call.
+var (
+	pollSubscriptions []__wasi_subscription_t
+	pollEvents        []__wasi_event_t
+)
+
+// netpollWait blocks the current goroutine until the file descriptor is ready
+// for reading (mode 'r') or writing (mode 'w'). It may return early, so the
+// caller must retry the I/O operation and wait again if needed.
+//go:linkname netpollWait net.runtime_pollWait
+func netpollWait(fd int32, mode int32) {
+	eventType := __wasi_eventtype_t_fd_read
+	if mode == 'w' {
+		eventType = __wasi_eventtype_t_fd_write
+	}
+
+	if !hasScheduler || hasThreads {
+		// Without a scheduler there are no other goroutines to run, and with
+		// threads the other goroutines can run on other threads. So simply
+		// block this thread until the file descriptor is ready.
+		subscriptions := [2]__wasi_subscription_t{{
+			u: __wasi_subscription_u_t{
+				tag: eventType,
+				u: __wasi_subscription_clock_t{
+					id: uint32(fd),
+				},
+			},
+		}}
+		nsubscriptions := uint32(1)
+		if hasThreads {
+			// Another thread may close the file descriptor in the meantime,
+			// which doesn't wake up poll_oneoff. So wake up regularly: the
+			// caller then notices that the file descriptor was closed.
+			subscriptions[1] = __wasi_subscription_t{
+				userData: 1,
+				u: __wasi_subscription_u_t{
+					tag: __wasi_eventtype_t_clock,
+					u: __wasi_subscription_clock_t{
+						timeout:   netpollThreadTimeout,
+						precision: timePrecisionNanoseconds,
+					},
+				},
+			}
+			nsubscriptions = 2
+		}
+		var events [2]__wasi_event_t
+		var nevents uint32
+		gcBlockingStart()
+		poll_oneoff(&subscriptions[0], &events[0], nsubscriptions, &nevents)
+		gcBlockingEnd()
+		return
+	}
+
+	t := task.Current()
+	t.Data = uint64(uint32(fd))<<8 | uint64(eventType)
+	t.Next = pollQueue
+	pollQueue = t
+	task.Pause()
+}
+
+// netpollUnblock makes all goroutines that wait for I/O on the given file
+// descriptor runnable. It is called before the file descriptor is closed.
+//go:linkname netpollUnblock net.runtime_pollUnblock
+func netpollUnblock(fd int32) {
+	q := &pollQueue
+	for *q != nil {
+		t := *q
+		if uint32(t.Data>>8) != uint32(fd) {
+			q = &t.Next
+			continue
+		}
+		*q = t.Next
+		t.Next = nil
+		runqueue.Push(t)
+	}
+}
+
+// pollIO waits until at least one of the goroutines in the poll queue can
+// continue, or until the timeout has expired if hasTimeout is set.
+func pollIO(timeout timeUnit, hasTimeout bool) {
+	pollSubscriptions = pollSubscriptions[:0]
+	for t := pollQueue; t != nil; t = t.Next {
+		pollSubscriptions = append(pollSubscriptions, __wasi_subscription_t{
+			userData: uint64(uintptr(unsafe.Pointer(t))),
+			u: __wasi_subscription_u_t{
+				tag: __wasi_eventtype_t(t.Data),
+				u: __wasi_subscription_clock_t{
+					id: uint32(t.Data >> 8),
+				},
+			},
+		})
+	}
+	if hasTimeout {
+		// The user data of a task can't be zero, so use that for the timer.
+		pollSubscriptions = append(pollSubscriptions, __wasi_subscription_t{
+			u: __wasi_subscription_u_t{
+				tag: __wasi_eventtype_t_clock,
+				u: __wasi_subscription_clock_t{
+					timeout:   uint64(timeout),
+					precision: timePrecisionNanoseconds,
+				},
+			},
+		})
+	}
+	if len(pollEvents) < len(pollSubscriptions) {
+		pollEvents = make([]__wasi_event_t, len(pollSubscriptions))
+	}
+
+	var nevents uint32
+	errno := poll_oneoff(&pollSubscriptions[0], &pollEvents[0], uint32(len(pollSubscriptions)), &nevents)
+	if errno != 0 {
+		runtimePanic("poll_oneoff failed")
+	}
+
+	// Make the goroutines runnable that had an event. Errors (such as a closed
+	// file descriptor) are reported as an event too, the goroutine will see
+	// the error when it retries the I/O operation.
+	for _, event := range pollEvents[:nevents] {
+		if event.userData == 0 {
+			continue
+		}
+		for q := &pollQueue; *q != nil; q = &(*q).Next {
+			t := *q
+			if uint64(uintptr(unsafe.Pointer(t))) == event.userData {
+				*q = t.Next
+				t.Next = nil
+				runqueue.Push(t)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build tinygo.wasm && wasi && buildmode.c_shared
+// +build tinygo.wasm,wasi,buildmode.c_shared
+
+package runtime
+
+import "unsafe"
+
+// _initialize is the entry point of a WASI reactor (built with
+// -buildmode=c-shared). The host calls it once after instantiating the module,
+// before calling any exported function. It runs the package initializers but
+// not the main function. The heap and any goroutines that are still running
+// are kept between calls to exported functions.
+//export _initialize
+func _initialize() {
+	// These need to be initialized early so that the heap can be initialized.
+	heapStart = uintptr(unsafe.Pointer(&heapStartSymbol))
+	heapEnd = uintptr(wasm_memory_size(0) * wasmPageSize)
+	runInit()
+}
@@ -0,0 +1,336 @@
+//go:build wasi_threads
+// +build wasi_threads
+
+package runtime
+
+// This file implements running goroutines in parallel on multiple WebAssembly
+// threads, using shared memory and the wasi-threads proposal:
+// https://github.com/WebAssembly/wasi-threads
+//
+// Every thread runs its own copy of the scheduler loop, and all threads share
+// the same run queue and sleep queue. These queues (and other shared runtime
+// state such as channels) are protected by the global lock that is taken with
+// interrupt.Disable. Goroutines are not bound to a thread: a goroutine that is
+// paused on one thread may be resumed on another.
+//
+// The garbage collector stops all other threads before a collection cycle.
+// Threads only stop at a safepoint: when allocating heap memory or when the
+// scheduler on that thread is idle. This means that a goroutine that runs for a
+// long time without allocating or blocking (or that is blocked in a call to the
+// host) delays garbage collection for all threads.
+
+import (
+	"runtime/interrupt"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Whether goroutines may run on more than one thread.
+const hasThreads = true
+
+// Stack size of the system stack of a new thread. The scheduler runs on this
+// stack, goroutines have their own stack.
+const threadStackSize = 64 * 1024
+
+//export llvm.wasm.memory.atomic.wait32
+func wasmMemoryAtomicWait32(ptr *uint32, expected uint32, timeout int64) int32
+
+//export llvm.wasm.memory.atomic.notify
+func wasmMemoryAtomicNotify(ptr *uint32, count uint32) uint32
+
+//export llvm.wasm.tls.size.i32
+func wasmTLSSize() uintptr
+
+//export llvm.wasm.tls.align.i32
+func wasmTLSAlign() uintptr
+
+// Spawn a new thread that calls wasi_thread_start with the given argument.
+// It returns the (positive) thread ID, or a negative value on failure.
+//go:wasm-module wasi
+//export thread-spawn
+func wasi_thread_spawn(startArg unsafe.Pointer) int32
+
+// futexWait blocks the current thread until *addr is no longer equal to val,
+// the timeout (in nanoseconds) has expired, or another thread calls futexWake
+// on the same address. A negative timeout means no timeout. It returns false
+// when the timeout expired.
+func futexWait(addr *uint32, val uint32, timeout int64) bool {
+	return wasmMemoryAtomicWait32(addr, val, timeout) != 2
+}
+
+// futexWake wakes up to count threads that wait on the given address.
+func futexWake(addr *uint32, count uint32) {
+	wasmMemoryAtomicNotify(addr, count)
+}
+
+// mutex is a lock for runtime internal data structures that may be accessed
+// from multiple threads at the same time. It must not be held while pausing the
+// current goroutine.
+type mutex struct {
+	// 0 means unlocked, 1 means locked, and 2 means locked while other threads
+	// may be waiting for it.
+	state uint32
+}
+
+func (m *mutex) Lock() {
+	if atomic.CompareAndSwapUint32(&m.state, 0, 1) {
+		return
+	}
+	for atomic.SwapUint32(&m.state, 2) != 0 {
+		futexWait(&m.state, 2, -1)
+	}
+}
+
+func (m *mutex) Unlock() {
+	if atomic.SwapUint32(&m.state, 0) == 2 {
+		futexWake(&m.state, 1)
+	}
+}
+
+// wasmThread contains the information about a thread that the runtime needs.
+// The first two fields are used from assembly in wasi_thread_start.
+type wasmThread struct {
+	stackTop   uintptr            // initial value of __stack_pointer
+	tls        unsafe.Pointer     // thread-local storage block
+	stack      unsafe.Pointer     // keeps the stack memory alive
+	stackChain **stackChainObject // &stackChainStart of this thread
+	next       *wasmThread
+}
+
+var (
+	// List of all threads, including the main thread. Only modified while
+	// holding gcLock.
+	threads *wasmThread
+
+	// The number of threads that run the scheduler (including the main
+	// thread), and the number of threads that should run it.
+	numThreads uint32 = 1
+	gomaxprocs uint32 = 4
+
+	// The number of threads that are waiting for a goroutine to run. Protected
+	// by the interrupt lock.
+	idleThreads uint32
+
+	// Number of times an idle thread may wake up to look for a runnable
+	// goroutine.
+	schedulerTokens uint32
+
+	// The scheduler loop of worker threads never finishes: all threads stop
+	// when the process exits.
+	threadsDone bool
+)
+
+// The thread that is running the current code.
+//go:threadlocal
+var currentThread *wasmThread
+
+// State of the stop-the-world mechanism used by the garbage collector.
+var (
+	// Number of threads that are running and may touch the heap, which is
+	// every thread that is not stopped at a safepoint.
+	gcRunningThreads uint32 = 1
+
+	// Set while a thread is running the garbage collector.
+	gcStopRequested uint32
+)
+
+// gcSafepoint stops the current thread while another thread is running the
+// garbage collector.
+func gcSafepoint() {
+	if atomic.LoadUint32(&gcStopRequested) != 0 {
+		gcBlockingStart()
+		gcBlockingEnd()
+	}
+}
+
+// gcBlockingStart marks the current thread as stopped, so that the garbage
+// collector won't wait for it. Between gcBlockingStart and gcBlockingEnd the
+// thread must not touch the heap or any stack object.
+func gcBlockingStart() {
+	atomic.AddUint32(&gcRunningThreads, ^uint32(0))
+	if atomic.LoadUint32(&gcStopRequested) != 0 {
+		futexWake(&gcRunningThreads, 1)
+	}
+}
+
+// gcBlockingEnd marks the current thread as running again, after waiting for a
+// garbage collection cycle to finish.
+func gcBlockingEnd() {
+	for {
+		for atomic.LoadUint32(&gcStopRequested) != 0 {
+			futexWait(&gcStopRequested, 1, -1)
+		}
+		atomic.AddUint32(&gcRunningThreads, 1)
+		if atomic.LoadUint32(&gcStopRequested) == 0 {
+			return
+		}
+		// A collection cycle started in the meantime, wait for it to finish.
+		gcBlockingStart()
+	}
+}
+
+// gcStopTheWorld waits until all other threads are stopped at a safepoint.
+func gcStopTheWorld() {
+	for !atomic.CompareAndSwapUint32(&gcStopRequested, 0, 1) {
+		// Another thread is running the garbage collector.
+		gcBlockingStart()
+		gcBlockingEnd()
+	}
+	for {
+		running := atomic.LoadUint32(&gcRunningThreads)
+		if running == 1 {
+			break
+		}
+		futexWait(&gcRunningThreads, running, -1)
+	}
+}
+
+// gcStartTheWorld lets all threads that were stopped by gcStopTheWorld continue.
+func gcStartTheWorld() {
+	atomic.StoreUint32(&gcStopRequested, 0)
+	futexWake(&gcStopRequested, ^uint32(0))
+}
+
+// markOtherThreadStacks marks all root pointers in the stacks of the other
+// threads. They are all stopped at a safepoint.
+func markOtherThreadStacks() {
+	for thread := threads; thread != nil; thread = thread.next {
+		if thread == currentThread || thread.stackChain == nil {
+			continue
+		}
+		markStackChain(*thread.stackChain)
+	}
+}
+
+// startThreads starts new threads until there are gomaxprocs threads running
+// the scheduler.
+func startThreads() {
+	if currentThread == nil {
+		// Register the main thread, which uses the stack and thread-local
+		// storage that the linker created.
+		mainThread := &wasmThread{stackChain: &stackChainStart}
+		gcLock.Lock()
+		threads = mainThread
+		gcLock.Unlock()
+		currentThread = mainThread
+	}
+
+	for atomic.LoadUint32(&numThreads) < gomaxprocs {
+		// Allocate the stack and thread-local storage for the new thread.
+		// Allocated memory is aligned to at least 16 bytes, which is the
+		// required stack alignment.
+		stack := alloc(threadStackSize, nil)
+		tlsAlign := wasmTLSAlign()
+		tls := alloc(wasmTLSSize()+tlsAlign, nil)
+		tls = unsafe.Pointer((uintptr(tls) + tlsAlign - 1) &^ (tlsAlign - 1))
+		thread := &wasmThread{
+			stackTop: uintptr(stack) + threadStackSize,
+			tls:      tls,
+			stack:    stack,
+		}
+
+		gcLock.Lock()
+		thread.next = threads
+		threads = thread
+		gcLock.Unlock()
+
+		atomic.AddUint32(&numThreads, 1)
+		if wasi_thread_spawn(unsafe.Pointer(thread)) < 0 {
+			runtimePanic("could not start thread")
+		}
+	}
+}
+
+// threadStart is called from wasi_thread_start on a new thread, after the stack
+// pointer and thread-local storage have been set up.
+//export tinygo_thread_start
+func threadStart(thread *wasmThread) {
+	currentThread = thread
+	thread.stackChain = &stackChainStart
+
+	// New threads start as stopped, so that the garbage collector doesn't wait
+	// for threads that are still starting.
+	gcBlockingEnd()
+
+	runScheduler(&threadsDone)
+}
+
+// schedulerIdle is called by the scheduler with the interrupt lock held when
+// there is no runnable goroutine. It waits until another thread makes a
+// goroutine runnable or until the next sleeping goroutine should be woken up,
+// and releases the interrupt lock.
+func schedulerIdle(i interrupt.State, now timeUnit) {
+	timeout := int64(-1)
+	if sleepQueue != nil {
+		timeLeft := timeUnit(sleepQueue.Data) - (now - sleepQueueBaseTime)
+		timeout = ticksToNanoseconds(timeLeft)
+		if timeout <= 0 {
+			// The next goroutine should be woken up right now.
+			interrupt.Restore(i)
+			return
+		}
+	} else if idleThreads+1 == atomic.LoadUint32(&numThreads) {
+		// All other threads are waiting too, so no goroutine can ever be
+		// made runnable again.
+		interrupt.Restore(i)
+		runtimePanic("deadlocked: no event source")
+	}
+	idleThreads++
+	interrupt.Restore(i)
+
+	// Wait until woken by schedulerWake, or until the timeout expired. The
+	// garbage collector can run in the meantime.
+	gcBlockingStart()
+	for {
+		tokens := atomic.LoadUint32(&schedulerTokens)
+		if tokens != 0 {
+			if atomic.CompareAndSwapUint32(&schedulerTokens, tokens, tokens-1) {
+				break
+			}
+			continue
+		}
+		if !futexWait(&schedulerTokens, 0, timeout) {
+			break
+		}
+	}
+	gcBlockingEnd()
+
+	i = interrupt.Disable()
+	idleThreads--
+	interrupt.Restore(i)
+}
+
+// schedulerWake wakes up an idle thread, if there is one, to run a goroutine
+// that was just made runnable.
+func schedulerWake() {
+	for {
+		tokens := atomic.LoadUint32(&schedulerTokens)
+		if tokens >= atomic.LoadUint32(&numThreads) {
+			// All threads will look for a runnable goroutine anyway.
+			return
+		}
+		if atomic.CompareAndSwapUint32(&schedulerTokens, tokens, tokens+1) {
+			futexWake(&schedulerTokens, 1)
+			return
+		}
+	}
+}
+
+// exitThreads is called when the main function returns. It exits the process,
+// which stops all other threads.
+func exitThreads() {
+	proc_exit(0)
+}
+
+// GOMAXPROCS sets the maximum number of threads that run goroutines and returns
+// the previous setting. The number of threads can only be increased: threads
+// that have been started keep running. If n < 1, it does not change the current
+// setting.
+func GOMAXPROCS(n int) int {
+	prev := int(gomaxprocs)
+	if n > prev {
+		gomaxprocs = uint32(n)
+		startThreads()
+	}
+	return prev
+}
@@ -143,6 +143,20 @@ func ticks() timeUnit {
 	return timeUnit(unbiasedTime)
 }
 
+// errno_t rand_s(unsigned int* randomValue);
+//export rand_s
+func libc_rand_s(randomValue *uint32) int32
+
+// Read 64 bits of random data using rand_s.
+func hardwareRand() (n uint64, ok bool) {
+	var n1, n2 uint32
+	errCode1 := libc_rand_s(&n1)
+	errCode2 := libc_rand_s(&n2)
+	n = uint64(n1)<<32 | uint64(n2)
+	ok = errCode1 == 0 && errCode2 == 0
+	return
+}
+
 //go:linkname now time.now
 func now() (sec int64, nsec int32, mono int64) {
 	// Get the current time in Windows "file time" format.
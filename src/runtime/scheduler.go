@@ -12,6 +12,7 @@ package runtime
 
 import (
 	"internal/task"
+	"runtime/interrupt"
 )
 
 const schedulerDebug = false
@@ -74,6 +75,7 @@ func Goexit() {
 // Add this task to the end of the run queue.
 func runqueuePushBack(t *task.Task) {
 	runqueue.Push(t)
+	schedulerWake()
 }
 
 // Add this task to the sleep queue, assuming its state is set to sleeping.
@@ -86,6 +88,7 @@ func addSleepTask(t *task.Task, duration timeUnit) {
 	}
 	t.Data = uint64(duration)
 	now := ticks()
+	i := interrupt.Disable()
 	if sleepQueue == nil {
 		scheduleLog("  -> sleep new queue")
 
@@ -110,19 +113,28 @@ func addSleepTask(t *task.Task, duration timeUnit) {
 	}
 	t.Next = *q
 	*q = t
+	interrupt.Restore(i)
 }
 
 // Run the scheduler until all tasks have finished.
 func scheduler() {
+	runScheduler(&schedulerDone)
+}
+
+// runScheduler runs the scheduler until *done is set.
+func runScheduler(done *bool) {
 	// Main scheduler loop.
 	var now timeUnit
-	for !schedulerDone {
+	for !*done {
 		scheduleLog("")
 		scheduleLog("  schedule")
-		if sleepQueue != nil {
+		if hasThreads || sleepQueue != nil {
 			now = ticks()
 		}
 
+		// The queues may be modified by other threads.
+		i := interrupt.Disable()
+
 		// Add tasks that are done sleeping to the end of the runqueue so they
 		// will be executed soon.
 		if sleepQueue != nil && now-sleepQueueBaseTime >= timeUnit(sleepQueue.Data) {
@@ -135,6 +147,13 @@ func scheduler() {
 		}
 
 		t := runqueue.Pop()
+		if t == nil && hasThreads {
+			// Other threads may still make a goroutine runnable, so wait for
+			// that instead of sleeping.
+			schedulerIdle(i, now)
+			continue
+		}
+		interrupt.Restore(i)
 		if t == nil {
 			if sleepQueue == nil {
 				if asyncScheduler {
@@ -20,12 +20,52 @@ func sleep(duration int64) {
 // With a scheduler, init and the main function are invoked in a goroutine before starting the scheduler.
 func run() {
 	initHeap()
+	startThreads()
 	go func() {
 		initAll()
 		callMain()
 		schedulerDone = true
+		exitThreads()
 	}()
 	scheduler()
 }
 
+// runInit is called by the entry point of a library (the _initialize function
+// of a WASI reactor) instead of run. It runs the package initializers but not
+// the main function, and returns once they have finished so that the host can
+// call exported functions.
+func runInit() {
+	initHeap()
+	initDone := false
+	go func() {
+		initAll()
+		initDone = true
+	}()
+	exportCallbackWait(&initDone)
+}
+
+// exportCallbackWait is called by the wrapper of a Go function that is exported
+// with //export, when the function was called while no goroutine was running:
+// from C code in a CGo package (for example from a C event loop that was not
+// started from Go), or by the host of a WebAssembly library. Such a function
+// may block, so it can't run on the system stack. Instead, the wrapper starts
+// it in a new goroutine and calls exportCallbackWait to run the scheduler until
+// the function has returned and set *done.
+//
+// Goroutines that are runnable at that point, such as goroutines started by
+// the exported function, are then run until they block or exit. Goroutines
+// that are sleeping continue the next time the scheduler runs, which is in the
+// next call to an exported function.
+func exportCallbackWait(done *bool) {
+	runScheduler(done)
+	for {
+		t := runqueue.Pop()
+		if t == nil {
+			break
+		}
+		scheduleLogTask("  run:", t)
+		t.Resume()
+	}
+}
+
 const hasScheduler = true
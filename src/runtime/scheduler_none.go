@@ -26,4 +26,12 @@ func run() {
 	callMain()
 }
 
+// runInit is called by the entry point of a library (the _initialize function
+// of a WASI reactor) instead of run. It runs the package initializers but not
+// the main function.
+func runInit() {
+	initHeap()
+	initAll()
+}
+
 const hasScheduler = false
@@ -0,0 +1,50 @@
+//go:build !wasi_threads
+// +build !wasi_threads
+
+package runtime
+
+// This file contains the stubs for systems where goroutines all run on a
+// single thread. See runtime_wasm_wasi_threads.go for the multithreaded
+// implementation.
+
+import "runtime/interrupt"
+
+// Whether goroutines may run on more than one thread.
+const hasThreads = false
+
+// mutex is a lock for runtime internal data structures that may be accessed
+// from multiple threads at the same time. It does nothing when there is only
+// one thread.
+type mutex struct{}
+
+func (m *mutex) Lock() {}
+
+func (m *mutex) Unlock() {}
+
+func gcSafepoint() {}
+
+func gcStopTheWorld() {}
+
+func gcStartTheWorld() {}
+
+func gcBlockingStart() {}
+
+func gcBlockingEnd() {}
+
+func markOtherThreadStacks() {}
+
+func schedulerWake() {}
+
+// schedulerIdle is only used when hasThreads is true.
+func schedulerIdle(i interrupt.State, now timeUnit) {
+	runtimePanic("unreachable: schedulerIdle")
+}
+
+func startThreads() {}
+
+func exitThreads() {}
+
+func GOMAXPROCS(n int) int {
+	// Note: setting GOMAXPROCS is ignored.
+	return 1
+}
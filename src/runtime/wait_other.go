@@ -1,5 +1,6 @@
-//go:build !tinygo.riscv && !cortexm
+//go:build !tinygo.riscv && !cortexm && !(tinygo.wasm && wasi)
 // +build !tinygo.riscv,!cortexm
+// +build !tinygo.wasm !wasi
 
 package runtime
 
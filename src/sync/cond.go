@@ -1,6 +1,9 @@
 package sync
 
-import "internal/task"
+import (
+	"internal/task"
+	"runtime/interrupt"
+)
 
 type Cond struct {
 	L Locker
@@ -40,21 +43,27 @@ func (c *Cond) trySignal() bool {
 }
 
 func (c *Cond) Signal() {
+	i := interrupt.Disable()
 	c.trySignal()
+	interrupt.Restore(i)
 }
 
 func (c *Cond) Broadcast() {
 	// Signal everything.
+	i := interrupt.Disable()
 	for c.trySignal() {
 	}
+	interrupt.Restore(i)
 }
 
 func (c *Cond) Wait() {
 	// Add an earlySignal frame to the stack so we can be signalled while unlocking.
+	i := interrupt.Disable()
 	early := earlySignal{
 		next: c.unlocking,
 	}
 	c.unlocking = &early
+	interrupt.Restore(i)
 
 	// Temporarily unlock L.
 	c.L.Unlock()
@@ -63,7 +72,9 @@ func (c *Cond) Wait() {
 	defer c.L.Lock()
 
 	// If we were signaled while unlocking, immediately complete.
+	i = interrupt.Disable()
 	if early.signaled {
+		interrupt.Restore(i)
 		return
 	}
 
@@ -80,5 +91,6 @@ func (c *Cond) Wait() {
 
 	// Wait for a signal.
 	c.blocked.Push(task.Current())
+	interrupt.Restore(i)
 	task.Pause()
 }
@@ -2,9 +2,15 @@ package sync
 
 import (
 	"internal/task"
+	"runtime/interrupt"
 	_ "unsafe"
 )
 
+// The state of mutexes, condition variables and wait groups is protected with
+// interrupt.Disable, because goroutines may run on multiple threads (for
+// example with the wasi-threads target) or in an interrupt handler. The
+// critical section must end before the current goroutine is paused.
+
 type Mutex struct {
 	locked  bool
 	blocked task.Stack
@@ -14,18 +20,23 @@ type Mutex struct {
 func scheduleTask(*task.Task)
 
 func (m *Mutex) Lock() {
+	i := interrupt.Disable()
 	if m.locked {
 		// Push self onto stack of blocked tasks, and wait to be resumed.
 		m.blocked.Push(task.Current())
+		interrupt.Restore(i)
 		task.Pause()
 		return
 	}
 
 	m.locked = true
+	interrupt.Restore(i)
 }
 
 func (m *Mutex) Unlock() {
+	i := interrupt.Disable()
 	if !m.locked {
+		interrupt.Restore(i)
 		panic("sync: unlock of unlocked Mutex")
 	}
 
@@ -35,6 +46,7 @@ func (m *Mutex) Unlock() {
 	} else {
 		m.locked = false
 	}
+	interrupt.Restore(i)
 }
 
 type RWMutex struct {
@@ -58,29 +70,35 @@ const (
 )
 
 func (rw *RWMutex) Lock() {
+	i := interrupt.Disable()
 	if rw.state == 0 {
 		// The mutex is completely unlocked.
 		// Lock without waiting.
 		rw.state = rwMutexStateWLocked
+		interrupt.Restore(i)
 		return
 	}
 
 	// Wait for the lock to be released.
 	rw.waitingWriters.Push(task.Current())
+	interrupt.Restore(i)
 	task.Pause()
 }
 
 func (rw *RWMutex) Unlock() {
+	i := interrupt.Disable()
 	switch rw.state {
 	case rwMutexStateWLocked:
 		// This is correct.
 
 	case rwMutexStateUnlocked:
 		// The mutex is already unlocked.
+		interrupt.Restore(i)
 		panic("sync: unlock of unlocked RWMutex")
 
 	default:
 		// The mutex is read-locked instead of write-locked.
+		interrupt.Restore(i)
 		panic("sync: write-unlock of read-locked RWMutex")
 	}
 
@@ -95,32 +113,40 @@ func (rw *RWMutex) Unlock() {
 		// Nothing is waiting for the lock.
 		rw.state = rwMutexStateUnlocked
 	}
+	interrupt.Restore(i)
 }
 
 func (rw *RWMutex) RLock() {
+	i := interrupt.Disable()
 	if rw.state == rwMutexStateWLocked {
 		// Wait for the write lock to be released.
 		rw.waitingReaders.Push(task.Current())
+		interrupt.Restore(i)
 		task.Pause()
 		return
 	}
 
 	if rw.state == rwMutexMaxReaders {
+		interrupt.Restore(i)
 		panic("sync: too many readers on RWMutex")
 	}
 
 	// Increase the reader count.
 	rw.state++
+	interrupt.Restore(i)
 }
 
 func (rw *RWMutex) RUnlock() {
+	i := interrupt.Disable()
 	switch rw.state {
 	case rwMutexStateUnlocked:
 		// The mutex is already unlocked.
+		interrupt.Restore(i)
 		panic("sync: unlock of unlocked RWMutex")
 
 	case rwMutexStateWLocked:
 		// The mutex is write-locked instead of read-locked.
+		interrupt.Restore(i)
 		panic("sync: read-unlock of write-locked RWMutex")
 	}
 
@@ -131,6 +157,7 @@ func (rw *RWMutex) RUnlock() {
 		// Try to unblock a writer.
 		rw.maybeUnblockWriter()
 	}
+	interrupt.Restore(i)
 }
 
 func (rw *RWMutex) maybeUnblockReaders() bool {
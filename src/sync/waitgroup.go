@@ -1,6 +1,9 @@
 package sync
 
-import "internal/task"
+import (
+	"internal/task"
+	"runtime/interrupt"
+)
 
 type WaitGroup struct {
 	counter uint
@@ -8,9 +11,11 @@ type WaitGroup struct {
 }
 
 func (wg *WaitGroup) Add(delta int) {
+	i := interrupt.Disable()
 	if delta > 0 {
 		// Check for overflow.
 		if uint(delta) > (^uint(0))-wg.counter {
+			interrupt.Restore(i)
 			panic("sync: WaitGroup counter overflowed")
 		}
 
@@ -19,6 +24,7 @@ func (wg *WaitGroup) Add(delta int) {
 	} else {
 		// Check for underflow.
 		if uint(-delta) > wg.counter {
+			interrupt.Restore(i)
 			panic("sync: negative WaitGroup counter")
 		}
 
@@ -34,6 +40,7 @@ func (wg *WaitGroup) Add(delta int) {
 			}
 		}
 	}
+	interrupt.Restore(i)
 }
 
 func (wg *WaitGroup) Done() {
@@ -41,13 +48,16 @@ func (wg *WaitGroup) Done() {
 }
 
 func (wg *WaitGroup) Wait() {
+	i := interrupt.Disable()
 	if wg.counter == 0 {
 		// Everything already finished.
+		interrupt.Restore(i)
 		return
 	}
 
 	// Push the current goroutine onto the waiter stack.
 	wg.waiters.Push(task.Current())
+	interrupt.Restore(i)
 
 	// Pause until the waiters are awoken by Add/Done.
 	task.Pause()
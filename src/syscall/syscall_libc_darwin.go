@@ -230,6 +230,15 @@ func Lstat(path string, p *Stat_t) (err error) {
 	return
 }
 
+func Chmod(path string, mode uint32) (err error) {
+	data := cstring(path)
+	fail := int(libc_chmod(&data[0], mode))
+	if fail < 0 {
+		err = getErrno()
+	}
+	return
+}
+
 func Fdopendir(fd int) (dir uintptr, err error) {
 	r0 := libc_fdopendir(int32(fd))
 	dir = uintptr(r0)
@@ -260,6 +269,10 @@ func libc_fdopendir(fd int32) unsafe.Pointer
 //export readdir_r$INODE64
 func libc_readdir_r(unsafe.Pointer, unsafe.Pointer, unsafe.Pointer) int32
 
+// int chmod(const char *pathname, mode_t mode);
+//export chmod
+func libc_chmod(pathname *byte, mode uint32) int32
+
 // int stat(const char *path, struct stat * buf);
 //export stat$INODE64
 func libc_stat(pathname *byte, ptr unsafe.Pointer) int32
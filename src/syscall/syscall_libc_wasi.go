@@ -175,8 +175,8 @@ const (
 
 // https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__struct_timespec.h
 type Timespec struct {
-	Sec  int32
-	Nsec int64
+	Sec  int64
+	Nsec int32
 }
 
 // https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__struct_stat.h
@@ -231,31 +231,113 @@ const (
 	S_IXUSR  = 0x40
 )
 
-// dummy
+// https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__header_dirent.h
+// These are the WASI file types. WASI has no named pipes, so wasi-libc reports
+// stream sockets as DT_FIFO.
 const (
-	DT_BLK     = 0x6
-	DT_CHR     = 0x2
-	DT_DIR     = 0x4
-	DT_FIFO    = 0x1
-	DT_LNK     = 0xa
-	DT_REG     = 0x8
-	DT_SOCK    = 0xc
-	DT_UNKNOWN = 0x0
-	DT_WHT     = 0xe
+	DT_UNKNOWN = 0
+	DT_BLK     = 1
+	DT_CHR     = 2
+	DT_DIR     = 3
+	DT_REG     = 4
+	DT_SOCK    = 5
+	DT_FIFO    = 6
+	DT_LNK     = 7
 )
 
-// dummy
+// https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__struct_dirent.h
+// The name is a flexible array member in C, it is terminated by a zero byte.
 type Dirent struct {
-	Ino    uint64
-	Reclen uint16
-	Type   uint8
-	Name   [1024]int8
+	Ino  uint64
+	Type uint8
+	Name [256]int8
 }
 
 func ReadDirent(fd int, buf []byte) (n int, err error) {
 	return -1, ENOSYS
 }
 
+func Fdopendir(fd int) (dir uintptr, err error) {
+	dir = uintptr(libc_fdopendir(int32(fd)))
+	if dir == 0 {
+		err = getErrno()
+	}
+	return
+}
+
+// readdir returns the next entry of the directory, or nil at the end of the
+// directory. The entry is only valid until the next call to readdir.
+func readdir(dir uintptr) (entry *Dirent, err error) {
+	// readdir only sets errno on failure, so it can't be distinguished from
+	// the end of the directory otherwise.
+	libcErrno = 0
+	entry = (*Dirent)(libc_readdir(unsafe.Pointer(dir)))
+	if entry == nil && libcErrno != 0 {
+		err = getErrno()
+	}
+	return
+}
+
+// fdclosedir frees the directory stream, without closing the underlying file
+// descriptor (unlike closedir).
+func fdclosedir(dir uintptr) (err error) {
+	if libc_fdclosedir(unsafe.Pointer(dir)) < 0 {
+		err = getErrno()
+	}
+	return
+}
+
+// Chmod only checks whether the file exists: WASI has no concept of file
+// permissions.
+func Chmod(path string, mode uint32) (err error) {
+	var stat Stat_t
+	return Stat(path, &stat)
+}
+
+// https://github.com/WebAssembly/wasi-libc/blob/main/libc-bottom-half/headers/public/__header_sys_socket.h
+const (
+	SHUT_RD   = 1
+	SHUT_WR   = 2
+	SHUT_RDWR = SHUT_RD | SHUT_WR
+)
+
+// https://github.com/WebAssembly/WASI/blob/main/phases/snapshot/docs.md#-fdflags-record
+const __WASI_FDFLAGS_NONBLOCK = 4
+
+// https://github.com/WebAssembly/WASI/blob/main/phases/snapshot/docs.md#-fdstat-record
+type fdstat struct {
+	filetype         uint8
+	flags            uint16
+	rightsBase       uint64
+	rightsInheriting uint64
+}
+
+// SetNonblock changes the blocking mode of the file descriptor. This calls
+// WASI directly instead of using fcntl, which is a variadic function in C.
+func SetNonblock(fd int, nonblocking bool) (err error) {
+	var stat fdstat
+	if errno := fd_fdstat_get(int32(fd), &stat); errno != 0 {
+		return Errno(errno)
+	}
+	flags := stat.flags &^ __WASI_FDFLAGS_NONBLOCK
+	if nonblocking {
+		flags |= __WASI_FDFLAGS_NONBLOCK
+	}
+	if errno := fd_fdstat_set_flags(int32(fd), flags); errno != 0 {
+		return Errno(errno)
+	}
+	return nil
+}
+
+// Shutdown shuts down the reading side (SHUT_RD), the writing side (SHUT_WR)
+// or both sides (SHUT_RDWR) of a socket.
+func Shutdown(fd int, how int) (err error) {
+	if errno := sock_shutdown(int32(fd), uint8(how)); errno != 0 {
+		return Errno(errno)
+	}
+	return nil
+}
+
 func Stat(path string, p *Stat_t) (err error) {
 	data := cstring(path)
 	n := libc_stat(&data[0], unsafe.Pointer(p))
@@ -295,3 +377,27 @@ func libc_fstat(fd int32, ptr unsafe.Pointer) int32
 // int lstat(const char *path, struct stat * buf);
 //export lstat
 func libc_lstat(pathname *byte, ptr unsafe.Pointer) int32
+
+// DIR *fdopendir(int fd);
+//export fdopendir
+func libc_fdopendir(fd int32) unsafe.Pointer
+
+// struct dirent *readdir(DIR *dirp);
+//export readdir
+func libc_readdir(dirp unsafe.Pointer) unsafe.Pointer
+
+// int fdclosedir(DIR *dirp);
+//export fdclosedir
+func libc_fdclosedir(dirp unsafe.Pointer) int32
+
+//go:wasm-module wasi_snapshot_preview1
+//export fd_fdstat_get
+func fd_fdstat_get(fd int32, stat *fdstat) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export fd_fdstat_set_flags
+func fd_fdstat_set_flags(fd int32, flags uint16) (errno uint16)
+
+//go:wasm-module wasi_snapshot_preview1
+//export sock_shutdown
+func sock_shutdown(fd int32, how uint8) (errno uint16)
@@ -0,0 +1,51 @@
+package main
+
+// This program is used to compare the speed of asyncify instrumentation. Most
+// of the time is spent in code that never blocks, but it makes many indirect
+// calls, which full asyncify instrumentation has to treat as possibly pausing.
+// One goroutine blocks on a channel, so the program does need asyncify.
+
+import "sort"
+
+type shape interface {
+	area() int
+}
+
+type rect struct{ w, h int }
+
+func (r rect) area() int { return r.w * r.h }
+
+type square struct{ size int }
+
+func (s square) area() int { return s.size * s.size }
+
+func work(round int) int {
+	shapes := make([]shape, 1000)
+	for i := range shapes {
+		n := (i*7919 + round) % 1000
+		if i%2 == 0 {
+			shapes[i] = rect{n, i % 10}
+		} else {
+			shapes[i] = square{n % 30}
+		}
+	}
+	sort.Slice(shapes, func(i, j int) bool {
+		return shapes[i].area() < shapes[j].area()
+	})
+	return shapes[0].area() + shapes[len(shapes)-1].area()
+}
+
+func main() {
+	results := make(chan int)
+	go func() {
+		for round := 0; round < 1000; round++ {
+			results <- work(round)
+		}
+		close(results)
+	}()
+	total := 0
+	for result := range results {
+		total += result
+	}
+	println("total:", total)
+}
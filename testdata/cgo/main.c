@@ -19,6 +19,8 @@ joined_t globalUnion;
 int globalUnionSize = sizeof(globalUnion);
 option_t globalOption = optionG;
 bitfield_t globalBitfield = {244, 15, 1, 2, 47, 5};
+packed_t globalPacked = {3, -123456, 789};
+int globalPackedSize = sizeof(globalPacked);
 
 int cflagsConstant = SOME_CONSTANT;
 
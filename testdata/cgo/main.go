@@ -5,6 +5,8 @@ package main
 int fortytwo(void);
 #include "main.h"
 int mul(int, int);
+int cxxSquare(int);
+int asmAdd(int, int);
 #include <string.h>
 #cgo CFLAGS: -DSOME_CONSTANT=17
 #define someDefine -5 + 2 * 7
@@ -48,6 +50,12 @@ func main() {
 	// functions in the header C snippet
 	println("headerfunc:", C.headerfunc(5))
 
+	// functions in a C++ file
+	println("C++ function:", C.cxxSquare(7))
+
+	// functions in an assembly file
+	println("assembly function:", C.asmAdd(3, 4))
+
 	// equivalent types
 	var goInt8 int8 = 5
 	var _ C.int8_t = goInt8
@@ -85,6 +93,14 @@ func main() {
 	C.globalBitfield.set_bitfield_c(0xff)
 	printBitfield(&C.globalBitfield)
 
+	// packed struct
+	println("packed:", C.int(unsafe.Sizeof(C.globalPacked)) == C.globalPackedSize)
+	println("packed a:", C.globalPacked.a)
+	println("packed b:", C.globalPacked.packedfield_b())
+	println("packed c:", C.globalPacked.packedfield_c())
+	C.globalPacked.set_packedfield_b(654321)
+	println("packed b:", C.globalPacked.packedfield_b())
+
 	// elaborated type
 	p := C.struct_point2d{x: 3, y: 5}
 	println("struct:", p.x, p.y)
@@ -115,6 +115,12 @@ typedef struct {
 	// Note that C++ allows bitfields bigger than the underlying type.
 } bitfield_t;
 
+typedef struct {
+	char  a;
+	int   b;
+	short c;
+} __attribute__((packed)) packed_t;
+
 // test globals and datatypes
 extern int global;
 extern int unusedGlobal;
@@ -136,6 +142,8 @@ extern joined_t globalUnion;
 extern int globalUnionSize;
 extern option_t globalOption;
 extern bitfield_t globalBitfield;
+extern packed_t globalPacked;
+extern int globalPackedSize;
 
 extern int smallEnumWidth;
 
@@ -0,0 +1,15 @@
+// Functions called from Go must have C linkage, but can use C++ features
+// internally.
+
+namespace {
+
+template <typename T>
+T square(T x) {
+	return x * x;
+}
+
+} // namespace
+
+extern "C" int cxxSquare(int x) {
+	return square(x);
+}
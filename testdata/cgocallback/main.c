@@ -0,0 +1,11 @@
+#include <stdlib.h>
+#include "_cgo_export.h"
+
+static void exitCallback(void) {
+	// Runs on the system stack, outside of any goroutine.
+	printResult(blockingDouble(21));
+}
+
+void registerExitCallback(void) {
+	atexit(exitCallback);
+}
@@ -0,0 +1,36 @@
+package main
+
+// Test that C can call a Go function that blocks while no goroutine is
+// running. This needs the tasks scheduler: the exported function is then
+// started in a new goroutine and the scheduler runs until it returns.
+
+/*
+void registerExitCallback(void);
+*/
+import "C"
+
+import "time"
+
+func main() {
+	// Called from a goroutine, so it is called directly.
+	println("direct call:", blockingDouble(3))
+
+	// Called by exit() after main.main and the scheduler have returned.
+	C.registerExitCallback()
+	println("main done")
+}
+
+//export blockingDouble
+func blockingDouble(n C.int) C.int {
+	result := make(chan C.int)
+	go func() {
+		time.Sleep(time.Millisecond)
+		result <- n * 2
+	}()
+	return <-result
+}
+
+//export printResult
+func printResult(n C.int) {
+	println("callback from exit:", n)
+}
@@ -0,0 +1,27 @@
+package main
+
+// Test C++ code that uses the C++ standard library and runtime support (such
+// as operator new, RTTI and guards for static variables). This needs libc++
+// and libc++abi, which are only linked on targets with a libc.
+
+/*
+int sumSorted(const int *values, int n, int *smallest);
+int decimalLength(int n);
+const char *shapeName(int corners);
+int counter(void);
+*/
+import "C"
+
+func main() {
+	values := []C.int{5, 3, 9, 1}
+	var smallest C.int
+	sum := C.sumSorted(&values[0], C.int(len(values)), &smallest)
+	println("sum:", sum, "smallest:", smallest)
+
+	println("decimal length:", C.decimalLength(-12345))
+
+	println("shape 0:", C.GoString(C.shapeName(0)))
+	println("shape 4:", C.GoString(C.shapeName(4)))
+
+	println("counter:", C.counter(), C.counter(), C.counter())
+}
@@ -0,0 +1,64 @@
+#include <algorithm>
+#include <memory>
+#include <numeric>
+#include <string>
+#include <vector>
+
+namespace {
+
+struct Shape {
+	virtual ~Shape() {}
+	virtual const char *name() const = 0;
+};
+
+struct Circle : Shape {
+	const char *name() const override { return "circle"; }
+};
+
+struct Polygon : Shape {
+	explicit Polygon(int corners) : corners(corners) {}
+	const char *name() const override { return "polygon"; }
+	int corners;
+};
+
+std::unique_ptr<Shape> makeShape(int corners) {
+	if (corners == 0) {
+		return std::make_unique<Circle>();
+	}
+	return std::make_unique<Polygon>(corners);
+}
+
+struct Counter {
+	Counter() : value(std::stoi("10")) {}
+	int value;
+};
+
+} // namespace
+
+extern "C" int sumSorted(const int *values, int n, int *smallest) {
+	std::vector<int> v(values, values + n);
+	std::sort(v.begin(), v.end());
+	*smallest = v.front();
+	return std::accumulate(v.begin(), v.end(), 0);
+}
+
+extern "C" int decimalLength(int n) {
+	return std::to_string(n).size();
+}
+
+extern "C" const char *shapeName(int corners) {
+	std::unique_ptr<Shape> shape = makeShape(corners);
+	// dynamic_cast needs RTTI.
+	if (Polygon *polygon = dynamic_cast<Polygon *>(shape.get())) {
+		if (polygon->corners == 4) {
+			return "square";
+		}
+	}
+	return shape->name();
+}
+
+extern "C" int counter() {
+	// Initialized on first use, which needs a guard variable.
+	static Counter c;
+	return c.value++;
+}
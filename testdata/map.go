@@ -125,6 +125,13 @@ func main() {
 	testBigMap(squares, 40)
 	println("tested growing of a map")
 
+	// test that iteration visits every key exactly once, wherever it starts
+	squares = make(map[int]int, 0)
+	testBigMap(squares, 100)
+	testMapIteration(squares)
+	println("tested map iteration")
+	println("map iteration start varies:", mapIterationStartVaries(squares))
+
 	floatcmplx()
 }
 
@@ -187,6 +194,44 @@ func lookup(m map[string]int, key string) {
 	println("lookup with comma-ok:", key, value, ok)
 }
 
+func testMapIteration(squares map[int]int) {
+	for round := 0; round < 10; round++ {
+		seen := make([]bool, len(squares))
+		for k, v := range squares {
+			if seen[k] {
+				println("key visited twice:", k)
+			}
+			if v != k*k {
+				println("unexpected value during iteration:", k, v)
+			}
+			seen[k] = true
+		}
+		for k, ok := range seen {
+			if !ok {
+				println("key not visited:", k)
+			}
+		}
+	}
+}
+
+// mapIterationStartVaries returns whether iterating over the same map several
+// times doesn't always start at the same key. The order is randomized unless
+// the program is built with -tags=maps.deterministic.
+func mapIterationStartVaries(m map[int]int) bool {
+	first := -1
+	for round := 0; round < 100; round++ {
+		for k := range m {
+			if first < 0 {
+				first = k
+			} else if k != first {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
 func testBigMap(squares map[int]int, n int) {
 	for i := 0; i < n; i++ {
 		if len(squares) != i {
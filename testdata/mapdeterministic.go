@@ -0,0 +1,46 @@
+package main
+
+// This test is built with -tags=maps.deterministic, which makes map iteration
+// order only depend on the operations done on the map.
+
+func main() {
+	m := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		m[i] = i * i
+	}
+	order := keys(m)
+	stable := true
+	for round := 0; round < 100; round++ {
+		if !equal(keys(m), order) {
+			stable = false
+		}
+	}
+	println("same map, same order:", stable)
+
+	// A map built in the same way iterates in the same order.
+	m2 := make(map[int]int)
+	for i := 0; i < 100; i++ {
+		m2[i] = i * i
+	}
+	println("equal map, same order:", equal(keys(m2), order))
+}
+
+func keys(m map[int]int) []int {
+	var keys []int
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
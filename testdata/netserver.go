@@ -0,0 +1,78 @@
+package main
+
+// This program is run by TestWasiSockets in main_test.go, with a listening
+// socket that wasmtime passes as file descriptor 3. It echoes everything it
+// receives on two connections, which are served at the same time. Afterwards
+// it checks that closing the listener stops a pending Accept.
+
+import (
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+func main() {
+	f := os.NewFile(3, "listener")
+	ln, err := net.FileListener(f)
+	if err != nil {
+		println("FileListener:", err.Error())
+		os.Exit(1)
+	}
+	// The listener took over the file descriptor, f must no longer refer to it
+	// and closing f must not close it.
+	if fd := f.Fd(); fd != ^uintptr(0) {
+		println("Fd after FileListener:", fd)
+		os.Exit(1)
+	}
+	if err := f.Close(); err != nil {
+		println("Close:", err.Error())
+		os.Exit(1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			println("Accept:", err.Error())
+			os.Exit(1)
+		}
+		wg.Add(1)
+		go func() {
+			echo(conn)
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+
+	// Close the listener while another goroutine waits in Accept.
+	accepted := make(chan error)
+	go func() {
+		_, err := ln.Accept()
+		accepted <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := ln.Close(); err != nil {
+		println("Close:", err.Error())
+		os.Exit(1)
+	}
+	if err := <-accepted; err == nil {
+		println("Accept succeeded after Close")
+		os.Exit(1)
+	}
+	println("done")
+}
+
+func echo(conn net.Conn) {
+	n, err := io.Copy(conn, conn)
+	if err != nil {
+		println("Copy:", err.Error())
+		os.Exit(1)
+	}
+	if err := conn.Close(); err != nil {
+		println("Close:", err.Error())
+		os.Exit(1)
+	}
+	println("echoed", n, "bytes")
+}
@@ -0,0 +1,68 @@
+package main
+
+// This program is built with -buildmode=c-shared and run by TestWasiReactor in
+// main_test.go. The exported functions are called by testdata/reactor.wat,
+// which wasmtime links to a single instance of this module.
+
+import "runtime"
+
+var initRuns int32
+
+func init() {
+	initRuns++
+	println("init:", initRuns)
+}
+
+// Not called in a reactor.
+func main() {
+	println("main called")
+}
+
+//export initCount
+func initCount() int32 {
+	return initRuns
+}
+
+// Heap memory allocated in one call must still be there in later calls.
+var values []int32
+
+//export appendValue
+func appendValue(n int32) {
+	values = append(values, n)
+}
+
+//export sumValues
+func sumValues() int32 {
+	sum := int32(0)
+	for _, n := range values {
+		sum += n
+	}
+	println("sum:", sum)
+	return sum
+}
+
+//export collect
+func collect() {
+	runtime.GC()
+}
+
+// A goroutine started in one call must keep running in later calls.
+var messages chan int32
+
+//export startReceiver
+func startReceiver() {
+	messages = make(chan int32)
+	go func() {
+		println("receiver started")
+		total := int32(0)
+		for n := range messages {
+			total += n
+			println("receiver got:", n, "total:", total)
+		}
+	}()
+}
+
+//export send
+func send(n int32) {
+	messages <- n
+}
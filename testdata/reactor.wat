@@ -0,0 +1,32 @@
+;; Calls the functions exported by testdata/reactor.go. wasmtime instantiates
+;; the reactor once (and calls its _initialize function) before running this
+;; module, so all calls go to the same instance. A failed check traps.
+(module
+  (import "reactor" "initCount" (func $initCount (result i32)))
+  (import "reactor" "appendValue" (func $appendValue (param i32)))
+  (import "reactor" "sumValues" (func $sumValues (result i32)))
+  (import "reactor" "collect" (func $collect))
+  (import "reactor" "startReceiver" (func $startReceiver))
+  (import "reactor" "send" (func $send (param i32)))
+
+  (func $check (param $ok i32)
+    (if (i32.eqz (local.get $ok))
+      (then unreachable)))
+
+  (func (export "_start")
+    ;; The package initializers ran once, from _initialize.
+    (call $check (i32.eq (call $initCount) (i32.const 1)))
+
+    ;; The heap is kept between calls, also across a garbage collection.
+    (call $appendValue (i32.const 1))
+    (call $appendValue (i32.const 2))
+    (call $collect)
+    (call $appendValue (i32.const 3))
+    (call $check (i32.eq (call $sumValues) (i32.const 6)))
+
+    ;; The goroutine started here receives the values sent in later calls.
+    (call $startReceiver)
+    (call $send (i32.const 10))
+    (call $send (i32.const 20))
+
+    (call $check (i32.eq (call $initCount) (i32.const 1)))))
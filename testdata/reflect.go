@@ -8,8 +8,11 @@ import (
 
 type (
 	myint    int
+	mystring string
 	myslice  []byte
 	myslice2 []myint
+	myints   []int
+	mymap    map[string]int
 	mychan   chan int
 	myptr    *int
 	point    struct {
@@ -343,6 +346,33 @@ func main() {
 	println("\nv.Interface() method")
 	testInterfaceMethod()
 
+	println("\nfunc types")
+	testFuncTypes()
+
+	println("\nfunc calls")
+	testCall()
+
+	println("\nMakeFunc")
+	testMakeFunc()
+
+	println("\nmethods")
+	testMethods()
+
+	println("\ntype names")
+	testTypeNames()
+
+	println("\nassignability")
+	testAssignability()
+
+	println("\nconversions")
+	testConversions()
+
+	println("\nmaps")
+	testMaps()
+
+	println("\nchannels")
+	testChannels()
+
 	// Test reflect.DeepEqual.
 	var selfref1, selfref2 selfref
 	selfref1.x = &selfref1
@@ -544,6 +574,452 @@ func testInterfaceMethod() {
 	}
 }
 
+type counter struct {
+	n    int
+	name string
+}
+
+func (c counter) Add(x int) int {
+	return c.n + x
+}
+
+func (c counter) Name() string {
+	return c.name
+}
+
+func (c *counter) Inc() {
+	c.n++
+}
+
+func (c counter) unexported() {
+}
+
+func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+func sum(prefix string, nums ...int) string {
+	total := 0
+	for _, n := range nums {
+		total += n
+	}
+	return prefix + itoa(total)
+}
+
+func describe(x interface{}) string {
+	switch x := x.(type) {
+	case int:
+		return "int " + itoa(x)
+	case string:
+		return "string " + x
+	default:
+		return "other"
+	}
+}
+
+func makePoint(x, y int16) point {
+	return point{x, y}
+}
+
+func testFuncTypes() {
+	t := reflect.TypeOf(divmod)
+	println("divmod:", t.NumIn(), t.NumOut(), t.IsVariadic(), t.In(0).Kind().String(), t.Out(1).Kind().String())
+	t = reflect.TypeOf(sum)
+	println("sum:", t.NumIn(), t.NumOut(), t.IsVariadic(), t.In(1).Kind().String(), t.In(1).Elem().Kind().String())
+	t = reflect.TypeOf(map[string][]int{})
+	println("map:", t.Key().Kind().String(), t.Elem().Kind().String())
+	println("same func type:", reflect.TypeOf(divmod) == reflect.TypeOf(func(x, y int) (int, int) { return 0, 0 }))
+}
+
+func testCall() {
+	out := reflect.ValueOf(divmod).Call([]reflect.Value{reflect.ValueOf(17), reflect.ValueOf(5)})
+	println("divmod(17, 5):", len(out), out[0].Int(), out[1].Int())
+	out = reflect.ValueOf(sum).Call([]reflect.Value{reflect.ValueOf("sum="), reflect.ValueOf(1), reflect.ValueOf(2), reflect.ValueOf(3)})
+	println("sum(1, 2, 3):", out[0].String())
+	out = reflect.ValueOf(sum).Call([]reflect.Value{reflect.ValueOf("sum=")})
+	println("sum():", out[0].String())
+	out = reflect.ValueOf(sum).CallSlice([]reflect.Value{reflect.ValueOf("sum="), reflect.ValueOf([]int{4, 5})})
+	println("sum([]int{4, 5}...):", out[0].String())
+	out = reflect.ValueOf(describe).Call([]reflect.Value{reflect.ValueOf(5)})
+	println("describe(5):", out[0].String())
+	out = reflect.ValueOf(makePoint).Call([]reflect.Value{reflect.ValueOf(int16(3)), reflect.ValueOf(int16(-4))})
+	p := out[0].Interface().(point)
+	println("makePoint(3, -4):", p.X, p.Y)
+	n := 10
+	closure := func(x int) { n += x }
+	out = reflect.ValueOf(closure).Call([]reflect.Value{reflect.ValueOf(5)})
+	println("closure:", len(out), n)
+
+	// Arguments only need to be assignable to the parameter types.
+	out = reflect.ValueOf(sum).CallSlice([]reflect.Value{reflect.ValueOf("sum="), reflect.ValueOf(myints{6, 7})})
+	println("sum(myints{6, 7}...):", out[0].String())
+	out = reflect.ValueOf(mapLen).Call([]reflect.Value{reflect.ValueOf(mymap{"a": 1, "b": 2})})
+	println("mapLen(mymap{...}):", out[0].Int())
+}
+
+func mapLen(m map[string]int) int {
+	return len(m)
+}
+
+func testMakeFunc() {
+	swap := func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{in[1], in[0]}
+	}
+	var intSwap func(int, int) (int, int)
+	reflect.ValueOf(&intSwap).Elem().Set(reflect.MakeFunc(reflect.TypeOf(intSwap), swap))
+	a, b := intSwap(1, 2)
+	println("intSwap(1, 2):", a, b)
+	var stringSwap func(string, string) (string, string)
+	stringSwap = reflect.MakeFunc(reflect.TypeOf(stringSwap), swap).Interface().(func(string, string) (string, string))
+	s1, s2 := stringSwap("a", "b")
+	println("stringSwap(a, b):", s1, s2)
+	fn := reflect.MakeFunc(reflect.TypeOf(describe), func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf("wrapped " + describe(in[0].Interface()))}
+	})
+	println("wrapped describe:", fn.Interface().(func(interface{}) string)("foo"))
+	out := fn.Call([]reflect.Value{reflect.ValueOf(3)})
+	println("call wrapped describe:", out[0].String())
+	var makeInts func() []int
+	makeInts = reflect.MakeFunc(reflect.TypeOf(makeInts), func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf(myints{1, 2, 3})}
+	}).Interface().(func() []int)
+	println("MakeFunc returning myints:", len(makeInts()))
+}
+
+func testMethods() {
+	c := counter{n: 5, name: "counter"}
+	t := reflect.TypeOf(c)
+	println("counter methods:", t.NumMethod())
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		println("  method:", m.Index, m.Name, m.Type.NumIn(), m.Type.NumOut())
+	}
+	println("*counter methods:", reflect.TypeOf(&c).NumMethod())
+	m, ok := reflect.TypeOf(&c).MethodByName("Inc")
+	println("*counter has Inc:", ok, m.Index)
+	_, ok = t.MethodByName("Inc")
+	println("counter has Inc:", ok)
+
+	out := reflect.ValueOf(c).MethodByName("Add").Call([]reflect.Value{reflect.ValueOf(3)})
+	println("c.Add(3):", out[0].Int())
+	out = reflect.ValueOf(c).Method(1).Call(nil)
+	println("c.Name():", out[0].String())
+	m, _ = t.MethodByName("Add")
+	out = m.Func.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(4)})
+	println("counter.Add(c, 4):", out[0].Int())
+	reflect.ValueOf(&c).MethodByName("Inc").Call(nil)
+	println("c.n after Inc:", c.n)
+	println("no such method:", reflect.ValueOf(c).MethodByName("Foo").IsValid())
+
+	println("stringer methods:", stringerType.NumMethod(), stringerType.Method(0).Name, stringerType.Method(0).Type.NumIn())
+	var itf interface{ Name() string } = c
+	v := reflect.ValueOf(&itf).Elem()
+	println("interface method:", v.Method(0).Call(nil)[0].String())
+}
+
+func testTypeNames() {
+	for _, v := range []interface{}{
+		0,
+		byte(0),
+		unsafe.Pointer(nil),
+		myint(0),
+		myslice{},
+		point{},
+		&point{},
+		errorValue,
+		&errorValue,
+		[]myint{},
+		[3]*point{},
+		map[string][]int{},
+		make(chan mychan),
+		divmod,
+		sum,
+		func(interface{}, ...point) {},
+		struct{}{},
+		mystruct{},
+		struct {
+			A int `json:"a"`
+			point
+		}{},
+		new(interface{ String() string }),
+		reflect.Value{},
+		reflect.Struct,
+	} {
+		t := reflect.TypeOf(v)
+		println("type:", t.String())
+		println("  name:", t.Name(), "pkgpath:", t.PkgPath())
+	}
+	t := reflect.TypeOf(mystruct{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		println("field:", field.Name, "pkgpath:", field.PkgPath)
+	}
+	println("value:", reflect.ValueOf(point{}).String(), reflect.Value{}.String())
+}
+
+type stringError interface {
+	String() string
+	Error() string
+}
+
+func testAssignability() {
+	for _, v := range []interface{}{
+		make(chan int),
+		make(<-chan int),
+		make(chan<- int),
+		make(chan (<-chan int)),
+		make(chan<- chan int),
+		make(mychan),
+	} {
+		t := reflect.TypeOf(v)
+		println("chan:", t.String(), t.ChanDir(), t.Elem().String())
+	}
+
+	types := []reflect.Type{
+		reflect.TypeOf(0),
+		reflect.TypeOf(myint(0)),
+		reflect.TypeOf([]myint{}),
+		reflect.TypeOf(myslice2{}),
+		reflect.TypeOf(myslice{}),
+		reflect.TypeOf(make(chan int)),
+		reflect.TypeOf(make(<-chan int)),
+		reflect.TypeOf(make(mychan)),
+		reflect.TypeOf(errorValue),
+		reflect.TypeOf(reflect.Struct),
+		reflect.TypeOf(counter{}),
+		reflect.TypeOf(&counter{}),
+		reflect.TypeOf(new(interface{})).Elem(),
+		errorType,
+		stringerType,
+		reflect.TypeOf(new(stringError)).Elem(),
+		reflect.TypeOf(new(interface{ Inc() })).Elem(),
+	}
+	for _, t := range types {
+		s := ""
+		for _, u := range types {
+			if t.AssignableTo(u) {
+				s += "1"
+			} else {
+				s += "0"
+			}
+		}
+		println(s, t.String())
+	}
+	for _, u := range types {
+		if u.Kind() == reflect.Interface {
+			println("implements:", u.String(), reflect.TypeOf(&counter{}).Implements(u), reflect.TypeOf(errorValue).Implements(u))
+		}
+	}
+
+	// Set a value of a different (but assignable) type.
+	var err error
+	reflect.ValueOf(&err).Elem().Set(reflect.ValueOf(errorValue))
+	println("set error:", err.Error())
+	var s myslice2
+	reflect.ValueOf(&s).Elem().Set(reflect.ValueOf([]myint{3, 5}))
+	println("set myslice2:", len(s), s[1])
+	var ints []int
+	reflect.ValueOf(&ints).Elem().Set(reflect.ValueOf(myints{1, 2}))
+	println("set []int:", len(ints), ints[1])
+	var m map[string]int
+	reflect.ValueOf(&m).Elem().Set(reflect.ValueOf(mymap{"x": 8}))
+	println("set map[string]int:", m["x"])
+	lists := reflect.Append(reflect.ValueOf([][]int{}), reflect.ValueOf(myints{3, 4}))
+	println("append myints to [][]int:", lists.Len(), lists.Index(0).Index(1).Int())
+}
+
+func testConversions() {
+	types := []reflect.Type{
+		reflect.TypeOf(0),
+		reflect.TypeOf(myint(0)),
+		reflect.TypeOf(uint8(0)),
+		reflect.TypeOf(float32(0)),
+		reflect.TypeOf(complex64(0)),
+		reflect.TypeOf(""),
+		reflect.TypeOf(mystring("")),
+		reflect.TypeOf([]byte{}),
+		reflect.TypeOf(myslice{}),
+		reflect.TypeOf([]rune{}),
+		reflect.TypeOf([]int{}),
+		reflect.TypeOf(&[2]int{}),
+		reflect.TypeOf(new(int)),
+		reflect.TypeOf(new(myint)),
+		reflect.TypeOf(point{}),
+		reflect.TypeOf(struct{ X, Y int16 }{}),
+		stringerType,
+	}
+	for _, t := range types {
+		s := ""
+		for _, u := range types {
+			if t.ConvertibleTo(u) {
+				s += "1"
+			} else {
+				s += "0"
+			}
+		}
+		println(s, t.String())
+	}
+
+	v := reflect.ValueOf(myint(-5)).Convert(reflect.TypeOf(0))
+	println("myint to int:", v.Type().String(), v.Int(), v.CanSet())
+	v = reflect.ValueOf(-1).Convert(reflect.TypeOf(uint8(0)))
+	println("int to uint8:", v.Type().String(), v.Uint())
+	v = reflect.ValueOf(uint64(1 << 40)).Convert(reflect.TypeOf(float32(0)))
+	println("uint64 to float32:", v.Type().String(), v.Float())
+	v = reflect.ValueOf(-3.75).Convert(reflect.TypeOf(int8(0)))
+	println("float64 to int8:", v.Type().String(), v.Int())
+	v = reflect.ValueOf(float32(2.5)).Convert(reflect.TypeOf(0.0))
+	println("float32 to float64:", v.Type().String(), v.Float())
+	v = reflect.ValueOf(complex64(1 + 2i)).Convert(reflect.TypeOf(complex128(0)))
+	println("complex64 to complex128:", v.Type().String(), real(v.Complex()), imag(v.Complex()))
+	v = reflect.ValueOf(65).Convert(reflect.TypeOf(mystring("")))
+	println("int to mystring:", v.Type().String(), v.String())
+	v = reflect.ValueOf(-1).Convert(reflect.TypeOf(""))
+	println("invalid rune:", v.String() == "\uFFFD")
+	v = reflect.ValueOf("héllo").Convert(reflect.TypeOf(myslice{}))
+	println("string to myslice:", v.Type().String(), v.Len(), v.Index(1).Uint())
+	v = reflect.ValueOf("héllo").Convert(reflect.TypeOf([]rune{}))
+	println("string to []rune:", v.Type().String(), v.Len(), v.Index(1).Int())
+	v = reflect.ValueOf([]rune{'a', 'é'}).Convert(reflect.TypeOf(""))
+	println("[]rune to string:", v.String())
+	v = reflect.ValueOf([]byte("xyz")).Convert(reflect.TypeOf(mystring("")))
+	println("[]byte to mystring:", v.Type().String(), v.String())
+	v = reflect.ValueOf([]int{4, 5, 6}).Convert(reflect.TypeOf(&[2]int{}))
+	println("slice to array pointer:", v.Type().String(), v.Elem().Index(1).Int())
+	n := myint(7)
+	v = reflect.ValueOf(&n).Convert(reflect.TypeOf(new(int)))
+	println("*myint to *int:", v.Type().String(), v.Elem().Int())
+	v = reflect.ValueOf(point{3, 4}).Convert(reflect.TypeOf(struct{ X, Y int16 }{}))
+	println("point to struct:", v.Type().String(), v.Field(1).Int())
+	v = reflect.ValueOf(reflect.Struct).Convert(stringerType)
+	println("Kind to stringer:", v.Type().String(), v.Kind() == reflect.Interface, v.Elem().Type().String())
+
+	// Converting an addressable value must copy it.
+	x := 3
+	v = reflect.ValueOf(&x).Elem().Convert(reflect.TypeOf(myint(0)))
+	x = 4
+	println("copied:", v.Int(), v.CanAddr())
+}
+
+func testMaps() {
+	// String keys.
+	m := reflect.MakeMap(reflect.TypeOf(map[string]int{}))
+	m.SetMapIndex(reflect.ValueOf("one"), reflect.ValueOf(1))
+	m.SetMapIndex(reflect.ValueOf("two"), reflect.ValueOf(2))
+	m.SetMapIndex(reflect.ValueOf("three"), reflect.ValueOf(3))
+	m.SetMapIndex(reflect.ValueOf("two"), reflect.Value{})
+	sm := m.Interface().(map[string]int)
+	println("string keys:", m.Len(), sm["one"], sm["two"], sm["three"])
+	println("lookup:", m.MapIndex(reflect.ValueOf("three")).Int(), m.MapIndex(reflect.ValueOf("two")).IsValid())
+	sum := 0
+	for _, key := range m.MapKeys() {
+		sum += len(key.String())
+	}
+	println("key lengths:", sum)
+
+	// Binary keys and values bigger than a pointer.
+	m = reflect.MakeMapWithSize(reflect.TypeOf(map[point][3]int{}), 20)
+	for i := 0; i < 20; i++ {
+		m.SetMapIndex(reflect.ValueOf(point{int16(i), -1}), reflect.ValueOf([3]int{i, i * 2, i * 3}))
+	}
+	pm := m.Interface().(map[point][3]int)
+	println("binary keys:", m.Len(), pm[point{7, -1}][2], m.MapIndex(reflect.ValueOf(point{11, -1})).Index(1).Int())
+	sum = 0
+	iter := m.MapRange()
+	for iter.Next() {
+		sum += int(iter.Key().Field(0).Int()) * int(iter.Value().Index(2).Int())
+	}
+	println("iterated:", sum)
+
+	// Keys that are stored in an interface by the compiler.
+	pm2 := map[float64]string{1.5: "a"}
+	m = reflect.ValueOf(pm2)
+	m.SetMapIndex(reflect.ValueOf(2.5), reflect.ValueOf("b"))
+	println("float keys:", m.Len(), pm2[2.5], m.MapIndex(reflect.ValueOf(1.5)).String())
+	for _, key := range m.MapKeys() {
+		if key.Float() == 2.5 {
+			println("float key:", key.Type().String(), key.Float())
+		}
+	}
+	type named struct {
+		name string
+		n    int
+	}
+	m = reflect.MakeMap(reflect.TypeOf(map[named]bool{}))
+	m.SetMapIndex(reflect.ValueOf(named{"x", 1}), reflect.ValueOf(true))
+	println("struct keys:", m.Interface().(map[named]bool)[named{"x", 1}], m.MapIndex(reflect.ValueOf(named{"x", 2})).IsValid(), m.MapKeys()[0].Type() == reflect.TypeOf(named{}))
+	m = reflect.MakeMap(reflect.TypeOf(map[interface{}]int{}))
+	m.SetMapIndex(reflect.ValueOf(3), reflect.ValueOf(30))
+	m.SetMapIndex(reflect.ValueOf("3"), reflect.ValueOf(33))
+	im := m.Interface().(map[interface{}]int)
+	println("interface keys:", im[3], im["3"], m.MapIndex(reflect.ValueOf(3)).Int(), m.MapKeys()[0].Kind().String())
+
+	// Named map type and nil maps.
+	var nilMap map[myint]string
+	m = reflect.ValueOf(nilMap)
+	println("nil map:", m.Len(), len(m.MapKeys()), m.MapIndex(reflect.ValueOf(myint(1))).IsValid())
+	m.SetMapIndex(reflect.ValueOf(myint(1)), reflect.Value{})
+	m = reflect.MakeMap(reflect.TypeOf(nilMap))
+	m.SetMapIndex(reflect.ValueOf(myint(5)), reflect.ValueOf("five"))
+	println("myint keys:", m.Interface().(map[myint]string)[5], m.MapKeys()[0].Type().String())
+}
+
+func testChannels() {
+	c := reflect.MakeChan(reflect.TypeOf(make(chan point)), 2)
+	println("chan:", c.Type().String(), c.Len(), c.Cap())
+	c.Send(reflect.ValueOf(point{1, 2}))
+	println("try send:", c.TrySend(reflect.ValueOf(point{3, 4})), c.TrySend(reflect.ValueOf(point{5, 6})), c.Len())
+	x, ok := c.Recv()
+	println("recv:", x.Field(0).Int(), x.Field(1).Int(), ok)
+	ch := c.Interface().(chan point)
+	p := <-ch
+	println("received:", p.X, p.Y)
+	x, ok = c.TryRecv()
+	println("try recv:", x.IsValid(), ok)
+
+	// Select.
+	c2 := make(chan string, 1)
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: c},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c2)},
+		{Dir: reflect.SelectDefault},
+	}
+	chosen, recv, recvOK := reflect.Select(cases)
+	println("select default:", chosen, recv.IsValid(), recvOK)
+	c2 <- "hello"
+	chosen, recv, recvOK = reflect.Select(cases)
+	println("select recv:", chosen, recv.String(), recvOK)
+	chosen, recv, recvOK = reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.Value{}},
+		{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c2), Send: reflect.ValueOf("world")},
+	})
+	println("select send:", chosen, recv.IsValid(), recvOK, <-c2)
+	go func() {
+		ch <- point{7, 8}
+	}()
+	chosen, recv, recvOK = reflect.Select(cases[:2])
+	println("select blocking:", chosen, recv.Field(1).Int(), recvOK)
+
+	// Closed and direction-restricted channels.
+	c.Close()
+	x, ok = c.Recv()
+	println("closed:", x.Field(0).Int(), ok)
+	var recvOnly <-chan string = c2
+	c2 <- "!"
+	x, ok = reflect.ValueOf(recvOnly).Recv()
+	println("recv-only:", x.String(), ok)
+}
+
+func itoa(n int) string {
+	if n < 0 {
+		return "-" + itoa(-n)
+	}
+	if n < 10 {
+		return string(rune('0' + n))
+	}
+	return itoa(n/10) + itoa(n%10)
+}
+
 var xorshift32State uint32 = 1
 
 func xorshift32(x uint32) uint32 {
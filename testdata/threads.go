@@ -0,0 +1,180 @@
+package main
+
+// This test is meant for targets where goroutines run in parallel on multiple
+// threads, but it also passes on targets with a single thread.
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+func main() {
+	println("GOMAXPROCS at least 1:", runtime.GOMAXPROCS(0) >= 1)
+
+	testWaitGroup()
+	testMutex()
+	testAtomic()
+	testChannels()
+	testAllocate()
+	testMalloc()
+}
+
+// Start a number of goroutines and wait until they're all done.
+func testWaitGroup() {
+	var wg sync.WaitGroup
+	results := make([]int, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			results[i] = i * i
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	sum := 0
+	for _, n := range results {
+		sum += n
+	}
+	println("waitgroup sum:", sum)
+}
+
+// Increment a counter from many goroutines, protected by a mutex.
+func testMutex() {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	counter := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < 1000; j++ {
+				mu.Lock()
+				counter++
+				mu.Unlock()
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	println("mutex counter:", counter)
+}
+
+// Increment a counter from many goroutines using atomic operations.
+func testAtomic() {
+	var wg sync.WaitGroup
+	var counter uint32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < 1000; j++ {
+				atomic.AddUint32(&counter, 1)
+			}
+			wg.Done()
+		}()
+	}
+	wg.Wait()
+	println("atomic counter:", atomic.LoadUint32(&counter))
+}
+
+// Send values through a pipeline of goroutines.
+func testChannels() {
+	in := make(chan int)
+	out := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			for n := range in {
+				out <- n * 2
+			}
+			wg.Done()
+		}()
+	}
+	go func() {
+		for i := 1; i <= 100; i++ {
+			in <- i
+		}
+		close(in)
+		wg.Wait()
+		close(out)
+	}()
+	sum := 0
+	for n := range out {
+		sum += n
+	}
+	println("channel sum:", sum)
+}
+
+type node struct {
+	next  *node
+	value int
+}
+
+// Allocate a lot of memory from multiple goroutines at the same time, so that
+// the garbage collector runs while other goroutines are running.
+func testAllocate() {
+	var wg sync.WaitGroup
+	sums := make([]int, 4)
+	for i := range sums {
+		wg.Add(1)
+		go func(i int) {
+			for round := 0; round < 100; round++ {
+				var list *node
+				for j := 0; j < 100; j++ {
+					list = &node{next: list, value: j}
+				}
+				for n := list; n != nil; n = n.next {
+					sums[i] += n.value
+				}
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	total := 0
+	for _, sum := range sums {
+		total += sum
+	}
+	println("allocation sum:", total)
+}
+
+//export malloc
+func malloc(size uintptr) unsafe.Pointer
+
+//export free
+func free(ptr unsafe.Pointer)
+
+// Call malloc and free from multiple goroutines at the same time, like C code
+// or a host running on several threads would. The pointers are stored as
+// uintptr, so only the allocator keeps the memory alive while the garbage
+// collector runs.
+func testMalloc() {
+	var wg sync.WaitGroup
+	sums := make([]int, 4)
+	for i := range sums {
+		wg.Add(1)
+		go func(i int) {
+			for round := 0; round < 20; round++ {
+				buffers := make([]uintptr, 50)
+				for j := range buffers {
+					ptr := malloc(64)
+					*(*int)(ptr) = j
+					buffers[j] = uintptr(ptr)
+				}
+				runtime.GC()
+				for _, buf := range buffers {
+					sums[i] += *(*int)(unsafe.Pointer(buf))
+					free(unsafe.Pointer(buf))
+				}
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	total := 0
+	for _, sum := range sums {
+		total += sum
+	}
+	println("malloc sum:", total)
+}
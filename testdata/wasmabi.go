@@ -0,0 +1,34 @@
+package main
+
+// This program is built with -buildmode=c-shared and run by TestWasmABI in
+// main_test.go. The exported functions are called by testdata/wasmabi.wat,
+// which passes strings like a host would: it allocates them with malloc, and
+// releases both the parameters and the results with free.
+
+import "runtime"
+
+// Not called in a reactor.
+func main() {
+}
+
+//export greet
+//go:wasm-abi ptrlen
+func greet(name string) string {
+	return "Hello, " + name + "!"
+}
+
+//export show
+//go:wasm-abi ptrlen
+func show(s string) {
+	println(s)
+}
+
+// Report the heap in use after a garbage collection, so that the host can
+// check that memory it freed is collected.
+//export heapInUse
+func heapInUse() int32 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return int32(stats.HeapInuse)
+}
@@ -0,0 +1,53 @@
+;; Calls the functions exported by testdata/wasmabi.go with the ptrlen ABI, the
+;; way a host would. wasmtime links this module to a single instance of the
+;; reactor. A failed check traps.
+(module
+  (import "reactor" "memory" (memory 0))
+  (import "reactor" "malloc" (func $malloc (param i32) (result i32)))
+  (import "reactor" "free" (func $free (param i32)))
+  (import "reactor" "greet" (func $greet (param i32 i32) (result i64)))
+  (import "reactor" "show" (func $show (param i32 i32)))
+  (import "reactor" "heapInUse" (func $heapInUse (result i32)))
+
+  (func $check (param $ok i32)
+    (if (i32.eqz (local.get $ok))
+      (then unreachable)))
+
+  ;; Call greet with "wasm" in memory allocated with malloc, which is freed
+  ;; again after the call. The result has the pointer in the upper 32 bits and
+  ;; the length in the lower 32 bits.
+  (func $callGreet (result i64)
+    (local $param i32)
+    (local $result i64)
+    (local.set $param (call $malloc (i32.const 4)))
+    (i32.store (local.get $param) (i32.const 0x6d736177)) ;; "wasm"
+    (local.set $result (call $greet (local.get $param) (i32.const 4)))
+    (call $free (local.get $param))
+    (local.get $result))
+
+  (func (export "_start")
+    (local $result i64)
+    (local $ptr i32)
+    (local $len i32)
+    (local $before i32)
+    (local $i i32)
+
+    ;; The result stays valid until it is freed, also across a garbage
+    ;; collection and when passed back to Go.
+    (local.set $result (call $callGreet))
+    (local.set $ptr (i32.wrap_i64 (i64.shr_u (local.get $result) (i64.const 32))))
+    (local.set $len (i32.wrap_i64 (local.get $result)))
+    (call $check (i32.eq (local.get $len) (i32.const 12)))
+    (drop (call $heapInUse))
+    (call $show (local.get $ptr) (local.get $len))
+    (call $free (local.get $ptr))
+
+    ;; Parameters and results that were freed are collected: without free,
+    ;; these calls would keep about 32kB of memory alive.
+    (local.set $before (call $heapInUse))
+    (loop $loop
+      (local.set $result (call $callGreet))
+      (call $free (i32.wrap_i64 (i64.shr_u (local.get $result) (i64.const 32))))
+      (local.set $i (i32.add (local.get $i) (i32.const 1)))
+      (br_if $loop (i32.lt_u (local.get $i) (i32.const 1000))))
+    (call $check (i32.lt_u (call $heapInUse) (i32.add (local.get $before) (i32.const 4096))))))
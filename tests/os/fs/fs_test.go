@@ -0,0 +1,266 @@
+package os_fs_test
+
+// Tests for file system support in the os package. They only use portable
+// behavior, so that they pass both natively and on WASI (where the temporary
+// directory is a preopened directory).
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "os_fs_test")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, name, data string) {
+	if err := os.WriteFile(name, []byte(data), 0644); err != nil {
+		t.Fatalf("WriteFile %s: %v", name, err)
+	}
+}
+
+func TestReadDir(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, filepath.Join(dir, "b.txt"), "b")
+	writeFile(t, filepath.Join(dir, "a.txt"), "a")
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir %s: %v", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+		lstat, err := os.Lstat(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Lstat: %v", err)
+		}
+		if entry.Type() != lstat.Mode().Type() {
+			t.Errorf("%s: Type() = %v, want %v", entry.Name(), entry.Type(), lstat.Mode().Type())
+		}
+		if entry.IsDir() != (entry.Name() == "sub") {
+			t.Errorf("%s: IsDir() = %v", entry.Name(), entry.IsDir())
+		}
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatalf("%s: Info: %v", entry.Name(), err)
+		}
+		if !os.SameFile(info, lstat) {
+			t.Errorf("%s: SameFile(info, lstat) = false", entry.Name())
+		}
+	}
+	if got, want := strings.Join(sorted(names), " "), "a.txt b.txt sub"; got != want {
+		t.Errorf("ReadDir returned %q, want %q", got, want)
+	}
+
+	if _, err := os.ReadDir(filepath.Join(dir, "a.txt")); err == nil {
+		t.Errorf("ReadDir on a regular file: expected an error")
+	}
+	if _, err := os.ReadDir(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("ReadDir on a missing directory: got %v, want a not exist error", err)
+	}
+}
+
+func TestReaddirnamesBatches(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"1", "2", "3"} {
+		writeFile(t, filepath.Join(dir, name), name)
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	var names []string
+	for {
+		batch, err := f.Readdirnames(2)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Readdirnames: %v", err)
+		}
+		if len(batch) == 0 || len(batch) > 2 {
+			t.Fatalf("Readdirnames(2) returned %d names", len(batch))
+		}
+		names = append(names, batch...)
+	}
+	if got, want := strings.Join(sorted(names), " "), "1 2 3"; got != want {
+		t.Errorf("Readdirnames returned %q, want %q", got, want)
+	}
+}
+
+// Closing a directory that was only partially read must free the directory
+// stream without closing the file descriptor twice.
+func TestReaddirnamesClose(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"1", "2", "3"} {
+		writeFile(t, filepath.Join(dir, name), name)
+	}
+
+	for i := 0; i < 100; i++ {
+		f, err := os.Open(dir)
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		names, err := f.Readdirnames(1)
+		if err != nil {
+			t.Fatalf("Readdirnames: %v", err)
+		}
+		if len(names) != 1 {
+			t.Fatalf("Readdirnames(1) returned %d names", len(names))
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+	}
+}
+
+func TestStat(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// File systems may store timestamps with a coarse granularity.
+	before := time.Now().Add(-2 * time.Second)
+	name := filepath.Join(dir, "file")
+	writeFile(t, name, "hello")
+	after := time.Now().Add(2 * time.Second)
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Name() != "file" {
+		t.Errorf("Name() = %q, want %q", info.Name(), "file")
+	}
+	if info.Size() != 5 {
+		t.Errorf("Size() = %d, want 5", info.Size())
+	}
+	if !info.Mode().IsRegular() {
+		t.Errorf("Mode() = %v, want a regular file", info.Mode())
+	}
+	if mtime := info.ModTime(); mtime.Before(before) || mtime.After(after) {
+		t.Errorf("ModTime() = %v, want between %v and %v", mtime, before, after)
+	}
+
+	info, err = os.Lstat(dir)
+	if err != nil {
+		t.Fatalf("Lstat: %v", err)
+	}
+	if !info.IsDir() {
+		t.Errorf("Lstat(%s).IsDir() = false", dir)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Stat on a missing file: got %v, want a not exist error", err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	from := filepath.Join(dir, "from")
+	to := filepath.Join(dir, "to")
+	writeFile(t, from, "contents")
+	if err := os.Rename(from, to); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("Stat of old name: got %v, want a not exist error", err)
+	}
+	data, err := os.ReadFile(to)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "contents" {
+		t.Errorf("ReadFile returned %q, want %q", data, "contents")
+	}
+}
+
+func TestRemoveAll(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "file"), "data")
+	writeFile(t, filepath.Join(root, "a", "b", "file"), "data")
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := os.Lstat(root); !os.IsNotExist(err) {
+		t.Errorf("Lstat after RemoveAll: got %v, want a not exist error", err)
+	}
+}
+
+func TestChdir(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	oldwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir %s: %v", dir, err)
+	}
+	defer os.Chdir(oldwd)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	// The temporary directory may be reached through a symlink, so compare
+	// the directories themselves instead of their names.
+	wdInfo, err := os.Stat(wd)
+	if err != nil {
+		t.Fatalf("Stat %s: %v", wd, err)
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Stat %s: %v", dir, err)
+	}
+	if !os.SameFile(wdInfo, dirInfo) {
+		t.Errorf("Getwd() = %q after Chdir(%q)", wd, dir)
+	}
+
+	// Relative paths are resolved against the new working directory.
+	writeFile(t, "relative", "data")
+	if _, err := os.Stat(filepath.Join(dir, "relative")); err != nil {
+		t.Errorf("Stat after creating a relative path: %v", err)
+	}
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "relative" {
+		t.Errorf("ReadDir(\".\") returned %v, want a single entry", entries)
+	}
+}
+
+func sorted(names []string) []string {
+	sort.Strings(names)
+	return names
+}
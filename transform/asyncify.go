@@ -0,0 +1,183 @@
+package transform
+
+// This file determines which functions need to be instrumented by the asyncify
+// pass of wasm-opt. Asyncify is how goroutines are implemented on WebAssembly:
+// a goroutine pauses by unwinding its call stack and continues by rewinding it.
+// By default, every function that might be on the call stack of a goroutine
+// while it pauses must be instrumented, which is a large cost both in code size
+// and in speed. But most functions can never be on such a call stack, because
+// they don't call (directly or indirectly) any function that blocks. Telling
+// asyncify about these functions avoids most of the overhead.
+
+import (
+	"sort"
+	"strings"
+
+	"tinygo.org/x/go-llvm"
+)
+
+// AsyncifyRemoveList returns the names of all functions defined in the module
+// that can never pause the current goroutine, sorted by name. These functions
+// don't need to be instrumented by asyncify and can be passed to wasm-opt with
+// --pass-arg=asyncify-removelist@...
+//
+// A goroutine pauses by calling tinygo_unwind. A function may pause if it calls
+// tinygo_unwind or another function that may pause. For calls that can't be
+// resolved, this analysis is conservative:
+//   * An indirect call may pause if any function that may pause has its address
+//     taken.
+//   * A call to an external function (for example, a C function) may pause if
+//     any function that may pause is visible outside of the module, because the
+//     external function might call it.
+// Calls to functions imported from the WebAssembly host never pause.
+//
+// Patterns in the list may contain a '*' wildcard, which matches any sequence
+// of characters. Therefore, functions with a '*' in their name (like methods on
+// pointer receivers) are left out if they could match a function that may
+// pause.
+func AsyncifyRemoveList(mod llvm.Module) []string {
+	// This is nil if no goroutine ever pauses.
+	unwind := mod.NamedFunction("tinygo_unwind")
+
+	// Collect all function definitions.
+	var functions []llvm.Value
+	for fn := mod.FirstFunction(); !fn.IsNil(); fn = llvm.NextFunction(fn) {
+		if !fn.IsDeclaration() {
+			functions = append(functions, fn)
+		}
+	}
+
+	// Find all functions that may pause, by iterating until no new function
+	// is found. This is not the fastest algorithm but the number of iterations
+	// is usually low: the depth of the deepest call chain that leads to
+	// tinygo_unwind.
+	mayPause := make(map[llvm.Value]bool)
+	indirectMayPause := false
+	externalMayPause := false
+	for {
+		changed := false
+		for _, fn := range functions {
+			if mayPause[fn] || !functionMayPause(fn, unwind, mayPause, indirectMayPause, externalMayPause) {
+				continue
+			}
+			mayPause[fn] = true
+			changed = true
+			if !indirectMayPause && isAddressTaken(fn) {
+				indirectMayPause = true
+			}
+			if !externalMayPause && fn.Linkage() != llvm.InternalLinkage && fn.Linkage() != llvm.PrivateLinkage {
+				externalMayPause = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// Create the list of functions that never pause.
+	var pausingNames []string
+	for _, fn := range functions {
+		if mayPause[fn] {
+			pausingNames = append(pausingNames, fn.Name())
+		}
+	}
+	var names []string
+	for _, fn := range functions {
+		if mayPause[fn] {
+			continue
+		}
+		name := fn.Name()
+		if strings.ContainsAny(name, ",\n") {
+			// Can't be represented in the list.
+			continue
+		}
+		if strings.Contains(name, "*") && wildcardMatchesAny(name, pausingNames) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// functionMayPause returns whether the given function contains a call that may
+// pause the current goroutine.
+func functionMayPause(fn, unwind llvm.Value, mayPause map[llvm.Value]bool, indirectMayPause, externalMayPause bool) bool {
+	for bb := fn.FirstBasicBlock(); !bb.IsNil(); bb = llvm.NextBasicBlock(bb) {
+		for inst := bb.FirstInstruction(); !inst.IsNil(); inst = llvm.NextInstruction(inst) {
+			if inst.IsACallInst().IsNil() {
+				continue
+			}
+			callee := inst.CalledValue()
+			if !callee.IsAConstantExpr().IsNil() && callee.Opcode() == llvm.BitCast {
+				callee = callee.Operand(0)
+			}
+			if callee.IsAFunction().IsNil() {
+				// Indirect call.
+				if indirectMayPause {
+					return true
+				}
+				continue
+			}
+			if callee == unwind || mayPause[callee] {
+				return true
+			}
+			if callee.IsDeclaration() && externalMayPause && !isHostImport(callee) {
+				// Calls a function outside of this module, which might call
+				// back into the module.
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isAddressTaken returns whether the function is used in any way other than as
+// the callee of a call instruction, which means it may be called indirectly.
+func isAddressTaken(fn llvm.Value) bool {
+	for use := fn.FirstUse(); !use.IsNil(); use = use.NextUse() {
+		user := use.User()
+		if user.IsACallInst().IsNil() || user.CalledValue() != fn {
+			return true
+		}
+	}
+	return false
+}
+
+// isHostImport returns whether the given function declaration is an LLVM
+// intrinsic or a function imported from the WebAssembly host. Neither can call
+// back into the module while a goroutine pauses.
+func isHostImport(fn llvm.Value) bool {
+	if strings.HasPrefix(fn.Name(), "llvm.") {
+		return true
+	}
+	return !fn.GetStringAttributeAtIndex(-1, "wasm-import-module").IsNil()
+}
+
+// wildcardMatchesAny returns whether the pattern, where '*' matches any
+// sequence of characters, matches any of the given names.
+func wildcardMatchesAny(pattern string, names []string) bool {
+	for _, name := range names {
+		if wildcardMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch returns whether the pattern matches the given name, using the
+// same rules as the asyncify lists of wasm-opt.
+func wildcardMatch(pattern, name string) bool {
+	if pattern == "" {
+		return name == ""
+	}
+	if pattern[0] == '*' {
+		for i := 0; i <= len(name); i++ {
+			if wildcardMatch(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	return name != "" && pattern[0] == name[0] && wildcardMatch(pattern[1:], name[1:])
+}
@@ -0,0 +1,34 @@
+package transform_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/transform"
+	"tinygo.org/x/go-llvm"
+)
+
+func TestAsyncifyRemoveList(t *testing.T) {
+	t.Parallel()
+
+	ctx := llvm.NewContext()
+	defer ctx.Dispose()
+	buf, err := llvm.NewMemoryBufferFromFile("testdata/asyncify.ll")
+	if err != nil {
+		t.Fatal("could not read file:", err)
+	}
+	mod, err := ctx.ParseIR(buf)
+	if err != nil {
+		t.Fatalf("could not load module:\n%v", err)
+	}
+
+	removeList := transform.AsyncifyRemoveList(mod)
+	expected := []string{
+		"(*main.T).Print",
+		"main.allocate",
+		"main.print",
+	}
+	if !reflect.DeepEqual(removeList, expected) {
+		t.Errorf("unexpected asyncify remove list:\nexpected: %q\nactual:   %q", expected, removeList)
+	}
+}
@@ -280,13 +280,17 @@ func (p *lowerInterfacesPass) run() error {
 
 	// Remove all method sets, which are now unnecessary and inhibit later
 	// optimizations if they are left in place. Also remove references to the
-	// interface type assert functions just to be sure.
+	// interface type assert functions just to be sure, unless the reflect
+	// package needs them to implement Type.Implements.
 	zeroUintptr := llvm.ConstNull(p.uintptrType)
+	keepTypeAsserts := hasUses(p.mod.NamedGlobal("reflect.typeAssertsSidetable"))
 	for _, t := range p.types {
 		initializer := t.typecode.Initializer()
 		methodSet := llvm.ConstExtractValue(initializer, []uint32{2})
 		initializer = llvm.ConstInsertValue(initializer, llvm.ConstNull(methodSet.Type()), []uint32{2})
-		initializer = llvm.ConstInsertValue(initializer, zeroUintptr, []uint32{4})
+		if !keepTypeAsserts {
+			initializer = llvm.ConstInsertValue(initializer, zeroUintptr, []uint32{4})
+		}
 		t.typecode.SetInitializer(initializer)
 	}
 
@@ -34,7 +34,16 @@ func hasUses(value llvm.Value) bool {
 // contents, and returns the global.
 // Note that it is left with the default linkage etc., you should set
 // linkage/constant/etc properties yourself.
+//
+// The contents can also be a slice of constant llvm.Value objects (of the given
+// element type), for example to store function pointers.
 func makeGlobalArray(mod llvm.Module, bufItf interface{}, name string, elementType llvm.Type) llvm.Value {
+	if values, ok := bufItf.([]llvm.Value); ok {
+		value := llvm.ConstArray(elementType, values)
+		global := llvm.AddGlobal(mod, value.Type(), name)
+		global.SetInitializer(value)
+		return global
+	}
 	buf := reflect.ValueOf(bufItf)
 	globalType := llvm.ArrayType(elementType, buf.Len())
 	global := llvm.AddGlobal(mod, globalType, name)
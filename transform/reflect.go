@@ -15,7 +15,8 @@ package transform
 //   * Prefix types (pointer, slice, interface, channel): these just add
 //     something to an existing type. For example, a pointer like *int just adds
 //     the fact that it's a pointer to an existing type (int).
-//     These are encoded efficiently by adding a prefix to a type code.
+//     These are encoded efficiently by adding a prefix to a type code. Channels
+//     also store their direction in the two lowest bits after the prefix.
 //   * Types with multiple fields (struct, array, func, map). All of these have
 //     multiple fields contained within. Most obviously structs can contain many
 //     types as fields. Also arrays contain not just the element type but also
@@ -26,6 +27,11 @@ package transform
 // This distinction is also important for how named types are encoded. At the
 // moment, named basic type just get a unique number assigned while named
 // non-basic types have their underlying type stored in a sidetable.
+//
+// Methods that can be called through reflection are not part of the type code.
+// They are stored in a separate sidetable that is indexed by type code, see
+// addMethodSet. Similarly, the interface type assert functions (used to
+// implement Type.Implements) are stored in a sidetable indexed by type code.
 
 import (
 	"encoding/binary"
@@ -90,6 +96,13 @@ type typeCodeAssignmentState struct {
 	namedBasicTypes    map[string]int
 	namedNonBasicTypes map[string]int
 
+	// The names of named basic types: for each named basic type, the type name
+	// (like "json.Number") and package path are stored as indices into the
+	// struct names sidetable. The number of a named basic type minus one is
+	// the index of its entry in this sidetable.
+	namedBasicTypesSidetable      []uint64
+	needsNamedBasicTypesSidetable bool
+
 	// Map of array types to their type code.
 	arrayTypes               map[string]int
 	arrayTypesSidetable      []byte
@@ -105,14 +118,51 @@ type typeCodeAssignmentState struct {
 	structNamesSidetable      []byte
 	needsStructTypesSidetable bool
 
-	// This byte array is stored in reflect.namedNonBasicTypesSidetable and is
-	// used at runtime to get details about a named non-basic type.
-	// Entries are varints (see makeVarint below and readVarint in
-	// reflect/sidetables.go for the encoding): one varint per entry. The
-	// integers in namedNonBasicTypes are indices into this array. Because these
-	// are varints, most type codes are really small (just one byte).
+	// Map of func types to their type code.
+	funcTypes               map[string]int
+	funcTypesSidetable      []byte
+	needsFuncTypesSidetable bool
+
+	// List of wrapper functions (as ptrtoint constants) to call a function
+	// through reflection. There are two wrappers for each func type: one used
+	// by reflect.Value.Call and one used by reflect.MakeFunc.
+	funcWrappersSidetable      []llvm.Value
+	needsFuncWrappersSidetable bool
+
+	// Map of map types to their type code.
+	mapTypes               map[string]int
+	mapTypesSidetable      []byte
+	needsMapTypesSidetable bool
+
+	// Map of other types (that don't need a sidetable) to their type code.
+	fallbackTypes map[string]int
+
+	// Method sets of all types with methods that can be called through
+	// reflection. The index sidetable is a list of {type code, offset} pairs
+	// sorted by type code, where the offset is an index into the method sets
+	// sidetable.
+	methodSetsSidetable      []byte
+	methodSetsIndex          [][2]uint64
+	needsMethodSetsSidetable bool
+
+	// List of {bound method wrapper, method} function pairs (as ptrtoint
+	// constants), referenced from the method sets sidetable.
+	methodFuncsSidetable      []llvm.Value
+	needsMethodFuncsSidetable bool
+
+	// List of {interface type code, type assert wrapper} pairs, see
+	// addTypeAssert.
+	typeAsserts               [][2]llvm.Value
+	needsTypeAssertsSidetable bool
+
+	// This array is stored in reflect.namedNonBasicTypesSidetable and is used
+	// at runtime to get details about a named non-basic type. Each entry
+	// consists of three integers: the type code of the underlying type, and
+	// the type name and package path (as indices into the struct names
+	// sidetable). The integers in namedNonBasicTypes are entry numbers in this
+	// array.
 	//
-	// Note that this byte buffer is not created when it is not needed
+	// Note that this array is not created when it is not needed
 	// (reflect.namedNonBasicTypesSidetable has no uses), see
 	// needsNamedTypesSidetable.
 	namedNonBasicTypesSidetable []uint64
@@ -170,10 +220,20 @@ func LowerReflect(mod llvm.Module) {
 		arrayTypes:                       make(map[string]int),
 		structTypes:                      make(map[string]int),
 		structNames:                      make(map[string]int),
+		funcTypes:                        make(map[string]int),
+		mapTypes:                         make(map[string]int),
+		fallbackTypes:                    make(map[string]int),
 		needsNamedNonBasicTypesSidetable: len(getUses(mod.NamedGlobal("reflect.namedNonBasicTypesSidetable"))) != 0,
+		needsNamedBasicTypesSidetable:    len(getUses(mod.NamedGlobal("reflect.namedBasicTypesSidetable"))) != 0,
 		needsStructTypesSidetable:        len(getUses(mod.NamedGlobal("reflect.structTypesSidetable"))) != 0,
 		needsStructNamesSidetable:        len(getUses(mod.NamedGlobal("reflect.structNamesSidetable"))) != 0,
 		needsArrayTypesSidetable:         len(getUses(mod.NamedGlobal("reflect.arrayTypesSidetable"))) != 0,
+		needsFuncTypesSidetable:          len(getUses(mod.NamedGlobal("reflect.funcTypesSidetable"))) != 0,
+		needsFuncWrappersSidetable:       len(getUses(mod.NamedGlobal("reflect.funcWrappersSidetable"))) != 0,
+		needsMapTypesSidetable:           len(getUses(mod.NamedGlobal("reflect.mapTypesSidetable"))) != 0,
+		needsMethodSetsSidetable:         len(getUses(mod.NamedGlobal("reflect.methodSetsIndexSidetable"))) != 0,
+		needsMethodFuncsSidetable:        len(getUses(mod.NamedGlobal("reflect.methodFuncsSidetable"))) != 0,
+		needsTypeAssertsSidetable:        len(getUses(mod.NamedGlobal("reflect.typeAssertsSidetable"))) != 0,
 	}
 	for _, t := range types {
 		num := state.getTypeCodeNum(t.typecode)
@@ -205,6 +265,28 @@ func LowerReflect(mod llvm.Module) {
 		}
 	}
 
+	// Collect the method sets of all types. This may add new entries to other
+	// sidetables (such as the func types sidetable), so it must be done before
+	// creating them.
+	if state.needsMethodSetsSidetable {
+		for _, t := range types {
+			state.addMethodSet(t.typecode)
+		}
+		sort.Slice(state.methodSetsIndex, func(i, j int) bool {
+			return state.methodSetsIndex[i][0] < state.methodSetsIndex[j][0]
+		})
+	}
+
+	// Collect the type assert functions of all interface types.
+	if state.needsTypeAssertsSidetable {
+		for _, t := range types {
+			state.addTypeAssert(mod, t.typecode)
+		}
+		sort.Slice(state.typeAsserts, func(i, j int) bool {
+			return state.typeAsserts[i][0].ZExtValue() < state.typeAsserts[j][0].ZExtValue()
+		})
+	}
+
 	// Only create this sidetable when it is necessary.
 	if state.needsNamedNonBasicTypesSidetable {
 		global := replaceGlobalIntWithArray(mod, "reflect.namedNonBasicTypesSidetable", state.namedNonBasicTypesSidetable)
@@ -212,6 +294,12 @@ func LowerReflect(mod llvm.Module) {
 		global.SetUnnamedAddr(true)
 		global.SetGlobalConstant(true)
 	}
+	if state.needsNamedBasicTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.namedBasicTypesSidetable", state.namedBasicTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
 	if state.needsArrayTypesSidetable {
 		global := replaceGlobalIntWithArray(mod, "reflect.arrayTypesSidetable", state.arrayTypesSidetable)
 		global.SetLinkage(llvm.InternalLinkage)
@@ -230,6 +318,60 @@ func LowerReflect(mod llvm.Module) {
 		global.SetUnnamedAddr(true)
 		global.SetGlobalConstant(true)
 	}
+	if state.needsFuncTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.funcTypesSidetable", state.funcTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsFuncWrappersSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.funcWrappersSidetable", state.funcWrappersSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsMapTypesSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.mapTypesSidetable", state.mapTypesSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsMethodSetsSidetable {
+		// The index starts with the number of entries, followed by the
+		// {type code, offset} pairs.
+		index := []uint64{uint64(len(state.methodSetsIndex))}
+		for _, entry := range state.methodSetsIndex {
+			index = append(index, entry[0], entry[1])
+		}
+		global := replaceGlobalIntWithArray(mod, "reflect.methodSetsIndexSidetable", index)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+		if hasUses(mod.NamedGlobal("reflect.methodSetsSidetable")) {
+			global := replaceGlobalIntWithArray(mod, "reflect.methodSetsSidetable", state.methodSetsSidetable)
+			global.SetLinkage(llvm.InternalLinkage)
+			global.SetUnnamedAddr(true)
+			global.SetGlobalConstant(true)
+		}
+	}
+	if state.needsMethodFuncsSidetable {
+		global := replaceGlobalIntWithArray(mod, "reflect.methodFuncsSidetable", state.methodFuncsSidetable)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
+	if state.needsTypeAssertsSidetable {
+		// Like the method sets index, this sidetable starts with the number of
+		// entries followed by the {type code, function} pairs.
+		table := []llvm.Value{llvm.ConstInt(uintptrType, uint64(len(state.typeAsserts)), false)}
+		for _, entry := range state.typeAsserts {
+			table = append(table, entry[0], entry[1])
+		}
+		global := replaceGlobalIntWithArray(mod, "reflect.typeAssertsSidetable", table)
+		global.SetLinkage(llvm.InternalLinkage)
+		global.SetUnnamedAddr(true)
+		global.SetGlobalConstant(true)
+	}
 
 	// Remove most objects created for interface and reflect lowering.
 	// They would normally be removed anyway in later passes, but not always.
@@ -237,14 +379,21 @@ func LowerReflect(mod llvm.Module) {
 	for _, typ := range types {
 		initializer := typ.typecode.Initializer()
 		references := llvm.ConstExtractValue(initializer, []uint32{0})
+		methods := llvm.ConstExtractValue(initializer, []uint32{5})
 		typ.typecode.SetInitializer(llvm.ConstNull(initializer.Type()))
-		if strings.HasPrefix(typ.name, "reflect/types.type:struct:") {
-			// Structs have a 'references' field that is not a typecode but
-			// a pointer to a runtime.structField array and therefore a
-			// bitcast. This global should be erased separately, otherwise
-			// typecode objects cannot be erased.
-			structFields := references.Operand(0)
-			structFields.EraseFromParentAsGlobal()
+		if strings.HasPrefix(typ.name, "reflect/types.type:struct:") ||
+			strings.HasPrefix(typ.name, "reflect/types.type:func:") ||
+			strings.HasPrefix(typ.name, "reflect/types.type:map:") {
+			// Structs, funcs and maps have a 'references' field that is not a
+			// typecode but a pointer to some other global (such as a
+			// runtime.structField array) and therefore a bitcast. This global
+			// should be erased separately, otherwise typecode objects cannot
+			// be erased.
+			references.Operand(0).EraseFromParentAsGlobal()
+		}
+		if methods != llvm.ConstPointerNull(methods.Type()) {
+			// Same for the list of methods callable through reflection.
+			methods.Operand(0).EraseFromParentAsGlobal()
 		}
 	}
 }
@@ -256,6 +405,7 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 	// Note: see src/reflect/type.go for bit allocations.
 	class, value := getClassAndValueFromTypeCode(typecode)
 	name := ""
+	namedTypecode := typecode
 	if class == "named" {
 		name = value
 		typecode = llvm.ConstExtractValue(typecode.Initializer(), []uint32{0})
@@ -272,7 +422,7 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 		}
 		if name != "" {
 			// This type is named, set the upper bits to the name ID.
-			num |= int64(state.getBasicNamedTypeNum(name)) << 5
+			num |= int64(state.getBasicNamedTypeNum(name, namedTypecode)) << 5
 		}
 		return big.NewInt(num << 1)
 	} else {
@@ -312,8 +462,9 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 				// We need to store full type information.
 				// First allocate a number in the named non-basic type
 				// sidetable.
-				index := len(state.namedNonBasicTypesSidetable)
-				state.namedNonBasicTypesSidetable = append(state.namedNonBasicTypesSidetable, 0)
+				index := len(state.namedNonBasicTypesSidetable) / 3
+				typeName, pkgPath := state.getTypeNameNumbers(namedTypecode)
+				state.namedNonBasicTypesSidetable = append(state.namedNonBasicTypesSidetable, 0, typeName, pkgPath)
 				state.namedNonBasicTypes[name] = index
 				// Get the typecode of the underlying type (which could be the
 				// element type in the case of pointers, for example).
@@ -326,7 +477,7 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 				// overflow due to adding types recursively in the case of
 				// linked lists (a pointer which points to a struct that
 				// contains that same pointer).
-				state.namedNonBasicTypesSidetable[index] = num.Uint64()
+				state.namedNonBasicTypesSidetable[index*3] = num.Uint64()
 				num = big.NewInt(int64(index))
 			}
 		}
@@ -340,7 +491,14 @@ func (state *typeCodeAssignmentState) getTypeCodeNum(typecode llvm.Value) *big.I
 // the type code used there in the type code.
 func (state *typeCodeAssignmentState) getNonBasicTypeCode(class string, typecode llvm.Value) *big.Int {
 	switch class {
-	case "chan", "pointer", "slice":
+	case "chan":
+		// Like a prefix-style type kind, but the lowest two bits of the upper
+		// bits contain the channel direction.
+		sub := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0})
+		dir := llvm.ConstExtractValue(typecode.Initializer(), []uint32{1}).ZExtValue()
+		num := state.getTypeCodeNum(sub)
+		return num.Lsh(num, 2).Or(num, big.NewInt(int64(dir)))
+	case "pointer", "slice":
 		// Prefix-style type kinds. The upper bits contain the element type.
 		sub := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0})
 		return state.getTypeCodeNum(sub)
@@ -352,12 +510,25 @@ func (state *typeCodeAssignmentState) getNonBasicTypeCode(class string, typecode
 		// More complicated type kind. The upper bits contain the index to the
 		// struct type in the struct types sidetable.
 		return big.NewInt(int64(state.getStructTypeNum(typecode)))
+	case "func":
+		// The upper bits contain the index to the signature in the func
+		// types sidetable.
+		return big.NewInt(int64(state.getFuncTypeNum(typecode)))
+	case "map":
+		// The upper bits contain the index to the key and value type in the
+		// map types sidetable.
+		return big.NewInt(int64(state.getMapTypeNum(typecode)))
 	default:
 		// Type has not yet been implemented, so fall back by using a unique
 		// number.
-		num := big.NewInt(int64(state.fallbackIndex))
+		name := typecode.Name()
+		if num, ok := state.fallbackTypes[name]; ok {
+			return big.NewInt(int64(num))
+		}
+		num := state.fallbackIndex
 		state.fallbackIndex++
-		return num
+		state.fallbackTypes[name] = num
+		return big.NewInt(int64(num))
 	}
 }
 
@@ -383,15 +554,38 @@ func getClassAndValueFromTypeCode(typecode llvm.Value) (class, value string) {
 // getBasicNamedTypeNum returns an appropriate (unique) number for the given
 // named type. If the name already has a number that number is returned, else a
 // new number is returned. The number is always non-zero.
-func (state *typeCodeAssignmentState) getBasicNamedTypeNum(name string) int {
+func (state *typeCodeAssignmentState) getBasicNamedTypeNum(name string, typecode llvm.Value) int {
 	if num, ok := state.namedBasicTypes[name]; ok {
 		return num
 	}
 	num := len(state.namedBasicTypes) + 1
 	state.namedBasicTypes[name] = num
+	if state.needsNamedBasicTypesSidetable {
+		typeName, pkgPath := state.getTypeNameNumbers(typecode)
+		state.namedBasicTypesSidetable = append(state.namedBasicTypesSidetable, typeName, pkgPath)
+	}
 	return num
 }
 
+// getTypeNameNumbers returns the type name (like "json.Decoder") and the
+// package path of the given named type, as indices into the struct names
+// sidetable.
+func (state *typeCodeAssignmentState) getTypeNameNumbers(typecode llvm.Value) (typeName, pkgPath uint64) {
+	nameGlobal := llvm.ConstExtractValue(typecode.Initializer(), []uint32{6})
+	nameBytes := getGlobalBytes(nameGlobal.Operand(0))
+
+	// The package path is not stored separately. Instead, it is derived from
+	// the typecode name, which contains the name of the type prefixed with the
+	// package path (for example "named:encoding/json.Decoder").
+	_, value := getClassAndValueFromTypeCode(typecode)
+	shortName := nameBytes[strings.LastIndexByte(string(nameBytes), '.')+1:]
+	path := strings.TrimSuffix(strings.TrimSuffix(value, string(shortName)), ".")
+
+	typeName = uint64(state.getStructNameNumber(nameBytes))
+	pkgPath = uint64(state.getStructNameNumber([]byte(path)))
+	return
+}
+
 // getArrayTypeNum returns the array type number, which is an index into the
 // reflect.arrayTypesSidetable or a unique number for this type if this table is
 // not used.
@@ -519,6 +713,16 @@ func (state *typeCodeAssignmentState) getStructTypeNum(typecode llvm.Value) int
 		if hasTag {
 			buf = append(buf, makeVarint(uint64(tagNumber))...)
 		}
+
+		// Add the package path for unexported fields.
+		if flagsByte&4 == 0 {
+			pkgPathGlobal := llvm.ConstExtractValue(field, []uint32{4})
+			if pkgPathGlobal == llvm.ConstPointerNull(pkgPathGlobal.Type()) {
+				panic("compiler: no package path for this unexported struct field")
+			}
+			pkgPathNumber := state.getStructNameNumber(getGlobalBytes(pkgPathGlobal.Operand(0)))
+			buf = append(buf, makeVarint(uint64(pkgPathNumber))...)
+		}
 	}
 
 	num := len(state.structTypesSidetable)
@@ -527,6 +731,194 @@ func (state *typeCodeAssignmentState) getStructTypeNum(typecode llvm.Value) int
 	return num
 }
 
+// getFuncTypeNum returns the func type number, which is an index into
+// reflect.funcTypesSidetable or a unique number for every func type if this
+// sidetable is not needed in the to-be-compiled program.
+func (state *typeCodeAssignmentState) getFuncTypeNum(typecode llvm.Value) int {
+	name := typecode.Name()
+	if num, ok := state.funcTypes[name]; ok {
+		// This func type already has an assigned type code.
+		return num
+	}
+
+	if !state.needsFuncTypesSidetable {
+		// We don't need func sidetables, so we can just assign monotonically
+		// increasing numbers to each func type.
+		num := len(state.funcTypes)
+		state.funcTypes[name] = num
+		return num
+	}
+
+	// The signature is a struct of the form
+	//     {i1 variadic, [n x typecode] params, [m x typecode] results, uintptr callWrapper, uintptr makeFuncWrapper}
+	// See makeFuncTypeSignature in the compiler.
+	signature := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0}).Operand(0).Initializer()
+	variadic := llvm.ConstExtractValue(signature, []uint32{0}).ZExtValue()
+	params := llvm.ConstExtractValue(signature, []uint32{1})
+	results := llvm.ConstExtractValue(signature, []uint32{2})
+	numParams := params.Type().ArrayLength()
+	numResults := results.Type().ArrayLength()
+
+	// An entry in the func types sidetable starts with the number of
+	// parameters (with the lowest bit indicating a variadic function) and the
+	// number of results, followed by all parameter and result types. Each of
+	// these is a varint.
+	buf := makeVarint(uint64(numParams)<<1 | variadic)
+	buf = append(buf, makeVarint(uint64(numResults))...)
+	for _, tuple := range []llvm.Value{params, results} {
+		for i := 0; i < tuple.Type().ArrayLength(); i++ {
+			typeNum := state.getTypeCodeNum(llvm.ConstExtractValue(tuple, []uint32{uint32(i)}))
+			if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+				// TODO: make this a regular error
+				panic("func parameter or result has a type code that is too big")
+			}
+			buf = append(buf, makeVarint(typeNum.Uint64())...)
+		}
+	}
+
+	// The last field is the index of the wrapper functions in the func
+	// wrappers sidetable. The list of wrappers is only created when needed,
+	// as it keeps all these wrappers alive.
+	wrapperIndex := len(state.funcWrappersSidetable) / 2
+	if state.needsFuncWrappersSidetable {
+		state.funcWrappersSidetable = append(state.funcWrappersSidetable,
+			llvm.ConstExtractValue(signature, []uint32{3}),
+			llvm.ConstExtractValue(signature, []uint32{4}))
+	}
+	buf = append(buf, makeVarint(uint64(wrapperIndex))...)
+
+	num := len(state.funcTypesSidetable)
+	state.funcTypes[name] = num
+	state.funcTypesSidetable = append(state.funcTypesSidetable, buf...)
+	return num
+}
+
+// getMapTypeNum returns the map type number, which is an index into
+// reflect.mapTypesSidetable or a unique number for every map type if this
+// sidetable is not needed in the to-be-compiled program.
+func (state *typeCodeAssignmentState) getMapTypeNum(typecode llvm.Value) int {
+	name := typecode.Name()
+	if num, ok := state.mapTypes[name]; ok {
+		// This map type already has an assigned type code.
+		return num
+	}
+
+	if !state.needsMapTypesSidetable {
+		// We don't need map sidetables, so we can just assign monotonically
+		// increasing numbers to each map type.
+		num := len(state.mapTypes)
+		state.mapTypes[name] = num
+		return num
+	}
+
+	// The map types sidetable is a sequence of {key type, value type}.
+	elems := llvm.ConstExtractValue(typecode.Initializer(), []uint32{0}).Operand(0).Initializer()
+	var buf []byte
+	for i := 0; i < 2; i++ {
+		typeNum := state.getTypeCodeNum(llvm.ConstExtractValue(elems, []uint32{uint32(i)}))
+		if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+			// TODO: make this a regular error
+			panic("map key or value has a type code that is too big")
+		}
+		buf = append(buf, makeVarint(typeNum.Uint64())...)
+	}
+
+	num := len(state.mapTypesSidetable)
+	state.mapTypes[name] = num
+	state.mapTypesSidetable = append(state.mapTypesSidetable, buf...)
+	return num
+}
+
+// addMethodSet adds the methods of the given type that can be called through
+// reflection to the method sets sidetable, if it has any.
+//
+// Each method set starts with the number of methods. For each method, it
+// stores the method name (an index into the struct names sidetable) and the
+// method signature. For types that are not interfaces, this is followed by the
+// signature with the receiver as the first parameter and the index of the
+// method in reflect.methodFuncsSidetable. All of these are varints.
+func (state *typeCodeAssignmentState) addMethodSet(typecode llvm.Value) {
+	methods := llvm.ConstExtractValue(typecode.Initializer(), []uint32{5})
+	if methods == llvm.ConstPointerNull(methods.Type()) {
+		// No methods callable through reflection.
+		return
+	}
+	methodsArray := methods.Operand(0).Initializer()
+	numMethods := methodsArray.Type().ArrayLength()
+
+	buf := makeVarint(uint64(numMethods))
+	for i := 0; i < numMethods; i++ {
+		method := llvm.ConstExtractValue(methodsArray, []uint32{uint32(i)})
+		nameBytes := getGlobalBytes(llvm.ConstExtractValue(method, []uint32{0}).Operand(0))
+		buf = append(buf, makeVarint(uint64(state.getStructNameNumber(nameBytes)))...)
+		for _, field := range []uint32{1, 2} {
+			methodType := llvm.ConstExtractValue(method, []uint32{field})
+			if methodType == llvm.ConstPointerNull(methodType.Type()) {
+				// Interface methods don't have a signature with receiver.
+				break
+			}
+			typeNum := state.getTypeCodeNum(methodType)
+			if typeNum.BitLen() > state.uintptrLen || !typeNum.IsUint64() {
+				// TODO: make this a regular error
+				panic("method has a type code that is too big")
+			}
+			buf = append(buf, makeVarint(typeNum.Uint64())...)
+			if field == 2 {
+				// This is a concrete method, add the functions to call it.
+				funcIndex := len(state.methodFuncsSidetable) / 2
+				if state.needsMethodFuncsSidetable {
+					state.methodFuncsSidetable = append(state.methodFuncsSidetable,
+						llvm.ConstExtractValue(method, []uint32{3}),
+						llvm.ConstExtractValue(method, []uint32{4}))
+				}
+				buf = append(buf, makeVarint(uint64(funcIndex))...)
+			}
+		}
+	}
+
+	typeNum := state.getTypeCodeNum(typecode)
+	state.methodSetsIndex = append(state.methodSetsIndex, [2]uint64{typeNum.Uint64(), uint64(len(state.methodSetsSidetable))})
+	state.methodSetsSidetable = append(state.methodSetsSidetable, buf...)
+}
+
+// addTypeAssert adds the type assert function of the given interface type to
+// the type asserts sidetable. Other types are ignored.
+//
+// The type assert function itself takes just the type code, which doesn't
+// match the calling convention of Go func values. Therefore a small wrapper is
+// created that also takes the (unused) context parameter, so that the reflect
+// package can call it as a func(rawType) bool.
+func (state *typeCodeAssignmentState) addTypeAssert(mod llvm.Module, typecode llvm.Value) {
+	typeAssert := llvm.ConstExtractValue(typecode.Initializer(), []uint32{4})
+	if typeAssert.IsAConstantExpr().IsNil() {
+		// Not an interface type.
+		return
+	}
+	fn := typeAssert.Operand(0)
+	wrapperName := fn.Name() + "$reflect"
+	wrapper := mod.NamedFunction(wrapperName)
+	if wrapper.IsNil() {
+		ctx := mod.Context()
+		uintptrType := fn.Type().ElementType().ParamTypes()[0]
+		i8ptrType := llvm.PointerType(ctx.Int8Type(), 0)
+		wrapperType := llvm.FunctionType(ctx.Int1Type(), []llvm.Type{uintptrType, i8ptrType}, false)
+		wrapper = llvm.AddFunction(mod, wrapperName, wrapperType)
+		wrapper.SetLinkage(llvm.InternalLinkage)
+		wrapper.SetUnnamedAddr(true)
+		builder := ctx.NewBuilder()
+		builder.SetInsertPointAtEnd(ctx.AddBasicBlock(wrapper, "entry"))
+		result := builder.CreateCall(fn, []llvm.Value{wrapper.Param(0)}, "")
+		builder.CreateRet(result)
+		builder.Dispose()
+	}
+
+	typeNum := state.getTypeCodeNum(typecode)
+	state.typeAsserts = append(state.typeAsserts, [2]llvm.Value{
+		llvm.ConstInt(typeAssert.Type(), typeNum.Uint64(), false),
+		llvm.ConstPtrToInt(wrapper, typeAssert.Type()),
+	})
+}
+
 // getStructNameNumber stores this string (name or tag) onto the struct names
 // sidetable. The format is a varint of the length of the struct, followed by
 // the raw bytes of the name. Multiple identical strings are stored under the
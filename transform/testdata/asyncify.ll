@@ -0,0 +1,74 @@
+target datalayout = "e-m:e-p:32:32-i64:64-n32:64-S128"
+target triple = "wasm32-unknown-wasi"
+
+%"internal/task.stackState" = type { i32, i32, i8 }
+
+declare void @tinygo_unwind(%"internal/task.stackState"*)
+
+declare i32 @fd_write(i32, i8*, i32, i32*) #0
+
+declare i8* @malloc(i32)
+
+declare void @llvm.memset.p0i8.i32(i8* nocapture writeonly, i8, i32, i1 immarg)
+
+; Pauses the current goroutine.
+define internal void @"internal/task.Pause"(i8* %context) {
+  call void @tinygo_unwind(%"internal/task.stackState"* null)
+  ret void
+}
+
+; Pauses indirectly, and is called indirectly.
+define internal void @"main.sleep"(i8* %context) {
+  call void @"internal/task.Pause"(i8* undef)
+  ret void
+}
+
+; Calls a function pointer, which may be main.sleep.
+define internal void @"main.callFunc"(void (i8*)* %fn, i8* %context) {
+  call void %fn(i8* undef)
+  ret void
+}
+
+; Only calls the host and intrinsics, never pauses.
+define internal void @"main.print"(i8* %buf, i8* %context) {
+  %nwritten = alloca i32
+  %result = call i32 @fd_write(i32 1, i8* %buf, i32 1, i32* %nwritten)
+  call void @llvm.memset.p0i8.i32(i8* %buf, i8 0, i32 1, i1 false)
+  ret void
+}
+
+; Calls an external (C) function, but no function that pauses is visible
+; outside of this module so it can't pause.
+define internal i8* @"main.allocate"(i8* %context) {
+  %ptr = call i8* @malloc(i32 16)
+  ret i8* %ptr
+}
+
+; A method with a pointer receiver that doesn't pause. The name works as a
+; wildcard pattern that doesn't match any pausing function.
+define internal void @"(*main.T).Print"(i8* %context) {
+  call void @"main.print"(i8* null, i8* undef)
+  ret void
+}
+
+; This name would match "(main.T).Sleep" as a wildcard pattern, so it must be
+; left out of the list.
+define internal void @"(*main.T).Sleep"(i8* %context) {
+  ret void
+}
+
+define internal void @"(main.T).Sleep"(i8* %context) {
+  call void @"main.sleep"(i8* undef)
+  ret void
+}
+
+define internal void @"main.main"(i8* %context) {
+  call void @"main.callFunc"(void (i8*)* @"main.sleep", i8* undef)
+  call void @"(*main.T).Print"(i8* undef)
+  call void @"(*main.T).Sleep"(i8* undef)
+  call void @"(main.T).Sleep"(i8* undef)
+  %ptr = call i8* @"main.allocate"(i8* undef)
+  ret void
+}
+
+attributes #0 = { "wasm-import-module"="wasi_snapshot_preview1" "wasm-import-name"="fd_write" }
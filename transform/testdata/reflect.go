@@ -39,7 +39,10 @@ func main() {
 	assertType(namedInt3(0), (1<<6)|intNum)
 
 	// Check for some "prefix-style" types.
-	assertType(make(chan int), (intNum<<5)|prefixChan)
+	// Channels also store the channel direction.
+	assertType(make(chan int), (intNum<<7)|(uintptr(reflect.BothDir)<<5)|prefixChan)
+	assertType(make(<-chan int), (intNum<<7)|(uintptr(reflect.RecvDir)<<5)|prefixChan)
+	assertType(make(chan<- int), (intNum<<7)|(uintptr(reflect.SendDir)<<5)|prefixChan)
 	assertType(new(int), (intNum<<5)|prefixPtr)
 	assertType([]int{}, (intNum<<5)|prefixSlice)
 }